@@ -0,0 +1,18 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package adiflistener receives logged contacts as raw ADIF records over UDP or
+TCP, as sent by JTDX, MSHV, JS8Call, fldigi and other programs that can forward
+each logged QSO to a logging program.
+
+A UDP datagram or a TCP stream may carry any number of records, optionally
+preceded by an ADIF header. Records are split on their <EOR> marker, honouring
+the length of every field so that a value containing "<" or "<EOR>" is not
+mistaken for a marker. On TCP a record may arrive over several reads; an
+incomplete record is discarded when the connection is closed.
+
+Parsing and logging the records is left to the caller.
+*/
+package adiflistener
@@ -0,0 +1,202 @@
+package adiflistener
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderr "errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+const (
+	defaultMaxRecordBytes = 64 * 1024
+	defaultIdleTimeout    = 10 * time.Minute
+	// maxDatagramBytes is the largest UDP payload.
+	maxDatagramBytes = 65535
+)
+
+// Config holds the settings of a listener.
+type Config struct {
+	// Protocol is "udp" or "tcp".
+	Protocol string
+	// Address is the host:port to listen on, e.g. "127.0.0.1:2333".
+	Address string
+	// MaxRecordBytes bounds a single record; a TCP connection sending a longer one is closed.
+	MaxRecordBytes int
+	// IdleTimeout is how long a TCP connection may stay silent before it is closed.
+	IdleTimeout time.Duration
+}
+
+// Handler receives the records of a running listener. Calls are made from the listener's goroutines, one per TCP
+// connection, so implementations must be safe for concurrent use.
+type Handler interface {
+	HandleRecord(record []byte, from net.Addr)
+}
+
+// Listener receives ADIF records on a UDP socket or from TCP connections.
+type Listener struct {
+	cfg Config
+	udp net.PacketConn
+	tcp net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Listen binds a listener for the given configuration, applying defaults for unset limits. Records are not read
+// until Serve is called.
+func Listen(cfg Config) (*Listener, error) {
+	const op errors.Op = "adiflistener.Listen"
+
+	cfg.Protocol = strings.ToLower(strings.TrimSpace(cfg.Protocol))
+	cfg.Address = strings.TrimSpace(cfg.Address)
+	if cfg.Address == "" {
+		return nil, errors.New(op).Msg("Listener address is required")
+	}
+	if cfg.MaxRecordBytes <= 0 {
+		cfg.MaxRecordBytes = defaultMaxRecordBytes
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+
+	l := &Listener{cfg: cfg, conns: make(map[net.Conn]struct{})}
+	var err error
+	switch cfg.Protocol {
+	case "udp":
+		l.udp, err = net.ListenPacket("udp", cfg.Address)
+	case "tcp":
+		l.tcp, err = net.Listen("tcp", cfg.Address)
+	default:
+		return nil, errors.New(op).Msgf("Unsupported listener protocol: %q", cfg.Protocol)
+	}
+	if err != nil {
+		return nil, errors.New(op).Err(err).Msgf("Failed to listen on %s %s", cfg.Protocol, cfg.Address)
+	}
+	return l, nil
+}
+
+// Addr returns the address the listener is bound to.
+func (l *Listener) Addr() net.Addr {
+	if l.udp != nil {
+		return l.udp.LocalAddr()
+	}
+	return l.tcp.Addr()
+}
+
+// Protocol returns "udp" or "tcp".
+func (l *Listener) Protocol() string {
+	return l.cfg.Protocol
+}
+
+// Serve passes every record received to the handler until the context is cancelled, and then closes the listener
+// and its connections. It returns nil once stopped by the context.
+func (l *Listener) Serve(ctx context.Context, h Handler) error {
+	stop := context.AfterFunc(ctx, l.Close)
+	defer stop()
+	defer l.Close()
+
+	var err error
+	if l.udp != nil {
+		err = l.serveUDP(h)
+	} else {
+		err = l.serveTCP(h)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// Close closes the listener and its connections. Serve does so when its context is cancelled.
+func (l *Listener) Close() {
+	if l.udp != nil {
+		_ = l.udp.Close()
+	}
+	if l.tcp != nil {
+		_ = l.tcp.Close()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for c := range l.conns {
+		_ = c.Close()
+	}
+}
+
+func (l *Listener) serveUDP(h Handler) error {
+	const op errors.Op = "adiflistener.Listener.serveUDP"
+
+	buf := make([]byte, maxDatagramBytes)
+	for {
+		n, from, err := l.udp.ReadFrom(buf)
+		if err != nil {
+			if stderr.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.New(op).Err(err)
+		}
+		// Each datagram stands alone: a record split over two datagrams is incomplete in both.
+		scanRecords(bytes.NewReader(buf[:n]), l.cfg.MaxRecordBytes, from, h)
+	}
+}
+
+func (l *Listener) serveTCP(h Handler) error {
+	const op errors.Op = "adiflistener.Listener.serveTCP"
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.tcp.Accept()
+		if err != nil {
+			if stderr.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errors.New(op).Err(err)
+		}
+
+		l.mu.Lock()
+		l.conns[conn] = struct{}{}
+		l.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				l.mu.Lock()
+				delete(l.conns, conn)
+				l.mu.Unlock()
+				_ = conn.Close()
+			}()
+			scanRecords(&idleReader{conn: conn, timeout: l.cfg.IdleTimeout}, l.cfg.MaxRecordBytes, conn.RemoteAddr(), h)
+		}()
+	}
+}
+
+// scanRecords passes each record read from r to the handler, until r is exhausted or fails, or a record is too long.
+func scanRecords(r io.Reader, maxBytes int, from net.Addr, h Handler) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, min(4096, maxBytes)), maxBytes)
+	sc.Split(ScanRecords)
+	for sc.Scan() {
+		h.HandleRecord(bytes.Clone(sc.Bytes()), from)
+	}
+}
+
+// idleReader reads from a connection, failing if nothing arrives within the timeout.
+type idleReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return 0, err
+	}
+	return r.conn.Read(p)
+}
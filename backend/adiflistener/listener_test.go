@@ -0,0 +1,162 @@
+package adiflistener
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a Handler that records every record it receives.
+type recorder struct {
+	mu      sync.Mutex
+	records []string
+	ch      chan string
+}
+
+func newRecorder() *recorder {
+	return &recorder{ch: make(chan string, 16)}
+}
+
+func (r *recorder) HandleRecord(record []byte, _ net.Addr) {
+	r.mu.Lock()
+	r.records = append(r.records, string(record))
+	r.mu.Unlock()
+	r.ch <- string(record)
+}
+
+func (r *recorder) wait(t *testing.T) string {
+	t.Helper()
+	select {
+	case rec := <-r.ch:
+		return rec
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a record")
+		return ""
+	}
+}
+
+// serve starts the listener and returns a function that stops it and waits for Serve to return.
+func serve(t *testing.T, l *Listener, h Handler) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Serve(ctx, h) }()
+	return func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Serve() error = %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Serve() did not return after cancellation")
+		}
+	}
+}
+
+func TestListen_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no address", Config{Protocol: "udp"}},
+		{"bad protocol", Config{Protocol: "sctp", Address: "127.0.0.1:0"}},
+		{"bad address", Config{Protocol: "tcp", Address: "not an address"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if l, err := Listen(tt.cfg); err == nil {
+				l.Close()
+				t.Error("Listen() should fail")
+			}
+		})
+	}
+}
+
+func TestListener_UDP(t *testing.T) {
+	l, err := Listen(Config{Protocol: "UDP", Address: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	rec := newRecorder()
+	stop := serve(t, l, rec)
+	defer stop()
+
+	conn, err := net.Dial("udp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err = conn.Write([]byte("<call:4>G4XY<band:3>20m<eor><call:5>DL1AB<band:3>40m<eor>")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := rec.wait(t); got != "<call:4>G4XY<band:3>20m<eor>" {
+		t.Errorf("first record = %q", got)
+	}
+	if got := rec.wait(t); got != "<call:5>DL1AB<band:3>40m<eor>" {
+		t.Errorf("second record = %q", got)
+	}
+}
+
+func TestListener_TCP(t *testing.T) {
+	l, err := Listen(Config{Protocol: "tcp", Address: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	rec := newRecorder()
+	stop := serve(t, l, rec)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// A record split over two writes is reassembled.
+	if _, err = conn.Write([]byte("<call:4>G4")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err = conn.Write([]byte("XY<band:3>20m<EOR>\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := rec.wait(t); got != "<call:4>G4XY<band:3>20m<EOR>" {
+		t.Errorf("record = %q", got)
+	}
+
+	// Stopping closes the open connection.
+	stop()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err = conn.Read(make([]byte, 1)); err == nil {
+		t.Error("the connection should be closed when the listener stops")
+	}
+}
+
+func TestListener_TCPRecordTooLong(t *testing.T) {
+	l, err := Listen(Config{Protocol: "tcp", Address: "127.0.0.1:0", MaxRecordBytes: 64})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	rec := newRecorder()
+	stop := serve(t, l, rec)
+	defer stop()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err = conn.Write([]byte("<comment:100>")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	_, _ = conn.Write(make([]byte, 100))
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	if ne, ok := err.(net.Error); err == nil || (ok && ne.Timeout()) {
+		t.Errorf("a connection sending a record that is too long should be closed, got %v", err)
+	}
+}
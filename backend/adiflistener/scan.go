@@ -0,0 +1,60 @@
+package adiflistener
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// ScanRecords is a bufio.SplitFunc that returns each ADIF record, up to and including its <EOR> marker. A header,
+// ended by <EOH>, and anything between records other than fields is skipped. Field values are skipped by their
+// declared length, so markers are only recognised outside values.
+func ScanRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start, i := 0, 0
+	for {
+		lt := bytes.IndexByte(data[i:], '<')
+		if lt < 0 {
+			break
+		}
+		lt += i
+		gt := bytes.IndexByte(data[lt:], '>')
+		if gt < 0 {
+			break // the tag is incomplete
+		}
+		gt += lt
+
+		name, spec, hasLen := bytes.Cut(data[lt+1:gt], []byte(":"))
+		name = bytes.TrimSpace(name)
+		switch {
+		case hasLen:
+			lenStr, _, _ := bytes.Cut(spec, []byte(":")) // the optional data type follows the length
+			n, convErr := strconv.Atoi(string(bytes.TrimSpace(lenStr)))
+			if convErr != nil || n < 0 {
+				i = gt + 1 // not a field; skip it as text
+				continue
+			}
+			end := gt + 1 + n
+			if end > len(data) {
+				return needMore(data, start, atEOF)
+			}
+			i = end
+		case bytes.EqualFold(name, []byte("EOR")):
+			if len(bytes.TrimSpace(data[start:lt])) > 0 {
+				return gt + 1, data[start : gt+1], nil
+			}
+			start, i = gt+1, gt+1 // an empty record
+		case bytes.EqualFold(name, []byte("EOH")):
+			start, i = gt+1, gt+1 // drop the header
+		default:
+			i = gt + 1
+		}
+	}
+	return needMore(data, start, atEOF)
+}
+
+// needMore discards what has been skipped and asks for more data, or drops an incomplete record at the end.
+func needMore(data []byte, start int, atEOF bool) (int, []byte, error) {
+	if atEOF {
+		return len(data), nil, nil
+	}
+	return start, nil, nil
+}
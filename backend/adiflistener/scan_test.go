@@ -0,0 +1,65 @@
+package adiflistener
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func scanAll(t *testing.T, input string, chunk int) []string {
+	t.Helper()
+	sc := bufio.NewScanner(&chunkReader{data: []byte(input), chunk: chunk})
+	sc.Split(ScanRecords)
+	var out []string
+	for sc.Scan() {
+		out = append(out, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	return out
+}
+
+// chunkReader returns its data a few bytes at a time, as a TCP stream may.
+type chunkReader struct {
+	data  []byte
+	chunk int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := min(r.chunk, len(p), len(r.data))
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestScanRecords(t *testing.T) {
+	rec1 := "<call:5>DL1AB <band:3>20m <mode:3>FT8 <eor>"
+	rec2 := "<CALL:4>G4XY<BAND:3>40m<MODE:2>CW<EOR>"
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"single record", rec1, []string{rec1}},
+		{"two records", rec1 + "\n" + rec2 + "\n", []string{rec1, "\n" + rec2}},
+		{"header skipped", "WSJT-X ADIF Export<adif_ver:5>3.1.0<programid:6>WSJT-X<EOH>\n" + rec2, []string{"\n" + rec2}},
+		{"marker inside a value", "<comment:11>send <EOR>!<call:4>G4XY<eor>", []string{"<comment:11>send <EOR>!<call:4>G4XY<eor>"}},
+		{"field with a type", "<qso_date:8:D>20261018<call:4>G4XY<EOR>", []string{"<qso_date:8:D>20261018<call:4>G4XY<EOR>"}},
+		{"empty records skipped", "<EOR>  <eor>" + rec2, []string{rec2}},
+		{"incomplete record dropped", rec2 + "<call:4>K1A", []string{rec2}},
+		{"nothing", "", nil},
+	}
+	for _, tt := range tests {
+		for _, chunk := range []int{1, 7, 4096} {
+			got := scanAll(t, tt.input, chunk)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("%s (chunk %d): got %q, want %q", tt.name, chunk, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package dxcluster
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+const (
+	defaultDialTimeout       = 10 * time.Second
+	defaultReconnectDelay    = 5 * time.Second
+	defaultMaxReconnectDelay = 5 * time.Minute
+	defaultIdleTimeout       = 10 * time.Minute
+	readBufferSize           = 4096
+	// maxPendingBytes bounds a partial line, so a misbehaving node cannot grow the buffer indefinitely.
+	maxPendingBytes = 8192
+)
+
+// State is the connection state of the client.
+type State string
+
+const (
+	StateDisconnected State = "disconnected"
+	StateConnecting   State = "connecting"
+	StateConnected    State = "connected" // logged in and receiving spots
+)
+
+// Config holds the connection settings for a cluster node.
+type Config struct {
+	Host     string
+	Port     int
+	Callsign string
+	Password string
+	// Commands are sent to the node, in order, once the login has completed (e.g., "set/filter", "sh/dx 20").
+	Commands          []string
+	DialTimeout       time.Duration
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// IdleTimeout is how long the connection may stay silent before it is assumed dead and re-established.
+	IdleTimeout time.Duration
+}
+
+// Handler receives the spots and state changes of a running client. Calls are made from the client's goroutine,
+// so implementations should return quickly.
+type Handler interface {
+	HandleSpot(spot Spot)
+	HandleState(state State, err error)
+}
+
+// Client is a DX cluster telnet client.
+type Client struct {
+	cfg Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// New returns a client for the given configuration, applying defaults for unset durations.
+func New(cfg Config) (*Client, error) {
+	const op errors.Op = "dxcluster.New"
+
+	cfg.Host = strings.TrimSpace(cfg.Host)
+	cfg.Callsign = strings.ToUpper(strings.TrimSpace(cfg.Callsign))
+	if cfg.Host == "" {
+		return nil, errors.New(op).Msg("Cluster host is required")
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return nil, errors.New(op).Msgf("Invalid cluster port: %d", cfg.Port)
+	}
+	if len(cfg.Callsign) < 3 {
+		return nil, errors.New(op).Msg("A callsign of at least 3 characters is required to log in")
+	}
+
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+	if cfg.MaxReconnectDelay < cfg.ReconnectDelay {
+		cfg.MaxReconnectDelay = max(defaultMaxReconnectDelay, cfg.ReconnectDelay)
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+
+	return &Client{cfg: cfg}, nil
+}
+
+// Run connects to the node and delivers spots to the handler until the context is cancelled, reconnecting with an
+// exponential backoff whenever the connection is lost. It always returns the context's error.
+func (c *Client) Run(ctx context.Context, h Handler) error {
+	delay := c.cfg.ReconnectDelay
+	for {
+		h.HandleState(StateConnecting, nil)
+
+		loggedIn, err := c.session(ctx, h)
+		if ctx.Err() != nil {
+			h.HandleState(StateDisconnected, nil)
+			return ctx.Err()
+		}
+		h.HandleState(StateDisconnected, err)
+
+		// A session that got as far as logging in resets the backoff.
+		if loggedIn {
+			delay = c.cfg.ReconnectDelay
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, c.cfg.MaxReconnectDelay)
+	}
+}
+
+// Send writes a command to the node, e.g. to change the spot filter while connected.
+func (c *Client) Send(cmd string) error {
+	const op errors.Op = "dxcluster.Client.Send"
+
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" || strings.ContainsAny(cmd, "\r\n") {
+		return errors.New(op).Msg("Invalid cluster command")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return errors.New(op).Msg("Not connected to the cluster")
+	}
+	if err := c.writeLine(cmd); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// session runs a single connection to the node. The bool return reports whether the login completed.
+func (c *Client) session(ctx context.Context, h Handler) (bool, error) {
+	const op errors.Op = "dxcluster.Client.session"
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	dialer := net.Dialer{Timeout: c.cfg.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+
+	// Unblock the read below as soon as the context is cancelled.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer func() {
+		stop()
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	l := &login{cfg: c.cfg}
+	buf := make([]byte, readBufferSize)
+	var pending []byte
+	for {
+		if err = conn.SetReadDeadline(time.Now().Add(c.cfg.IdleTimeout)); err != nil {
+			return l.done, errors.New(op).Err(err)
+		}
+		n, rerr := conn.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		for {
+			i := bytes.IndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			line := string(bytes.TrimRight(pending[:i], "\r"))
+			pending = pending[i+1:]
+
+			spot, isSpot := ParseSpot(line)
+			if _, err = c.advanceLogin(l, line, isSpot, h); err != nil {
+				return l.done, errors.New(op).Err(err)
+			}
+			if isSpot {
+				h.HandleSpot(spot)
+			}
+		}
+
+		// Prompts are not newline-terminated, so check the partial line as well. A prompt that has been answered
+		// is dropped, so it does not prefix the next line.
+		if len(pending) > 0 {
+			acted, lerr := c.advanceLogin(l, string(pending), false, h)
+			if lerr != nil {
+				return l.done, errors.New(op).Err(lerr)
+			}
+			if acted || len(pending) > maxPendingBytes {
+				pending = pending[:0]
+			}
+		}
+
+		if rerr != nil {
+			return l.done, errors.New(op).Err(rerr)
+		}
+	}
+}
+
+// advanceLogin feeds a line (or a partial, unterminated line) to the login state machine and sends whatever it
+// calls for. The bool return reports whether the text was acted upon.
+func (c *Client) advanceLogin(l *login, text string, isSpot bool, h Handler) (bool, error) {
+	send, acted, completed := l.next(text, isSpot)
+	if !acted {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	for _, s := range send {
+		if err := c.writeLine(s); err != nil {
+			c.mu.Unlock()
+			return true, err
+		}
+	}
+	c.mu.Unlock()
+
+	if completed {
+		h.HandleState(StateConnected, nil)
+	}
+	return true, nil
+}
+
+// writeLine sends a single line to the node. The caller must hold c.mu.
+func (c *Client) writeLine(s string) error {
+	if c.conn == nil {
+		return net.ErrClosed
+	}
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.cfg.DialTimeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write([]byte(s + "\r\n"))
+	return err
+}
+
+// login tracks the progress of logging in to a node. The node software differs in its prompts, so the callsign is
+// sent on anything that looks like a login prompt, the password when asked for, and the commands on the first node
+// prompt (a line ending in '>') or the first spot, whichever comes first.
+type login struct {
+	cfg          Config
+	sentCall     bool
+	sentPassword bool
+	done         bool
+}
+
+func (l *login) next(text string, isSpot bool) (send []string, acted, completed bool) {
+	if l.done {
+		return nil, false, false
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(text))
+	switch {
+	case !l.sentCall && (strings.HasSuffix(lower, "login:") || strings.HasSuffix(lower, "call:") ||
+		strings.Contains(lower, "enter your call")):
+		l.sentCall = true
+		return []string{l.cfg.Callsign}, true, false
+	case l.sentCall && !l.sentPassword && strings.HasSuffix(lower, "password:"):
+		l.sentPassword = true
+		return []string{l.cfg.Password}, true, false
+	case l.sentCall && (isSpot || strings.HasSuffix(lower, ">")):
+		l.done = true
+		return l.cfg.Commands, true, true
+	}
+
+	return nil, false, false
+}
@@ -0,0 +1,271 @@
+package dxcluster
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a Handler that records everything it receives.
+type recorder struct {
+	mu     sync.Mutex
+	spots  []Spot
+	states []State
+	spotCh chan Spot
+}
+
+func newRecorder() *recorder {
+	return &recorder{spotCh: make(chan Spot, 16)}
+}
+
+func (r *recorder) HandleSpot(spot Spot) {
+	r.mu.Lock()
+	r.spots = append(r.spots, spot)
+	r.mu.Unlock()
+	r.spotCh <- spot
+}
+
+func (r *recorder) HandleState(state State, _ error) {
+	r.mu.Lock()
+	r.states = append(r.states, state)
+	r.mu.Unlock()
+}
+
+func (r *recorder) waitSpot(t *testing.T) Spot {
+	t.Helper()
+	select {
+	case s := <-r.spotCh:
+		return s
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a spot")
+		return Spot{}
+	}
+}
+
+// standInNode is a minimal cluster node: it prompts for a login and optional password, records the lines it is
+// sent, and lets the test push lines to the connected client.
+type standInNode struct {
+	ln       net.Listener
+	password bool
+	received chan string
+	conns    chan net.Conn
+}
+
+func newStandInNode(t *testing.T, password bool) *standInNode {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	n := &standInNode{ln: ln, password: password, received: make(chan string, 32), conns: make(chan net.Conn, 4)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go n.serve()
+	return n
+}
+
+func (n *standInNode) port() int {
+	return n.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (n *standInNode) serve() {
+	for {
+		conn, err := n.ln.Accept()
+		if err != nil {
+			return
+		}
+		go n.handle(conn)
+	}
+}
+
+func (n *standInNode) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	_, _ = conn.Write([]byte("Welcome to the stand-in node\r\nlogin: "))
+	call, err := r.ReadString('\n')
+	if err != nil {
+		return
+	}
+	n.received <- strings.TrimSpace(call)
+
+	if n.password {
+		_, _ = conn.Write([]byte("password: "))
+		pw, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n.received <- strings.TrimSpace(pw)
+	}
+
+	_, _ = conn.Write([]byte("Hello " + strings.TrimSpace(call) + "\r\n" + strings.TrimSpace(call) + " de STANDIN >"))
+	n.conns <- conn
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n.received <- strings.TrimSpace(line)
+	}
+}
+
+func (n *standInNode) expect(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-n.received:
+		if got != want {
+			t.Fatalf("node received %q, want %q", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for the node to receive %q", want)
+	}
+}
+
+func (n *standInNode) accept(t *testing.T) net.Conn {
+	t.Helper()
+	select {
+	case c := <-n.conns:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the client to log in")
+		return nil
+	}
+}
+
+func runClient(t *testing.T, cfg Config, h Handler) (*Client, context.CancelFunc, <-chan error) {
+	t.Helper()
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx, h) }()
+	t.Cleanup(cancel)
+	return c, cancel, done
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing host", Config{Port: 7300, Callsign: "G4ABC"}},
+		{"invalid port", Config{Host: "localhost", Port: 0, Callsign: "G4ABC"}},
+		{"missing callsign", Config{Host: "localhost", Port: 7300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
+
+func TestClient_LoginCommandsAndSpots(t *testing.T) {
+	node := newStandInNode(t, true)
+	rec := newRecorder()
+
+	c, cancel, done := runClient(t, Config{
+		Host:     "127.0.0.1",
+		Port:     node.port(),
+		Callsign: "g4abc",
+		Password: "secret",
+		Commands: []string{"set/filter", "sh/dx 5"},
+	}, rec)
+
+	node.expect(t, "G4ABC")
+	node.expect(t, "secret")
+	conn := node.accept(t)
+	node.expect(t, "set/filter")
+	node.expect(t, "sh/dx 5")
+
+	_, _ = conn.Write([]byte("DX de SP5XYZ:     14025.0  JA1ABC       CW 599                         1234Z JO91\r\n"))
+	if spot := rec.waitSpot(t); spot.DxCall != "JA1ABC" || spot.Frequency != 14025.0 {
+		t.Errorf("spot = %+v", spot)
+	}
+
+	if err := c.Send("sh/dx 10"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	node.expect(t, "sh/dx 10")
+	if err := c.Send("bye\r\nsh/dx"); err == nil {
+		t.Error("Send() should reject multi-line commands")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run() = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	want := []State{StateConnecting, StateConnected, StateDisconnected}
+	if len(rec.states) != len(want) {
+		t.Fatalf("states = %v, want %v", rec.states, want)
+	}
+	for i := range want {
+		if rec.states[i] != want[i] {
+			t.Errorf("states = %v, want %v", rec.states, want)
+		}
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	node := newStandInNode(t, false)
+	rec := newRecorder()
+
+	runClient(t, Config{
+		Host:           "127.0.0.1",
+		Port:           node.port(),
+		Callsign:       "G4ABC",
+		ReconnectDelay: 10 * time.Millisecond,
+	}, rec)
+
+	node.expect(t, "G4ABC")
+	first := node.accept(t)
+	_ = first.Close()
+
+	node.expect(t, "G4ABC")
+	second := node.accept(t)
+	_, _ = second.Write([]byte("DX de W1AW:  28400.0  JA1ABC  SSB  1200Z\r\n"))
+	if spot := rec.waitSpot(t); spot.DxCall != "JA1ABC" {
+		t.Errorf("spot = %+v", spot)
+	}
+}
+
+func TestClient_IdleTimeout(t *testing.T) {
+	node := newStandInNode(t, false)
+	rec := newRecorder()
+
+	runClient(t, Config{
+		Host:           "127.0.0.1",
+		Port:           node.port(),
+		Callsign:       "G4ABC",
+		ReconnectDelay: 10 * time.Millisecond,
+		IdleTimeout:    100 * time.Millisecond,
+	}, rec)
+
+	// The node never sends anything after the login, so the client must give up on it and log in again.
+	node.expect(t, "G4ABC")
+	node.accept(t)
+	node.expect(t, "G4ABC")
+}
+
+func TestClient_SendWhenDisconnected(t *testing.T) {
+	c, err := New(Config{Host: "127.0.0.1", Port: 7300, Callsign: "G4ABC"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = c.Send("sh/dx"); err == nil {
+		t.Error("Send() should fail when not connected")
+	}
+}
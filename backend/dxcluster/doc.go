@@ -0,0 +1,17 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package dxcluster implements a telnet client for DX clusters running DXSpider,
+AR-Cluster, CC-Cluster or compatible software.
+
+The Client logs in with the configured callsign (and password, if the node asks
+for one), sends the configured filter commands once the node prompt appears, and
+then parses every "DX de" line into a Spot. Lost or idle connections are
+re-established with an exponential backoff until the context passed to Run is
+cancelled.
+
+Enrichment of spots (DXCC entity, worked/needed status) is left to the caller.
+*/
+package dxcluster
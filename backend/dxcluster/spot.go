@@ -0,0 +1,56 @@
+package dxcluster
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// spotRegex matches a DX spot, in any case, as broadcast by the common cluster node software, e.g.:
+//
+//	DX de SP5XYZ:     14025.0  G4ABC        CW 599 tnx QSO                1234Z JO91
+var spotRegex = regexp.MustCompile(`(?i)^DX DE ([A-Z0-9/#\-]+):?\s+(\d+(?:\.\d+)?)\s+([A-Z0-9/]+)\s+(.*?)\s*(\d{4})Z(?:\s+([A-R]{2}\d{2}))?\s*$`)
+
+// spotStartRegex finds the start of a spot in a line.
+var spotStartRegex = regexp.MustCompile(`(?i)DX DE `)
+
+// Spot is a single parsed DX spot.
+type Spot struct {
+	Spotter   string  `json:"spotter"`
+	Frequency float64 `json:"frequency"` // kHz
+	DxCall    string  `json:"dx_call"`
+	Comment   string  `json:"comment"`
+	Time      string  `json:"time"`    // HHMM, UTC
+	Locator   string  `json:"locator"` // the spotter's locator, if sent by the node
+	Raw       string  `json:"raw"`
+}
+
+// ParseSpot parses a "DX de" line. The bool return is false if the line is not a spot.
+func ParseSpot(line string) (Spot, bool) {
+	line = strings.TrimRight(line, "\r\n\a")
+	// Some nodes do not terminate their prompt, in which case it prefixes the next spot. The line is matched as
+	// received, as it may hold bytes that would change length if case-mapped.
+	if loc := spotStartRegex.FindStringIndex(line); loc != nil && loc[0] > 0 {
+		line = line[loc[0]:]
+	}
+	m := spotRegex.FindStringSubmatch(line)
+	if m == nil {
+		return Spot{}, false
+	}
+
+	freq, err := strconv.ParseFloat(m[2], 64)
+	if err != nil || freq <= 0 {
+		return Spot{}, false
+	}
+
+	// The comment keeps its original case.
+	return Spot{
+		Spotter:   strings.ToUpper(strings.TrimRight(m[1], "-#")),
+		Frequency: freq,
+		DxCall:    strings.ToUpper(m[3]),
+		Comment:   strings.TrimSpace(m[4]),
+		Time:      m[5],
+		Locator:   strings.ToUpper(m[6]),
+		Raw:       line,
+	}, true
+}
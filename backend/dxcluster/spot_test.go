@@ -0,0 +1,69 @@
+package dxcluster
+
+import "testing"
+
+func TestParseSpot(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Spot
+		ok   bool
+	}{
+		{
+			name: "dxspider with locator",
+			line: "DX de SP5XYZ:     14025.0  G4ABC        CW 599 tnx QSO                1234Z JO91\r\n",
+			want: Spot{Spotter: "SP5XYZ", Frequency: 14025.0, DxCall: "G4ABC", Comment: "CW 599 tnx QSO", Time: "1234", Locator: "JO91"},
+			ok:   true,
+		},
+		{
+			name: "skimmer spotter without locator",
+			line: "DX de DK9IP-#:    7012.3  EA8/G4ABC    CW 18 dB 24 WPM CQ             0815Z",
+			want: Spot{Spotter: "DK9IP", Frequency: 7012.3, DxCall: "EA8/G4ABC", Comment: "CW 18 dB 24 WPM CQ", Time: "0815"},
+			ok:   true,
+		},
+		{
+			name: "empty comment",
+			line: "DX de W1AW:  28400.0  JA1ABC  1200Z",
+			want: Spot{Spotter: "W1AW", Frequency: 28400.0, DxCall: "JA1ABC", Time: "1200"},
+			ok:   true,
+		},
+		{
+			name: "prefixed by an unterminated prompt",
+			line: "G4ABC de GB7XYZ 18-Oct-2026 1200Z dxspider >DX de W1AW:  21074.0  JA1ABC  FT8 -10  1201Z",
+			want: Spot{Spotter: "W1AW", Frequency: 21074.0, DxCall: "JA1ABC", Comment: "FT8 -10", Time: "1201"},
+			ok:   true,
+		},
+		{
+			name: "lower-case",
+			line: "dx de w1aw:  14025.0  g4abc  cw tnx  1200Z fn31",
+			want: Spot{Spotter: "W1AW", Frequency: 14025.0, DxCall: "G4ABC", Comment: "cw tnx", Time: "1200", Locator: "FN31"},
+			ok:   true,
+		},
+		{
+			// Latin-1 bytes are not valid UTF-8 and must not shift the comment.
+			name: "non-UTF-8 comment",
+			line: "\xe9\xe9>DX de F5XYZ:  14025.0  G4ABC  CW caf\xe9 \xe9t\xe9  1200Z",
+			want: Spot{Spotter: "F5XYZ", Frequency: 14025.0, DxCall: "G4ABC", Comment: "CW caf\xe9 \xe9t\xe9", Time: "1200"},
+			ok:   true,
+		},
+		{name: "wwv", line: "WWV de W0MU <18>:   SFI=150, A=5, K=1, No Storms -> No Storms", ok: false},
+		{name: "announcement", line: "To ALL de G4ABC: contest starts at 1200Z", ok: false},
+		{name: "no time", line: "DX de W1AW:  14025.0  G4ABC  CW", ok: false},
+		{name: "empty", line: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseSpot(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ParseSpot() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			got.Raw = ""
+			if got != tt.want {
+				t.Errorf("ParseSpot() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package facade
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/Station-Manager/adif"
+	"github.com/Station-Manager/errors"
+)
+
+// AdifImportResult is the outcome of an ADIF import.
+type AdifImportResult struct {
+	Records    int                `json:"records"`
+	Imported   int                `json:"imported"`
+	Duplicates int                `json:"duplicates"` // already in the logbook
+	Rejected   []AdifImportReject `json:"rejected"`
+}
+
+// AdifImportReject is a record that could not be imported.
+type AdifImportReject struct {
+	Record int    `json:"record"` // 1-based position in the file
+	Call   string `json:"call"`
+	Reason string `json:"reason"`
+}
+
+// ImportAdif logs the QSOs of an ADIF file to the current logbook. Each record is converted as the ADIF listeners
+// convert a received record, and QSOs already in the logbook are skipped, so a file can be imported again after
+// adding to it. A record that cannot be logged is reported and does not stop the import.
+func (s *Service) ImportAdif(data string) (AdifImportResult, error) {
+	const op errors.Op = "facade.Service.ImportAdif"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return AdifImportResult{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return AdifImportResult{}, errors.Root(err)
+	}
+
+	parsed, err := adif.Marshal([]byte(data))
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to parse ADIF file")
+		return AdifImportResult{}, errors.Root(err)
+	}
+
+	result := AdifImportResult{Records: len(parsed.Records), Rejected: make([]AdifImportReject, 0)}
+	for i, rec := range parsed.Records {
+		if err = s.dbContext().Err(); err != nil {
+			return result, errors.Root(errors.New(op).Err(err).Msg("Import cancelled"))
+		}
+
+		logged, rerr := s.importAdifRecord(rec)
+		switch {
+		case rerr != nil:
+			result.Rejected = append(result.Rejected, AdifImportReject{
+				Record: i + 1,
+				Call:   rec.Call,
+				Reason: errors.Root(rerr).Error(),
+			})
+		case logged:
+			result.Imported++
+		default:
+			result.Duplicates++
+		}
+	}
+
+	s.LoggerService.InfoWith().Int("records", result.Records).Int("imported", result.Imported).
+		Int("duplicates", result.Duplicates).Int("rejected", len(result.Rejected)).Msg("ADIF file imported")
+	return result, nil
+}
+
+// importAdifRecord logs the QSO of a record unless the logbook already holds it.
+func (s *Service) importAdifRecord(rec adif.Record) (bool, error) {
+	const op errors.Op = "facade.Service.importAdifRecord"
+
+	qso, err := s.qsoFromAdifRecord(rec)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	return s.logUnlessLogged(qso)
+}
+
+// ExportAdif returns the QSOs in the current logbook that match the search as an ADIF file, oldest first. A search
+// without a limit exports every matching QSO.
+func (s *Service) ExportAdif(search QsoSearch) (string, error) {
+	const op errors.Op = "facade.Service.ExportAdif"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return "", errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return "", errors.Root(err)
+	}
+
+	unlimited := search.Limit == 0
+	if err := search.normalize(); err != nil {
+		return "", errors.Root(errors.New(op).Err(err))
+	}
+	if unlimited {
+		// SQLite takes a negative limit as no limit.
+		search.Limit = -1
+	}
+
+	qsos, err := s.searchQsos(search)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSOs for export")
+		return "", errors.Root(err)
+	}
+	slices.Reverse(qsos)
+
+	file := adif.Adif{Records: make([]adif.Record, 0, len(qsos))}
+	for _, qso := range qsos {
+		qso.Freq = adifFreqMHz(qso.Freq)
+		qso.FreqRx = adifFreqMHz(qso.FreqRx)
+		file.Records = append(file.Records, adif.QsoToRecord(qso))
+	}
+	return file.String(), nil
+}
+
+// adifFreqMHz converts a frequency in Hz, as the log keeps it, to MHz for an ADIF file. The ADIF module takes a
+// value of 7 or 8 characters as Hz and converts it itself, to three decimals and only below 100 MHz, so the value is
+// given to the Hz with enough digits to be left alone.
+func adifFreqMHz(hz string) string {
+	n, ok := catFreqHz(hz)
+	if !ok {
+		return hz
+	}
+	mhz := strconv.FormatFloat(float64(n)/1e6, 'f', 6, 64)
+	for len(mhz) < 9 {
+		mhz += "0"
+	}
+	return mhz
+}
@@ -0,0 +1,108 @@
+package facade
+
+import (
+	"strings"
+	"testing"
+)
+
+// =============================================================================
+// Import Tests
+// =============================================================================
+
+func TestImportAdif(t *testing.T) {
+	s := createAdifTestService(t)
+
+	file := "Exported by a test\n<adif_ver:5>3.1.4<eoh>\n" + testAdifRecord + "\n" +
+		"<call:4>G4XY<freq:3>abc<band:3>20m<mode:2>CW<qso_date:8>20261018<time_on:4>1200<eor>\n" +
+		"<call:4>G4XY<band:3>40m<freq:5>7.025<mode:2>CW<qso_date:8>20261018<time_on:4>0800" +
+		"<rst_sent:3>599<rst_rcvd:3>579<country:7>England<dxcc:3>223<eor>\n"
+
+	result, err := s.ImportAdif(file)
+	if err != nil {
+		t.Fatalf("ImportAdif() error = %v", err)
+	}
+	if result.Records != 3 || result.Imported != 2 || result.Duplicates != 0 || len(result.Rejected) != 1 {
+		t.Fatalf("ImportAdif() = %+v, want 3 records, 2 imported, 1 rejected", result)
+	}
+	if r := result.Rejected[0]; r.Record != 2 || r.Call != "G4XY" || r.Reason == "" {
+		t.Errorf("Rejected[0] = %+v, want record 2 of G4XY with a reason", r)
+	}
+
+	// Importing the file again logs nothing new.
+	result, err = s.ImportAdif(file)
+	if err != nil {
+		t.Fatalf("ImportAdif(again) error = %v", err)
+	}
+	if result.Imported != 0 || result.Duplicates != 2 {
+		t.Errorf("ImportAdif(again) = %+v, want 2 duplicates", result)
+	}
+	if n := adifQsoCount(t, s); n != 2 {
+		t.Errorf("%d QSOs logged, want 2", n)
+	}
+}
+
+func TestImportAdif_NotStarted(t *testing.T) {
+	s := createTestService()
+	if _, err := s.ImportAdif(testAdifRecord); err == nil {
+		t.Error("ImportAdif() error = nil, want an error")
+	}
+}
+
+// =============================================================================
+// Export Tests
+// =============================================================================
+
+func TestExportAdif(t *testing.T) {
+	s := createAdifTestService(t)
+	insertTestQso(t, s, "G4XY", "20m", "SSB")
+	insertTestQso(t, s, "DL1ABC", "40m", "CW")
+
+	data, err := s.ExportAdif(QsoSearch{})
+	if err != nil {
+		t.Fatalf("ExportAdif() error = %v", err)
+	}
+	if !strings.Contains(data, "<EOH>") || strings.Count(data, "<EOR>") != 2 {
+		t.Fatalf("ExportAdif() = %q, want a header and 2 records", data)
+	}
+	if g, d := strings.Index(data, "G4XY"), strings.Index(data, "DL1ABC"); g < 0 || d < g {
+		t.Errorf("ExportAdif() = %q, want G4XY before DL1ABC", data)
+	}
+	if !strings.Contains(data, "14.250000") {
+		t.Errorf("ExportAdif() = %q, want the frequency in MHz", data)
+	}
+
+	data, err = s.ExportAdif(QsoSearch{Band: "40m"})
+	if err != nil {
+		t.Fatalf("ExportAdif(40m) error = %v", err)
+	}
+	if strings.Count(data, "<EOR>") != 1 || !strings.Contains(data, "DL1ABC") {
+		t.Errorf("ExportAdif(40m) = %q, want only DL1ABC", data)
+	}
+
+	// An exported file imports as the QSOs already logged.
+	result, err := s.ImportAdif(data)
+	if err != nil {
+		t.Fatalf("ImportAdif() error = %v", err)
+	}
+	if result.Duplicates != 1 {
+		t.Errorf("ImportAdif(export) = %+v, want 1 duplicate", result)
+	}
+}
+
+func TestAdifFreqMHz(t *testing.T) {
+	tests := []struct {
+		hz, want string
+	}{
+		{"14250000", "14.250000"},
+		{"7025000", "7.0250000"},
+		{"144300000", "144.300000"},
+		{"1836600", "1.8366000"},
+		{"", ""},
+		{"abc", "abc"},
+	}
+	for _, tt := range tests {
+		if got := adifFreqMHz(tt.hz); got != tt.want {
+			t.Errorf("adifFreqMHz(%q) = %q, want %q", tt.hz, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package facade
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Station-Manager/adif"
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/adiflistener"
+	"github.com/Station-Manager/types"
+)
+
+// adifListener holds the ADIF listeners for the current run.
+type adifListener struct {
+	listeners []*adiflistener.Listener
+}
+
+// newAdifListener binds the configured ADIF listeners if they are enabled in the app options. It returns nil if
+// they are disabled, and fails if any of them cannot be bound.
+func newAdifListener(opts AdifListenerOptions) (*adifListener, error) {
+	const op errors.Op = "facade.newAdifListener"
+	if !opts.Enabled || len(opts.Endpoints) == 0 {
+		return nil, nil
+	}
+
+	a := &adifListener{}
+	for _, ep := range opts.Endpoints {
+		l, err := adiflistener.Listen(adiflistener.Config{Protocol: ep.Protocol, Address: ep.Address})
+		if err != nil {
+			a.close()
+			return nil, errors.New(op).Err(err)
+		}
+		a.listeners = append(a.listeners, l)
+	}
+	return a, nil
+}
+
+func (a *adifListener) close() {
+	for _, l := range a.listeners {
+		l.Close()
+	}
+}
+
+// adifListenerWorker serves the ADIF listeners until shutdown.
+func (s *Service) adifListenerWorker(shutdown <-chan struct{}) {
+	a := s.adifListener
+	if a == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, l := range a.listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.LoggerService.InfoWith().Str("protocol", l.Protocol()).Str("addr", l.Addr().String()).Msg("ADIF listener started")
+			if err := l.Serve(ctx, &adifRecordHandler{service: s}); err != nil {
+				s.LoggerService.ErrorWith().Err(err).Str("addr", l.Addr().String()).Msg("ADIF listener failed")
+			}
+		}()
+	}
+	wg.Wait()
+	s.LoggerService.DebugWith().Msg("ADIF listeners stopped")
+}
+
+// adifRecordHandler logs the records received by the ADIF listeners. It implements adiflistener.Handler.
+type adifRecordHandler struct {
+	service *Service
+}
+
+func (h *adifRecordHandler) HandleRecord(record []byte, from net.Addr) {
+	s := h.service
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	logged, err := s.logAdifRecord(record)
+	switch {
+	case err != nil:
+		s.LoggerService.WarnWith().Err(err).Str("from", from.String()).Msg("Failed to log the QSO received as ADIF")
+	case !logged:
+		s.LoggerService.DebugWith().Str("from", from.String()).Msg("Ignoring a duplicate of a QSO received as ADIF")
+	}
+}
+
+// logAdifRecord parses a received ADIF record and logs it through LogQso, unless the QSO is already in the
+// logbook. It reports whether the QSO was logged.
+func (s *Service) logAdifRecord(record []byte) (bool, error) {
+	const op errors.Op = "facade.Service.logAdifRecord"
+
+	parsed, err := adif.Marshal(record)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	if len(parsed.Records) != 1 {
+		return false, errors.New(op).Msgf("Expected one ADIF record, got %d", len(parsed.Records))
+	}
+
+	qso, err := s.qsoFromAdifRecord(parsed.Records[0])
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+
+	logged, err := s.logUnlessLogged(qso)
+	if err != nil || !logged {
+		return false, err
+	}
+	s.LoggerService.InfoWith().Str("callsign", qso.Call).Str("band", qso.Band).Str("mode", qso.Mode).
+		Msg("Logged QSO received as ADIF")
+	s.emitEvent(EventExternalQso.String(), qso)
+	return true, nil
+}
+
+// logUnlessLogged logs a QSO from another program or an import unless the logbook already holds it, and reports
+// whether it was logged.
+func (s *Service) logUnlessLogged(qso types.Qso) (bool, error) {
+	const op errors.Op = "facade.Service.logUnlessLogged"
+
+	s.externalQsoMu.Lock()
+	defer s.externalQsoMu.Unlock()
+
+	dup, err := s.isLoggedQso(qso)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	if dup {
+		return false, nil
+	}
+
+	if _, err = s.logQso(qso); err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	return true, nil
+}
+
+// qsoFromAdifRecord converts a received record to a QSO in the current logbook, in the form the app logs it:
+// frequencies in Hz, times as HHMM, submodes under their ADIF mode. The logging station is taken from the config,
+// overridden by the record where it says more about the QSO; a record from another station callsign is rejected.
+func (s *Service) qsoFromAdifRecord(rec adif.Record) (types.Qso, error) {
+	const op errors.Op = "facade.Service.qsoFromAdifRecord"
+
+	details := rec.QsoDetails
+	contacted := rec.ContactedStation
+	contacted.Call = strings.ToUpper(strings.TrimSpace(contacted.Call))
+	if len(contacted.Call) < 3 {
+		return types.Qso{}, errors.New(op).Msg(errMsgInvalidCallsign)
+	}
+
+	if call := strings.TrimSpace(rec.StationCallsign); call != "" &&
+		!strings.EqualFold(s.parseCallsign(call), s.parseCallsign(s.CurrentLogbook.Callsign)) {
+		return types.Qso{}, errors.New(op).Msgf("The QSO was made as %s, not the logbook's callsign %s", call,
+			s.CurrentLogbook.Callsign)
+	}
+
+	for _, f := range []*string{&details.Freq, &details.FreqRx} {
+		if *f == "" {
+			continue
+		}
+		mhz, err := strconv.ParseFloat(strings.TrimSpace(*f), 64)
+		if err != nil || mhz <= 0 {
+			return types.Qso{}, errors.New(op).Msgf("Invalid frequency: %q", *f)
+		}
+		*f = strconv.FormatInt(int64(math.Round(mhz*1e6)), 10)
+	}
+	if details.Freq == "" {
+		// The log requires the frequency.
+		return types.Qso{}, errors.New(op).Msg("The QSO has no frequency")
+	}
+	details.Band = strings.ToLower(strings.TrimSpace(details.Band))
+	if hz, ok := catFreqHz(details.Freq); ok && details.Band == "" {
+		details.Band = bandForKhz(float64(hz) / 1000)
+	}
+	details.BandRx = strings.ToLower(strings.TrimSpace(details.BandRx))
+	if hz, ok := catFreqHz(details.FreqRx); ok && details.BandRx == "" {
+		details.BandRx = bandForKhz(float64(hz) / 1000)
+	}
+
+	normalizeExternalMode(&details)
+
+	// The log keeps times to the minute.
+	details.TimeOn = adifTimeHHMM(details.TimeOn)
+	details.TimeOff = adifTimeHHMM(details.TimeOff)
+	if details.TimeOff == "" {
+		details.TimeOff = details.TimeOn
+	}
+	if details.QsoDateOff == "" {
+		details.QsoDateOff = details.QsoDate
+	}
+	if details.AntPath == "" {
+		details.AntPath = "S"
+	}
+
+	station, err := s.initLoggingStationSection()
+	if err != nil {
+		return types.Qso{}, errors.New(op).Err(err)
+	}
+	for dst, src := range map[*string]string{
+		&station.MyGridsquare: rec.MyGridsquare,
+		&station.MyRig:        rec.MyRig,
+		&station.MyAntenna:    rec.MyAntenna,
+		&station.MySig:        rec.MySig,
+		&station.MySigInfo:    rec.MySigInfo,
+		&station.MyWwffRef:    rec.MyWwffRef,
+		&station.Operator:     rec.Operator,
+	} {
+		if v := strings.TrimSpace(src); v != "" {
+			*dst = v
+		}
+	}
+
+	qso := types.Qso{
+		LogbookID:           s.CurrentLogbook.ID,
+		QsoDetails:          details,
+		ContactedStation:    contacted,
+		LoggingStation:      station,
+		QrzComUploadStatus:  adif.NoString,
+		SmQsoUploadStatus:   adif.NoString,
+		SmFwrdByEmailStatus: adif.NoString,
+	}
+
+	// Programs seldom send the entity, which the log requires for its statistics and awards.
+	if qso.Country == "" || qso.DXCC == "" {
+		if country, cerr := s.initCountrySection(contacted.Call); cerr != nil {
+			s.LoggerService.WarnWith().Err(cerr).Str("callsign", contacted.Call).Msg("Failed to look up the country of a QSO received as ADIF")
+		} else if cerr = mergeCountryIntoContactedStation(&qso.ContactedStation, country); cerr == nil {
+			qso.CountryDetails = country
+		}
+	}
+
+	return qso, nil
+}
+
+// isLoggedQso reports whether the logbook already holds a QSO with the same call, band, mode, date and time.
+func (s *Service) isLoggedQso(qso types.Qso) (bool, error) {
+	const op errors.Op = "facade.Service.isLoggedQso"
+
+	const query = `SELECT EXISTS (SELECT 1
+              FROM qso
+              WHERE logbook_id = ?
+                AND deleted_at IS NULL
+                AND upper(call) = ?
+                AND lower(band) = ?
+                AND upper(mode) = ?
+                AND qso_date = ?
+                AND time_on = ?)`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, qso.LogbookID, strings.ToUpper(qso.Call),
+		strings.ToLower(qso.Band), strings.ToUpper(qso.Mode), qso.QsoDate, qso.TimeOn)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var exists bool
+	if rows.Next() {
+		if err = rows.Scan(&exists); err != nil {
+			return false, errors.New(op).Err(err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	return exists, nil
+}
+
+// normalizeExternalMode brings the mode of a QSO logged by another program to the form the log keeps it in.
+func normalizeExternalMode(details *types.QsoDetails) {
+	details.Mode = strings.ToUpper(strings.TrimSpace(details.Mode))
+	details.Submode = strings.ToUpper(strings.TrimSpace(details.Submode))
+	if !modes.IsValidMode(details.Mode) {
+		// Programs that predate a mode's ADIF submode, such as FT4, send it as the mode. The WSJT-X modes that the
+		// modes enum does not list are logged as MFSK, keeping the mode as the submode.
+		if m, ok := modes.GetModeBySubmode(details.Mode); ok {
+			details.Mode, details.Submode = m.String(), details.Mode
+		} else if unlistedMfskModes[details.Mode] {
+			details.Mode, details.Submode = modes.MFSK.String(), details.Mode
+		}
+	}
+}
+
+// adifTimeHHMM truncates an ADIF time (HHMM or HHMMSS) to HHMM.
+func adifTimeHHMM(t string) string {
+	t = strings.TrimSpace(t)
+	if len(t) > 4 {
+		return t[:4]
+	}
+	return t
+}
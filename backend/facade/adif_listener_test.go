@@ -0,0 +1,204 @@
+package facade
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+const testAdifRecord = "<call:6>DL1ABC <gridsquare:4>JO62 <mode:3>FT4 <rst_sent:3>-10 <rst_rcvd:3>-07 " +
+	"<qso_date:8>20261018 <time_on:6>123015 <qso_date_off:8>20261018 <time_off:6>123115 <band:3>20m " +
+	"<freq:9>14.080500 <station_callsign:4>W1AW <my_gridsquare:6>FN31pr <country:7>Germany <dxcc:3>230 <eor>"
+
+func createAdifTestService(t *testing.T) *Service {
+	t.Helper()
+	s := createDatabaseTestService(t)
+	if err := s.initializeValidation(); err != nil {
+		t.Fatalf("initializeValidation() error = %v", err)
+	}
+	return s
+}
+
+func adifQsoCount(t *testing.T, s *Service) int64 {
+	t.Helper()
+	n, err := s.DatabaseService.FetchQsoCountByLogbookId(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchQsoCountByLogbookId() error = %v", err)
+	}
+	return n
+}
+
+// =============================================================================
+// Record Conversion Tests
+// =============================================================================
+
+func TestQsoFromAdifRecord(t *testing.T) {
+	s := createAdifTestService(t)
+
+	logged, err := s.logAdifRecord([]byte(testAdifRecord))
+	if err != nil || !logged {
+		t.Fatalf("logAdifRecord() = %v, %v", logged, err)
+	}
+
+	history, err := s.DatabaseService.FetchQsoSliceByCallsign("DL1ABC")
+	if err != nil || len(history) != 1 {
+		t.Fatalf("FetchQsoSliceByCallsign() = %v, %v", history, err)
+	}
+	qso, err := s.DatabaseService.FetchQsoById(history[0].ID)
+	if err != nil {
+		t.Fatalf("FetchQsoById() error = %v", err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want string
+	}{
+		{"mode", qso.Mode, "MFSK"},
+		{"submode", qso.Submode, "FT4"},
+		{"freq", qso.Freq, "14080500"},
+		{"time_on", qso.TimeOn, "1230"},
+		{"time_off", qso.TimeOff, "1231"},
+		{"station_callsign", qso.StationCallsign, "W1AW"},
+		{"my_gridsquare", qso.MyGridsquare, "FN31pr"},
+		{"rst_sent", qso.RstSent, "-10"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestQsoFromAdifRecord_BandFromFrequency(t *testing.T) {
+	s := createAdifTestService(t)
+
+	logged, err := s.logAdifRecord([]byte("<call:4>G4XY<mode:2>CW<freq:5>7.025<qso_date:8>20261018<time_on:4>0800" +
+		"<rst_sent:3>599<rst_rcvd:3>579<country:7>England<dxcc:3>223<eor>"))
+	if err != nil || !logged {
+		t.Fatalf("logAdifRecord() = %v, %v", logged, err)
+	}
+	history, _ := s.DatabaseService.FetchQsoSliceByCallsign("G4XY")
+	if len(history) != 1 || history[0].Band != "40m" {
+		t.Errorf("history = %+v, want one QSO on 40m", history)
+	}
+}
+
+func TestLogAdifRecord_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+	}{
+		{"no record", "<adif_ver:5>3.1.0<eoh>"},
+		{"no call", "<band:3>20m<freq:2>14<mode:2>CW<qso_date:8>20261018<time_on:4>1200<eor>"},
+		{"no frequency", "<call:4>G4XY<band:3>20m<mode:2>CW<qso_date:8>20261018<time_on:4>1200<eor>"},
+		{"bad frequency", "<call:4>G4XY<freq:3>abc<band:3>20m<mode:2>CW<qso_date:8>20261018<time_on:4>1200<eor>"},
+		{"other station", "<call:4>G4XY<station_callsign:4>K1AB<freq:2>14<band:3>20m<mode:2>CW<qso_date:8>20261018<time_on:4>1200" +
+			"<country:7>England<dxcc:3>223<eor>"},
+		{"invalid band", "<call:4>G4XY<band:4>99km<freq:2>14<mode:2>CW<qso_date:8>20261018<time_on:4>1200" +
+			"<country:7>England<dxcc:3>223<eor>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createAdifTestService(t)
+			if logged, err := s.logAdifRecord([]byte(tt.record)); err == nil || logged {
+				t.Errorf("logAdifRecord() = %v, %v, want an error", logged, err)
+			}
+			if n := adifQsoCount(t, s); n != 0 {
+				t.Errorf("%d QSOs logged, want none", n)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Duplicate Tests
+// =============================================================================
+
+func TestLogAdifRecord_Duplicate(t *testing.T) {
+	s := createAdifTestService(t)
+
+	if logged, err := s.logAdifRecord([]byte(testAdifRecord)); err != nil || !logged {
+		t.Fatalf("logAdifRecord() = %v, %v", logged, err)
+	}
+	// A repeated broadcast, even with the seconds differing, is the same QSO.
+	repeat := []byte(testAdifRecord[:len(testAdifRecord)-5] + "<time_on:6>123059 <eor>")
+	for _, rec := range [][]byte{[]byte(testAdifRecord), repeat} {
+		if logged, err := s.logAdifRecord(rec); err != nil || logged {
+			t.Errorf("logAdifRecord(duplicate) = %v, %v, want not logged", logged, err)
+		}
+	}
+	if n := adifQsoCount(t, s); n != 1 {
+		t.Errorf("%d QSOs logged, want 1", n)
+	}
+
+	// Another band is another QSO.
+	other := []byte("<call:6>DL1ABC<band:3>40m<freq:5>7.074<mode:3>FT8<qso_date:8>20261018<time_on:4>1230" +
+		"<country:7>Germany<dxcc:3>230<eor>")
+	if logged, err := s.logAdifRecord(other); err != nil || !logged {
+		t.Errorf("logAdifRecord(other band) = %v, %v, want logged", logged, err)
+	}
+}
+
+// =============================================================================
+// Listener Tests
+// =============================================================================
+
+func TestNewAdifListener(t *testing.T) {
+	a, err := newAdifListener(AdifListenerOptions{Endpoints: []AdifListenerEndpoint{{Protocol: "udp", Address: "127.0.0.1:0"}}})
+	if err != nil || a != nil {
+		t.Errorf("newAdifListener(disabled) = %v, %v, want nil", a, err)
+	}
+
+	_, err = newAdifListener(AdifListenerOptions{Enabled: true, Endpoints: []AdifListenerEndpoint{
+		{Protocol: "udp", Address: "127.0.0.1:0"},
+		{Protocol: "smoke", Address: "127.0.0.1:0"},
+	}})
+	if err == nil {
+		t.Error("newAdifListener() should fail for an invalid endpoint")
+	}
+}
+
+func TestAdifListenerWorker(t *testing.T) {
+	s := createAdifTestService(t)
+	a, err := newAdifListener(AdifListenerOptions{Enabled: true, Endpoints: []AdifListenerEndpoint{
+		{Protocol: "udp", Address: "127.0.0.1:0"},
+		{Protocol: "tcp", Address: "127.0.0.1:0"},
+	}})
+	if err != nil {
+		t.Fatalf("newAdifListener() error = %v", err)
+	}
+	s.adifListener = a
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.adifListenerWorker(shutdown)
+		close(done)
+	}()
+
+	// The same QSO broadcast over UDP and sent over TCP is logged once.
+	for _, l := range a.listeners {
+		conn, err := net.Dial(l.Protocol(), l.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		if _, err = conn.Write([]byte(testAdifRecord)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		_ = conn.Close()
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return adifQsoCount(t, s) == 1 }) {
+		t.Fatalf("%d QSOs logged, want 1", adifQsoCount(t, s))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := adifQsoCount(t, s); n != 1 {
+		t.Errorf("%d QSOs logged, want the duplicate ignored", n)
+	}
+
+	close(shutdown)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("adifListenerWorker did not stop on shutdown")
+	}
+}
@@ -0,0 +1,539 @@
+package facade
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/Station-Manager/database/sqlite/adapters"
+	"github.com/Station-Manager/enums/upload"
+	"github.com/Station-Manager/enums/upload/action"
+	"github.com/Station-Manager/enums/upload/status"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+	"github.com/aarondl/sqlboiler/v4/boil"
+)
+
+// Audit actions, as stored in the qso_audit table.
+const (
+	auditInsert = "insert"
+	auditUpdate = "update"
+	auditDelete = "delete"
+	auditRevert = "revert"
+)
+
+// QsoAuditEntry is one change in the history of a QSO. The changes list the fields that differ between the QSO
+// before and after the change; an insert lists the fields that were set, a delete those that were cleared.
+type QsoAuditEntry struct {
+	ID         int64            `json:"id"`
+	QsoID      int64            `json:"qso_id"`
+	CreatedAt  string           `json:"created_at"`
+	Action     string           `json:"action"`
+	Operator   string           `json:"operator"`
+	Changes    []QsoFieldChange `json:"changes"`
+	RevertedTo int64            `json:"reverted_to,omitempty"`
+}
+
+// QsoFieldChange is a single changed field of a QSO. The values are JSON, null where the field was not set.
+type QsoFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// FetchQsoHistory returns the audit trail of the given QSO, oldest change first.
+func (s *Service) FetchQsoHistory(qsoId int64) ([]QsoAuditEntry, error) {
+	const op errors.Op = "facade.Service.FetchQsoHistory"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return nil, errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	const query = `SELECT id, qso_id, strftime('%Y-%m-%d %H:%M:%S', created_at), action, operator, diff, COALESCE(reverted_to, 0)
+FROM qso_audit
+WHERE qso_id = ?
+ORDER BY id`
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, qsoId)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSO history")
+		return nil, errors.Root(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	history := make([]QsoAuditEntry, 0)
+	for rows.Next() {
+		var e QsoAuditEntry
+		var diff string
+		if err = rows.Scan(&e.ID, &e.QsoID, &e.CreatedAt, &e.Action, &e.Operator, &diff, &e.RevertedTo); err != nil {
+			return nil, errors.Root(errors.New(op).Err(err))
+		}
+		if e.Changes, err = decodeQsoDiff(diff); err != nil {
+			return nil, errors.Root(errors.New(op).Err(err))
+		}
+		history = append(history, e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Root(errors.New(op).Err(err))
+	}
+
+	return history, nil
+}
+
+// DeleteQso deletes a QSO from the logbook. The QSO is soft-deleted, so that it can be restored from its history.
+// The forwarders cannot delete, so a QSO already uploaded to the online services stays there.
+func (s *Service) DeleteQso(qsoId int64) error {
+	const op errors.Op = "facade.Service.DeleteQso"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	before, err := s.qsoSnapshot(qsoId)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSO")
+		return errors.Root(err)
+	}
+	if before == nil {
+		return errors.New(op).Err(errors.ErrNotFound).Msg("QSO not found")
+	}
+
+	if err = s.softDeleteQso(qsoId, *before); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to delete QSO")
+		return errors.Root(err)
+	}
+
+	s.auditQsoChange(qsoId, auditDelete, before, nil, 0)
+
+	return nil
+}
+
+// RevertQso restores a QSO to the version recorded by the given audit entry, i.e. the QSO as it was just after that
+// change. Reverting to a deletion deletes the QSO; reverting a deleted QSO to an earlier version restores it. The
+// QSO is queued for upload as an update unless it was deleted, and the revert itself is recorded in the QSO's history.
+func (s *Service) RevertQso(auditId int64) error {
+	const op errors.Op = "facade.Service.RevertQso"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if auditId < 1 {
+		return errors.New(op).Msg("Invalid audit entry ID")
+	}
+
+	qsoId, target, err := s.fetchAuditVersion(auditId)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch audit entry")
+		return errors.Root(err)
+	}
+
+	current, err := s.qsoSnapshot(qsoId)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSO")
+		return errors.Root(err)
+	}
+
+	changes, err := qsoDiff(current, target)
+	if err != nil {
+		return errors.Root(errors.New(op).Err(err))
+	}
+	if len(changes) == 0 {
+		// Already at that version.
+		return nil
+	}
+
+	if target == nil {
+		err = s.softDeleteQso(qsoId, *current)
+	} else {
+		err = s.restoreQso(*target, current == nil)
+	}
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to revert QSO")
+		return errors.Root(err)
+	}
+
+	after, err := s.qsoSnapshot(qsoId)
+	if err != nil {
+		// The revert is done; only its audit entry is lost.
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch reverted QSO")
+		return nil
+	}
+	s.auditQsoChange(qsoId, auditRevert, current, after, auditId)
+
+	return nil
+}
+
+// softDeleteQso marks the QSO as deleted. No upload is queued, as the forwarders only insert and update.
+func (s *Service) softDeleteQso(qsoId int64, qso types.Qso) error {
+	const op errors.Op = "facade.Service.softDeleteQso"
+
+	const stmt = `UPDATE qso SET deleted_at = datetime('now', 'localtime') WHERE id = ? AND deleted_at IS NULL`
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, qsoId); err != nil {
+		return errors.New(op).Err(err)
+	}
+	s.invalidateStats(qso.LogbookID)
+	s.broadcastN1mmContact(n1mmContactDelete, qso)
+
+	return nil
+}
+
+// restoreQso writes back an earlier version of a QSO, undeleting it in the same transaction if it was deleted, and
+// queues its upload as an update: a deleted QSO was never removed from the online services.
+func (s *Service) restoreQso(qso types.Qso, deleted bool) error {
+	const op errors.Op = "facade.Service.restoreQso"
+
+	model, err := adapters.QsoTypeToModel(qso)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	model.ModifiedAt.SetValid(time.Now())
+
+	ctx := s.dbContext()
+	tx, txCancel, err := s.DatabaseService.BeginTxContext(ctx)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer txCancel()
+	defer func() { _ = tx.Rollback() }() // No-op after successful commit
+
+	if deleted {
+		const stmt = `UPDATE qso SET deleted_at = NULL WHERE id = ?`
+		if _, err = tx.ExecContext(ctx, stmt, qso.ID); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+	if _, err = model.Update(ctx, tx, boil.Infer()); err != nil {
+		return errors.New(op).Err(err)
+	}
+	if err = tx.Commit(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	if err = s.saveQslFromQso(qso.ID, qso.Qsl); err != nil {
+		return errors.New(op).Err(err)
+	}
+	s.invalidateStats(qso.LogbookID)
+	if deleted {
+		s.broadcastN1mmContact(n1mmContactInfo, qso)
+	} else {
+		s.broadcastN1mmContact(n1mmContactReplace, qso)
+	}
+
+	if err = s.enqueueQsoUpload(qso.ID, action.Update); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// enqueueQsoUpload queues an upload action for the QSO. An upload can only be queued once per QSO, service and
+// action, so an action that was queued before (e.g. by an earlier edit) is queued again as a fresh pending upload.
+func (s *Service) enqueueQsoUpload(qsoId int64, act action.Action) error {
+	const op errors.Op = "facade.Service.enqueueQsoUpload"
+
+	const stmt = `INSERT INTO qso_upload (qso_id, service, action)
+VALUES (?, ?, ?)
+ON CONFLICT (qso_id, service, action) DO UPDATE SET status          = ?,
+                                                    attempts        = 0,
+                                                    last_attempt_at = NULL,
+                                                    last_error      = NULL`
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, qsoId, upload.OnlineServiceQRZ.String(), act.String(),
+		status.Pending.String()); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// qsoSnapshot returns the QSO as it is stored, with its QSL card state, or nil if the QSO has been deleted.
+func (s *Service) qsoSnapshot(qsoId int64) (*types.Qso, error) {
+	const op errors.Op = "facade.Service.qsoSnapshot"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), `SELECT deleted_at IS NOT NULL FROM qso WHERE id = ?`, qsoId)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	var deleted bool
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&deleted)
+	} else {
+		err = rows.Err()
+	}
+	_ = rows.Close()
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	if !found {
+		return nil, errors.New(op).Err(errors.ErrNotFound).Msg("QSO not found")
+	}
+	if deleted {
+		return nil, nil
+	}
+
+	qso, err := s.DatabaseService.FetchQsoById(qsoId)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	if err = s.mergeQslIntoQso(&qso); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return &qso, nil
+}
+
+// fetchAuditVersion returns the QSO of an audit entry and the version of the QSO the entry left behind, nil for a
+// deletion.
+func (s *Service) fetchAuditVersion(auditId int64) (int64, *types.Qso, error) {
+	const op errors.Op = "facade.Service.fetchAuditVersion"
+
+	var qsoId int64
+	var after sql.NullString
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), `SELECT qso_id, after FROM qso_audit WHERE id = ?`, auditId)
+	if err != nil {
+		return 0, nil, errors.New(op).Err(err)
+	}
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&qsoId, &after)
+	} else {
+		err = rows.Err()
+	}
+	_ = rows.Close()
+	if err != nil {
+		return 0, nil, errors.New(op).Err(err)
+	}
+	if !found {
+		return 0, nil, errors.New(op).Err(errors.ErrNotFound).Msg("Audit entry not found")
+	}
+	if !after.Valid {
+		return qsoId, nil, nil
+	}
+
+	var qso types.Qso
+	if err = json.Unmarshal([]byte(after.String), &qso); err != nil {
+		return 0, nil, errors.New(op).Err(err)
+	}
+
+	return qsoId, &qso, nil
+}
+
+// auditQsoChange records a change of a QSO in its audit trail. Auditing never fails the change itself, so errors are
+// logged only.
+func (s *Service) auditQsoChange(qsoId int64, act string, before, after *types.Qso, revertedTo int64) {
+	if err := s.recordQsoAudit(qsoId, act, before, after, revertedTo); err != nil {
+		s.LoggerService.ErrorWith().Err(err).Int64("qso_id", qsoId).Msg("Failed to record QSO audit entry.")
+	}
+}
+
+func (s *Service) recordQsoAudit(qsoId int64, act string, before, after *types.Qso, revertedTo int64) error {
+	const op errors.Op = "facade.Service.recordQsoAudit"
+
+	changes, err := qsoDiff(before, after)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	diff, err := encodeQsoDiff(changes)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	beforeJSON, err := qsoJSON(before)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	afterJSON, err := qsoJSON(after)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	var reverted sql.NullInt64
+	if revertedTo > 0 {
+		reverted = sql.NullInt64{Int64: revertedTo, Valid: true}
+	}
+
+	const stmt = `INSERT INTO qso_audit (qso_id, action, operator, before, after, diff, reverted_to) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	if _, err = s.DatabaseService.ExecContext(s.dbContext(), stmt, qsoId, act, s.auditOperator(), beforeJSON, afterJSON,
+		diff, reverted); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// auditOperator returns who is recorded as making a change: the current operator, else the callsign of the current
+// logbook.
+func (s *Service) auditOperator() string {
+	if operator := s.currentOperator(); operator.Callsign != "" {
+		return operator.Callsign
+	}
+	return s.CurrentLogbook.Callsign
+}
+
+func qsoJSON(qso *types.Qso) (sql.NullString, error) {
+	if qso == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(qso)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// qsoDiff compares two versions of a QSO field by field, by their JSON form, and returns the fields that differ in
+// field name order. A nil QSO has no fields set. A field that is unset on one side and empty on the other is not
+// reported as changed.
+func qsoDiff(before, after *types.Qso) ([]QsoFieldChange, error) {
+	b, err := qsoFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := qsoFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(a)+len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]QsoFieldChange, 0)
+	for _, name := range names {
+		bv, av := b[name], a[name]
+		if bytes.Equal(bv, av) || (emptyJSON(bv) && emptyJSON(av)) {
+			continue
+		}
+		changes = append(changes, QsoFieldChange{Field: name, Before: jsonOrNull(bv), After: jsonOrNull(av)})
+	}
+
+	return changes, nil
+}
+
+// qsoFields returns the fields of a QSO by their JSON name. The fields of nested objects are flattened into dotted
+// names, e.g. "country_details.name", so that a change is reported for the field itself.
+func qsoFields(qso *types.Qso) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if qso == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(qso)
+	if err != nil {
+		return nil, err
+	}
+	if err = flattenJSON("", data, fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func flattenJSON(prefix string, data json.RawMessage, fields map[string]json.RawMessage) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	for name, value := range object {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if len(value) > 0 && value[0] == '{' {
+			if err := flattenJSON(name, value, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		fields[name] = value
+	}
+	return nil
+}
+
+// emptyJSON reports whether a JSON value is missing or the zero value of its type.
+func emptyJSON(v json.RawMessage) bool {
+	switch string(v) {
+	case "", "null", `""`, "0", "false", "{}", "[]":
+		return true
+	}
+	return false
+}
+
+func jsonOrNull(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "null"
+	}
+	return string(v)
+}
+
+// auditDiffValue is the stored form of a changed field in the diff column: {"field": {"before": .., "after": ..}}.
+type auditDiffValue struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+func encodeQsoDiff(changes []QsoFieldChange) (string, error) {
+	diff := make(map[string]auditDiffValue, len(changes))
+	for _, c := range changes {
+		diff[c.Field] = auditDiffValue{Before: json.RawMessage(c.Before), After: json.RawMessage(c.After)}
+	}
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeQsoDiff(data string) ([]QsoFieldChange, error) {
+	var diff map[string]auditDiffValue
+	if err := json.Unmarshal([]byte(data), &diff); err != nil {
+		return nil, err
+	}
+
+	changes := make([]QsoFieldChange, 0, len(diff))
+	for field, v := range diff {
+		changes = append(changes, QsoFieldChange{Field: field, Before: jsonOrNull(v.Before), After: jsonOrNull(v.After)})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes, nil
+}
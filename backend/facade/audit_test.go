@@ -0,0 +1,241 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/types"
+)
+
+// =============================================================================
+// Diff Tests
+// =============================================================================
+
+func TestQsoDiff(t *testing.T) {
+	base := types.Qso{ID: 1, QsoDetails: types.QsoDetails{Band: "20m", Mode: "SSB"}}
+	edited := base
+	edited.Mode = "CW"
+	edited.Comment = "tnx"
+
+	tests := []struct {
+		name   string
+		before *types.Qso
+		after  *types.Qso
+		want   []QsoFieldChange
+	}{
+		{name: "unchanged", before: &base, after: &base, want: nil},
+		{name: "both missing", before: nil, after: nil, want: nil},
+		{
+			name:   "edited",
+			before: &base,
+			after:  &edited,
+			want: []QsoFieldChange{
+				{Field: "comment", Before: `""`, After: `"tnx"`},
+				{Field: "mode", Before: `"SSB"`, After: `"CW"`},
+			},
+		},
+		{
+			name:   "inserted",
+			before: nil,
+			after:  &base,
+			want: []QsoFieldChange{
+				{Field: "band", Before: "null", After: `"20m"`},
+				{Field: "id", Before: "null", After: "1"},
+				{Field: "mode", Before: "null", After: `"SSB"`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qsoDiff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("qsoDiff() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("qsoDiff() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("qsoDiff()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestQsoDiff_RoundTrip(t *testing.T) {
+	before := types.Qso{QsoDetails: types.QsoDetails{Mode: "SSB"}}
+	after := types.Qso{QsoDetails: types.QsoDetails{Mode: "CW"}}
+	changes, err := qsoDiff(&before, &after)
+	if err != nil {
+		t.Fatalf("qsoDiff() error = %v", err)
+	}
+
+	encoded, err := encodeQsoDiff(changes)
+	if err != nil {
+		t.Fatalf("encodeQsoDiff() error = %v", err)
+	}
+	decoded, err := decodeQsoDiff(encoded)
+	if err != nil {
+		t.Fatalf("decodeQsoDiff() error = %v", err)
+	}
+	if len(decoded) != 1 || decoded[0] != changes[0] {
+		t.Errorf("decodeQsoDiff(%s) = %+v, want %+v", encoded, decoded, changes)
+	}
+}
+
+// =============================================================================
+// History and Revert Tests
+// =============================================================================
+
+// uploadActions returns the pending upload actions of a QSO.
+func uploadActions(t *testing.T, s *Service, qsoId int64) map[string]bool {
+	t.Helper()
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(),
+		`SELECT action FROM qso_upload WHERE qso_id = ? AND status = 'pending'`, qsoId)
+	if err != nil {
+		t.Fatalf("QueryContext() error = %v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	actions := make(map[string]bool)
+	for rows.Next() {
+		var a string
+		if err = rows.Scan(&a); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		actions[a] = true
+	}
+	return actions
+}
+
+func TestQsoHistoryAndRevert(t *testing.T) {
+	s := createAdifTestService(t)
+
+	id, err := s.logQso(newTestQso(s, "G4XY", "20m", "SSB"))
+	if err != nil {
+		t.Fatalf("logQso() error = %v", err)
+	}
+
+	for _, mode := range []string{"CW", "FM"} {
+		qso, ferr := s.DatabaseService.FetchQsoById(id)
+		if ferr != nil {
+			t.Fatalf("FetchQsoById() error = %v", ferr)
+		}
+		qso.Mode = mode
+		// Editing a QSO more than once must not fail on the queued update upload.
+		if err = s.UpdateQso(qso); err != nil {
+			t.Fatalf("UpdateQso(%s) error = %v", mode, err)
+		}
+	}
+
+	history, err := s.FetchQsoHistory(id)
+	if err != nil {
+		t.Fatalf("FetchQsoHistory() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("FetchQsoHistory() = %d entries, want 3", len(history))
+	}
+	if history[0].Action != auditInsert || history[1].Action != auditUpdate || history[0].Operator != "W1AW" {
+		t.Errorf("FetchQsoHistory() = %+v, want an insert by W1AW then updates", history)
+	}
+	last := history[2].Changes
+	if len(last) != 1 || last[0].Field != "mode" || last[0].Before != `"CW"` || last[0].After != `"FM"` {
+		t.Errorf("update changes = %+v, want mode CW -> FM", last)
+	}
+
+	// Back to the first edit.
+	if err = s.RevertQso(history[1].ID); err != nil {
+		t.Fatalf("RevertQso() error = %v", err)
+	}
+	qso, err := s.DatabaseService.FetchQsoById(id)
+	if err != nil {
+		t.Fatalf("FetchQsoById() error = %v", err)
+	}
+	if qso.Mode != "CW" {
+		t.Errorf("Mode after revert = %q, want CW", qso.Mode)
+	}
+
+	history, err = s.FetchQsoHistory(id)
+	if err != nil {
+		t.Fatalf("FetchQsoHistory() error = %v", err)
+	}
+	revert := history[len(history)-1]
+	if revert.Action != auditRevert || revert.RevertedTo != history[1].ID {
+		t.Errorf("last entry = %+v, want a revert to entry %d", revert, history[1].ID)
+	}
+	if !uploadActions(t, s, id)["update"] {
+		t.Error("revert did not queue an update upload")
+	}
+
+	// Reverting to the current version changes nothing.
+	if err = s.RevertQso(history[1].ID); err != nil {
+		t.Fatalf("RevertQso() error = %v", err)
+	}
+	if again, _ := s.FetchQsoHistory(id); len(again) != len(history) {
+		t.Errorf("no-op revert added an entry: %d entries, want %d", len(again), len(history))
+	}
+}
+
+func TestDeleteQsoAndRestore(t *testing.T) {
+	s := createAdifTestService(t)
+
+	id, err := s.logQso(newTestQso(s, "G4XY", "20m", "SSB"))
+	if err != nil {
+		t.Fatalf("logQso() error = %v", err)
+	}
+	if err = s.DeleteQso(id); err != nil {
+		t.Fatalf("DeleteQso() error = %v", err)
+	}
+	if err = s.DeleteQso(id); err == nil {
+		t.Error("DeleteQso() of a deleted QSO error = nil, want an error")
+	}
+	if count, _ := s.DatabaseService.FetchQsoCountByLogbookId(s.CurrentLogbook.ID); count != 0 {
+		t.Errorf("QSO count after delete = %d, want 0", count)
+	}
+	// The forwarders cannot delete, so no upload is queued.
+	if uploadActions(t, s, id)["delete"] {
+		t.Error("delete queued a delete upload")
+	}
+
+	history, err := s.FetchQsoHistory(id)
+	if err != nil {
+		t.Fatalf("FetchQsoHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[1].Action != auditDelete {
+		t.Fatalf("FetchQsoHistory() = %+v, want insert then delete", history)
+	}
+
+	// Reverting to the inserted version restores the QSO.
+	if err = s.RevertQso(history[0].ID); err != nil {
+		t.Fatalf("RevertQso() error = %v", err)
+	}
+	if count, _ := s.DatabaseService.FetchQsoCountByLogbookId(s.CurrentLogbook.ID); count != 1 {
+		t.Errorf("QSO count after restore = %d, want 1", count)
+	}
+	if !uploadActions(t, s, id)["update"] {
+		t.Error("restore did not queue an update upload")
+	}
+
+	// And reverting to the deletion deletes it again.
+	if err = s.RevertQso(history[1].ID); err != nil {
+		t.Fatalf("RevertQso() error = %v", err)
+	}
+	if count, _ := s.DatabaseService.FetchQsoCountByLogbookId(s.CurrentLogbook.ID); count != 0 {
+		t.Errorf("QSO count after reverting to the deletion = %d, want 0", count)
+	}
+}
+
+func TestQsoAudit_NotStarted(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchQsoHistory(1); err == nil {
+		t.Error("FetchQsoHistory() error = nil, want an error")
+	}
+	if err := s.DeleteQso(1); err == nil {
+		t.Error("DeleteQso() error = nil, want an error")
+	}
+	if err := s.RevertQso(1); err == nil {
+		t.Error("RevertQso() error = nil, want an error")
+	}
+}
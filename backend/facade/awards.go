@@ -0,0 +1,582 @@
+package facade
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Station-Manager/adif"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// Supported awards.
+const (
+	AwardDXCC = "DXCC"
+	AwardWAS  = "WAS"
+	AwardWAZ  = "WAZ"
+	AwardIOTA = "IOTA"
+	AwardGrid = "GRID"
+)
+
+// Confirmation sources. Paper QSL confirmations are taken from the QSL card workflow; LoTW and eQSL confirmations
+// are imported from the services' ADIF reports with ImportQslConfirmations, or recorded against a single QSO with
+// SetQsoConfirmation.
+const (
+	ConfirmationLotw = "LOTW"
+	ConfirmationQsl  = "QSL"
+	ConfirmationEqsl = "EQSL"
+)
+
+// AllAwards lists the supported awards, in display order.
+var AllAwards = []string{AwardDXCC, AwardWAS, AwardWAZ, AwardIOTA, AwardGrid}
+
+var allConfirmationSources = []string{ConfirmationLotw, ConfirmationQsl, ConfirmationEqsl}
+
+// awardTotals is the number of references available for awards that have a fixed set. The DXCC total is the number
+// of current entities on the DXCC list; the country table only holds the countries that have been looked up.
+var awardTotals = map[string]int{
+	AwardDXCC: 340,
+	AwardWAS:  50,
+	AwardWAZ:  40,
+}
+
+// awardRefExpressions derive an award reference from a QSO row aliased as "q". DXCC references are the ADIF entity
+// codes, as country names differ between the lookup services. WAS has no source column, so it relies entirely on
+// references set with SetQsoAwardReference, which override the derived value for any award.
+var awardRefExpressions = map[string]string{
+	AwardDXCC: `NULLIF(CAST(CAST(json_extract(q.additional_data, '$.dxcc') AS INTEGER) AS TEXT), '0')`,
+	AwardWAS:  `NULL`,
+	AwardWAZ:  `NULLIF(CAST(CAST(json_extract(q.additional_data, '$.cqz') AS INTEGER) AS TEXT), '0')`,
+	AwardIOTA: `UPPER(TRIM(json_extract(q.additional_data, '$.iota')))`,
+	AwardGrid: `UPPER(substr(TRIM(json_extract(q.additional_data, '$.gridsquare')), 1, 4))`,
+}
+
+var (
+	usStates = []string{
+		"AK", "AL", "AR", "AZ", "CA", "CO", "CT", "DE", "FL", "GA", "HI", "IA", "ID", "IL", "IN", "KS", "KY",
+		"LA", "MA", "MD", "ME", "MI", "MN", "MO", "MS", "MT", "NC", "ND", "NE", "NH", "NJ", "NM", "NV", "NY",
+		"OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VA", "VT", "WA", "WI", "WV", "WY",
+	}
+	iotaRefRegex = regexp.MustCompile(`^(AF|AN|AS|EU|NA|OC|SA)-\d{3}$`)
+	gridRefRegex = regexp.MustCompile(`^[A-R]{2}\d{2}$`)
+)
+
+// AwardCount is the number of worked and confirmed references in one category (band, mode or overall).
+type AwardCount struct {
+	Worked    int `json:"worked"`
+	Confirmed int `json:"confirmed"`
+}
+
+// AwardSlot is a band/mode combination in which a reference has been worked.
+type AwardSlot struct {
+	Band      string `json:"band"`
+	Mode      string `json:"mode"`
+	Confirmed bool   `json:"confirmed"`
+}
+
+// AwardReference is a single award reference (an entity, state, zone, island or grid) and the slots it was worked in.
+type AwardReference struct {
+	Reference string      `json:"reference"`
+	Confirmed bool        `json:"confirmed"`
+	Slots     []AwardSlot `json:"slots"`
+}
+
+// AwardProgress is the worked/confirmed progress for one award in the current logbook.
+type AwardProgress struct {
+	Award      string                `json:"award"`
+	Total      int                   `json:"total"` // zero when the award has no fixed number of references
+	Worked     int                   `json:"worked"`
+	Confirmed  int                   `json:"confirmed"`
+	ByBand     map[string]AwardCount `json:"by_band"`
+	ByMode     map[string]AwardCount `json:"by_mode"`
+	References []AwardReference      `json:"references,omitempty"`
+}
+
+// AwardNeeds reports whether a contact with a station would be a new DXCC entity, or a new band or mode slot
+// for the station's entity.
+type AwardNeeds struct {
+	Call        string `json:"call"`
+	Country     string `json:"country"`
+	Band        string `json:"band"`
+	Mode        string `json:"mode"`
+	NewDxcc     bool   `json:"new_dxcc"`
+	NewBandSlot bool   `json:"new_band_slot"`
+	NewModeSlot bool   `json:"new_mode_slot"`
+}
+
+// FetchAwardProgress computes the worked/confirmed progress for the given award in the current logbook, per band
+// and per mode, including the individual references. Only the given confirmation sources are considered; if none
+// are given, all sources are used.
+func (s *Service) FetchAwardProgress(award string, sources []string) (AwardProgress, error) {
+	const op errors.Op = "facade.Service.FetchAwardProgress"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return AwardProgress{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return AwardProgress{}, errors.Root(err)
+	}
+
+	award = strings.ToUpper(strings.TrimSpace(award))
+	if _, ok := awardRefExpressions[award]; !ok {
+		return AwardProgress{}, errors.New(op).Msgf("Unknown award: %q", award)
+	}
+
+	sources, err := normalizeConfirmationSources(sources)
+	if err != nil {
+		return AwardProgress{}, errors.New(op).Err(err)
+	}
+
+	progress, err := s.computeAwardProgress(award, sources, true)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Str("award", award).Msg("Failed to compute award progress")
+		return AwardProgress{}, errors.Root(err)
+	}
+
+	return progress, nil
+}
+
+// FetchAwardsSummary computes the progress for every supported award, without the individual references.
+func (s *Service) FetchAwardsSummary(sources []string) ([]AwardProgress, error) {
+	const op errors.Op = "facade.Service.FetchAwardsSummary"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	sources, err := normalizeConfirmationSources(sources)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	summary := make([]AwardProgress, 0, len(AllAwards))
+	for _, award := range AllAwards {
+		progress, perr := s.computeAwardProgress(award, sources, false)
+		if perr != nil {
+			perr = errors.New(op).Err(perr)
+			s.LoggerService.ErrorWith().Err(perr).Str("award", award).Msg("Failed to compute award progress")
+			return nil, errors.Root(perr)
+		}
+		summary = append(summary, progress)
+	}
+
+	return summary, nil
+}
+
+// FetchAwardNeeds reports whether a contact with the given callsign on the given band and mode would be a new
+// DXCC entity, band slot or mode slot. The band and mode are optional.
+func (s *Service) FetchAwardNeeds(callsign, band, mode string) (AwardNeeds, error) {
+	const op errors.Op = "facade.Service.FetchAwardNeeds"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return AwardNeeds{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return AwardNeeds{}, errors.Root(err)
+	}
+
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if len(callsign) < 3 {
+		return AwardNeeds{}, errors.New(op).Msg(errMsgInvalidCallsign)
+	}
+
+	country, err := s.resolveCountry(callsign)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to resolve country for callsign")
+		return AwardNeeds{}, errors.Root(err)
+	}
+
+	needs, err := s.computeAwardNeeds(callsign, country, band, mode)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to compute award needs")
+		return AwardNeeds{}, errors.Root(err)
+	}
+
+	return needs, nil
+}
+
+// SetQsoConfirmation records (or removes) a confirmation of the given QSO by a confirmation source.
+func (s *Service) SetQsoConfirmation(qsoId int64, source string, confirmed bool) error {
+	const op errors.Op = "facade.Service.SetQsoConfirmation"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	source = strings.ToUpper(strings.TrimSpace(source))
+	if !slices.Contains(allConfirmationSources, source) {
+		return errors.New(op).Msgf("Unknown confirmation source: %q", source)
+	}
+
+	var stmt string
+	var args []any
+	switch {
+	case source == ConfirmationQsl && confirmed:
+		stmt = `INSERT INTO qsl_card (qso_id, rcvd_status) VALUES (?, 'Y')
+ON CONFLICT (qso_id) DO UPDATE SET rcvd_status = 'Y', modified_at = datetime('now', 'localtime')`
+		args = []any{qsoId}
+	case source == ConfirmationQsl:
+		stmt = `UPDATE qsl_card SET rcvd_status = 'N', modified_at = datetime('now', 'localtime') WHERE qso_id = ?`
+		args = []any{qsoId}
+	case confirmed:
+		stmt = `INSERT OR IGNORE INTO qso_confirmation (qso_id, source) VALUES (?, ?)`
+		args = []any{qsoId, source}
+	default:
+		stmt = `DELETE FROM qso_confirmation WHERE qso_id = ? AND source = ?`
+		args = []any{qsoId, source}
+	}
+
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, args...); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set QSO confirmation")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// QslConfirmationImport is the outcome of importing a LoTW or eQSL confirmation report.
+type QslConfirmationImport struct {
+	Records   int `json:"records"`
+	Confirmed int `json:"confirmed"` // confirmations matched to a QSO in the logbook
+	Unmatched int `json:"unmatched"` // confirmations of QSOs the logbook does not hold
+}
+
+// ImportQslConfirmations records the confirmations in an ADIF report downloaded from LoTW or eQSL against the
+// matching QSOs in the current logbook. A QSO matches on its call, band, date and time; records that are not
+// confirmed (QSL_RCVD other than Y) are skipped. Importing a report again is harmless.
+func (s *Service) ImportQslConfirmations(source, data string) (QslConfirmationImport, error) {
+	const op errors.Op = "facade.Service.ImportQslConfirmations"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return QslConfirmationImport{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return QslConfirmationImport{}, errors.Root(err)
+	}
+
+	source = strings.ToUpper(strings.TrimSpace(source))
+	if source != ConfirmationLotw && source != ConfirmationEqsl {
+		return QslConfirmationImport{}, errors.New(op).Msgf("Confirmations cannot be imported from %q", source)
+	}
+
+	parsed, err := adif.Marshal([]byte(data))
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to parse confirmation report")
+		return QslConfirmationImport{}, errors.Root(err)
+	}
+
+	result := QslConfirmationImport{Records: len(parsed.Records)}
+	for _, rec := range parsed.Records {
+		if !strings.EqualFold(strings.TrimSpace(rec.QslRcvd), "Y") {
+			continue
+		}
+		ids, qerr := s.fetchConfirmedQsoIds(rec)
+		if qerr != nil {
+			qerr = errors.New(op).Err(qerr)
+			s.LoggerService.ErrorWith().Err(qerr).Msg("Failed to match a confirmation to the logbook")
+			return result, errors.Root(qerr)
+		}
+		if len(ids) == 0 {
+			result.Unmatched++
+			continue
+		}
+		for _, id := range ids {
+			if _, qerr = s.DatabaseService.ExecContext(s.dbContext(),
+				`INSERT OR IGNORE INTO qso_confirmation (qso_id, source) VALUES (?, ?)`, id, source); qerr != nil {
+				qerr = errors.New(op).Err(qerr)
+				s.LoggerService.ErrorWith().Err(qerr).Msg("Failed to set QSO confirmation")
+				return result, errors.Root(qerr)
+			}
+		}
+		result.Confirmed++
+	}
+
+	s.LoggerService.InfoWith().Str("source", source).Int("records", result.Records).Int("confirmed", result.Confirmed).
+		Int("unmatched", result.Unmatched).Msg("QSL confirmations imported")
+	return result, nil
+}
+
+// SetQsoAwardReference sets the award reference of a QSO, overriding any reference derived from the QSO itself.
+// This is required for WAS, as QSOs carry no state. An empty reference removes the override.
+func (s *Service) SetQsoAwardReference(qsoId int64, award, reference string) error {
+	const op errors.Op = "facade.Service.SetQsoAwardReference"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	award = strings.ToUpper(strings.TrimSpace(award))
+	reference = strings.ToUpper(strings.TrimSpace(reference))
+	if _, ok := awardRefExpressions[award]; !ok {
+		return errors.New(op).Msgf("Unknown award: %q", award)
+	}
+
+	var err error
+	if reference == "" {
+		_, err = s.DatabaseService.ExecContext(s.dbContext(), `DELETE FROM qso_award_ref WHERE qso_id = ? AND award = ?`, qsoId, award)
+	} else {
+		if err = validateAwardReference(award, reference); err != nil {
+			return errors.New(op).Err(err)
+		}
+		_, err = s.DatabaseService.ExecContext(s.dbContext(), `INSERT INTO qso_award_ref (qso_id, award, reference) VALUES (?, ?, ?)
+ON CONFLICT (qso_id, award) DO UPDATE SET reference = excluded.reference`, qsoId, award, reference)
+	}
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set QSO award reference")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// computeAwardProgress aggregates the worked/confirmed state per reference, band and mode with a single query.
+func (s *Service) computeAwardProgress(award string, sources []string, withReferences bool) (AwardProgress, error) {
+	const op errors.Op = "facade.Service.computeAwardProgress"
+
+	query := `SELECT ref, band, mode, MAX(confirmed)
+FROM (SELECT COALESCE(r.reference, NULLIF(` + awardRefExpressions[award] + `, '')) AS ref,
+             q.band, q.mode, ` + confirmedExpression(sources) + ` AS confirmed
+      FROM qso q
+               LEFT JOIN qso_award_ref r ON r.qso_id = q.id AND r.award = ?
+      WHERE q.logbook_id = ?
+        AND q.deleted_at IS NULL)
+WHERE ref IS NOT NULL
+GROUP BY ref, band, mode
+ORDER BY ref, band, mode`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, award, s.CurrentLogbook.ID)
+	if err != nil {
+		return AwardProgress{}, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	progress := AwardProgress{
+		Award:  award,
+		Total:  awardTotals[award],
+		ByBand: make(map[string]AwardCount),
+		ByMode: make(map[string]AwardCount),
+	}
+
+	// Track per-reference state for the band and mode breakdowns, as a reference counts once per band/mode.
+	type refState struct {
+		AwardReference
+		bands map[string]bool // band -> confirmed
+		modes map[string]bool // mode -> confirmed
+	}
+	var refs []*refState
+	for rows.Next() {
+		var ref, band, mode string
+		var confirmed bool
+		if err = rows.Scan(&ref, &band, &mode, &confirmed); err != nil {
+			return AwardProgress{}, errors.New(op).Err(err)
+		}
+		if len(refs) == 0 || refs[len(refs)-1].Reference != ref {
+			refs = append(refs, &refState{
+				AwardReference: AwardReference{Reference: ref},
+				bands:          make(map[string]bool),
+				modes:          make(map[string]bool),
+			})
+		}
+		r := refs[len(refs)-1]
+		r.Confirmed = r.Confirmed || confirmed
+		r.bands[band] = r.bands[band] || confirmed
+		r.modes[mode] = r.modes[mode] || confirmed
+		r.Slots = append(r.Slots, AwardSlot{Band: band, Mode: mode, Confirmed: confirmed})
+	}
+	if err = rows.Err(); err != nil {
+		return AwardProgress{}, errors.New(op).Err(err)
+	}
+
+	for _, r := range refs {
+		progress.Worked++
+		if r.Confirmed {
+			progress.Confirmed++
+		}
+		for band, confirmed := range r.bands {
+			progress.ByBand[band] = incrementAwardCount(progress.ByBand[band], confirmed)
+		}
+		for mode, confirmed := range r.modes {
+			progress.ByMode[mode] = incrementAwardCount(progress.ByMode[mode], confirmed)
+		}
+		if withReferences {
+			progress.References = append(progress.References, r.AwardReference)
+		}
+	}
+
+	return progress, nil
+}
+
+// computeAwardNeeds determines the DXCC, band slot and mode slot needs for a country from the worked-before matrix.
+func (s *Service) computeAwardNeeds(callsign string, country types.Country, band, mode string) (AwardNeeds, error) {
+	const op errors.Op = "facade.Service.computeAwardNeeds"
+
+	matrix, err := s.computeWorkedMatrix(callsign, country, "")
+	if err != nil {
+		return AwardNeeds{Call: callsign, Country: country.Name, Band: band, Mode: mode}, errors.New(op).Err(err)
+	}
+
+	return matrix.awardNeeds(band, mode), nil
+}
+
+// fetchConfirmedQsoIds returns the IDs of the QSOs in the current logbook confirmed by a report record. The log keeps
+// times to the minute, so a report's seconds are ignored.
+func (s *Service) fetchConfirmedQsoIds(rec adif.Record) ([]int64, error) {
+	const op errors.Op = "facade.Service.fetchConfirmedQsoIds"
+
+	const query = `SELECT id
+FROM qso
+WHERE logbook_id = ?
+  AND deleted_at IS NULL
+  AND upper(call) = ?
+  AND lower(band) = ?
+  AND qso_date = ?
+  AND time_on = ?`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, s.CurrentLogbook.ID,
+		strings.ToUpper(strings.TrimSpace(rec.Call)), strings.ToLower(strings.TrimSpace(rec.Band)),
+		strings.TrimSpace(rec.QsoDate), adifTimeHHMM(rec.TimeOn))
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return ids, nil
+}
+
+// confirmedExpression returns an SQL expression that is true when the QSO aliased "q" is confirmed by any of the
+// given sources.
+func confirmedExpression(sources []string) string {
+	var parts []string
+	var electronic []string
+	for _, src := range sources {
+		if src == ConfirmationQsl {
+			parts = append(parts, `EXISTS (SELECT 1 FROM qsl_card k WHERE k.qso_id = q.id AND k.rcvd_status IN ('Y', 'V'))`)
+			continue
+		}
+		electronic = append(electronic, "'"+src+"'")
+	}
+	if len(electronic) > 0 {
+		parts = append(parts, `EXISTS (SELECT 1 FROM qso_confirmation c WHERE c.qso_id = q.id AND c.source IN (`+strings.Join(electronic, ", ")+`))`)
+	}
+	if len(parts) == 0 {
+		return "0"
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// normalizeConfirmationSources validates and upper-cases the sources. An empty list means all sources.
+func normalizeConfirmationSources(sources []string) ([]string, error) {
+	const op errors.Op = "facade.normalizeConfirmationSources"
+
+	if len(sources) == 0 {
+		return allConfirmationSources, nil
+	}
+
+	normalized := make([]string, 0, len(sources))
+	for _, src := range sources {
+		src = strings.ToUpper(strings.TrimSpace(src))
+		if !slices.Contains(allConfirmationSources, src) {
+			return nil, errors.New(op).Msgf("Unknown confirmation source: %q", src)
+		}
+		if !slices.Contains(normalized, src) {
+			normalized = append(normalized, src)
+		}
+	}
+
+	return normalized, nil
+}
+
+// validateAwardReference checks the format of an upper-cased award reference.
+func validateAwardReference(award, reference string) error {
+	const op errors.Op = "facade.validateAwardReference"
+
+	valid := true
+	switch award {
+	case AwardWAS:
+		valid = slices.Contains(usStates, reference)
+	case AwardWAZ:
+		zone, err := strconv.Atoi(reference)
+		valid = err == nil && zone >= 1 && zone <= 40 && strconv.Itoa(zone) == reference
+	case AwardIOTA:
+		valid = iotaRefRegex.MatchString(reference)
+	case AwardGrid:
+		valid = gridRefRegex.MatchString(reference)
+	case AwardDXCC:
+		code, err := strconv.Atoi(reference)
+		valid = err == nil && code >= 1 && code <= 999 && strconv.Itoa(code) == reference
+	}
+	if !valid {
+		return errors.New(op).Msgf("Invalid %s reference: %q", award, reference)
+	}
+
+	return nil
+}
+
+func incrementAwardCount(c AwardCount, confirmed bool) AwardCount {
+	c.Worked++
+	if confirmed {
+		c.Confirmed++
+	}
+	return c
+}
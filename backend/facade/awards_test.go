@@ -0,0 +1,303 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/types"
+)
+
+// =============================================================================
+// Award Guard Tests
+// =============================================================================
+
+func TestFetchAwardProgress_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchAwardProgress(AwardDXCC, nil); err == nil {
+		t.Error("FetchAwardProgress() should fail when service not initialized")
+	}
+}
+
+func TestFetchAwardProgress_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchAwardProgress(AwardDXCC, nil); err == nil {
+		t.Error("FetchAwardProgress() should fail when service not started")
+	}
+}
+
+func TestFetchAwardProgress_InvalidInput(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.FetchAwardProgress("WAC", nil); err == nil {
+		t.Error("FetchAwardProgress() should fail for an unknown award")
+	}
+	if _, err := s.FetchAwardProgress(AwardDXCC, []string{"CLUBLOG"}); err == nil {
+		t.Error("FetchAwardProgress() should fail for an unknown confirmation source")
+	}
+}
+
+func TestFetchAwardNeeds_InvalidCallsign(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.FetchAwardNeeds("AB", "20m", "SSB"); err == nil {
+		t.Error("FetchAwardNeeds() should fail for an invalid callsign")
+	}
+}
+
+func TestSetQsoAwardReference_InvalidInput(t *testing.T) {
+	s := createStartedTestService()
+
+	tests := []struct {
+		name      string
+		id        int64
+		award     string
+		reference string
+	}{
+		{"invalid id", 0, AwardWAS, "CA"},
+		{"unknown award", 1, "WAC", "EU"},
+		{"unknown state", 1, AwardWAS, "XX"},
+		{"zone out of range", 1, AwardWAZ, "41"},
+		{"malformed iota", 1, AwardIOTA, "EU5"},
+		{"malformed grid", 1, AwardGrid, "IO9"},
+		{"entity name", 1, AwardDXCC, "ENGLAND"},
+		{"entity code padded", 1, AwardDXCC, "0223"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.SetQsoAwardReference(tt.id, tt.award, tt.reference); err == nil {
+				t.Error("SetQsoAwardReference() should fail")
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Award Progress Tests
+// =============================================================================
+
+func TestFetchAwardProgress_DXCC(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	england20 := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	insertTestQso(t, s, "G4XYZ", "40m", "CW")
+	japan := newTestQso(s, "JA1ABC", "20m", "CW")
+	japan.ContactedStation.Country = "Japan"
+	japan.ContactedStation.DXCC = "339"
+	insertTestQsoValue(t, s, japan)
+
+	if err := s.SetQsoConfirmation(england20, ConfirmationLotw, true); err != nil {
+		t.Fatalf("SetQsoConfirmation() error = %v", err)
+	}
+
+	progress, err := s.FetchAwardProgress(AwardDXCC, nil)
+	if err != nil {
+		t.Fatalf("FetchAwardProgress() error = %v", err)
+	}
+	if progress.Worked != 2 || progress.Confirmed != 1 || progress.Total != 340 {
+		t.Errorf("progress = %d/%d of %d, want 2/1 of 340", progress.Worked, progress.Confirmed, progress.Total)
+	}
+	if got := progress.ByBand["20m"]; got != (AwardCount{Worked: 2, Confirmed: 1}) {
+		t.Errorf("ByBand[20m] = %+v", got)
+	}
+	if got := progress.ByBand["40m"]; got != (AwardCount{Worked: 1, Confirmed: 0}) {
+		t.Errorf("ByBand[40m] = %+v", got)
+	}
+	if got := progress.ByMode["CW"]; got != (AwardCount{Worked: 2, Confirmed: 0}) {
+		t.Errorf("ByMode[CW] = %+v", got)
+	}
+	if len(progress.References) != 2 || progress.References[0].Reference != "223" || len(progress.References[0].Slots) != 2 {
+		t.Errorf("References = %+v", progress.References)
+	}
+
+	// Restricting the sources to paper QSLs drops the LoTW confirmation.
+	progress, err = s.FetchAwardProgress(AwardDXCC, []string{"qsl"})
+	if err != nil {
+		t.Fatalf("FetchAwardProgress() error = %v", err)
+	}
+	if progress.Confirmed != 0 {
+		t.Errorf("Confirmed with QSL only = %d, want 0", progress.Confirmed)
+	}
+
+	if err = s.MarkQslCardReceived(england20, "B"); err != nil {
+		t.Fatalf("MarkQslCardReceived() error = %v", err)
+	}
+	if progress, _ = s.FetchAwardProgress(AwardDXCC, []string{"QSL"}); progress.Confirmed != 1 {
+		t.Errorf("Confirmed after QSL received = %d, want 1", progress.Confirmed)
+	}
+}
+
+func TestImportQslConfirmations(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	insertTestQso(t, s, "G4XYZ", "40m", "CW")
+
+	const report = `ARRL Logbook of the World Status Report
+<EOH>
+<CALL:5>G4ABC<BAND:3>20M<MODE:3>SSB<QSO_DATE:8>20240301<TIME_ON:6>120012<QSL_RCVD:1>Y<EOR>
+<CALL:5>G4XYZ<BAND:3>40M<MODE:2>CW<QSO_DATE:8>20240301<TIME_ON:4>1200<QSL_RCVD:1>N<EOR>
+<CALL:6>JA1ABC<BAND:3>15M<MODE:2>CW<QSO_DATE:8>20240301<TIME_ON:4>1300<QSL_RCVD:1>Y<EOR>
+`
+
+	result, err := s.ImportQslConfirmations("lotw", report)
+	if err != nil {
+		t.Fatalf("ImportQslConfirmations() error = %v", err)
+	}
+	if result != (QslConfirmationImport{Records: 3, Confirmed: 1, Unmatched: 1}) {
+		t.Errorf("ImportQslConfirmations() = %+v, want 3 records, 1 confirmed, 1 unmatched", result)
+	}
+
+	// Importing the report again is harmless.
+	if _, err = s.ImportQslConfirmations(ConfirmationLotw, report); err != nil {
+		t.Fatalf("ImportQslConfirmations() again error = %v", err)
+	}
+
+	progress, err := s.FetchAwardProgress(AwardDXCC, []string{ConfirmationLotw})
+	if err != nil {
+		t.Fatalf("FetchAwardProgress() error = %v", err)
+	}
+	if progress.Confirmed != 1 || len(progress.References) != 1 {
+		t.Fatalf("progress = %+v, want the entity confirmed", progress)
+	}
+	for _, slot := range progress.References[0].Slots {
+		if want := slot.Band == "20m"; slot.Confirmed != want {
+			t.Errorf("slot %+v confirmed = %v, want %v", slot, slot.Confirmed, want)
+		}
+	}
+	if progress, _ = s.FetchAwardProgress(AwardDXCC, []string{ConfirmationEqsl}); progress.Confirmed != 0 {
+		t.Errorf("Confirmed by eQSL = %d, want 0", progress.Confirmed)
+	}
+
+	if _, err = s.ImportQslConfirmations(ConfirmationQsl, report); err == nil {
+		t.Error("ImportQslConfirmations() of paper QSLs error = nil, want an error")
+	}
+}
+
+func TestFetchAwardProgress_DerivedAndOverriddenReferences(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	qso := newTestQso(s, "W6ABC", "20m", "SSB")
+	qso.ContactedStation.CQZ = "03"
+	qso.ContactedStation.Gridsquare = "cm87wj"
+	qso.ContactedStation.Iota = "NA-066"
+	id := insertTestQsoValue(t, s, qso)
+
+	tests := []struct {
+		award string
+		want  string
+	}{
+		{AwardWAZ, "3"},
+		{AwardGrid, "CM87"},
+		{AwardIOTA, "NA-066"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.award, func(t *testing.T) {
+			progress, err := s.FetchAwardProgress(tt.award, nil)
+			if err != nil {
+				t.Fatalf("FetchAwardProgress() error = %v", err)
+			}
+			if len(progress.References) != 1 || progress.References[0].Reference != tt.want {
+				t.Errorf("References = %+v, want %s", progress.References, tt.want)
+			}
+		})
+	}
+
+	// WAS has no derived reference until one is set.
+	progress, err := s.FetchAwardProgress(AwardWAS, nil)
+	if err != nil {
+		t.Fatalf("FetchAwardProgress() error = %v", err)
+	}
+	if progress.Worked != 0 {
+		t.Errorf("WAS worked = %d, want 0", progress.Worked)
+	}
+
+	if err = s.SetQsoAwardReference(id, "was", "ca"); err != nil {
+		t.Fatalf("SetQsoAwardReference() error = %v", err)
+	}
+	if progress, _ = s.FetchAwardProgress(AwardWAS, nil); progress.Worked != 1 || progress.References[0].Reference != "CA" {
+		t.Errorf("WAS after override = %+v", progress)
+	}
+
+	if err = s.SetQsoAwardReference(id, AwardWAS, ""); err != nil {
+		t.Fatalf("SetQsoAwardReference() error = %v", err)
+	}
+	if progress, _ = s.FetchAwardProgress(AwardWAS, nil); progress.Worked != 0 {
+		t.Errorf("WAS after removing override = %d, want 0", progress.Worked)
+	}
+}
+
+func TestFetchAwardsSummary(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	summary, err := s.FetchAwardsSummary(nil)
+	if err != nil {
+		t.Fatalf("FetchAwardsSummary() error = %v", err)
+	}
+	if len(summary) != len(AllAwards) {
+		t.Fatalf("FetchAwardsSummary() returned %d awards, want %d", len(summary), len(AllAwards))
+	}
+	if summary[0].Award != AwardDXCC || summary[0].Worked != 1 || summary[0].References != nil {
+		t.Errorf("summary[0] = %+v", summary[0])
+	}
+}
+
+// =============================================================================
+// Award Needs Tests
+// =============================================================================
+
+func TestComputeAwardNeeds(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	tests := []struct {
+		name    string
+		country string
+		ccode   string
+		band    string
+		mode    string
+		want    AwardNeeds
+	}{
+		{"new entity", "Japan", "JP", "20m", "SSB", AwardNeeds{NewDxcc: true, NewBandSlot: true, NewModeSlot: true}},
+		{"worked slot", "england", "", "20m", "SSB", AwardNeeds{}},
+		{"new band slot", "England", "", "40m", "SSB", AwardNeeds{NewBandSlot: true}},
+		{"new mode slot", "England", "", "20m", "CW", AwardNeeds{NewModeSlot: true}},
+		{"band and mode unknown", "Japan", "JP", "", "", AwardNeeds{NewDxcc: true}},
+		{"country unknown", "", "", "20m", "SSB", AwardNeeds{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.computeAwardNeeds("X1ABC", types.Country{Name: tt.country, Ccode: tt.ccode}, tt.band, tt.mode)
+			if err != nil {
+				t.Fatalf("computeAwardNeeds() error = %v", err)
+			}
+			if got.NewDxcc != tt.want.NewDxcc || got.NewBandSlot != tt.want.NewBandSlot || got.NewModeSlot != tt.want.NewModeSlot {
+				t.Errorf("computeAwardNeeds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAwardNeeds_UsesLocalCountry(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	if _, err := s.DatabaseService.InsertCountry(types.Country{Name: "England", Prefix: "G", Continent: "EU"}); err != nil {
+		t.Fatalf("InsertCountry() error = %v", err)
+	}
+
+	needs, err := s.FetchAwardNeeds("g3xyz/p", "40m", "SSB")
+	if err != nil {
+		t.Fatalf("FetchAwardNeeds() error = %v", err)
+	}
+	if needs.Country != "England" || needs.NewDxcc || !needs.NewBandSlot || needs.NewModeSlot {
+		t.Errorf("FetchAwardNeeds() = %+v", needs)
+	}
+}
+
+func TestConfirmedExpression(t *testing.T) {
+	if got := confirmedExpression(nil); got != "0" {
+		t.Errorf("confirmedExpression(nil) = %q", got)
+	}
+	got := confirmedExpression([]string{ConfirmationLotw, ConfirmationEqsl})
+	if !containsString(got, "c.source IN ('LOTW', 'EQSL')") || containsString(got, "qsl_card") {
+		t.Errorf("confirmedExpression() = %q", got)
+	}
+}
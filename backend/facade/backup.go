@@ -0,0 +1,409 @@
+package facade
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+const (
+	// backupDirName is the directory, in the working directory, backups are written to unless configured otherwise.
+	backupDirName = "backups"
+	// Backup files are named backupFilePrefix + UTC timestamp + backupFileSuffix, so they sort by age.
+	backupFilePrefix = "backup-"
+	backupFileSuffix = ".db"
+	backupTimeLayout = "20060102-150405.000"
+	// sqliteHeader starts every SQLite database file.
+	sqliteHeader = "SQLite format 3\x00"
+)
+
+// BackupInfo describes a backup of the database.
+type BackupInfo struct {
+	Name      string    `json:"name"` // file name in the backup directory
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupDatabase takes a backup of the database now, then removes the backups beyond the retention count.
+func (s *Service) BackupDatabase() (BackupInfo, error) {
+	const op errors.Op = "facade.Service.BackupDatabase"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return BackupInfo{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return BackupInfo{}, errors.Root(err)
+	}
+
+	info, err := s.backupDatabase("manual")
+	if err != nil {
+		return BackupInfo{}, errors.Root(errors.New(op).Err(err))
+	}
+	return info, nil
+}
+
+// FetchBackups returns the backups in the backup directory, newest first.
+func (s *Service) FetchBackups() ([]BackupInfo, error) {
+	const op errors.Op = "facade.Service.FetchBackups"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	backups, err := s.listBackups()
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to list backups")
+		return nil, errors.Root(err)
+	}
+	slices.Reverse(backups)
+	return backups, nil
+}
+
+// RestoreBackup replaces the database with a backup, given by its name in the backup directory or by a path. The
+// backup is checked first: it must be an intact SQLite database holding the current logbook. The database is backed
+// up before it is replaced, then reopened, starting a new session; the frontend is sent EventDatabaseRestored.
+// The workers wait for the database while it is swapped.
+func (s *Service) RestoreBackup(name string) error {
+	const op errors.Op = "facade.Service.RestoreBackup"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	src := name
+	if filepath.Base(name) == name {
+		src = filepath.Join(s.backupDir(), name)
+	}
+	if err := s.validateDatabaseFile(src); err != nil {
+		err = errors.New(op).Err(err).Msg("Not a valid backup")
+		s.LoggerService.ErrorWith().Err(err).Str("path", src).Msg("Backup failed validation")
+		return errors.Root(err)
+	}
+
+	// Not rotated, which could remove the backup being restored.
+	if _, err := s.snapshotDatabase("pre-restore"); err != nil {
+		err = errors.New(op).Err(err).Msg("Failed to back up the database before restoring")
+		s.LoggerService.ErrorWith().Err(err).Msg("Restore cancelled")
+		return errors.Root(err)
+	}
+
+	if err := s.swapDatabase(src); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to restore the backup")
+		return errors.Root(err)
+	}
+
+	s.statsMu.Lock()
+	s.statsCache = nil
+	s.statsMu.Unlock()
+	s.rate.reset()
+
+	s.LoggerService.InfoWith().Str("path", src).Msg("Database restored from backup")
+	s.emitEvent(EventDatabaseRestored.String(), s.CurrentLogbook)
+	return nil
+}
+
+// backupDatabase takes a snapshot of the database and rotates the backups. The reason is logged.
+func (s *Service) backupDatabase(reason string) (BackupInfo, error) {
+	const op errors.Op = "facade.Service.backupDatabase"
+
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	info, err := s.snapshotDatabase(reason)
+	if err != nil {
+		return BackupInfo{}, errors.New(op).Err(err)
+	}
+	if err = s.rotateBackups(); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to remove old backups")
+	}
+	return info, nil
+}
+
+// snapshotDatabase writes the open database to a new file in the backup directory with VACUUM INTO, which is
+// consistent while the database is in use, and checks the file. The caller holds backupMu.
+func (s *Service) snapshotDatabase(reason string) (BackupInfo, error) {
+	const op errors.Op = "facade.Service.snapshotDatabase"
+
+	dir := s.backupDir()
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return BackupInfo{}, errors.New(op).Err(err)
+	}
+
+	now := time.Now().UTC()
+	name := backupFilePrefix + now.Format(backupTimeLayout) + backupFileSuffix
+	path := filepath.Join(dir, name)
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), `VACUUM INTO ?`, path); err != nil {
+		return BackupInfo{}, errors.New(op).Err(err)
+	}
+	if err := s.validateDatabaseFile(path); err != nil {
+		_ = os.Remove(path)
+		return BackupInfo{}, errors.New(op).Err(err).Msg("Backup failed validation")
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return BackupInfo{}, errors.New(op).Err(err)
+	}
+	info := BackupInfo{Name: name, Size: fi.Size(), CreatedAt: now}
+	s.LoggerService.InfoWith().Str("name", name).Str("reason", reason).Int64("size", info.Size).Msg("Database backed up")
+	return info, nil
+}
+
+// rotateBackups removes the oldest backups beyond the retention count. Zero keeps every backup.
+func (s *Service) rotateBackups() error {
+	const op errors.Op = "facade.Service.rotateBackups"
+
+	keep := s.options.Backup.Keep
+	if keep <= 0 {
+		return nil
+	}
+	backups, err := s.listBackups()
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	for len(backups) > keep {
+		if err = os.Remove(filepath.Join(s.backupDir(), backups[0].Name)); err != nil {
+			return errors.New(op).Err(err)
+		}
+		s.LoggerService.DebugWith().Str("name", backups[0].Name).Msg("Old backup removed")
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// listBackups returns the backups in the backup directory, oldest first. Other files are ignored.
+func (s *Service) listBackups() ([]BackupInfo, error) {
+	const op errors.Op = "facade.Service.listBackups"
+
+	entries, err := os.ReadDir(s.backupDir())
+	if os.IsNotExist(err) {
+		return []BackupInfo{}, nil
+	}
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	backups := make([]BackupInfo, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, backupFileSuffix) {
+			continue
+		}
+		created, perr := time.Parse(backupTimeLayout, strings.TrimSuffix(strings.TrimPrefix(name, backupFilePrefix), backupFileSuffix))
+		if perr != nil {
+			continue
+		}
+		fi, ierr := e.Info()
+		if ierr != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{Name: name, Size: fi.Size(), CreatedAt: created})
+	}
+	// ReadDir sorts by name, which is by age.
+	return backups, nil
+}
+
+// backupDir returns the directory backups are written to.
+func (s *Service) backupDir() string {
+	if dir := s.options.Backup.Dir; dir != "" {
+		return dir
+	}
+	return filepath.Join(s.ConfigService.WorkingDir, backupDirName)
+}
+
+// validateDatabaseFile checks that a file is an intact SQLite database holding the log tables and the current
+// logbook. The file is opened read-only on its own connection.
+func (s *Service) validateDatabaseFile(path string) error {
+	const op errors.Op = "facade.Service.validateDatabaseFile"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(f, header)
+	_ = f.Close()
+	if err != nil || !bytes.Equal(header, []byte(sqliteHeader)) {
+		return errors.New(op).Msg("not a SQLite database")
+	}
+
+	db, err := sql.Open(s.DatabaseService.DatabaseConfig.Driver, "file:"+(&url.URL{Path: path}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = db.Close() }()
+	ctx := s.dbContext()
+
+	var integrity string
+	if err = db.QueryRowContext(ctx, `PRAGMA integrity_check(1)`).Scan(&integrity); err != nil {
+		return errors.New(op).Err(err)
+	}
+	if integrity != "ok" {
+		return errors.New(op).Msgf("integrity check failed: %s", integrity)
+	}
+
+	var tables int
+	const tablesQuery = `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name IN ('logbook', 'qso')`
+	if err = db.QueryRowContext(ctx, tablesQuery).Scan(&tables); err != nil {
+		return errors.New(op).Err(err)
+	}
+	if tables != 2 {
+		return errors.New(op).Msg("not a log database")
+	}
+
+	var logbooks int
+	const logbookQuery = `SELECT count(*) FROM logbook WHERE id = ? AND deleted_at IS NULL`
+	if err = db.QueryRowContext(ctx, logbookQuery, s.CurrentLogbook.ID).Scan(&logbooks); err != nil {
+		return errors.New(op).Err(err)
+	}
+	if logbooks == 0 {
+		return errors.New(op).Msgf("logbook %d not found", s.CurrentLogbook.ID)
+	}
+
+	return nil
+}
+
+// replaceDatabaseFile copies src over the closed database at dst. The copy is written next to dst and renamed over
+// it, so dst is either replaced or left as it was. The write-ahead log of the old database is removed.
+func replaceDatabaseFile(src, dst string) error {
+	const op errors.Op = "facade.replaceDatabaseFile"
+
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp := dst + ".restore"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return errors.New(op).Err(err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err = os.Remove(dst + suffix); err != nil && !os.IsNotExist(err) {
+			_ = os.Remove(tmp)
+			return errors.New(op).Err(err)
+		}
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
+
+// swapDatabase closes the database, replaces its file with src and reopens it, holding dbGate throughout so that
+// the workers wait. The database is reopened whether or not the file was replaced; the current logbook is known to
+// be in either.
+func (s *Service) swapDatabase(src string) error {
+	const op errors.Op = "facade.Service.swapDatabase"
+
+	s.dbGate.Lock()
+	defer s.dbGate.Unlock()
+
+	_, sessionID := s.currentOperatorSession()
+	if err := s.DatabaseService.SoftDeleteSessionByID(sessionID); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to soft-delete session ID")
+	}
+	if err := s.DatabaseService.Close(); err != nil {
+		return errors.New(op).Err(err).Msg("Failed to close database for restore")
+	}
+
+	swapErr := replaceDatabaseFile(src, s.DatabaseService.DatabaseConfig.Path)
+	if swapErr != nil {
+		s.LoggerService.ErrorWith().Err(swapErr).Msg("Failed to replace the database file, reopening the current one")
+	}
+	if err := s.reopenDatabase(); err != nil {
+		return errors.New(op).Err(err).Msg("Failed to reopen database after restore")
+	}
+	if swapErr != nil {
+		return errors.New(op).Err(swapErr)
+	}
+	return nil
+}
+
+// reopenDatabase opens and migrates the database again, reloads the current logbook and starts a new session.
+func (s *Service) reopenDatabase() error {
+	const op errors.Op = "facade.Service.reopenDatabase"
+
+	if err := s.openDatabase(); err != nil {
+		return errors.New(op).Err(err)
+	}
+	logbook, err := s.DatabaseService.FetchLogbookByID(s.CurrentLogbook.ID)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	s.CurrentLogbook = logbook
+
+	s.operatorMu.Lock()
+	defer s.operatorMu.Unlock()
+	if s.sessionID, err = s.DatabaseService.GenerateSession(); err != nil {
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
+
+// backupWorker takes a backup every configured interval. The first is due an interval after the newest backup, so
+// a logger that is never left running for a whole interval is still backed up.
+func (s *Service) backupWorker(shutdown <-chan struct{}) {
+	interval := time.Duration(s.options.Backup.IntervalHours) * time.Hour
+
+	delay := time.Duration(0)
+	if backups, err := s.listBackups(); err == nil && len(backups) > 0 {
+		delay = max(time.Until(backups[len(backups)-1].CreatedAt.Add(interval)), 0)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			s.LoggerService.DebugWith().Msg("Backup worker received shutdown signal")
+			return
+		case <-s.ctx.Done():
+			s.LoggerService.DebugWith().Msg("Backup worker context cancelled")
+			return
+		case <-timer.C:
+			if _, err := s.backupDatabase("scheduled"); err != nil {
+				s.LoggerService.ErrorWith().Err(err).Msg("Scheduled database backup failed")
+			}
+			timer.Reset(interval)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package facade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createBackupTestService(t *testing.T, keep int) *Service {
+	t.Helper()
+	s := createDatabaseTestService(t)
+	s.options.Backup = BackupOptions{Dir: filepath.Join(t.TempDir(), "backups"), Keep: keep}
+	return s
+}
+
+// =============================================================================
+// Backup Tests
+// =============================================================================
+
+func TestBackupDatabase(t *testing.T) {
+	s := createBackupTestService(t, 2)
+	insertTestQso(t, s, "G4XY", "20m", "SSB")
+
+	var names []string
+	for range 3 {
+		info, err := s.BackupDatabase()
+		if err != nil {
+			t.Fatalf("BackupDatabase() error = %v", err)
+		}
+		if info.Size == 0 || info.CreatedAt.IsZero() {
+			t.Errorf("BackupDatabase() = %+v", info)
+		}
+		if err = s.validateDatabaseFile(filepath.Join(s.backupDir(), info.Name)); err != nil {
+			t.Errorf("validateDatabaseFile(%s) error = %v", info.Name, err)
+		}
+		names = append(names, info.Name)
+	}
+
+	// The oldest is rotated out; the list is newest first.
+	backups, err := s.FetchBackups()
+	if err != nil {
+		t.Fatalf("FetchBackups() error = %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != names[2] || backups[1].Name != names[1] {
+		t.Errorf("FetchBackups() = %+v, want %v", backups, names[1:])
+	}
+}
+
+func TestFetchBackups_IgnoresOtherFiles(t *testing.T) {
+	s := createBackupTestService(t, 0)
+	if err := os.MkdirAll(s.backupDir(), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"notes.txt", "backup-latest.db"} {
+		if err := os.WriteFile(filepath.Join(s.backupDir(), name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := s.FetchBackups()
+	if err != nil || len(backups) != 0 {
+		t.Errorf("FetchBackups() = %+v, %v, want none", backups, err)
+	}
+}
+
+// =============================================================================
+// Restore Tests
+// =============================================================================
+
+func TestRestoreBackup(t *testing.T) {
+	s := createBackupTestService(t, 1)
+	insertTestQso(t, s, "G4XY", "20m", "SSB")
+	info, err := s.BackupDatabase()
+	if err != nil {
+		t.Fatalf("BackupDatabase() error = %v", err)
+	}
+	insertTestQso(t, s, "DL1ABC", "40m", "CW")
+	oldSession := s.sessionID
+
+	if err = s.RestoreBackup(info.Name); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if n := adifQsoCount(t, s); n != 1 {
+		t.Errorf("%d QSOs after restore, want 1", n)
+	}
+	if s.sessionID == oldSession {
+		t.Error("session not renewed after restore")
+	}
+
+	// The replaced database was backed up first, without rotating out the restored backup.
+	backups, err := s.FetchBackups()
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("FetchBackups() = %+v, %v, want the restored and the pre-restore backups", backups, err)
+	}
+	if err = s.RestoreBackup(backups[0].Name); err != nil {
+		t.Fatalf("RestoreBackup(pre-restore) error = %v", err)
+	}
+	if n := adifQsoCount(t, s); n != 2 {
+		t.Errorf("%d QSOs after restoring the pre-restore backup, want 2", n)
+	}
+}
+
+func TestRestoreBackup_WaitsForWorkers(t *testing.T) {
+	s := createBackupTestService(t, 1)
+	insertTestQso(t, s, "G4XY", "20m", "SSB")
+	info, err := s.BackupDatabase()
+	if err != nil {
+		t.Fatalf("BackupDatabase() error = %v", err)
+	}
+
+	// A worker in the middle of its database work holds the gate for reading.
+	s.dbGate.RLock()
+	done := make(chan error, 1)
+	go func() { done <- s.RestoreBackup(info.Name) }()
+
+	select {
+	case err = <-done:
+		s.dbGate.RUnlock()
+		t.Fatalf("RestoreBackup() = %v before the worker was done", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if n := adifQsoCount(t, s); n != 1 {
+		t.Errorf("%d QSOs while a worker holds the gate, want 1", n)
+	}
+	s.dbGate.RUnlock()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatalf("RestoreBackup() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RestoreBackup() did not finish after the worker was done")
+	}
+}
+
+func TestRestoreBackup_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, s *Service) string
+	}{
+		{
+			name: "missing file",
+			setup: func(t *testing.T, s *Service) string {
+				return "backup-20260101-000000.000.db"
+			},
+		},
+		{
+			name: "not a database",
+			setup: func(t *testing.T, s *Service) string {
+				path := filepath.Join(t.TempDir(), "log.db")
+				if err := os.WriteFile(path, []byte("<call:4>G4XY<eor>"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+		},
+		{
+			name: "other logbook",
+			setup: func(t *testing.T, s *Service) string {
+				info, err := s.BackupDatabase()
+				if err != nil {
+					t.Fatalf("BackupDatabase() error = %v", err)
+				}
+				s.CurrentLogbook.ID = 99
+				t.Cleanup(func() { s.CurrentLogbook.ID = 1 })
+				return info.Name
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createBackupTestService(t, 0)
+			insertTestQso(t, s, "G4XY", "20m", "SSB")
+			name := tt.setup(t, s)
+
+			if err := s.RestoreBackup(name); err == nil {
+				t.Fatal("RestoreBackup() error = nil, want an error")
+			}
+			s.CurrentLogbook.ID = 1
+			if n := adifQsoCount(t, s); n != 1 {
+				t.Errorf("%d QSOs, want the database untouched", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package facade
+
+import (
+	"strings"
+
+	"github.com/Station-Manager/enums/bands"
+	"github.com/Station-Manager/enums/modes"
+)
+
+// bandEdge is the frequency range of an amateur band, in kHz. The ranges are the widest allocation across the
+// IARU regions, so a frequency maps to a band wherever the station is.
+type bandEdge struct {
+	band     bands.Band
+	min, max float64
+}
+
+var bandEdges = []bandEdge{
+	{bands.Band160, 1800, 2000},
+	{bands.Band80, 3500, 4000},
+	{bands.Band60, 5060, 5450},
+	{bands.Band40, 7000, 7300},
+	{bands.Band30, 10100, 10150},
+	{bands.Band20, 14000, 14350},
+	{bands.Band17, 18068, 18168},
+	{bands.Band15, 21000, 21450},
+	{bands.Band12, 24890, 24990},
+	{bands.Band10, 28000, 29700},
+	{bands.Band6, 50000, 54000},
+}
+
+// bandSegment is a simplified IARU band plan for a band, in kHz: CW below cwEnd, phone from phoneStart, and the
+// narrow-band data modes in between. Bands without a phone allocation have a zero phoneStart.
+type bandSegment struct {
+	band              bands.Band
+	cwEnd, phoneStart float64
+	lowerSideband     bool
+}
+
+var bandSegments = []bandSegment{
+	{bands.Band160, 1838, 1843, true},
+	{bands.Band80, 3570, 3600, true},
+	{bands.Band60, 5354, 5354, false},
+	{bands.Band40, 7040, 7060, true},
+	{bands.Band30, 10130, 0, false},
+	{bands.Band20, 14070, 14101, false},
+	{bands.Band17, 18095, 18111, false},
+	{bands.Band15, 21070, 21151, false},
+	{bands.Band12, 24915, 24931, false},
+	{bands.Band10, 28070, 28320, false},
+	{bands.Band6, 50100, 50100, false},
+}
+
+// ft8DialKhz are the FT8 and FT4 dial frequencies; a signal up to ftWindowKhz above one is taken as MFSK.
+var ft8DialKhz = []float64{
+	1840, 3573, 3575, 5357, 7047.5, 7074, 10136, 10140, 14074, 14080, 18100, 18104, 21074, 21140, 24915, 24919,
+	28074, 28180, 50313, 50318,
+}
+
+const ftWindowKhz = 3
+
+// unlistedMfskModes are the WSJT-X modes that the modes enum lists neither as modes nor as submodes; they are
+// taken as MFSK.
+var unlistedMfskModes = map[string]bool{"FT8": true, "JT65": true, "JT9": true, "WSPR": true}
+
+// bandForKhz returns the band for a frequency in kHz, or an empty string if it is outside the supported bands.
+func bandForKhz(khz float64) string {
+	for _, e := range bandEdges {
+		if khz >= e.min && khz <= e.max {
+			return e.band.String()
+		}
+	}
+	return ""
+}
+
+// bandIndex returns the position of a band in the band plan, lowest first, or len(bandEdges) if it is not in it.
+func bandIndex(band string) int {
+	for i, e := range bandEdges {
+		if e.band.String() == band {
+			return i
+		}
+	}
+	return len(bandEdges)
+}
+
+// modeFromComment returns the mode named in a free-text comment (e.g., a DX spot's), or an empty string. Submodes
+// are mapped to their main mode, and the WSJT-X modes that the modes enum does not list are taken as MFSK.
+func modeFromComment(comment string) string {
+	for _, f := range strings.Fields(strings.ToUpper(comment)) {
+		f = strings.Trim(f, ".,:;!()[]")
+		switch {
+		case modes.IsValidMode(f):
+			return f
+		case unlistedMfskModes[f]:
+			return modes.MFSK.String()
+		}
+		if m, ok := modes.GetModeBySubmode(f); ok {
+			return m.String()
+		}
+	}
+	return ""
+}
+
+// modeForKhz returns the mode most likely in use at a frequency according to the band plan, or an empty string
+// for the data sub-bands, where it cannot be told.
+func modeForKhz(khz float64) string {
+	for _, f := range ft8DialKhz {
+		if khz >= f && khz <= f+ftWindowKhz {
+			return modes.MFSK.String()
+		}
+	}
+
+	band := bandForKhz(khz)
+	for _, seg := range bandSegments {
+		if seg.band.String() != band {
+			continue
+		}
+		switch {
+		case khz < seg.cwEnd:
+			return modes.CW.String()
+		case seg.phoneStart > 0 && khz >= seg.phoneStart:
+			return modes.SSB.String()
+		}
+		return ""
+	}
+	return ""
+}
+
+// sidebandForKhz returns the conventional SSB sideband at a frequency: LSB below 10 MHz, except on 60m.
+func sidebandForKhz(khz float64) string {
+	band := bandForKhz(khz)
+	for _, seg := range bandSegments {
+		if seg.band.String() == band && seg.lowerSideband {
+			return modes.LSB.String()
+		}
+	}
+	return modes.USB.String()
+}
@@ -0,0 +1,100 @@
+package facade
+
+import "testing"
+
+func TestBandForKhz(t *testing.T) {
+	tests := []struct {
+		khz  float64
+		want string
+	}{
+		{1830, "160m"},
+		{3799.5, "80m"},
+		{5357, "60m"},
+		{7074, "40m"},
+		{10136, "30m"},
+		{14000, "20m"},
+		{14350, "20m"},
+		{18100, "17m"},
+		{21074, "15m"},
+		{24915, "12m"},
+		{28400, "10m"},
+		{50313, "6m"},
+		{14351, ""},
+		{144300, ""},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		if got := bandForKhz(tt.khz); got != tt.want {
+			t.Errorf("bandForKhz(%v) = %q, want %q", tt.khz, got, tt.want)
+		}
+	}
+}
+
+func TestModeFromComment(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    string
+	}{
+		{"CW 599 tnx QSO", "CW"},
+		{"cw 24 wpm", "CW"},
+		{"up 5 USB", "SSB"},
+		{"FT8 -10 dB", "MFSK"},
+		{"FT4", "MFSK"},
+		{"RTTY contest", "RTTY"},
+		{"(PSK31)", "PSK"},
+		{"tnx QSO", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := modeFromComment(tt.comment); got != tt.want {
+			t.Errorf("modeFromComment(%q) = %q, want %q", tt.comment, got, tt.want)
+		}
+	}
+}
+
+func TestModeForKhz(t *testing.T) {
+	tests := []struct {
+		khz  float64
+		want string
+	}{
+		{1825, "CW"},
+		{1840.5, "MFSK"},
+		{3790, "SSB"},
+		{7010, "CW"},
+		{7074.8, "MFSK"},
+		{7045, ""},
+		{10110, "CW"},
+		{10145, ""},
+		{14025, "CW"},
+		{14080.5, "MFSK"},
+		{14090, ""},
+		{14250, "SSB"},
+		{28500, "SSB"},
+		{50150, "SSB"},
+		{144300, ""},
+	}
+	for _, tt := range tests {
+		if got := modeForKhz(tt.khz); got != tt.want {
+			t.Errorf("modeForKhz(%v) = %q, want %q", tt.khz, got, tt.want)
+		}
+	}
+}
+
+func TestSidebandForKhz(t *testing.T) {
+	tests := []struct {
+		khz  float64
+		want string
+	}{
+		{1850, "LSB"},
+		{3700, "LSB"},
+		{5357, "USB"},
+		{7150, "LSB"},
+		{14250, "USB"},
+		{50150, "USB"},
+	}
+	for _, tt := range tests {
+		if got := sidebandForKhz(tt.khz); got != tt.want {
+			t.Errorf("sidebandForKhz(%v) = %q, want %q", tt.khz, got, tt.want)
+		}
+	}
+}
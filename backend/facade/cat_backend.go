@@ -0,0 +1,75 @@
+package facade
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/rigctld"
+	"github.com/Station-Manager/types"
+)
+
+// The CAT backends a rig can be selected to use in the app options.
+const (
+	catBackendSerial  = "serial"
+	catBackendRigctld = "rigctld"
+)
+
+// catBackend is the part of the CAT service the facade uses. It is implemented by cat.Service, for rigs driven over
+// a serial port, and by rigctld.Client, for rigs driven by a Hamlib daemon.
+type catBackend interface {
+	Start() error
+	Stop() error
+	EnqueueCommand(cmdName cmds.CatCmdName, params ...string) error
+	StatusChannel() (<-chan types.CatStatus, error)
+	RigConfig() types.RigConfig
+}
+
+// catService returns the CAT backend for the current run: the CatService, unless the app options select another
+// backend for the rig.
+func (s *Service) catService() catBackend {
+	if s.altCat != nil {
+		return s.altCat
+	}
+	return s.CatService
+}
+
+// newCatBackend creates the CAT backend selected for the rig in the app options. It returns nil if the rig uses the
+// serial CAT service.
+func (s *Service) newCatBackend(rig types.RigConfig, backends []CatBackendOptions) (catBackend, error) {
+	const op errors.Op = "facade.Service.newCatBackend"
+
+	var opts *CatBackendOptions
+	for i := range backends {
+		if backends[i].RigID == rig.ID {
+			opts = &backends[i]
+			break
+		}
+	}
+	if opts == nil {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(opts.Backend)) {
+	case "", catBackendSerial:
+		return nil, nil
+	case catBackendRigctld:
+		client, err := rigctld.New(rigctld.Config{
+			Host:         opts.Host,
+			Port:         opts.Port,
+			Rig:          rig,
+			PollInterval: time.Duration(opts.PollIntervalMs) * time.Millisecond,
+			Timeout:      time.Duration(opts.TimeoutMs) * time.Millisecond,
+			ErrorHandler: func(err error) {
+				s.LoggerService.WarnWith().Err(err).Msg("rigctld CAT backend error")
+			},
+		})
+		if err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		return client, nil
+	}
+
+	return nil, errors.New(op).Msgf("Unknown CAT backend %q for rig %d", opts.Backend, rig.ID)
+}
@@ -0,0 +1,88 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/logging-app/backend/rigctld"
+	"github.com/Station-Manager/types"
+)
+
+func TestNewCatBackend(t *testing.T) {
+	rig := types.RigConfig{ID: 2, Name: "IC-7300"}
+
+	tests := []struct {
+		name        string
+		backends    []CatBackendOptions
+		wantRigctld bool
+		wantErr     bool
+	}{
+		{"no options", nil, false, false},
+		{"other rig", []CatBackendOptions{{RigID: 1, Backend: "rigctld", Host: "localhost"}}, false, false},
+		{"serial", []CatBackendOptions{{RigID: 2, Backend: "serial"}}, false, false},
+		{"rigctld", []CatBackendOptions{{RigID: 1, Backend: "serial"}, {RigID: 2, Backend: "RigCtlD", Host: "localhost"}}, true, false},
+		{"rigctld without host", []CatBackendOptions{{RigID: 2, Backend: "rigctld"}}, false, true},
+		{"unknown backend", []CatBackendOptions{{RigID: 2, Backend: "flrig"}}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createInitializedTestService()
+			backend, err := s.newCatBackend(rig, tt.backends)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newCatBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, ok := backend.(*rigctld.Client); ok != tt.wantRigctld {
+				t.Errorf("newCatBackend() = %T, want rigctld %v", backend, tt.wantRigctld)
+			}
+			if !tt.wantRigctld && backend != nil {
+				t.Errorf("newCatBackend() = %T, want nil for the serial CAT service", backend)
+			}
+		})
+	}
+}
+
+func TestCatService_SelectsBackend(t *testing.T) {
+	s := createStartedTestService()
+	if s.catService() != s.CatService {
+		t.Error("catService() should return the CatService by default")
+	}
+
+	client, err := rigctld.New(rigctld.Config{Host: "localhost", Rig: types.RigConfig{ID: 2, Name: "IC-7300"}})
+	if err != nil {
+		t.Fatalf("rigctld.New() error = %v", err)
+	}
+	s.altCat = client
+	if s.catService() != client {
+		t.Error("catService() should return the selected backend")
+	}
+
+	caps, err := s.FetchRigCapabilities()
+	if err != nil {
+		t.Fatalf("FetchRigCapabilities() error = %v", err)
+	}
+	if caps.Rig != "IC-7300" || !caps.Frequency || !caps.Split || !caps.Ptt || caps.Filter {
+		t.Errorf("FetchRigCapabilities() = %+v", caps)
+	}
+}
+
+// The rigctld backend must accept the commands the facade sends under the same names.
+func TestRigctldCommandNames(t *testing.T) {
+	names := map[cmds.CatCmdName]cmds.CatCmdName{
+		catCmdSetFreq:  rigctld.CmdSetFreq,
+		catCmdSetFreqB: rigctld.CmdSetFreqB,
+		catCmdSetMode:  rigctld.CmdSetMode,
+		catCmdSetVfo:   rigctld.CmdSetVfo,
+		catCmdSetSplit: rigctld.CmdSetSplit,
+		catCmdPttOn:    rigctld.CmdPttOn,
+		catCmdPttOff:   rigctld.CmdPttOff,
+
+		catCmdSendCw:      rigctld.CmdSendCw,
+		catCmdStopCw:      rigctld.CmdStopCw,
+		catCmdSetKeySpeed: rigctld.CmdSetKeySpeed,
+	}
+	for facadeName, rigctldName := range names {
+		if facadeName != rigctldName {
+			t.Errorf("facade command %s is named %s by rigctld", facadeName, rigctldName)
+		}
+	}
+}
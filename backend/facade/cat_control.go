@@ -0,0 +1,139 @@
+package facade
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// CAT commands the facade sends to control the rig. Like the commands in the cmds enum, they are defined per rig in
+// the rig config; a rig without one of them cannot be controlled that way from the app.
+const (
+	// catCmdSetFreq sets VFO A. Its single parameter is the frequency in Hz, zero-padded to the length of the rig's
+	// VFO A frequency state, e.g. "FA%s;" for Yaesu rigs.
+	catCmdSetFreq cmds.CatCmdName = "SETFREQ"
+	// catCmdSetFreqB sets VFO B, as catCmdSetFreq does VFO A, e.g. "FB%s;".
+	catCmdSetFreqB cmds.CatCmdName = "SETFREQB"
+	// catCmdSetMode sets the main mode. Its single parameter is the rig's own mode code, taken from the value
+	// mappings of the rig's main mode state, e.g. "MD0%s;" for Yaesu rigs.
+	catCmdSetMode cmds.CatCmdName = "SETMODE"
+	// catCmdSetFilter sets the receive filter. Its single parameter is the rig's own filter code, passed through as
+	// given, e.g. "SH0%s;".
+	catCmdSetFilter cmds.CatCmdName = "SETFILTER"
+	// catCmdSetVfo selects the VFO. Its single parameter is the rig's code from the VFO select state, e.g. "VS%s;".
+	catCmdSetVfo cmds.CatCmdName = "SETVFO"
+	// catCmdSetSplit turns split on or off. Its single parameter is the rig's code from the split state, e.g. "ST%s;".
+	catCmdSetSplit cmds.CatCmdName = "SETSPLIT"
+	// catCmdPttOn and catCmdPttOff key and unkey the transmitter. They take no parameters, e.g. "TX1;" and "TX0;".
+	catCmdPttOn  cmds.CatCmdName = "PTTON"
+	catCmdPttOff cmds.CatCmdName = "PTTOFF"
+)
+
+// defaultFreqDigits is the frequency parameter width for rigs that have no VFO A frequency state.
+const defaultFreqDigits = 9
+
+// rigModeLabels lists, per app mode (and SSB sideband), the rig mode labels to look for in the rig's main mode value
+// mappings, in order of preference.
+var rigModeLabels = map[string][]string{
+	modes.CW.String():   {"CW-U", "CW", "CW-L"},
+	modes.LSB.String():  {"LSB"},
+	modes.USB.String():  {"USB"},
+	modes.AM.String():   {"AM"},
+	modes.FM.String():   {"FM"},
+	modes.RTTY.String(): {"RTTY-L", "RTTY", "RTTY-U"},
+	modes.MFSK.String(): {"DATA-U", "PKT-U", "DATA", "USB"},
+	modes.PSK.String():  {"PSK", "DATA-U", "PKT-U", "USB"},
+}
+
+// tuneRig enqueues the commands to set the rig to the given frequency and mode, followed by a read so the frontend
+// picks up the new state. The mode is left unchanged if it is empty or the rig has no way of setting it.
+func (s *Service) tuneRig(khz float64, mode string) error {
+	const op errors.Op = "facade.Service.tuneRig"
+
+	cfg := s.catService().RigConfig()
+	if !hasCatCommand(cfg, catCmdSetFreq) {
+		return errors.New(op).Msgf("The rig config has no %s command", catCmdSetFreq)
+	}
+
+	if err := s.catService().EnqueueCommand(catCmdSetFreq, rigFreqParam(cfg, tags.VfoAFreq, khz)); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	if mode != "" && hasCatCommand(cfg, catCmdSetMode) {
+		if code, ok := rigModeParam(cfg, mode, khz); ok {
+			if err := s.catService().EnqueueCommand(catCmdSetMode, code); err != nil {
+				return errors.New(op).Err(err)
+			}
+		} else {
+			s.LoggerService.WarnWith().Str("mode", mode).Msg("The rig config has no mode mapping for the mode")
+		}
+	}
+
+	if err := s.catService().EnqueueCommand(cmds.Read); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// hasCatCommand reports whether the rig config defines the named command.
+func hasCatCommand(cfg types.RigConfig, name cmds.CatCmdName) bool {
+	for _, c := range cfg.CatCommands {
+		if c.Name == name.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// rigFreqParam formats a frequency in kHz as the rig expects it: Hz, zero-padded to the width of the rig's frequency
+// state for the VFO.
+func rigFreqParam(cfg types.RigConfig, vfoTag tags.CatStateTag, khz float64) string {
+	return fmt.Sprintf("%0*d", rigFreqDigits(cfg, vfoTag), int64(math.Round(khz*1000)))
+}
+
+// rigFreqDigits returns the width of the rig's frequency state for the VFO.
+func rigFreqDigits(cfg types.RigConfig, vfoTag tags.CatStateTag) int {
+	if m, ok := catStateMarker(cfg, vfoTag); ok && m.Length > 0 {
+		return m.Length
+	}
+	return defaultFreqDigits
+}
+
+// rigModeParam returns the rig's code for an app mode at a frequency, using the SSB sideband conventional there.
+func rigModeParam(cfg types.RigConfig, mode string, khz float64) (string, bool) {
+	m, ok := catStateMarker(cfg, tags.MainMode)
+	if !ok {
+		return "", false
+	}
+
+	if mode == modes.SSB.String() {
+		mode = sidebandForKhz(khz)
+	}
+	for _, label := range rigModeLabels[mode] {
+		for _, vm := range m.ValueMappings {
+			if strings.EqualFold(vm.Value, label) {
+				return vm.Key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// catStateMarker returns the rig's state marker for a tag.
+func catStateMarker(cfg types.RigConfig, tag tags.CatStateTag) (types.Marker, bool) {
+	for _, st := range cfg.CatStates {
+		for _, m := range st.Markers {
+			if m.Tag == tag.String() {
+				return m, true
+			}
+		}
+	}
+	return types.Marker{}, false
+}
@@ -0,0 +1,109 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// testRigConfig returns a Yaesu-style rig config with set commands and main mode mappings.
+func testRigConfig() types.RigConfig {
+	return types.RigConfig{
+		CatCommands: []types.CatCommand{
+			{Name: cmds.Read.String(), Cmd: "FA;MD0;"},
+			{Name: catCmdSetFreq.String(), Cmd: "FA%s;"},
+			{Name: catCmdSetMode.String(), Cmd: "MD0%s;"},
+		},
+		CatStates: []types.CatState{
+			{Prefix: "FA", Markers: []types.Marker{{Tag: tags.VfoAFreq.String(), Length: 9}}},
+			{Prefix: "MD0", Markers: []types.Marker{{
+				Tag:    tags.MainMode.String(),
+				Length: 1,
+				ValueMappings: []types.ValueMapping{
+					{Key: "1", Value: "LSB"},
+					{Key: "2", Value: "USB"},
+					{Key: "3", Value: "CW-U"},
+					{Key: "4", Value: "FM"},
+					{Key: "6", Value: "RTTY-L"},
+					{Key: "C", Value: "DATA-U"},
+				},
+			}}},
+		},
+	}
+}
+
+func TestRigFreqParam(t *testing.T) {
+	cfg := testRigConfig()
+
+	tests := []struct {
+		name string
+		cfg  types.RigConfig
+		khz  float64
+		want string
+	}{
+		{"20m", cfg, 14025.0, "014025000"},
+		{"6m", cfg, 50313.5, "050313500"},
+		{"rounds to the hertz", cfg, 7074.0004, "007074000"},
+		{"default width without a VFO A state", types.RigConfig{}, 1830, "001830000"},
+		{"marker width", types.RigConfig{CatStates: []types.CatState{{Markers: []types.Marker{{Tag: tags.VfoAFreq.String(), Length: 11}}}}}, 14025, "00014025000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rigFreqParam(tt.cfg, tags.VfoAFreq, tt.khz); got != tt.want {
+				t.Errorf("rigFreqParam() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRigModeParam(t *testing.T) {
+	cfg := testRigConfig()
+
+	tests := []struct {
+		mode   string
+		khz    float64
+		want   string
+		wantOk bool
+	}{
+		{"CW", 14025, "3", true},
+		{"SSB", 14250, "2", true},
+		{"SSB", 7150, "1", true},
+		{"SSB", 5357, "2", true},
+		{"FM", 29600, "4", true},
+		{"RTTY", 14085, "6", true},
+		{"MFSK", 14074, "C", true},
+		{"AM", 7200, "", false},
+		{"PACKET", 14100, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, ok := rigModeParam(cfg, tt.mode, tt.khz)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("rigModeParam(%q, %v) = %q, %v; want %q, %v", tt.mode, tt.khz, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	if _, ok := rigModeParam(types.RigConfig{}, "CW", 14025); ok {
+		t.Error("rigModeParam() should fail without a main mode state")
+	}
+}
+
+func TestHasCatCommand(t *testing.T) {
+	cfg := testRigConfig()
+	if !hasCatCommand(cfg, catCmdSetFreq) || !hasCatCommand(cfg, cmds.Read) {
+		t.Error("hasCatCommand() should find configured commands")
+	}
+	if hasCatCommand(cfg, cmds.PlayBack) {
+		t.Error("hasCatCommand() should not find unconfigured commands")
+	}
+}
+
+func TestTuneRig_NoSetFreqCommand(t *testing.T) {
+	s := createStartedTestService()
+	if err := s.tuneRig(14025, "CW"); err == nil {
+		t.Error("tuneRig() should fail when the rig config has no SETFREQ command")
+	}
+}
@@ -0,0 +1,228 @@
+package facade
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/errors"
+)
+
+// CAT connection states, as carried by EventCatConnection.
+const (
+	catConnected    = "connected"
+	catDisconnected = "disconnected"
+	catReconnecting = "reconnecting"
+)
+
+// CatConnectionStatus is the state of the connection to the rig, as seen by the CAT health supervisor.
+type CatConnectionStatus struct {
+	Enabled   bool      `json:"enabled"`
+	State     string    `json:"state"`
+	Attempt   int       `json:"attempt"`     // the reconnection attempt, while reconnecting
+	RetryInMs int64     `json:"retry_in_ms"` // the delay before the attempt, while reconnecting
+	Error     string    `json:"error"`       // the reason for the last disconnection or failed attempt, if any
+	Since     time.Time `json:"since"`
+}
+
+// catHealth holds the CAT connection status for the current run.
+type catHealth struct {
+	mu     sync.Mutex
+	status CatConnectionStatus
+}
+
+// reset sets the status for a new run. The rig is taken to be disconnected until it reports its state.
+func (h *catHealth) reset(enabled bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = CatConnectionStatus{Enabled: enabled, State: catDisconnected, Since: time.Now().UTC()}
+}
+
+// get returns the current status.
+func (h *catHealth) get() CatConnectionStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// set records a status, reporting whether it differs from the current one.
+func (h *catHealth) set(state string, attempt int, retryIn time.Duration, err error) (CatConnectionStatus, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	msg := ""
+	if err != nil {
+		msg = errors.Root(err).Error()
+	}
+	if h.status.State == state && h.status.Attempt == attempt && h.status.Error == msg {
+		return h.status, false
+	}
+
+	h.status.State = state
+	h.status.Attempt = attempt
+	h.status.RetryInMs = retryIn.Milliseconds()
+	h.status.Error = msg
+	h.status.Since = time.Now().UTC()
+	return h.status, true
+}
+
+// FetchCatConnectionStatus returns the state of the connection to the rig.
+func (s *Service) FetchCatConnectionStatus() (CatConnectionStatus, error) {
+	const op errors.Op = "facade.Service.FetchCatConnectionStatus"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return CatConnectionStatus{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return CatConnectionStatus{}, errors.Root(err)
+	}
+
+	return s.catHealth.get(), nil
+}
+
+// setCatConnection records the CAT connection state and emits it to the frontend if it changed.
+func (s *Service) setCatConnection(state string, attempt int, retryIn time.Duration, err error) {
+	if status, changed := s.catHealth.set(state, attempt, retryIn, err); changed {
+		s.emitEvent(EventCatConnection.String(), status)
+	}
+}
+
+// catHealthSupervisor watches the CAT status updates until shutdown. Rigs only report changes, so a rig that has
+// been silent for a heartbeat is probed with a read command; if it does not answer, the CAT backend is restarted,
+// with a backoff, until it does.
+func (s *Service) catHealthSupervisor(shutdown <-chan struct{}) {
+	opts := s.options.CatHealth
+	heartbeat := time.Duration(opts.HeartbeatMs) * time.Millisecond
+	started := time.Now()
+
+	for {
+		_, updated, changed := s.catState.get()
+		if updated.IsZero() {
+			updated = started
+		} else if time.Since(updated) < heartbeat {
+			s.setCatConnection(catConnected, 0, 0, nil)
+		}
+
+		timer := time.NewTimer(max(heartbeat-time.Since(updated), 0))
+		select {
+		case <-shutdown:
+			timer.Stop()
+			return
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-changed:
+			timer.Stop()
+			s.setCatConnection(catConnected, 0, 0, nil)
+			continue
+		case <-timer.C:
+		}
+
+		if s.probeCat(shutdown, opts) {
+			s.setCatConnection(catConnected, 0, 0, nil)
+			continue
+		}
+		if !s.reconnectCat(shutdown, opts) {
+			return
+		}
+	}
+}
+
+// probeCat asks the rig for its state and reports whether it answered in time.
+func (s *Service) probeCat(shutdown <-chan struct{}, opts CatHealthOptions) bool {
+	_, _, changed := s.catState.get()
+	if err := s.catService().EnqueueCommand(cmds.Read); err != nil {
+		s.LoggerService.DebugWith().Err(err).Msg("Failed to probe the rig")
+		return false
+	}
+
+	timer := time.NewTimer(time.Duration(opts.ProbeTimeoutMs) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-changed:
+		return true
+	case <-timer.C:
+	case <-shutdown:
+	case <-s.ctx.Done():
+	}
+	return false
+}
+
+// reconnectCat restarts the CAT backend until the rig answers. It returns false if shut down first.
+func (s *Service) reconnectCat(shutdown <-chan struct{}, opts CatHealthOptions) bool {
+	const op errors.Op = "facade.Service.reconnectCat"
+
+	var lastErr error = errors.New(op).Msg("The rig stopped responding")
+	s.LoggerService.WarnWith().Err(lastErr).Msg("CAT connection lost, reconnecting")
+	s.setCatConnection(catDisconnected, 0, 0, lastErr)
+
+	for attempt := 1; ; attempt++ {
+		delay := catReconnectDelay(attempt, opts)
+		s.setCatConnection(catReconnecting, attempt, delay, lastErr)
+
+		// The backend may reconnect by itself, e.g., rigctld, in which case the rig reports its state.
+		_, _, changed := s.catState.get()
+		timer := time.NewTimer(delay)
+		select {
+		case <-shutdown:
+			timer.Stop()
+			return false
+		case <-s.ctx.Done():
+			timer.Stop()
+			return false
+		case <-changed:
+			timer.Stop()
+			s.LoggerService.InfoWith().Int("attempt", attempt).Msg("CAT connection restored")
+			s.setCatConnection(catConnected, 0, 0, nil)
+			return true
+		case <-timer.C:
+		}
+
+		if err := s.restartCat(); err != nil {
+			lastErr = err
+			s.LoggerService.WarnWith().Err(err).Int("attempt", attempt).Msg("Failed to restart the CAT backend")
+			continue
+		}
+
+		// The rig may have been power-cycled, losing the settings made by the init command.
+		if err := s.catService().EnqueueCommand(cmds.Init); err != nil {
+			s.LoggerService.DebugWith().Err(err).Msg("Failed to re-initialize the rig")
+		}
+		if s.probeCat(shutdown, opts) {
+			s.LoggerService.InfoWith().Int("attempt", attempt).Msg("CAT connection restored")
+			s.setCatConnection(catConnected, 0, 0, nil)
+			return true
+		}
+		lastErr = errors.New(op).Msg("The rig did not respond after the CAT backend was restarted")
+	}
+}
+
+// restartCat stops and starts the CAT backend. Both backends keep their status channel, so the status listener
+// carries on.
+func (s *Service) restartCat() error {
+	const op errors.Op = "facade.Service.restartCat"
+
+	cat := s.catService()
+	if err := cat.Stop(); err != nil {
+		s.LoggerService.DebugWith().Err(err).Msg("Failed to stop the CAT backend before restarting it")
+	}
+	if err := cat.Start(); err != nil {
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
+
+// catReconnectDelay returns the delay before a reconnection attempt: the minimum delay, doubled per attempt up to
+// the maximum.
+func catReconnectDelay(attempt int, opts CatHealthOptions) time.Duration {
+	delay := time.Duration(opts.ReconnectMinMs) * time.Millisecond
+	limit := time.Duration(opts.ReconnectMaxMs) * time.Millisecond
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
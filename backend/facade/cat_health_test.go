@@ -0,0 +1,195 @@
+package facade
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// createHealthTestService returns a started service with short CAT health timings and a stub CAT backend that
+// answers read commands while alive is set.
+func createHealthTestService(alive *atomic.Bool) (*Service, *stubCatBackend) {
+	s := createStartedTestService()
+	s.options = defaultAppOptions()
+	s.options.CatHealth = CatHealthOptions{
+		Enabled:        true,
+		HeartbeatMs:    40,
+		ProbeTimeoutMs: 30,
+		ReconnectMinMs: 5,
+		ReconnectMaxMs: 20,
+	}
+	s.catHealth.reset(true)
+
+	stub := &stubCatBackend{onEnqueue: func(name cmds.CatCmdName, _ ...string) error {
+		if name == cmds.Read && alive.Load() {
+			go s.catState.update(types.CatStatus{tags.VfoAFreq.String(): "014074000"}, time.Now())
+		}
+		return nil
+	}}
+	s.altCat = stub
+	return s, stub
+}
+
+// runHealthSupervisor runs the supervisor until the test ends.
+func runHealthSupervisor(t *testing.T, s *Service) {
+	t.Helper()
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.catHealthSupervisor(shutdown)
+	}()
+	t.Cleanup(func() {
+		close(shutdown)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("the supervisor did not exit on shutdown")
+		}
+	})
+}
+
+// =============================================================================
+// Supervisor Tests
+// =============================================================================
+
+func TestCatHealthSupervisor_ConnectedOnStatus(t *testing.T) {
+	var alive atomic.Bool
+	s, stub := createHealthTestService(&alive)
+	runHealthSupervisor(t, s)
+
+	s.catState.update(types.CatStatus{tags.MainMode.String(): "USB"}, time.Now())
+	if !waitFor(t, time.Second, func() bool { return s.catHealth.get().State == catConnected }) {
+		t.Fatalf("status = %+v, want connected after a status update", s.catHealth.get())
+	}
+	if stub.starts.Load() != 0 {
+		t.Errorf("starts = %d, want no restart", stub.starts.Load())
+	}
+}
+
+func TestCatHealthSupervisor_ProbesSilentRig(t *testing.T) {
+	var alive atomic.Bool
+	alive.Store(true)
+	s, stub := createHealthTestService(&alive)
+	runHealthSupervisor(t, s)
+
+	// The rig reports nothing by itself, but answers the probes.
+	time.Sleep(200 * time.Millisecond)
+	if got := s.catHealth.get(); got.State != catConnected {
+		t.Errorf("status = %+v, want connected", got)
+	}
+	if stub.starts.Load() != 0 {
+		t.Errorf("starts = %d, want no restart of a rig that answers", stub.starts.Load())
+	}
+}
+
+func TestCatHealthSupervisor_ReconnectsWithBackoff(t *testing.T) {
+	var alive atomic.Bool
+	s, stub := createHealthTestService(&alive)
+	stub.onStart = func() error {
+		// The port comes back on the third attempt.
+		if stub.starts.Load() < 3 {
+			return errors.New("serial port not found")
+		}
+		alive.Store(true)
+		return nil
+	}
+	runHealthSupervisor(t, s)
+
+	if !waitFor(t, time.Second, func() bool { return s.catHealth.get().State == catReconnecting }) {
+		t.Fatalf("status = %+v, want reconnecting", s.catHealth.get())
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return s.catHealth.get().State == catConnected }) {
+		t.Fatalf("status = %+v, want connected after the restart", s.catHealth.get())
+	}
+
+	if got := stub.starts.Load(); got != 3 {
+		t.Errorf("starts = %d, want 3", got)
+	}
+	if got := s.catHealth.get(); got.Attempt != 0 || got.Error != "" {
+		t.Errorf("status = %+v, want the attempt and error cleared", got)
+	}
+}
+
+func TestCatHealthSupervisor_RecoversWithoutRestart(t *testing.T) {
+	var alive atomic.Bool
+	s, stub := createHealthTestService(&alive)
+	s.options.CatHealth.ReconnectMinMs = 200
+	s.options.CatHealth.ReconnectMaxMs = 200
+	runHealthSupervisor(t, s)
+
+	if !waitFor(t, time.Second, func() bool { return s.catHealth.get().State == catReconnecting }) {
+		t.Fatalf("status = %+v, want reconnecting", s.catHealth.get())
+	}
+
+	// The backend reconnects by itself before the restart is due.
+	s.catState.update(types.CatStatus{tags.MainMode.String(): "USB"}, time.Now())
+	if !waitFor(t, time.Second, func() bool { return s.catHealth.get().State == catConnected }) {
+		t.Fatalf("status = %+v, want connected", s.catHealth.get())
+	}
+	if got := stub.starts.Load(); got != 0 {
+		t.Errorf("starts = %d, want no restart", got)
+	}
+}
+
+// =============================================================================
+// Status Tests
+// =============================================================================
+
+func TestCatHealth_SetReportsChanges(t *testing.T) {
+	var h catHealth
+	h.reset(true)
+
+	if _, changed := h.set(catDisconnected, 0, 0, nil); changed {
+		t.Error("set() reported a change for the same state")
+	}
+	status, changed := h.set(catReconnecting, 1, time.Second, errors.New("no answer"))
+	if !changed || status.Attempt != 1 || status.RetryInMs != 1000 || status.Error != "no answer" {
+		t.Errorf("set() = %+v, %v", status, changed)
+	}
+	if _, changed = h.set(catReconnecting, 2, 2*time.Second, errors.New("no answer")); !changed {
+		t.Error("set() should report a new attempt")
+	}
+}
+
+func TestFetchCatConnectionStatus(t *testing.T) {
+	s := createStartedTestService()
+	s.catHealth.reset(false)
+
+	got, err := s.FetchCatConnectionStatus()
+	if err != nil {
+		t.Fatalf("FetchCatConnectionStatus() error = %v", err)
+	}
+	if got.Enabled || got.State != catDisconnected {
+		t.Errorf("FetchCatConnectionStatus() = %+v", got)
+	}
+
+	if _, err = createInitializedTestService().FetchCatConnectionStatus(); err == nil {
+		t.Error("FetchCatConnectionStatus() should fail when not started")
+	}
+}
+
+func TestCatReconnectDelay(t *testing.T) {
+	opts := CatHealthOptions{ReconnectMinMs: 1000, ReconnectMaxMs: 10000}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := catReconnectDelay(tt.attempt, opts); got != tt.want {
+			t.Errorf("catReconnectDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package facade
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// catSnapshot holds the rig state as last reported by the CAT backend. Each status holds only the states that were
+// reported, so they are merged into the snapshot. The zero value is ready for use.
+type catSnapshot struct {
+	mu      sync.Mutex
+	status  types.CatStatus
+	updated time.Time
+	// changed is closed, and replaced, on every update, so a caller can wait for the next one.
+	changed chan struct{}
+}
+
+// update merges a status into the snapshot.
+func (c *catSnapshot) update(status types.CatStatus, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.status == nil {
+		c.status = make(types.CatStatus, len(status))
+	}
+	maps.Copy(c.status, status)
+	c.updated = at
+
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// reset clears the snapshot for a new run, as the rig may have changed.
+func (c *catSnapshot) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.status = nil
+	c.updated = time.Time{}
+}
+
+// get returns a copy of the snapshot and when it was last updated, with a channel that is closed on the next update.
+func (c *catSnapshot) get() (types.CatStatus, time.Time, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.changed == nil {
+		c.changed = make(chan struct{})
+	}
+	return maps.Clone(c.status), c.updated, c.changed
+}
+
+// freshCatStatus returns the rig state if it is recent enough to pre-fill a QSO. Rigs only report changes, so an
+// older snapshot is refreshed with a read command; if the rig does not answer in time, it is taken to be
+// disconnected and nothing is returned.
+func (s *Service) freshCatStatus(opts CatPrefillOptions) (types.CatStatus, bool) {
+	maxAge := time.Duration(opts.MaxAgeSeconds) * time.Second
+
+	status, updated, changed := s.catState.get()
+	if !updated.IsZero() && time.Since(updated) <= maxAge {
+		return status, true
+	}
+
+	if err := s.catService().EnqueueCommand(cmds.Read); err != nil {
+		s.LoggerService.DebugWith().Err(err).Msg("Failed to refresh the CAT state for the new QSO")
+		return nil, false
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(time.Duration(opts.RefreshWaitMs) * time.Millisecond):
+		s.LoggerService.DebugWith().Msg("The rig did not report its state in time; not pre-filling the QSO")
+		return nil, false
+	}
+
+	status, _, _ = s.catState.get()
+	return status, true
+}
+
+// prefillQsoFromCat sets the frequencies, bands, mode and transmit power of a new QSO from the live rig state. When
+// split, the frequencies are assigned as the frontend does when logging: FREQ from the selected VFO and FREQ_RX from
+// the other.
+func (s *Service) prefillQsoFromCat(details *types.QsoDetails) {
+	opts := s.options.CatPrefill
+	if !opts.Enabled {
+		return
+	}
+
+	status, ok := s.freshCatStatus(opts)
+	if !ok {
+		return
+	}
+
+	freq, other := status[tags.VfoAFreq.String()], status[tags.VfoBFreq.String()]
+	if normalizeVfo(status[tags.Select.String()]) == vfoB {
+		freq, other = other, freq
+	}
+
+	if hz, ok := catFreqHz(freq); ok {
+		details.Freq = strconv.FormatInt(hz, 10)
+		details.Band = bandForKhz(float64(hz) / 1000)
+	}
+	if strings.HasPrefix(status[tags.Split.String()], "ON") {
+		if hz, ok := catFreqHz(other); ok {
+			details.FreqRx = strconv.FormatInt(hz, 10)
+			details.BandRx = bandForKhz(float64(hz) / 1000)
+		}
+	}
+
+	if mode, submode := catModeToQso(status[tags.MainMode.String()], details.Freq); mode != "" {
+		details.Mode = mode
+		details.Submode = submode
+	}
+
+	if pwr, err := strconv.Atoi(strings.TrimSpace(status[tags.TxPwr.String()])); err == nil && pwr >= 0 {
+		// Some rigs report power in other units than watts; the multiplier converts them, as in the frontend.
+		if s.requiredCfgs != nil && s.requiredCfgs.UsePowerMultiplier && s.requiredCfgs.PowerMultiplier > 0 {
+			pwr *= s.requiredCfgs.PowerMultiplier
+		}
+		details.TxPwr = strconv.Itoa(pwr)
+	}
+}
+
+// catFreqHz parses a CAT frequency, which is in Hz and zero-padded.
+func catFreqHz(freq string) (int64, bool) {
+	hz, err := strconv.ParseInt(strings.TrimSpace(freq), 10, 64)
+	if err != nil || hz <= 0 {
+		return 0, false
+	}
+	return hz, true
+}
+
+// catModeToQso converts a rig mode label, such as "USB", "CW-U" or "DATA-U", to an ADIF mode and submode. The data
+// modes do not say which mode is in use, so they are taken from the band plan, if it tells, or left empty.
+func catModeToQso(label, freqHz string) (mode, submode string) {
+	label = strings.ToUpper(strings.TrimSpace(label))
+	if label == "" {
+		return "", ""
+	}
+	if modes.IsValidMode(label) {
+		return label, ""
+	}
+	if m, ok := modes.GetModeBySubmode(label); ok {
+		return m.String(), label
+	}
+
+	base, _, _ := strings.Cut(label, "-")
+	switch base {
+	case "CW", "AM", "FM", "RTTY", "PSK":
+		return base, ""
+	case "DATA", "PKT":
+		if hz, ok := catFreqHz(freqHz); ok && modeForKhz(float64(hz)/1000) == modes.MFSK.String() {
+			return modes.MFSK.String(), ""
+		}
+	}
+	return "", ""
+}
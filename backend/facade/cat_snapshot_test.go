@@ -0,0 +1,254 @@
+package facade
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// stubCatBackend is a CAT backend that calls onEnqueue for every command instead of talking to a rig, and reports
+// the statuses sent on statuses, if set. Start calls onStart, if set, and the starts and stops are counted.
+type stubCatBackend struct {
+	rig       types.RigConfig
+	onEnqueue func(name cmds.CatCmdName, params ...string) error
+	onStart   func() error
+	statuses  chan types.CatStatus
+	starts    atomic.Int32
+	stops     atomic.Int32
+}
+
+func (b *stubCatBackend) Start() error {
+	b.starts.Add(1)
+	if b.onStart != nil {
+		return b.onStart()
+	}
+	return nil
+}
+func (b *stubCatBackend) Stop() error {
+	b.stops.Add(1)
+	return nil
+}
+func (b *stubCatBackend) EnqueueCommand(name cmds.CatCmdName, params ...string) error {
+	if b.onEnqueue != nil {
+		return b.onEnqueue(name, params...)
+	}
+	return nil
+}
+func (b *stubCatBackend) StatusChannel() (<-chan types.CatStatus, error) {
+	if b.statuses != nil {
+		return b.statuses, nil
+	}
+	return make(chan types.CatStatus), nil
+}
+func (b *stubCatBackend) RigConfig() types.RigConfig { return b.rig }
+
+func createPrefillTestService() *Service {
+	s := createStartedTestService()
+	s.options = defaultAppOptions()
+	return s
+}
+
+// =============================================================================
+// Snapshot Tests
+// =============================================================================
+
+func TestCatSnapshot_MergesUpdates(t *testing.T) {
+	var c catSnapshot
+	_, _, changed := c.get()
+
+	at := time.Now()
+	c.update(types.CatStatus{tags.VfoAFreq.String(): "014074000", tags.MainMode.String(): "USB"}, at)
+	select {
+	case <-changed:
+	default:
+		t.Error("update() should signal a waiting caller")
+	}
+
+	c.update(types.CatStatus{tags.MainMode.String(): "CW-U"}, at.Add(time.Second))
+	status, updated, _ := c.get()
+	if status[tags.VfoAFreq.String()] != "014074000" || status[tags.MainMode.String()] != "CW-U" {
+		t.Errorf("get() = %v, want the merged states", status)
+	}
+	if !updated.Equal(at.Add(time.Second)) {
+		t.Errorf("updated = %v, want the last update", updated)
+	}
+
+	// The returned status is a copy.
+	status[tags.MainMode.String()] = "AM"
+	if again, _, _ := c.get(); again[tags.MainMode.String()] != "CW-U" {
+		t.Error("get() should return a copy of the snapshot")
+	}
+
+	c.reset()
+	if status, updated, _ = c.get(); len(status) != 0 || !updated.IsZero() {
+		t.Errorf("reset() left %v at %v", status, updated)
+	}
+}
+
+// =============================================================================
+// Prefill Tests
+// =============================================================================
+
+func TestPrefillQsoFromCat(t *testing.T) {
+	tests := []struct {
+		name   string
+		status types.CatStatus
+		want   types.QsoDetails
+	}{
+		{
+			name: "simplex",
+			status: types.CatStatus{
+				tags.VfoAFreq.String(): "014025000",
+				tags.VfoBFreq.String(): "007030000",
+				tags.Split.String():    "OFF",
+				tags.Select.String():   "VFO-A",
+				tags.MainMode.String(): "CW-U",
+				tags.TxPwr.String():    "100",
+			},
+			want: types.QsoDetails{Freq: "14025000", Band: "20m", Mode: "CW", TxPwr: "100"},
+		},
+		{
+			name: "split on vfo b",
+			status: types.CatStatus{
+				tags.VfoAFreq.String(): "014195000",
+				tags.VfoBFreq.String(): "014200000",
+				tags.Split.String():    "ON",
+				tags.Select.String():   "VFO-B",
+				tags.MainMode.String(): "USB",
+			},
+			want: types.QsoDetails{Freq: "14200000", Band: "20m", FreqRx: "14195000", BandRx: "20m", Mode: "SSB", Submode: "USB"},
+		},
+		{
+			name: "ft8 data mode",
+			status: types.CatStatus{
+				tags.VfoAFreq.String(): "007074000",
+				tags.MainMode.String(): "DATA-U",
+			},
+			want: types.QsoDetails{Freq: "7074000", Band: "40m", Mode: "MFSK"},
+		},
+		{
+			name:   "nothing usable",
+			status: types.CatStatus{tags.VfoAFreq.String(): "", tags.MainMode.String(): "", tags.TxPwr.String(): "x"},
+			want:   types.QsoDetails{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createPrefillTestService()
+			s.catState.update(tt.status, time.Now())
+
+			var got types.QsoDetails
+			s.prefillQsoFromCat(&got)
+			if got != tt.want {
+				t.Errorf("prefillQsoFromCat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefillQsoFromCat_PowerMultiplier(t *testing.T) {
+	s := createPrefillTestService()
+	s.requiredCfgs = &types.RequiredConfigs{UsePowerMultiplier: true, PowerMultiplier: 10}
+	s.catState.update(types.CatStatus{tags.TxPwr.String(): "050"}, time.Now())
+
+	var got types.QsoDetails
+	s.prefillQsoFromCat(&got)
+	if got.TxPwr != "500" {
+		t.Errorf("TxPwr = %q, want %q", got.TxPwr, "500")
+	}
+}
+
+func TestPrefillQsoFromCat_Disabled(t *testing.T) {
+	s := createPrefillTestService()
+	s.options.CatPrefill.Enabled = false
+	s.catState.update(types.CatStatus{tags.VfoAFreq.String(): "014025000"}, time.Now())
+
+	var got types.QsoDetails
+	s.prefillQsoFromCat(&got)
+	if got.Freq != "" {
+		t.Errorf("Freq = %q, want no prefill when disabled", got.Freq)
+	}
+}
+
+func TestPrefillQsoFromCat_StaleRefreshed(t *testing.T) {
+	s := createPrefillTestService()
+	s.catState.update(types.CatStatus{tags.VfoAFreq.String(): "014025000"}, time.Now().Add(-time.Minute))
+
+	// The rig answers the refresh with its current frequency.
+	s.altCat = &stubCatBackend{onEnqueue: func(name cmds.CatCmdName, _ ...string) error {
+		if name == cmds.Read {
+			go s.catState.update(types.CatStatus{tags.VfoAFreq.String(): "021074000"}, time.Now())
+		}
+		return nil
+	}}
+
+	var got types.QsoDetails
+	s.prefillQsoFromCat(&got)
+	if got.Freq != "21074000" || got.Band != "15m" {
+		t.Errorf("prefillQsoFromCat() = %+v, want the refreshed state", got)
+	}
+}
+
+func TestPrefillQsoFromCat_StaleDisconnected(t *testing.T) {
+	s := createPrefillTestService()
+	s.options.CatPrefill.RefreshWaitMs = 20
+	s.catState.update(types.CatStatus{tags.VfoAFreq.String(): "014025000"}, time.Now().Add(-time.Minute))
+
+	reads := 0
+	s.altCat = &stubCatBackend{onEnqueue: func(name cmds.CatCmdName, _ ...string) error {
+		if name == cmds.Read {
+			reads++
+		}
+		return nil
+	}}
+
+	var got types.QsoDetails
+	s.prefillQsoFromCat(&got)
+	if reads != 1 {
+		t.Errorf("refresh reads = %d, want 1", reads)
+	}
+	if got.Freq != "" {
+		t.Errorf("Freq = %q, want no prefill from a rig that does not answer", got.Freq)
+	}
+}
+
+func TestPrefillQsoFromCat_NoCatService(t *testing.T) {
+	// The test CAT service is not started, so the refresh cannot be sent.
+	s := createPrefillTestService()
+
+	var got types.QsoDetails
+	s.prefillQsoFromCat(&got)
+	if got != (types.QsoDetails{}) {
+		t.Errorf("prefillQsoFromCat() = %+v, want no prefill", got)
+	}
+}
+
+func TestCatModeToQso(t *testing.T) {
+	tests := []struct {
+		label, freq       string
+		wantMode, wantSub string
+	}{
+		{"USB", "", "SSB", "USB"},
+		{"lsb", "", "SSB", "LSB"},
+		{"CW-U", "", "CW", ""},
+		{"CW-L", "", "CW", ""},
+		{"FM-N", "", "FM", ""},
+		{"AM", "", "AM", ""},
+		{"RTTY-L", "", "RTTY", ""},
+		{"PSK", "", "PSK", ""},
+		{"DATA-U", "14074000", "MFSK", ""},
+		{"DATA-U", "14090000", "", ""},
+		{"DATA-FM", "", "", ""},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		mode, sub := catModeToQso(tt.label, tt.freq)
+		if mode != tt.wantMode || sub != tt.wantSub {
+			t.Errorf("catModeToQso(%q, %q) = %q, %q; want %q, %q", tt.label, tt.freq, mode, sub, tt.wantMode, tt.wantSub)
+		}
+	}
+}
@@ -43,12 +43,15 @@ func (s *Service) openAndLoadFromDatabase() error {
 	s.CurrentLogbook = logbook
 
 	// Generate a new session id
-	s.sessionID, err = s.DatabaseService.GenerateSession()
+	sessionID, err := s.DatabaseService.GenerateSession()
 	if err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to generate new session ID.")
 		return err
 	}
+	s.operatorMu.Lock()
+	s.sessionID = sessionID
+	s.operatorMu.Unlock()
 
 	return nil
 }
@@ -67,6 +70,11 @@ func (s *Service) openDatabase() error {
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to migrate database.")
 		return err
 	}
+	if err := s.migrateAppSchema(); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to migrate app schema.")
+		return err
+	}
 
 	return nil
 }
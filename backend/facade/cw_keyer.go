@@ -0,0 +1,335 @@
+package facade
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// CAT commands for the rig's internal CW keyer. Like the rig control commands, they are defined per rig in the rig
+// config.
+const (
+	// catCmdSendCw sends text with the keyer. Its single parameter is the text, at most the chunk length set in the
+	// app options, e.g. "KY %s;" for Kenwood rigs.
+	catCmdSendCw cmds.CatCmdName = "SENDCW"
+	// catCmdStopCw aborts the text being sent. It takes no parameters, e.g. "KY0;".
+	catCmdStopCw cmds.CatCmdName = "STOPCW"
+	// catCmdSetKeySpeed sets the keyer speed. Its single parameter is the speed in WPM, zero-padded to three digits,
+	// e.g. "KS%s;".
+	catCmdSetKeySpeed cmds.CatCmdName = "SETKEYSPEED"
+)
+
+const (
+	minCwWpm = 4
+	maxCwWpm = 60
+)
+
+// cwMacroVariable matches a variable in a CW macro, e.g. "{MYCALL}".
+var cwMacroVariable = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+// CwMacro is a CW memory, sent by its key (e.g., "F1"). The text may hold variables, which are expanded from the
+// QSO being logged: {MYCALL}, {CALL}, {RST}, {STX} and {NAME}.
+type CwMacro struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Text  string `json:"text"`
+}
+
+// FetchCwMacros returns the CW macros set in the app options.
+func (s *Service) FetchCwMacros() ([]CwMacro, error) {
+	const op errors.Op = "facade.Service.FetchCwMacros"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	return append(make([]CwMacro, 0, len(s.options.CwKeyer.Macros)), s.options.CwKeyer.Macros...), nil
+}
+
+// SendCwMacro expands the macro with the given key (e.g., "F1") for the QSO being logged and sends it with the keyer.
+// The frontend passes the QSO as it stands, including the contest serial number.
+func (s *Service) SendCwMacro(key string, qso types.Qso) error {
+	const op errors.Op = "facade.Service.SendCwMacro"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	macro, ok := s.cwMacro(key)
+	if !ok {
+		err := errors.New(op).Msgf("No CW macro for %q", key)
+		s.LoggerService.ErrorWith().Err(err).Str("key", key).Msg("Failed to send the CW macro")
+		return errors.Root(err)
+	}
+
+	text, err := expandCwMacro(macro.Text, s.cwMacroValues(qso))
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Str("key", macro.Key).Msg("Failed to expand the CW macro")
+		return errors.Root(err)
+	}
+
+	if err = s.sendCw(text); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Str("key", macro.Key).Msg("Failed to send the CW macro")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SendCwText sends free text with the keyer.
+func (s *Service) SendCwText(text string) error {
+	const op errors.Op = "facade.Service.SendCwText"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if err := s.sendCw(text); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to send CW text")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// AbortCw stops the text being sent by the keyer.
+func (s *Service) AbortCw() error {
+	const op errors.Op = "facade.Service.AbortCw"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if w := s.winKeyer; w != nil {
+		if err := w.keyer.Abort(); err != nil {
+			err = errors.New(op).Err(err)
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to abort CW")
+			return errors.Root(err)
+		}
+		return nil
+	}
+
+	if err := s.enqueueCwCommand(catCmdStopCw); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to abort CW")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SetCwSpeed sets the speed of the keyer, in WPM.
+func (s *Service) SetCwSpeed(wpm int) error {
+	const op errors.Op = "facade.Service.SetCwSpeed"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if wpm < minCwWpm || wpm > maxCwWpm {
+		err := errors.New(op).Msgf("Invalid CW speed: %d WPM, want %d to %d", wpm, minCwWpm, maxCwWpm)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the CW speed")
+		return errors.Root(err)
+	}
+
+	if w := s.winKeyer; w != nil {
+		if err := w.keyer.SetSpeed(wpm); err != nil {
+			err = errors.New(op).Err(err)
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the CW speed")
+			return errors.Root(err)
+		}
+		return nil
+	}
+
+	if err := s.enqueueCwCommand(catCmdSetKeySpeed, fmt.Sprintf("%03d", wpm)); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the CW speed")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// cwMacro returns the macro with the given key.
+func (s *Service) cwMacro(key string) (CwMacro, bool) {
+	key = strings.TrimSpace(key)
+	for _, m := range s.options.CwKeyer.Macros {
+		if strings.EqualFold(m.Key, key) {
+			return m, true
+		}
+	}
+	return CwMacro{}, false
+}
+
+// cwMacroValues returns the values of the macro variables for a QSO. The station callsign falls back to the
+// logbook's, and the report to 599, as they may not be set until the QSO is logged.
+func (s *Service) cwMacroValues(qso types.Qso) map[string]string {
+	mycall := qso.StationCallsign
+	if strings.TrimSpace(mycall) == "" {
+		mycall = s.CurrentLogbook.Callsign
+	}
+	rst := qso.RstSent
+	if strings.TrimSpace(rst) == "" {
+		rst = "599"
+	}
+
+	return map[string]string{
+		"MYCALL": mycall,
+		"CALL":   qso.Call,
+		"RST":    rst,
+		"STX":    qso.STX,
+		"NAME":   qso.Name,
+	}
+}
+
+// expandCwMacro replaces the variables in a macro with their values. An unknown variable is an error, rather than
+// being sent as is.
+func expandCwMacro(text string, values map[string]string) (string, error) {
+	const op errors.Op = "facade.expandCwMacro"
+
+	var unknown string
+	expanded := cwMacroVariable.ReplaceAllStringFunc(text, func(v string) string {
+		name := strings.ToUpper(v[1 : len(v)-1])
+		value, ok := values[name]
+		if !ok {
+			unknown = v
+			return v
+		}
+		return strings.TrimSpace(value)
+	})
+	if unknown != "" {
+		return "", errors.New(op).Msgf("Unknown CW macro variable: %s", unknown)
+	}
+
+	return expanded, nil
+}
+
+// sendCw sends text with the WinKeyer, if there is one, or else with the rig's keyer, in chunks it can take.
+func (s *Service) sendCw(text string) error {
+	const op errors.Op = "facade.Service.sendCw"
+
+	text, err := cwText(text)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	// The WinKeyer buffers the text itself, pausing the writes when its buffer is nearly full.
+	if w := s.winKeyer; w != nil {
+		if err = w.keyer.Send(text); err != nil {
+			return errors.New(op).Err(err)
+		}
+		return nil
+	}
+
+	for _, chunk := range cwChunks(text, s.options.CwKeyer.ChunkLength) {
+		if err = s.enqueueCwCommand(catCmdSendCw, chunk); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+
+	return nil
+}
+
+// enqueueCwCommand enqueues a keyer command if the rig config defines it.
+func (s *Service) enqueueCwCommand(name cmds.CatCmdName, params ...string) error {
+	const op errors.Op = "facade.Service.enqueueCwCommand"
+
+	if !hasCatCommand(s.catService().RigConfig(), name) {
+		return errors.New(op).Msgf("The rig config has no %s command", name)
+	}
+	if err := s.catService().EnqueueCommand(name, params...); err != nil {
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
+
+// cwText normalizes text for the keyer: upper case with single spaces. Characters that have no Morse code are an
+// error, as keyers skip or garble them.
+func cwText(text string) (string, error) {
+	const op errors.Op = "facade.cwText"
+
+	text = strings.Join(strings.Fields(strings.ToUpper(text)), " ")
+	if text == "" {
+		return "", errors.New(op).Msg("There is no CW text to send")
+	}
+	if i := strings.IndexFunc(text, func(r rune) bool { return !isCwRune(r) }); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(text[i:])
+		return "", errors.New(op).Msgf("The character %q cannot be sent in CW", r)
+	}
+
+	return text, nil
+}
+
+// isCwRune reports whether a character has a Morse code that rig keyers send.
+func isCwRune(r rune) bool {
+	switch {
+	case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune(" /?.,=+-()':\"@", r)
+}
+
+// cwChunks splits text into chunks of at most size characters, breaking between words where possible. Each chunk
+// but the last keeps its trailing space, so the words stay apart when the keyer joins them.
+func cwChunks(text string, size int) []string {
+	if size <= 0 || len(text) <= size {
+		return []string{text}
+	}
+
+	var chunks []string
+	for len(text) > size {
+		cut := strings.LastIndexByte(text[:size], ' ') + 1
+		if cut <= 0 {
+			cut = size
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
@@ -0,0 +1,236 @@
+package facade
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// sentCw records the commands sent to a stub CAT backend.
+type sentCw struct {
+	name   cmds.CatCmdName
+	params []string
+}
+
+// createCwTestService returns a started service with the default CW macros and a stub CAT backend whose rig config
+// has the keyer commands.
+func createCwTestService() (*Service, *[]sentCw) {
+	s := createStartedTestService()
+	s.options = defaultAppOptions()
+	s.CurrentLogbook = types.Logbook{Callsign: "M0CMC"}
+
+	sent := &[]sentCw{}
+	s.altCat = &stubCatBackend{
+		rig: types.RigConfig{CatCommands: []types.CatCommand{
+			{Name: catCmdSendCw.String(), Cmd: "KY %s;"},
+			{Name: catCmdStopCw.String(), Cmd: "KY0;"},
+			{Name: catCmdSetKeySpeed.String(), Cmd: "KS%s;"},
+		}},
+		onEnqueue: func(name cmds.CatCmdName, params ...string) error {
+			*sent = append(*sent, sentCw{name: name, params: params})
+			return nil
+		},
+	}
+	return s, sent
+}
+
+// =============================================================================
+// Macro Tests
+// =============================================================================
+
+func TestExpandCwMacro(t *testing.T) {
+	values := map[string]string{"MYCALL": "M0CMC", "CALL": "K1ABC", "RST": "599", "STX": "042", "NAME": " Bob "}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"no variables", "CQ TEST", "CQ TEST", false},
+		{"variables", "{CALL} {RST} {STX}", "K1ABC 599 042", false},
+		{"lower case variable", "TU {mycall}", "TU M0CMC", false},
+		{"trimmed value", "TNX {NAME}", "TNX Bob", false},
+		{"unknown variable", "{CALL} {QTH}", "", true},
+		{"unclosed brace", "{CALL", "{CALL", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandCwMacro(tt.text, values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandCwMacro() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expandCwMacro() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCwText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"cq  test\tk1abc ", "CQ TEST K1ABC", false},
+		{"5nn 001?", "5NN 001?", false},
+		{"K1ABC/P", "K1ABC/P", false},
+		{"   ", "", true},
+		{"73 ☺", "", true},
+		{"CQ#", "", true},
+	}
+	for _, tt := range tests {
+		got, err := cwText(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("cwText(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("cwText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// The character is reported whole, not as the first byte of its encoding.
+	if _, err := cwText("73 ☺"); err == nil || !strings.Contains(errors.Root(err).Error(), `'☺'`) {
+		t.Errorf("cwText() error = %v, want it to name '☺'", err)
+	}
+}
+
+func TestCwChunks(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+		want []string
+	}{
+		{"CQ TEST", 24, []string{"CQ TEST"}},
+		{"CQ TEST", 0, []string{"CQ TEST"}},
+		{"CQ TEST M0CMC M0CMC TEST", 10, []string{"CQ TEST ", "M0CMC ", "M0CMC TEST"}},
+		{"ABCDEFGHIJ", 4, []string{"ABCD", "EFGH", "IJ"}},
+	}
+	for _, tt := range tests {
+		got := cwChunks(tt.text, tt.size)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("cwChunks(%q, %d) = %q, want %q", tt.text, tt.size, got, tt.want)
+		}
+		if joined := strings.Join(got, ""); joined != tt.text {
+			t.Errorf("cwChunks(%q, %d) joined = %q", tt.text, tt.size, joined)
+		}
+	}
+}
+
+func TestDefaultCwMacros(t *testing.T) {
+	macros := defaultAppOptions().CwKeyer.Macros
+	if len(macros) != 12 {
+		t.Fatalf("default macros = %d, want F1 to F12", len(macros))
+	}
+	s, _ := createCwTestService()
+	values := s.cwMacroValues(types.Qso{})
+	for _, m := range macros {
+		if _, err := expandCwMacro(m.Text, values); err != nil {
+			t.Errorf("macro %s: %v", m.Key, err)
+		}
+	}
+}
+
+// =============================================================================
+// Keyer Tests
+// =============================================================================
+
+func TestSendCwMacro(t *testing.T) {
+	s, sent := createCwTestService()
+
+	qso := types.Qso{}
+	qso.Call = "K1ABC"
+	qso.STX = "042"
+	if err := s.SendCwMacro("f2", qso); err != nil {
+		t.Fatalf("SendCwMacro() error = %v", err)
+	}
+	want := []sentCw{{name: catCmdSendCw, params: []string{"599 042"}}}
+	if !reflect.DeepEqual(*sent, want) {
+		t.Errorf("sent %+v, want %+v", *sent, want)
+	}
+
+	*sent = nil
+	s.options.CwKeyer.ChunkLength = 12
+	if err := s.SendCwMacro("F1", qso); err != nil {
+		t.Fatalf("SendCwMacro() error = %v", err)
+	}
+	want = []sentCw{
+		{name: catCmdSendCw, params: []string{"CQ TEST "}},
+		{name: catCmdSendCw, params: []string{"M0CMC M0CMC "}},
+		{name: catCmdSendCw, params: []string{"TEST"}},
+	}
+	if !reflect.DeepEqual(*sent, want) {
+		t.Errorf("sent %+v, want the macro in chunks", *sent)
+	}
+
+	if err := s.SendCwMacro("F13", qso); err == nil {
+		t.Error("SendCwMacro() should fail for an unknown key")
+	}
+}
+
+func TestSendCwMacro_StationCallsign(t *testing.T) {
+	s, sent := createCwTestService()
+
+	qso := types.Qso{}
+	qso.StationCallsign = "GB2XYZ"
+	if err := s.SendCwMacro("F4", qso); err != nil {
+		t.Fatalf("SendCwMacro() error = %v", err)
+	}
+	if len(*sent) != 1 || (*sent)[0].params[0] != "GB2XYZ" {
+		t.Errorf("sent %+v, want the QSO's station callsign", *sent)
+	}
+}
+
+func TestAbortCwAndSpeed(t *testing.T) {
+	s, sent := createCwTestService()
+
+	if err := s.AbortCw(); err != nil {
+		t.Fatalf("AbortCw() error = %v", err)
+	}
+	if err := s.SetCwSpeed(28); err != nil {
+		t.Fatalf("SetCwSpeed() error = %v", err)
+	}
+	want := []sentCw{{name: catCmdStopCw}, {name: catCmdSetKeySpeed, params: []string{"028"}}}
+	if !reflect.DeepEqual(*sent, want) {
+		t.Errorf("sent %+v, want %+v", *sent, want)
+	}
+
+	for _, wpm := range []int{0, 3, 61} {
+		if err := s.SetCwSpeed(wpm); err == nil {
+			t.Errorf("SetCwSpeed(%d) should fail", wpm)
+		}
+	}
+}
+
+func TestCwKeyer_NoRigCommand(t *testing.T) {
+	s, _ := createCwTestService()
+	s.altCat = &stubCatBackend{}
+
+	if err := s.SendCwText("CQ"); err == nil {
+		t.Error("SendCwText() should fail when the rig config has no keyer command")
+	}
+	if err := s.AbortCw(); err == nil {
+		t.Error("AbortCw() should fail when the rig config has no keyer command")
+	}
+}
+
+func TestCwKeyer_ServiceState(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchCwMacros(); err == nil {
+		t.Error("FetchCwMacros() should fail when not started")
+	}
+	if err := s.SendCwMacro("F1", types.Qso{}); err == nil {
+		t.Error("SendCwMacro() should fail when not started")
+	}
+	if err := s.AbortCw(); err == nil {
+		t.Error("AbortCw() should fail when not started")
+	}
+	if err := s.SetCwSpeed(25); err == nil {
+		t.Error("SetCwSpeed() should fail when not started")
+	}
+}
@@ -0,0 +1,86 @@
+package facade
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Station-Manager/config"
+	"github.com/Station-Manager/database/sqlite"
+	"github.com/Station-Manager/logging"
+	"github.com/Station-Manager/types"
+)
+
+// createDatabaseTestService creates a started Service backed by a real, migrated SQLite database in a temporary
+// directory. A default logbook and session are created so QSOs can be inserted straight away.
+func createDatabaseTestService(t *testing.T) *Service {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfgService := &config.Service{WorkingDir: dir}
+	if err := cfgService.Initialize(); err != nil {
+		t.Fatalf("config.Initialize() error = %v", err)
+	}
+	cfgService.AppConfig.DatastoreConfig.Path = filepath.Join(dir, "db", "data.db")
+
+	logger := &logging.Service{}
+	db := &sqlite.Service{ConfigService: cfgService, LoggerService: logger}
+	if err := db.Initialize(); err != nil {
+		t.Fatalf("sqlite.Initialize() error = %v", err)
+	}
+
+	s := createStartedTestService()
+	s.ConfigService = cfgService
+	s.DatabaseService = db
+	if err := s.openDatabase(); err != nil {
+		t.Fatalf("openDatabase() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err := db.UpsertLogbook(types.Logbook{ID: 1, Name: "Test Logbook", Callsign: "W1AW"}); err != nil {
+		t.Fatalf("UpsertLogbook() error = %v", err)
+	}
+	sessionID, err := db.GenerateSession()
+	if err != nil {
+		t.Fatalf("GenerateSession() error = %v", err)
+	}
+	s.sessionID = sessionID
+
+	return s
+}
+
+// newTestQso returns a minimal valid QSO for the given call, band and mode in the test service's logbook and session.
+func newTestQso(s *Service, call, band, mode string) types.Qso {
+	return types.Qso{
+		LogbookID: s.CurrentLogbook.ID,
+		SessionID: s.sessionID,
+		QsoDetails: types.QsoDetails{
+			Band:    band,
+			Mode:    mode,
+			Freq:    "14250000",
+			QsoDate: "20240301",
+			TimeOn:  "1200",
+			TimeOff: "1205",
+			RstSent: "59",
+			RstRcvd: "59",
+		},
+		ContactedStation: types.ContactedStation{Call: call, Country: "England", DXCC: "223"},
+		LoggingStation:   types.LoggingStation{StationCallsign: "W1AW"},
+	}
+}
+
+// insertTestQso inserts a minimal valid QSO for the given call, band and mode into the test database and returns its ID.
+func insertTestQso(t *testing.T, s *Service, call, band, mode string) int64 {
+	t.Helper()
+	return insertTestQsoValue(t, s, newTestQso(s, call, band, mode))
+}
+
+// insertTestQsoValue inserts the given QSO into the test database and returns its ID.
+func insertTestQsoValue(t *testing.T, s *Service, qso types.Qso) int64 {
+	t.Helper()
+
+	id, err := s.DatabaseService.InsertQso(qso)
+	if err != nil {
+		t.Fatalf("InsertQso() error = %v", err)
+	}
+	return id
+}
@@ -21,10 +21,25 @@ It orchestrates the following subsystems:
   - LoggerService: Structured logging
   - DatabaseService: SQLite database operations (QSOs, logbooks, contacts)
   - CatService: Radio CAT (Computer Aided Transceiver) control
+  - rigctld: Alternative CAT backend for rigs driven by a Hamlib rigctld daemon (backend/rigctld),
+    selected per rig in the app options
   - HamnutLookupService: Callsign lookup via HamQTH
   - QrzLookupService: Callsign lookup via QRZ.com
   - EmailService: ADIF file forwarding via email
   - Forwarders: QSO upload to online services (QRZ.com logbook, etc.)
+  - DX Cluster: Telnet cluster client (backend/dxcluster) with spot enrichment
+  - WinKeyer: K1EL WinKeyer driver (backend/winkeyer), used instead of the rig's keyer when
+    selected in the app options
+  - N1MM broadcaster: N1MM+ compatible UDP packets of logged QSOs and the rig state, for tools
+    such as score reporters and station displays
+  - ADIF listeners: UDP/TCP listeners (backend/adiflistener) that log the QSOs other programs
+    (JTDX, MSHV, JS8Call, fldigi) send as ADIF records
+  - WSJT-X integration: the handler of the WSJT-X listeners of the listeners service, which parses
+    the WSJT-X network protocol (backend/wsjtx), colours decoded callsigns by worked/needed status,
+    answers decodes chosen in the logger and logs the QSOs logged in WSJT-X (when auto_log is set)
+  - REST API: token-protected local HTTP API (backend/restapi) for scripts and other station
+    software to log, search and look up QSOs and read the CAT and session state, with a
+    WebSocket stream of the events emitted to the frontend
 
 # Lifecycle
 
@@ -35,14 +50,33 @@ The facade follows a strict lifecycle:
  3. Start(ctx) - Opens database, starts CAT service, launches worker goroutines
  4. Stop() - Gracefully shuts down workers, closes database, cleans up resources
 
+The command line uses StartHeadless(ctx) and StopHeadless() instead of Start and Stop: only the
+database, the default logbook and the forwarders are set up, for ImportAdif, ExportAdif,
+ForwardUploads, CheckDatabase, RunMaintenance and the other methods that work on the log alone.
+
 # Concurrency Model
 
 The facade manages several concurrent subsystems:
 
-  - CAT Status Listener: Receives radio status updates and emits events to the frontend
+  - CAT Status Listener: Receives radio status updates and emits them to the frontend, merging
+    bursts so that only the latest state is emitted, at most once per the configured interval
+  - CAT Health Supervisor: Probes a silent rig and restarts the CAT backend with backoff when it
+    does not answer, emitting the connection state to the frontend (when enabled)
   - QSO Forwarding Workers: Pool of workers that upload QSOs to online services
   - DB Write Worker: Serializes all database writes to prevent SQLite busy errors
   - Polling Loop: Periodically checks for pending QSO uploads
+  - QSO Rate Emitter: Periodically emits the rolling session QSO rate to the frontend
+  - DX Cluster Worker: Runs the cluster connection and enriches incoming spots (when enabled)
+  - WinKeyer Worker: Runs the WinKeyer and emits the echoed characters and its status (when selected)
+  - N1MM Radio Worker: Broadcasts the rig state as N1MM+ RadioInfo packets (when enabled)
+  - ADIF Listener Worker: Serves the ADIF listeners and logs each received QSO through LogQso,
+    ignoring QSOs already in the logbook (when enabled)
+  - WSJT-X Worker: Classifies each decode the WSJT-X listeners receive against the logbook,
+    highlighting its callsign in WSJT-X and adding it to the rolling per-band activity, which is
+    emitted to the frontend at most once per the configured interval (when enabled)
+  - REST API Worker: Serves the REST API, one goroutine per request (when enabled)
+  - Backup Worker: Backs up the database with VACUUM INTO every configured interval, rotating
+    the old backups (when enabled); Stop takes a last backup before closing the database
 
 All workers respond to context cancellation and shutdown signals for graceful termination.
 
@@ -52,13 +86,85 @@ Methods on the Service struct are automatically bound to the frontend via Wails.
 The frontend can call these methods directly:
 
   - FetchUiConfig() - Get UI configuration
-  - NewQso(callsign) - Initialize a new QSO with callsign lookup
+  - NewQso(callsign) - Initialize a new QSO with callsign lookup, its worked-before matrix and award needs
   - LogQso(qso) - Save a QSO to the database
   - UpdateQso(qso) - Update an existing QSO
+  - DeleteQso(qsoId) - Delete a QSO; it can be restored from its history
+  - FetchQsoHistory(qsoId), RevertQso(auditId) - Get the audit trail of a QSO, and restore the QSO to
+    the version left by one of its changes, queueing its upload
+  - SearchQsos(search) - Find QSOs in the current logbook by call (or "DL*" prefix), band, mode
+    and date range, most recent first
   - Ready() - Signal that the UI is ready to receive CAT updates
+  - FetchLogbookStats(logbookId) - Get aggregated logbook statistics (cached per logbook), including
+    a summary per operator
+  - FetchOperators(), SaveOperator(operator), RemoveOperator(callsign) - Manage the registry of the
+    station's operators for multi-operator events
+  - FetchCurrentOperator(), SwitchOperator(callsign) - Get the operator at the station, and hand it
+    over to another, starting a new session; QSOs logged without an OPERATOR are stamped with it
+  - QsySpot(spot) - Tune the rig to a DX spot and start a QSO for the spotted call
+  - FetchRigCapabilities() - Get the rig controls supported by the rig config
+  - SetRigFrequency(vfo, khz), SetRigMode(mode), SetRigFilter(code), SelectRigVfo(vfo), SetRigSplit(on),
+    SetRigPtt(on) - Control the rig via CAT
+  - FetchCatConnectionStatus() - Get the state of the connection to the rig
+  - FetchCwMacros(), SendCwMacro(key, qso), SendCwText(text), AbortCw(), SetCwSpeed(wpm) - Send
+    CW with the rig's keyer or a WinKeyer, expanding macro variables from the QSO being logged
+  - FetchCwKeyerStatus(), SetCwWeight(weight) - Get the WinKeyer state and set its keying weight
+  - FetchWsjtxActivity() - Get the recent WSJT-X decodes per band, with the DXCC entity,
+    distance and worked/needed status of each calling station
+  - ReplyToWsjtxDecode(decode) - Make WSJT-X answer a decode, as if it had been double-clicked
 
 Events are emitted to the frontend using Wails runtime.EventsEmit for real-time updates
-(e.g., radio frequency/mode changes).
+(e.g., radio frequency/mode changes). When the REST API is enabled, the same events are
+published on its WebSocket event stream, so that a second screen or a remote display can
+follow the station.
+
+# App-owned Tables
+
+Data that the shared database module has no columns for is kept in tables owned by the
+logging app. Their DDL lives in schema.go and is applied idempotently each time the
+database is opened, after the core migrations have run:
+
+  - qsl_card: Paper QSL state (sent/received, route, manager, printed) per QSO
+  - qso_confirmation: LoTW and eQSL confirmations per QSO
+  - qso_award_ref: Operator-set award references (e.g., WAS states) per QSO
+  - qso_audit: Every insert, update, delete and revert of a QSO, with the operator, the QSO as
+    JSON before and after and a per-field diff
+  - operator: The registered operators of the station, by callsign
+
+# App Options
+
+Settings for features that the shared config module has no section for are kept in
+app_options.json in the working directory. The file is generated with defaults on first
+start; sections missing from an existing file take their defaults.
+
+The cat_backends section selects the CAT backend per rig ID: "serial" (the default) or
+"rigctld", with the host and port of the daemon. The cat_prefill section controls
+pre-filling new QSOs from the live rig state, and how old that state may be before it is
+no longer trusted. The cat_status_events section sets the minimum interval between CAT
+status events, and the cat_health section the heartbeat, probe timeout and reconnection
+backoff of the CAT health supervisor. The rig_control section sets how long a transmitter
+keyed from the app may stay keyed before it is unkeyed, and how long to wait for the rig to
+confirm a PTT change. The cw_keyer section selects the keyer ("cat" or
+"winkeyer", with its serial port, speed and weight), and holds the CW macros (F1-F12) and
+the most characters the rig's keyer takes in one command. The n1mm section enables the N1MM+
+broadcasts and sets the addresses the contact and RadioInfo packets are sent to, the
+RadioInfo interval and the station name. A contactinfo packet is sent when a QSO is logged
+and a contactreplace when it is updated, both with an ID derived from the QSO so that tools
+can match them. The adif_listener section enables receiving logged QSOs as ADIF records and
+lists the protocol ("udp" or "tcp") and address of each listener. A received QSO is logged to
+the current logbook unless one with the same call, band, mode, date and time (to the minute)
+is already there, so repeated broadcasts are logged once. The wsjtx section enables the WSJT-X
+integration, fed by the listeners of the shared config with the "wsjtx" handler, and sets the
+highlight colours: a new DXCC entity, a new band or mode slot, and a station already worked on
+the band. A highlight is only sent when a
+callsign's class changes, and is cleared when it no longer applies. Its activity subsection
+sets how long and how many decodes per band are kept, and the minimum interval between
+activity events. Distances are measured from the logging station's grid, or the grid WSJT-X
+reports if it is not configured. The rest_api section enables the REST API and sets its
+address, which defaults to the loopback interface, and the token every request must send as
+a bearer token; the API is not started without one. QSOs logged through the API are
+completed like those from the ADIF listeners, and rejected if they were made as another
+station callsign than the logbook's.
 
 # Validation
 
@@ -0,0 +1,329 @@
+package facade
+
+import (
+	"context"
+	stderr "errors"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/dxcluster"
+	"github.com/Station-Manager/types"
+)
+
+const (
+	// dxSpotQueueSize bounds the spots waiting to be enriched. If enrichment falls behind, new spots are dropped.
+	dxSpotQueueSize = 64
+	// dxCountryCacheSize bounds the per-run cache of resolved countries; the cache is cleared when it is full.
+	dxCountryCacheSize = 2000
+)
+
+// DxSpot is a DX cluster spot enriched with the DXCC entity of the spotted station and its worked/needed status
+// in the current logbook.
+type DxSpot struct {
+	Spot             dxcluster.Spot `json:"spot"`
+	Band             string         `json:"band"`
+	Mode             string         `json:"mode"` // empty unless named in the spot comment
+	Country          string         `json:"country"`
+	Continent        string         `json:"continent"`
+	CallWorked       bool           `json:"call_worked"`
+	CallWorkedOnBand bool           `json:"call_worked_on_band"`
+	NewDxcc          bool           `json:"new_dxcc"`
+	NewBandSlot      bool           `json:"new_band_slot"`
+	NewModeSlot      bool           `json:"new_mode_slot"`
+	ReceivedAt       time.Time      `json:"received_at"`
+}
+
+// DxClusterStatus is the connection state of the DX cluster client.
+type DxClusterStatus struct {
+	Enabled bool      `json:"enabled"`
+	Host    string    `json:"host"`
+	Port    int       `json:"port"`
+	State   string    `json:"state"`
+	Error   string    `json:"error"` // the reason for the last disconnection, if any
+	Since   time.Time `json:"since"`
+}
+
+// dxCluster holds the state of the DX cluster client for the current run.
+type dxCluster struct {
+	mu        sync.Mutex
+	client    *dxcluster.Client
+	status    DxClusterStatus
+	spots     []DxSpot // oldest first
+	maxSpots  int
+	countries map[string]types.Country
+
+	queue chan dxcluster.Spot
+}
+
+// HandleSpot queues a spot for enrichment. It implements dxcluster.Handler.
+func (d *dxCluster) HandleSpot(spot dxcluster.Spot) {
+	select {
+	case d.queue <- spot:
+	default:
+		// Enrichment is behind; drop the spot rather than block the cluster connection.
+	}
+}
+
+// HandleState records a change of connection state. It implements dxcluster.Handler.
+func (d *dxCluster) HandleState(state dxcluster.State, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.State = string(state)
+	d.status.Since = time.Now().UTC()
+	d.status.Error = ""
+	if err != nil {
+		d.status.Error = errors.Root(err).Error()
+	}
+}
+
+// addSpot stores an enriched spot, replacing an earlier spot of the same station on the same band.
+func (d *dxCluster) addSpot(spot DxSpot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, s := range d.spots {
+		if s.Spot.DxCall == spot.Spot.DxCall && s.Band == spot.Band {
+			d.spots = append(d.spots[:i], d.spots[i+1:]...)
+			break
+		}
+	}
+	d.spots = append(d.spots, spot)
+	if len(d.spots) > d.maxSpots {
+		d.spots = d.spots[len(d.spots)-d.maxSpots:]
+	}
+}
+
+// FetchDxSpots returns the recent DX cluster spots, newest first.
+func (s *Service) FetchDxSpots() ([]DxSpot, error) {
+	const op errors.Op = "facade.Service.FetchDxSpots"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	spots := make([]DxSpot, 0)
+	d := s.dxCluster
+	if d == nil {
+		return spots, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := len(d.spots) - 1; i >= 0; i-- {
+		spots = append(spots, d.spots[i])
+	}
+
+	return spots, nil
+}
+
+// FetchDxClusterStatus returns the connection state of the DX cluster client.
+func (s *Service) FetchDxClusterStatus() (DxClusterStatus, error) {
+	const op errors.Op = "facade.Service.FetchDxClusterStatus"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return DxClusterStatus{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return DxClusterStatus{}, errors.Root(err)
+	}
+
+	d := s.dxCluster
+	if d == nil {
+		return DxClusterStatus{State: string(dxcluster.StateDisconnected)}, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.status, nil
+}
+
+// SendDxClusterCommand sends a command, such as a filter change, to the connected cluster node.
+func (s *Service) SendDxClusterCommand(cmd string) error {
+	const op errors.Op = "facade.Service.SendDxClusterCommand"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	d := s.dxCluster
+	if d == nil || d.client == nil {
+		return errors.New(op).Msg("The DX cluster client is not enabled")
+	}
+
+	if err := d.client.Send(cmd); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to send DX cluster command")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// newDxCluster creates the DX cluster client from the options. It returns nil if the client is disabled.
+func (s *Service) newDxCluster(opts DxClusterOptions) (*dxCluster, error) {
+	const op errors.Op = "facade.Service.newDxCluster"
+
+	if !opts.Enabled {
+		return nil, nil
+	}
+
+	callsign := opts.Callsign
+	if callsign == "" {
+		callsign = s.CurrentLogbook.Callsign
+	}
+
+	client, err := dxcluster.New(dxcluster.Config{
+		Host:              opts.Host,
+		Port:              opts.Port,
+		Callsign:          callsign,
+		Password:          opts.Password,
+		Commands:          opts.Commands,
+		ReconnectDelay:    time.Duration(opts.ReconnectSeconds) * time.Second,
+		MaxReconnectDelay: time.Duration(opts.MaxReconnectSeconds) * time.Second,
+		IdleTimeout:       time.Duration(opts.IdleTimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return &dxCluster{
+		client:    client,
+		status:    DxClusterStatus{Enabled: true, Host: opts.Host, Port: opts.Port, State: string(dxcluster.StateDisconnected)},
+		maxSpots:  max(opts.MaxSpots, 1),
+		countries: make(map[string]types.Country),
+		queue:     make(chan dxcluster.Spot, dxSpotQueueSize),
+	}, nil
+}
+
+// dxClusterWorker runs the DX cluster client until shutdown, enriching each spot and emitting it to the frontend.
+func (s *Service) dxClusterWorker(shutdown <-chan struct{}) {
+	d := s.dxCluster
+	if d == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case spot := <-d.queue:
+				s.dbGate.RLock()
+				enriched := s.enrichDxSpot(d, spot)
+				s.dbGate.RUnlock()
+				d.addSpot(enriched)
+				s.emitEvent(EventDxSpot.String(), enriched)
+			}
+		}
+	}()
+
+	_ = d.client.Run(ctx, &dxClusterHandler{dxCluster: d, service: s})
+	wg.Wait()
+	s.LoggerService.DebugWith().Msg("DX cluster client stopped")
+}
+
+// dxClusterHandler extends the dxCluster's handler to log and emit state changes as they happen.
+type dxClusterHandler struct {
+	*dxCluster
+	service *Service
+}
+
+func (h *dxClusterHandler) HandleState(state dxcluster.State, err error) {
+	h.dxCluster.HandleState(state, err)
+	if err != nil {
+		h.service.LoggerService.WarnWith().Err(err).Msg("DX cluster connection lost")
+	}
+	h.dxCluster.mu.Lock()
+	status := h.dxCluster.status
+	h.dxCluster.mu.Unlock()
+	h.service.emitEvent(EventDxClusterStatus.String(), status)
+}
+
+// enrichDxSpot adds the band, mode, DXCC entity and worked/needed status to a spot. Failures leave the
+// corresponding fields empty; the spot is still worth showing.
+func (s *Service) enrichDxSpot(d *dxCluster, spot dxcluster.Spot) DxSpot {
+	enriched := DxSpot{
+		Spot:       spot,
+		Band:       bandForKhz(spot.Frequency),
+		Mode:       modeFromComment(spot.Comment),
+		ReceivedAt: time.Now().UTC(),
+	}
+
+	country := s.cachedCountry(d.countries, spot.DxCall)
+	enriched.Country = country.Name
+	enriched.Continent = country.Continent
+
+	matrix, err := s.computeWorkedMatrix(spot.DxCall, country, "")
+	if err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("callsign", spot.DxCall).Msg("Failed to compute worked status for DX spot")
+		return enriched
+	}
+
+	for _, c := range matrix.Cells {
+		enriched.CallWorked = enriched.CallWorked || c.CallWorked
+		enriched.CallWorkedOnBand = enriched.CallWorkedOnBand || (c.CallWorked && c.Band == enriched.Band)
+	}
+	needs := matrix.awardNeeds(enriched.Band, enriched.Mode)
+	enriched.NewDxcc = needs.NewDxcc
+	enriched.NewBandSlot = needs.NewBandSlot
+	enriched.NewModeSlot = needs.NewModeSlot
+
+	return enriched
+}
+
+// cachedCountry resolves the country of a callsign from the local country table, caching it in countries for the
+// run, as spots and decodes name the same stations over and over. There is no online lookup, as it would hold up the
+// queue, and failures are not cached, so a country looked up later (e.g., when a QSO is started) is picked up.
+func (s *Service) cachedCountry(countries map[string]types.Country, callsign string) types.Country {
+	key := s.parseCallsign(callsign)
+	if country, ok := countries[key]; ok {
+		return country
+	}
+
+	country, err := s.DatabaseService.FetchCountryByCallsign(key)
+	if err != nil {
+		if !stderr.Is(err, errors.ErrNotFound) {
+			s.LoggerService.DebugWith().Err(err).Str("callsign", callsign).Msg("Failed to resolve country")
+		}
+		return types.Country{}
+	}
+
+	if len(countries) >= dxCountryCacheSize {
+		clear(countries)
+	}
+	countries[key] = country
+
+	return country
+}
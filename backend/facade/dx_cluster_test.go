@@ -0,0 +1,262 @@
+package facade
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/logging-app/backend/dxcluster"
+	"github.com/Station-Manager/types"
+)
+
+// =============================================================================
+// DX Cluster Guard Tests
+// =============================================================================
+
+func TestFetchDxSpots_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchDxSpots(); err == nil {
+		t.Error("FetchDxSpots() should fail when service not initialized")
+	}
+}
+
+func TestFetchDxSpots_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchDxSpots(); err == nil {
+		t.Error("FetchDxSpots() should fail when service not started")
+	}
+}
+
+func TestFetchDxClusterStatus_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchDxClusterStatus(); err == nil {
+		t.Error("FetchDxClusterStatus() should fail when service not started")
+	}
+}
+
+func TestSendDxClusterCommand_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if err := s.SendDxClusterCommand("sh/dx"); err == nil {
+		t.Error("SendDxClusterCommand() should fail when service not started")
+	}
+}
+
+func TestDxCluster_Disabled(t *testing.T) {
+	s := createStartedTestService()
+
+	spots, err := s.FetchDxSpots()
+	if err != nil || spots == nil || len(spots) != 0 {
+		t.Errorf("FetchDxSpots() = %v, %v; want an empty list", spots, err)
+	}
+	status, err := s.FetchDxClusterStatus()
+	if err != nil || status.Enabled || status.State != string(dxcluster.StateDisconnected) {
+		t.Errorf("FetchDxClusterStatus() = %+v, %v", status, err)
+	}
+	if err = s.SendDxClusterCommand("sh/dx"); err == nil {
+		t.Error("SendDxClusterCommand() should fail when the client is disabled")
+	}
+}
+
+// =============================================================================
+// DX Cluster Unit Tests
+// =============================================================================
+
+func TestNewDxCluster(t *testing.T) {
+	s := createStartedTestService()
+
+	tests := []struct {
+		name    string
+		opts    DxClusterOptions
+		wantNil bool
+		wantErr bool
+	}{
+		{"disabled", DxClusterOptions{Host: "dx.example.net", Port: 7300}, true, false},
+		{"enabled defaults to the logbook callsign", DxClusterOptions{Enabled: true, Host: "dx.example.net", Port: 7300}, false, false},
+		{"missing host", DxClusterOptions{Enabled: true, Port: 7300}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := s.newDxCluster(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newDxCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (d == nil) != tt.wantNil {
+				t.Errorf("newDxCluster() = %v, wantNil %v", d, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestDxCluster_AddSpot(t *testing.T) {
+	d := &dxCluster{maxSpots: 3}
+
+	d.addSpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC", Comment: "first"}, Band: "20m"})
+	d.addSpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC"}, Band: "40m"})
+	d.addSpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC", Comment: "second"}, Band: "20m"})
+	if len(d.spots) != 2 || d.spots[1].Spot.Comment != "second" {
+		t.Fatalf("spots = %+v, want the 20m spot replaced", d.spots)
+	}
+
+	d.addSpot(DxSpot{Spot: dxcluster.Spot{DxCall: "JA1ABC"}, Band: "20m"})
+	d.addSpot(DxSpot{Spot: dxcluster.Spot{DxCall: "K1ABC"}, Band: "20m"})
+	if len(d.spots) != 3 || d.spots[0].Band != "20m" || d.spots[2].Spot.DxCall != "K1ABC" {
+		t.Errorf("spots = %+v, want the oldest spot dropped", d.spots)
+	}
+}
+
+func TestDxCluster_HandleSpotDropsWhenFull(t *testing.T) {
+	d := &dxCluster{queue: make(chan dxcluster.Spot, 1)}
+	d.HandleSpot(dxcluster.Spot{DxCall: "G4ABC"})
+	d.HandleSpot(dxcluster.Spot{DxCall: "JA1ABC"}) // must not block
+
+	if got := <-d.queue; got.DxCall != "G4ABC" {
+		t.Errorf("queued spot = %+v", got)
+	}
+}
+
+// =============================================================================
+// DX Cluster Database Tests
+// =============================================================================
+
+func TestEnrichDxSpot(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "CW")
+
+	d := &dxCluster{countries: map[string]types.Country{
+		"G4ABC":  {Name: "England", Continent: "EU"},
+		"G3XYZ":  {Name: "England", Continent: "EU"},
+		"JA1ABC": {Name: "Japan", Ccode: "JP", Continent: "AS"},
+	}}
+
+	tests := []struct {
+		name string
+		spot dxcluster.Spot
+		want DxSpot
+	}{
+		{
+			name: "worked call on the band",
+			spot: dxcluster.Spot{DxCall: "G4ABC", Frequency: 14025, Comment: "CW"},
+			want: DxSpot{Band: "20m", Mode: "CW", Country: "England", Continent: "EU", CallWorked: true, CallWorkedOnBand: true},
+		},
+		{
+			name: "new call, worked entity, new band and mode",
+			spot: dxcluster.Spot{DxCall: "G3XYZ", Frequency: 7150, Comment: "SSB"},
+			want: DxSpot{Band: "40m", Mode: "SSB", Country: "England", Continent: "EU", NewBandSlot: true, NewModeSlot: true},
+		},
+		{
+			name: "new entity",
+			spot: dxcluster.Spot{DxCall: "JA1ABC", Frequency: 21074, Comment: "FT8"},
+			want: DxSpot{Band: "15m", Mode: "MFSK", Country: "Japan", Continent: "AS", NewDxcc: true, NewBandSlot: true, NewModeSlot: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.enrichDxSpot(d, tt.spot)
+			tt.want.Spot = tt.spot
+			tt.want.ReceivedAt = got.ReceivedAt
+			if got != tt.want {
+				t.Errorf("enrichDxSpot() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedCountry(t *testing.T) {
+	s := createDatabaseTestService(t)
+	countries := make(map[string]types.Country)
+
+	// Not in the local table: no online lookup, and nothing cached.
+	if got := s.cachedCountry(countries, "JA1ABC/P"); got.Name != "" || len(countries) != 0 {
+		t.Fatalf("cachedCountry() = %+v, cache %v; want no country and nothing cached", got, countries)
+	}
+
+	if _, err := s.DatabaseService.InsertCountry(types.Country{Name: "Japan", Prefix: "JA", Ccode: "JP", Continent: "AS"}); err != nil {
+		t.Fatalf("InsertCountry() error = %v", err)
+	}
+	if got := s.cachedCountry(countries, "JA1ABC/P"); got.Name != "Japan" || countries["JA1ABC"].Name != "Japan" {
+		t.Errorf("cachedCountry() = %+v, cache %v; want Japan, cached", got, countries)
+	}
+}
+
+func TestDxClusterWorker(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan string, 8)
+	go func() {
+		conn, aerr := ln.Accept()
+		if aerr != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("login: "))
+		call, _ := r.ReadString('\n')
+		received <- call
+		_, _ = conn.Write([]byte("W1AW de STANDIN >"))
+		_, _ = conn.Write([]byte("\r\nDX de SP5XYZ:  14025.0  JA1ABC  CW 599  1234Z\r\n"))
+		_, _ = r.ReadString('\n') // block until the client disconnects
+	}()
+
+	d, err := s.newDxCluster(DxClusterOptions{
+		Enabled:  true,
+		Host:     "127.0.0.1",
+		Port:     ln.Addr().(*net.TCPAddr).Port,
+		MaxSpots: 10,
+	})
+	if err != nil {
+		t.Fatalf("newDxCluster() error = %v", err)
+	}
+	d.countries["JA1ABC"] = types.Country{Name: "Japan", Ccode: "JP", Continent: "AS"}
+	s.dxCluster = d
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.dxClusterWorker(shutdown)
+		close(done)
+	}()
+	defer cancel()
+
+	select {
+	case call := <-received:
+		if call != "W1AW\r\n" {
+			t.Errorf("login = %q, want the logbook callsign", call)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the login")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	var spots []DxSpot
+	for time.Now().Before(deadline) {
+		if spots, err = s.FetchDxSpots(); err == nil && len(spots) == 1 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(spots) != 1 || spots[0].Spot.DxCall != "JA1ABC" || !spots[0].NewDxcc || spots[0].Band != "20m" {
+		t.Fatalf("FetchDxSpots() = %+v", spots)
+	}
+
+	status, err := s.FetchDxClusterStatus()
+	if err != nil || status.State != string(dxcluster.StateConnected) {
+		t.Errorf("FetchDxClusterStatus() = %+v, %v", status, err)
+	}
+
+	close(shutdown)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("dxClusterWorker() did not stop on shutdown")
+	}
+}
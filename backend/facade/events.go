@@ -0,0 +1,82 @@
+package facade
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// EventName is the name of an event emitted by the facade to the frontend, in addition to the shared events
+// defined in the enums module.
+type EventName string
+
+const (
+	// EventAwardStatus carries the award needs (new DXCC, band or mode slot) of the callsign passed to NewQso, as
+	// also returned by NewQso.
+	EventAwardStatus EventName = "AWARD_STATUS"
+	// EventWorkedMatrix carries the band x mode worked-before matrix of the callsign passed to NewQso, as also
+	// returned by NewQso.
+	EventWorkedMatrix EventName = "WORKED_MATRIX"
+	// EventQsoRate carries the rolling QSO rate for the current session. It is emitted on every LogQso and
+	// periodically while the service is running.
+	EventQsoRate EventName = "QSO_RATE"
+	// EventDxSpot carries an enriched DX cluster spot.
+	EventDxSpot EventName = "DX_SPOT"
+	// EventDxClusterStatus carries the connection state of the DX cluster client whenever it changes.
+	EventDxClusterStatus EventName = "DX_CLUSTER_STATUS"
+	// EventCatConnection carries the state of the connection to the rig (connected, disconnected or reconnecting)
+	// whenever it changes.
+	EventCatConnection EventName = "CAT_CONNECTION"
+	// EventCwEcho carries the characters sent by the WinKeyer, as it sends them.
+	EventCwEcho EventName = "CW_ECHO"
+	// EventCwKeyerStatus carries the state of the WinKeyer whenever it changes.
+	EventCwKeyerStatus EventName = "CW_KEYER_STATUS"
+	// EventExternalQso carries a QSO logged by another program, from an ADIF record or through the REST API.
+	EventExternalQso EventName = "EXTERNAL_QSO"
+	// EventWsjtxActivity carries the recent decodes of the WSJT-X instances on the bands that have new decodes. It
+	// is emitted at most once per the configured interval.
+	EventWsjtxActivity EventName = "WSJTX_ACTIVITY"
+	// EventDatabaseRestored carries the current logbook after the database was replaced by a backup; everything
+	// loaded from the database before should be fetched again.
+	EventDatabaseRestored EventName = "DATABASE_RESTORED"
+	// EventOperatorChanged carries the operator the station was handed over to; the callsign is empty when it was
+	// handed back to the logging station configuration's operator.
+	EventOperatorChanged EventName = "OPERATOR_CHANGED"
+)
+
+func (en EventName) String() string {
+	return string(en)
+}
+
+// AllEvents is bound to the frontend as an enum, alongside events.AllEvents.
+var AllEvents = []struct {
+	Value  EventName
+	TSName string
+}{
+	{Value: EventAwardStatus, TSName: "AwardStatus"},
+	{Value: EventWorkedMatrix, TSName: "WorkedMatrix"},
+	{Value: EventQsoRate, TSName: "QsoRate"},
+	{Value: EventDxSpot, TSName: "DxSpot"},
+	{Value: EventDxClusterStatus, TSName: "DxClusterStatus"},
+	{Value: EventCatConnection, TSName: "CatConnection"},
+	{Value: EventCwEcho, TSName: "CwEcho"},
+	{Value: EventCwKeyerStatus, TSName: "CwKeyerStatus"},
+	{Value: EventExternalQso, TSName: "ExternalQso"},
+	{Value: EventWsjtxActivity, TSName: "WsjtxActivity"},
+	{Value: EventDatabaseRestored, TSName: "DatabaseRestored"},
+	{Value: EventOperatorChanged, TSName: "OperatorChanged"},
+}
+
+// wailsEventsKey is the context key under which the Wails runtime stores its event bus.
+const wailsEventsKey = "events"
+
+// emitEvent emits an event to the frontend and to the subscribers of the REST API's event stream. The frontend is
+// skipped unless the service context was provided by the Wails runtime, as the runtime terminates the process if
+// called with any other context.
+func (s *Service) emitEvent(name string, data any) {
+	if api := s.restApi.Load(); api != nil {
+		api.Publish(name, data)
+	}
+	if s.ctx == nil || s.ctx.Value(wailsEventsKey) == nil {
+		return
+	}
+	runtime.EventsEmit(s.ctx, name, data)
+}
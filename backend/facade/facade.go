@@ -1,6 +1,7 @@
 package facade
 
 import (
+	"cmp"
 	"net/url"
 	"strconv"
 	"strings"
@@ -61,7 +62,7 @@ func (s *Service) FetchUiConfig() (*types.UiConfig, error) {
 	return &types.UiConfig{
 		DefaultRigID:       requiredCfg.DefaultRigID,
 		Logbook:            s.CurrentLogbook,
-		RigName:            s.CatService.RigConfig().Name,
+		RigName:            s.catService().RigConfig().Name,
 		DefaultIsRandomQso: requiredCfg.DefaultIsRandomQso,
 		DefaultTxPower:     requiredCfg.DefaultTxPower,
 		UsePowerMultiplier: requiredCfg.UsePowerMultiplier,
@@ -109,12 +110,12 @@ func (s *Service) Ready() error {
 		return errors.Root(err)
 	}
 
-	if err := s.CatService.EnqueueCommand(cmds.Init); err != nil {
+	if err := s.catService().EnqueueCommand(cmds.Init); err != nil {
 		s.LoggerService.ErrorWith().Err(err).Msgf("Failed to enqueue command: %s", cmds.Init)
 		return errors.Root(err)
 	}
 
-	if err := s.CatService.EnqueueCommand(cmds.Read); err != nil {
+	if err := s.catService().EnqueueCommand(cmds.Read); err != nil {
 		s.LoggerService.ErrorWith().Err(err).Msgf("Failed to enqueue command: %s", cmds.Read)
 		return errors.Root(err)
 	}
@@ -122,8 +123,9 @@ func (s *Service) Ready() error {
 	return nil
 }
 
-// NewQso initializes a new QSO object with the given callsign.
-func (s *Service) NewQso(callsign string) (*types.Qso, error) {
+// NewQso initializes a new QSO object with the given callsign, along with the worked-before matrix of the callsign
+// and its entity, and whether it would be a new DXCC entity, band slot or mode slot.
+func (s *Service) NewQso(callsign string) (*NewQsoResult, error) {
 	const op errors.Op = "facade.Service.NewQso"
 	if !s.initialized.Load() {
 		err := errors.New(op).Msg(errMsgServiceNotInit)
@@ -143,12 +145,15 @@ func (s *Service) NewQso(callsign string) (*types.Qso, error) {
 		return nil, errors.New(op).Msg(errMsgInvalidCallsign)
 	}
 
-	qso, err := s.initializeQso(callsign)
+	result, err := s.initializeQso(callsign)
 	if err != nil {
 		return nil, errors.Root(err)
 	}
 
-	return qso, nil
+	// The matrix and needs are also emitted, for views other than the one that asked for the QSO.
+	s.emitWorkedBefore(result)
+
+	return result, nil
 }
 
 // LogQso inserts a new QSO into the database.
@@ -166,13 +171,26 @@ func (s *Service) LogQso(qso types.Qso) error {
 		return errors.Root(err)
 	}
 
-	// Set the current session ID
-	qso.SessionID = s.sessionID
+	_, err := s.logQso(qso)
+	return err
+}
+
+// logQso inserts a new QSO into the current session and returns its ID.
+func (s *Service) logQso(qso types.Qso) (int64, error) {
+	const op errors.Op = "facade.Service.logQso"
+
+	// Set the current session ID, and the operator if the QSO does not name one: the current operator, else the
+	// station callsign (as ADIF treats a QSO without an OPERATOR).
+	operator, sessionID := s.currentOperatorSession()
+	qso.SessionID = sessionID
+	if strings.TrimSpace(qso.Operator) == "" {
+		qso.Operator = cmp.Or(operator.Callsign, qso.StationCallsign, s.CurrentLogbook.Callsign)
+	}
 
 	if err := s.validate.Struct(qso); err != nil {
 		verr := errors.New(op).Msg("QSO Validation failed")
 		s.LoggerService.ErrorWith().Err(err).Msg("QSO Validation failed")
-		return verr
+		return 0, verr
 	}
 
 	distance, direction := s.distanceAndDirection(qso)
@@ -184,9 +202,15 @@ func (s *Service) LogQso(qso types.Qso) error {
 	if err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to insert QSO into database.")
-		return errors.Root(err)
+		return 0, errors.Root(err)
 	}
 	s.LoggerService.InfoWith().Str("callsign", qso.Call).Msg("QSO logged successfully")
+	s.invalidateStats(qso.LogbookID)
+	s.recordQsoRate(qso.Band)
+
+	logged := qso
+	logged.ID = qsoId
+	s.broadcastN1mmContact(n1mmContactInfo, logged)
 
 	// Check if the contacted station exists in the database and insert or update it if it does not
 	// match the current QSO's contacted station. The ContactedStation object is loaded when
@@ -201,14 +225,25 @@ func (s *Service) LogQso(qso types.Qso) error {
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to insert or update country.")
 	}
 
+	if err = s.saveQslFromQso(qsoId, qso.Qsl); err != nil {
+		// Not fatal; the QSL state can be corrected from the QSL workflow.
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to save QSL card state.")
+	}
+
+	if after, aerr := s.qsoSnapshot(qsoId); aerr != nil {
+		s.LoggerService.ErrorWith().Err(aerr).Msg("Failed to fetch QSO for the audit trail.")
+	} else {
+		s.auditQsoChange(qsoId, auditInsert, nil, after, 0)
+	}
+
 	// The last operation is to add an upload record.
 	if err = s.DatabaseService.InsertQsoUpload(qsoId, action.Insert, upload.OnlineServiceQRZ); err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to insert QSO upload into database.")
-		return errors.Root(err)
+		return 0, errors.Root(err)
 	}
 
-	return nil
+	return qsoId, nil
 }
 
 // UpdateQso updates an existing QSO record in the database and logs the operation; validates input and service state.
@@ -247,13 +282,35 @@ func (s *Service) UpdateQso(qso types.Qso) error {
 		}
 	}
 
-	if err := s.DatabaseService.UpdateQso(qso); err != nil {
+	// The QSO as it was, for the audit trail.
+	before, err := s.qsoSnapshot(qso.ID)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSO from database.")
+		return errors.Root(err)
+	}
+
+	if err = s.DatabaseService.UpdateQso(qso); err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to update QSO in database.")
 		return errors.Root(err)
 	}
+	s.invalidateStats(qso.LogbookID)
+	s.broadcastN1mmContact(n1mmContactReplace, qso)
+
+	if err = s.saveQslFromQso(qso.ID, qso.Qsl); err != nil {
+		// Not fatal; the QSL state can be corrected from the QSL workflow.
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to save QSL card state.")
+	}
 
-	if err := s.DatabaseService.InsertQsoUpload(qso.ID, action.Update, upload.OnlineServiceQRZ); err != nil {
+	if after, aerr := s.qsoSnapshot(qso.ID); aerr != nil {
+		s.LoggerService.ErrorWith().Err(aerr).Msg("Failed to fetch QSO for the audit trail.")
+	} else {
+		s.auditQsoChange(qso.ID, auditUpdate, before, after, 0)
+	}
+
+	// A QSO may be edited more than once, so the update is queued again rather than inserted.
+	if err = s.enqueueQsoUpload(qso.ID, action.Update); err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to insert QSO upload into database.")
 		return errors.Root(err)
@@ -277,13 +334,23 @@ func (s *Service) CurrentSessionQsoSlice() ([]types.Qso, error) {
 		return nil, errors.Root(err)
 	}
 
-	list, err := s.DatabaseService.FetchQsoSliceBySessionID(s.sessionID)
+	_, sessionID := s.currentOperatorSession()
+	list, err := s.DatabaseService.FetchQsoSliceBySessionID(sessionID)
 	if err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSOs by session ID.")
 		return nil, errors.Root(err)
 	}
 
+	// Merged so that a QSO edited from the list keeps its QSL card.
+	for i := range list {
+		if err = s.mergeQslIntoQso(&list[i]); err != nil {
+			err = errors.New(op).Err(err)
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to merge QSL card into QSO.")
+			return nil, errors.Root(err)
+		}
+	}
+
 	return list, nil
 }
 
@@ -408,6 +475,10 @@ func (s *Service) GetQsoById(id int64) (types.Qso, error) {
 		return types.Qso{}, errors.Root(err)
 	}
 
+	if err = s.mergeQslIntoQso(&qso); err != nil {
+		s.LoggerService.WarnWith().Err(err).Int64("id", id).Msg("Failed to merge QSL card state into QSO")
+	}
+
 	return qso, nil
 }
 
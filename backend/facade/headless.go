@@ -0,0 +1,94 @@
+package facade
+
+import (
+	"context"
+
+	"github.com/Station-Manager/errors"
+)
+
+// StartHeadless starts the service for the command line: the database is opened and the default logbook loaded, with
+// a new session, and the forwarders are resolved. The CAT service, the listeners and the workers are not started, so
+// only the methods that use the log may be called.
+func (s *Service) StartHeadless(ctx context.Context) error {
+	const op errors.Op = "facade.Service.StartHeadless"
+
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started.CompareAndSwap(false, true) {
+		// Service already started
+		return nil
+	}
+
+	if s.container == nil {
+		s.started.Store(false)
+		return errors.New(op).Msg("Container is nil. Please call SetContainer() before calling StartHeadless()")
+	}
+
+	if ctx == nil || ctx.Err() != nil {
+		s.started.Store(false)
+		err := errors.New(op).Msg("Context cannot be nil or cancelled")
+		s.LoggerService.ErrorWith().Msg("Context cannot be nil or cancelled")
+		return errors.Root(err)
+	}
+	s.ctx = ctx
+
+	if err := s.openAndLoadFromDatabase(); err != nil {
+		s.started.Store(false)
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to open and load from database.")
+		return errors.Root(err)
+	}
+
+	options, err := s.loadAppOptions()
+	if err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to load app options, continuing with defaults")
+	}
+	s.options = options
+
+	if err = s.resolveForwarders(); err != nil {
+		s.started.Store(false)
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// StopHeadless ends the session started by StartHeadless and closes the database.
+func (s *Service) StopHeadless() error {
+	const op errors.Op = "facade.Service.StopHeadless"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started.CompareAndSwap(true, false) {
+		return nil
+	}
+
+	var shutdownErrors []error
+	_, sessionID := s.currentOperatorSession()
+	if err := s.DatabaseService.SoftDeleteSessionByID(sessionID); err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to soft-delete session ID")
+		shutdownErrors = append(shutdownErrors, err)
+	}
+	if err := s.DatabaseService.Close(); err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to close database")
+		shutdownErrors = append(shutdownErrors, err)
+	}
+
+	if len(shutdownErrors) > 0 {
+		return errors.New(op).Msgf("shutdown completed with %d error(s)", len(shutdownErrors))
+	}
+
+	return nil
+}
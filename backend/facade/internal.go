@@ -1,6 +1,7 @@
 package facade
 
 import (
+	stderr "errors"
 	"strconv"
 	"strings"
 	"time"
@@ -107,6 +108,8 @@ func (s *Service) initializeForwarding() error {
 		forwardingQueue: make(chan types.QsoUpload, s.requiredCfgs.QsoForwardingQueueSize),
 		dbWriteQueue:    make(chan func() error, s.requiredCfgs.DatabaseWriteQueueSize), // Buffered to handle bursts
 		fetchPending: func() ([]types.QsoUpload, error) {
+			s.dbGate.RLock()
+			defer s.dbGate.RUnlock()
 			return s.DatabaseService.FetchPendingUploads()
 		},
 		sendAndMarkDone: func(qsoUpload types.QsoUpload) error {
@@ -222,6 +225,9 @@ func (s *Service) forwardNetworkOnly(provider interface{}, qsoUpload types.QsoUp
 func (s *Service) updateDatabaseOnly(qsoUpload types.QsoUpload, networkErr error) error {
 	const op errors.Op = "facade.Service.updateDatabaseOnly"
 
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
 	errState := ""
 	uploadStatus := status.Failed
 
@@ -277,3 +283,26 @@ func (s *Service) distanceAndDirection(qso types.Qso) (string, string) {
 	}
 	return qso.CountryDetails.LongPathDistance, qso.CountryDetails.LongPathBearing
 }
+
+// resolveCountry returns the country (DXCC entity) for a callsign from the local country table, falling back to an
+// online lookup for prefixes that have not been logged before. Unlike initCountrySection, nothing is written back.
+func (s *Service) resolveCountry(callsign string) (types.Country, error) {
+	const op errors.Op = "facade.Service.resolveCountry"
+
+	parsedCallsign := s.parseCallsign(callsign)
+
+	country, err := s.DatabaseService.FetchCountryByCallsign(parsedCallsign)
+	if err == nil {
+		return country, nil
+	}
+	if !stderr.Is(err, errors.ErrNotFound) {
+		return types.Country{}, errors.New(op).Err(err)
+	}
+
+	country, err = s.HamnutLookupService.Lookup(parsedCallsign)
+	if err != nil {
+		return types.Country{}, errors.New(op).Err(err)
+	}
+
+	return country, nil
+}
@@ -1,25 +1,98 @@
 package facade
 
 import (
+	"maps"
 	"sync"
+	"time"
 
 	"github.com/Station-Manager/enums/events"
 	"github.com/Station-Manager/errors"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/Station-Manager/types"
 )
 
-const (
-	// eventEmitBufferSize limits the number of pending status events to prevent goroutine leaks.
-	// If the buffer is full, older events are dropped to avoid unbounded growth.
-	eventEmitBufferSize = 16
-)
+// statusCoalescer merges CAT status updates until they are emitted, so a burst of updates (e.g., while tuning) is
+// emitted as a single, latest-value-wins status rather than queued or dropped.
+type statusCoalescer struct {
+	mu      sync.Mutex
+	pending types.CatStatus
+	// ready holds a signal while there is a pending status.
+	ready chan struct{}
+}
+
+func newStatusCoalescer() *statusCoalescer {
+	return &statusCoalescer{ready: make(chan struct{}, 1)}
+}
+
+// put merges a status into the pending status.
+func (c *statusCoalescer) put(status types.CatStatus) {
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = make(types.CatStatus, len(status))
+	}
+	maps.Copy(c.pending, status)
+	c.mu.Unlock()
+
+	select {
+	case c.ready <- struct{}{}:
+	default:
+		// Already signalled; the update has been merged into the pending status.
+	}
+}
+
+// take returns the pending status, or nil if there is none, and clears it.
+func (c *statusCoalescer) take() types.CatStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-// catStatusChannelListener listens to the CAT status updates channel and logs received updates or handles shutdown signals.
-// It uses a bounded worker pool to prevent goroutine leaks when status updates arrive faster than they can be emitted.
+	status := c.pending
+	c.pending = nil
+	return status
+}
+
+// run emits the pending status whenever there is one, at most once per interval, until stop is closed.
+func (c *statusCoalescer) run(stop <-chan struct{}, interval time.Duration, emit func(types.CatStatus)) {
+	runThrottled(stop, c.ready, interval, func() (types.CatStatus, bool) {
+		status := c.take()
+		return status, status != nil
+	}, emit)
+}
+
+// runThrottled emits what take returns each time ready is signalled, at most once per interval, until stop is
+// closed. Signals received while waiting out the interval are merged, as take returns everything pending; the bool
+// return of take is false if there is nothing to emit.
+func runThrottled[T any](stop, ready <-chan struct{}, interval time.Duration, take func() (T, bool), emit func(T)) {
+	var last time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ready:
+		}
+
+		if wait := interval - time.Since(last); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		if v, ok := take(); ok {
+			emit(v)
+			last = time.Now()
+		}
+	}
+}
+
+// catStatusChannelListener listens to the CAT status updates channel, keeping the snapshot of the rig state
+// up to date and emitting the updates to the frontend until shutdown. Updates are coalesced and emitted at most
+// once per the configured interval, so the frontend always receives the latest state without being flooded.
 func (s *Service) catStatusChannelListener(shutdown <-chan struct{}) {
 	const op errors.Op = "facade.Service.catStatusChannelListener"
 
-	statusChannel, err := s.CatService.StatusChannel()
+	statusChannel, err := s.catService().StatusChannel()
 	if err != nil {
 		err = errors.New(op).Err(err).Msg("Failed to get cat status channel.")
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to start CAT status channel listener.")
@@ -32,60 +105,48 @@ func (s *Service) catStatusChannelListener(shutdown <-chan struct{}) {
 		return
 	}
 
-	// Create a buffered channel for event emission to bound goroutine creation
-	eventQueue := make(chan map[string]string, eventEmitBufferSize)
+	emit := func(status types.CatStatus) {
+		s.emitEvent(events.Status.String(), status)
+	}
+	interval := time.Duration(s.options.CatStatusEvents.MinIntervalMs) * time.Millisecond
 
-	// Start a single worker goroutine to emit events sequentially
+	// Start a single goroutine to emit the coalesced updates
+	coalescer := newStatusCoalescer()
+	stop := make(chan struct{})
 	var emitWg sync.WaitGroup
 	emitWg.Add(1)
 	go func() {
 		defer emitWg.Done()
-		for {
-			select {
-			case <-shutdown:
-				s.LoggerService.DebugWith().Msg("Event emitter received shutdown signal")
-				return
-			case <-s.ctx.Done():
-				s.LoggerService.DebugWith().Msg("Event emitter context cancelled")
-				return
-			case statusCopy, ok := <-eventQueue:
-				if !ok {
-					return
-				}
-				// Emit the event to the frontend
-				runtime.EventsEmit(s.ctx, events.Status.String(), statusCopy)
-			}
-		}
+		coalescer.run(stop, interval, emit)
 	}()
+	stopEmitter := func() {
+		close(stop)
+		emitWg.Wait()
+	}
 
 	// Main listener loop
 	for {
 		select {
 		case <-shutdown:
 			s.LoggerService.DebugWith().Msg("CAT status listener received shutdown signal")
-			close(eventQueue)
-			emitWg.Wait()
+			stopEmitter()
 			return
 		case <-s.ctx.Done():
 			s.LoggerService.DebugWith().Msg("CAT status listener context cancelled")
-			close(eventQueue)
-			emitWg.Wait()
+			stopEmitter()
 			return
 		case status, ok := <-statusChannel:
 			if !ok {
 				s.LoggerService.InfoWith().Msg("CAT status channel closed, listener exiting")
-				close(eventQueue)
-				emitWg.Wait()
+				stopEmitter()
+				// Make sure the last state reaches the frontend.
+				if pending := coalescer.take(); pending != nil {
+					emit(pending)
+				}
 				return
 			}
-			// Non-blocking send to the event queue; drop if full to prevent blocking
-			select {
-			case eventQueue <- status:
-				// Successfully queued
-			default:
-				// Queue is full, drop this update to prevent blocking
-				s.LoggerService.DebugWith().Msg("Event queue full, dropping status update")
-			}
+			s.catState.update(status, time.Now())
+			coalescer.put(status)
 		}
 	}
 }
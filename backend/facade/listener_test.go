@@ -0,0 +1,192 @@
+package facade
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// emitRecorder records the statuses emitted by a coalescer.
+type emitRecorder struct {
+	mu       sync.Mutex
+	statuses []types.CatStatus
+	times    []time.Time
+}
+
+func (r *emitRecorder) emit(status types.CatStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, status)
+	r.times = append(r.times, time.Now())
+}
+
+func (r *emitRecorder) snapshot() ([]types.CatStatus, []time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]types.CatStatus(nil), r.statuses...), append([]time.Time(nil), r.times...)
+}
+
+// waitFor polls until cond holds or the timeout expires.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+// =============================================================================
+// Coalescer Tests
+// =============================================================================
+
+func TestStatusCoalescer_MergesPending(t *testing.T) {
+	c := newStatusCoalescer()
+	if got := c.take(); got != nil {
+		t.Fatalf("take() = %v, want nil with nothing pending", got)
+	}
+
+	c.put(types.CatStatus{tags.VfoAFreq.String(): "014074000", tags.MainMode.String(): "USB"})
+	c.put(types.CatStatus{tags.VfoAFreq.String(): "014075000"})
+
+	got := c.take()
+	if got[tags.VfoAFreq.String()] != "014075000" || got[tags.MainMode.String()] != "USB" {
+		t.Errorf("take() = %v, want the latest frequency merged with the mode", got)
+	}
+	if again := c.take(); again != nil {
+		t.Errorf("take() = %v after a take, want nil", again)
+	}
+}
+
+func TestStatusCoalescer_BurstEmitsLatestState(t *testing.T) {
+	c := newStatusCoalescer()
+	rec := &emitRecorder{}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.run(stop, 30*time.Millisecond, rec.emit)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	const updates = 200
+	for i := 1; i <= updates; i++ {
+		c.put(types.CatStatus{tags.VfoAFreq.String(): fmt.Sprintf("%09d", 14000000+i)})
+	}
+
+	want := fmt.Sprintf("%09d", 14000000+updates)
+	ok := waitFor(t, time.Second, func() bool {
+		statuses, _ := rec.snapshot()
+		return len(statuses) > 0 && statuses[len(statuses)-1][tags.VfoAFreq.String()] == want
+	})
+	if !ok {
+		statuses, _ := rec.snapshot()
+		t.Fatalf("emitted %v, want the last state %s emitted", statuses, want)
+	}
+
+	statuses, times := rec.snapshot()
+	if len(statuses) >= updates {
+		t.Errorf("emitted %d statuses for %d updates, want them coalesced", len(statuses), updates)
+	}
+	for i := 1; i < len(times); i++ {
+		// Allow for timer jitter.
+		if gap := times[i].Sub(times[i-1]); gap < 25*time.Millisecond {
+			t.Errorf("emits %d and %d were %v apart, want at least the interval", i-1, i, gap)
+		}
+	}
+}
+
+func TestStatusCoalescer_StopWithoutEmit(t *testing.T) {
+	c := newStatusCoalescer()
+	rec := &emitRecorder{}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.run(stop, time.Hour, rec.emit)
+	}()
+
+	// The first status goes out at once; the second waits out the interval.
+	c.put(types.CatStatus{tags.MainMode.String(): "USB"})
+	waitFor(t, time.Second, func() bool {
+		statuses, _ := rec.snapshot()
+		return len(statuses) == 1
+	})
+	c.put(types.CatStatus{tags.MainMode.String(): "CW-U"})
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("run() did not return after stop")
+	}
+
+	if statuses, _ := rec.snapshot(); len(statuses) != 1 {
+		t.Errorf("emitted %v, want only the first status", statuses)
+	}
+	if got := c.take(); got[tags.MainMode.String()] != "CW-U" {
+		t.Errorf("take() = %v, want the status still pending after stop", got)
+	}
+}
+
+// =============================================================================
+// Listener Tests
+// =============================================================================
+
+func TestCatStatusChannelListener_UpdatesSnapshot(t *testing.T) {
+	s := createStartedTestService()
+	s.options = defaultAppOptions()
+	statuses := make(chan types.CatStatus)
+	s.altCat = &stubCatBackend{statuses: statuses}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.catStatusChannelListener(make(chan struct{}))
+	}()
+
+	statuses <- types.CatStatus{tags.VfoAFreq.String(): "007074000"}
+	statuses <- types.CatStatus{tags.MainMode.String(): "DATA-U"}
+	close(statuses)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the listener did not exit when the status channel closed")
+	}
+
+	status, updated, _ := s.catState.get()
+	if updated.IsZero() || status[tags.VfoAFreq.String()] != "007074000" || status[tags.MainMode.String()] != "DATA-U" {
+		t.Errorf("snapshot = %v, want the merged statuses", status)
+	}
+}
+
+func TestCatStatusChannelListener_Shutdown(t *testing.T) {
+	s := createStartedTestService()
+	s.options = defaultAppOptions()
+	s.altCat = &stubCatBackend{statuses: make(chan types.CatStatus)}
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.catStatusChannelListener(shutdown)
+	}()
+
+	close(shutdown)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the listener did not exit on shutdown")
+	}
+}
@@ -0,0 +1,464 @@
+package facade
+
+import (
+	"database/sql"
+	stderr "errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Station-Manager/errors"
+)
+
+// The maintenance jobs, in the order RunMaintenance runs them.
+const (
+	maintenanceIntegrity        = "integrity"
+	maintenanceForeignKeys      = "foreign_keys"
+	maintenanceOrphanedUploads  = "orphaned_uploads"
+	maintenanceOrphanedStations = "orphaned_stations"
+	maintenanceCountryLinks     = "country_links"
+	maintenanceVacuum           = "vacuum"
+)
+
+// maxMaintenanceDetails caps the details listed per job; Found still counts every problem.
+const maxMaintenanceDetails = 50
+
+// cascadeTables are the tables whose rows are deleted with the QSO they belong to, so a row left without its QSO can
+// be deleted too.
+var cascadeTables = []string{"qso_upload", "qsl_card", "qso_confirmation", "qso_award_ref"}
+
+// DatabaseCheck is the outcome of CheckDatabase. The database is sound when Problems is empty.
+type DatabaseCheck struct {
+	Problems []string `json:"problems"`
+}
+
+// Ok reports whether the check found no problems.
+func (c DatabaseCheck) Ok() bool {
+	return len(c.Problems) == 0
+}
+
+// MaintenanceReport is the outcome of RunMaintenance, one entry per job.
+type MaintenanceReport struct {
+	Fix  bool             `json:"fix"`
+	Jobs []MaintenanceJob `json:"jobs"`
+}
+
+// MaintenanceJob is the outcome of one maintenance job.
+type MaintenanceJob struct {
+	Name    string   `json:"name"`
+	Found   int      `json:"found"`
+	Fixed   int      `json:"fixed"`
+	Details []string `json:"details"`
+	// Skipped says why the job did not run, e.g. the vacuum without the fix mode.
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Advisory says that the job only reports what may need a look, e.g. the country links; what it finds is not
+	// counted as a problem.
+	Advisory bool `json:"advisory,omitempty"`
+}
+
+// Ok reports whether every job ran and every problem found was fixed, leaving out the findings of advisory jobs.
+func (r MaintenanceReport) Ok() bool {
+	for _, job := range r.Jobs {
+		if job.Error != "" || (!job.Advisory && job.Found > job.Fixed) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckDatabase checks the database file for corruption and the rows for broken references between the tables.
+func (s *Service) CheckDatabase() (DatabaseCheck, error) {
+	const op errors.Op = "facade.Service.CheckDatabase"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return DatabaseCheck{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return DatabaseCheck{}, errors.Root(err)
+	}
+
+	check := DatabaseCheck{Problems: make([]string, 0)}
+
+	integrity, err := s.integrityProblems()
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to check the database integrity")
+		return DatabaseCheck{}, errors.Root(err)
+	}
+	check.Problems = append(check.Problems, integrity...)
+
+	keys, err := s.foreignKeyProblems()
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to check the database foreign keys")
+		return DatabaseCheck{}, errors.Root(err)
+	}
+	for _, key := range keys {
+		check.Problems = append(check.Problems, key.String())
+	}
+
+	if !check.Ok() {
+		s.LoggerService.WarnWith().Int("problems", len(check.Problems)).Msg("Database check found problems")
+	}
+	return check, nil
+}
+
+// RunMaintenance checks the database and the consistency of the log: corruption, broken references, uploads and
+// contacted stations left behind by deleted QSOs, and QSOs whose country is not in the country table. With fix, the
+// database is backed up, the problems that can be fixed are, and the file is vacuumed. The countries are only reported,
+// and do not count against the report's Ok. Nothing is fixed in a database that fails the integrity check; restore a
+// backup instead.
+func (s *Service) RunMaintenance(fix bool) (MaintenanceReport, error) {
+	const op errors.Op = "facade.Service.RunMaintenance"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return MaintenanceReport{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return MaintenanceReport{}, errors.Root(err)
+	}
+
+	report := MaintenanceReport{Fix: fix, Jobs: make([]MaintenanceJob, 0, 6)}
+
+	integrity := s.checkIntegrity()
+	report.Jobs = append(report.Jobs, integrity)
+	if fix && (integrity.Found > 0 || integrity.Error != "") {
+		s.LoggerService.WarnWith().Msg("Database failed the integrity check, nothing will be fixed")
+		fix = false
+	}
+	if fix {
+		if _, err := s.backupDatabase("maintenance"); err != nil {
+			err = errors.New(op).Err(err).Msg("Failed to back up the database before fixing it")
+			s.LoggerService.ErrorWith().Err(err).Msg("Maintenance cancelled")
+			return report, errors.Root(err)
+		}
+	}
+
+	jobs := []struct {
+		name string
+		run  func(job *MaintenanceJob, fix bool) error
+	}{
+		{maintenanceForeignKeys, s.fixForeignKeys},
+		{maintenanceOrphanedUploads, s.fixOrphanedUploads},
+		{maintenanceOrphanedStations, s.fixOrphanedStations},
+		{maintenanceCountryLinks, s.checkCountryLinks},
+		{maintenanceVacuum, s.vacuumDatabase},
+	}
+	for _, j := range jobs {
+		job := MaintenanceJob{Name: j.name, Details: make([]string, 0)}
+		if err := j.run(&job, fix); err != nil {
+			job.Error = errors.Root(err).Error()
+			s.LoggerService.ErrorWith().Err(err).Str("job", j.name).Msg("Maintenance job failed")
+		}
+		report.Jobs = append(report.Jobs, job)
+	}
+
+	if fix {
+		// The fixes may change what the statistics count.
+		s.statsMu.Lock()
+		s.statsCache = nil
+		s.statsMu.Unlock()
+	}
+
+	s.LoggerService.InfoWith().Bool("fix", report.Fix).Bool("ok", report.Ok()).Msg("Database maintenance completed")
+	return report, nil
+}
+
+// checkIntegrity runs the integrity check as a maintenance job. Corruption cannot be fixed here.
+func (s *Service) checkIntegrity() MaintenanceJob {
+	job := MaintenanceJob{Name: maintenanceIntegrity, Details: make([]string, 0)}
+	problems, err := s.integrityProblems()
+	if err != nil {
+		job.Error = errors.Root(err).Error()
+		return job
+	}
+	for _, p := range problems {
+		job.add(p)
+	}
+	return job
+}
+
+// fixForeignKeys finds the rows referencing a missing row. Those of the tables cleared with their QSO are deleted;
+// the others, QSOs without their logbook or session, are only reported.
+func (s *Service) fixForeignKeys(job *MaintenanceJob, fix bool) error {
+	const op errors.Op = "facade.Service.fixForeignKeys"
+
+	keys, err := s.foreignKeyProblems()
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	for _, key := range keys {
+		job.add(key.String())
+		if !fix || !slices.Contains(cascadeTables, key.table) {
+			continue
+		}
+		// The table name is one of cascadeTables, not input.
+		if _, err = s.DatabaseService.ExecContext(s.dbContext(), `DELETE FROM `+key.table+` WHERE rowid = ?`, key.rowid); err != nil {
+			return errors.New(op).Err(err)
+		}
+		job.Fixed++
+	}
+	return nil
+}
+
+// fixOrphanedUploads finds the uploads of QSOs that no longer exist, and the pending uploads of deleted QSOs other
+// than the deletion itself, and deletes them.
+func (s *Service) fixOrphanedUploads(job *MaintenanceJob, fix bool) error {
+	const op errors.Op = "facade.Service.fixOrphanedUploads"
+
+	const query = `SELECT u.id, printf('upload %d of QSO %d to %s (%s)', u.id, u.qso_id, u.service, u.action)
+FROM qso_upload u
+LEFT JOIN qso q ON q.id = u.qso_id
+WHERE q.id IS NULL
+   OR (q.deleted_at IS NOT NULL AND u.action <> 'delete' AND u.status <> 'uploaded')
+ORDER BY u.id`
+	ids, err := s.collectMaintenanceRows(job, query)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	if !fix || len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	res, err := s.DatabaseService.ExecContext(s.dbContext(), `DELETE FROM qso_upload WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	n, _ := res.RowsAffected()
+	job.Fixed = int(n)
+	return nil
+}
+
+// fixOrphanedStations finds the contacted stations that no QSO in the log is with, and soft-deletes them.
+func (s *Service) fixOrphanedStations(job *MaintenanceJob, fix bool) error {
+	const op errors.Op = "facade.Service.fixOrphanedStations"
+
+	const query = `SELECT cs.id, printf('contacted station %s has no QSO', cs.call)
+FROM contacted_station cs
+WHERE cs.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM qso q WHERE q.call = cs.call AND q.deleted_at IS NULL)
+ORDER BY cs.id`
+	ids, err := s.collectMaintenanceRows(job, query)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	if !fix || len(ids) == 0 {
+		return nil
+	}
+
+	placeholders, args := inPlaceholders(ids)
+	update := `UPDATE contacted_station SET deleted_at = datetime('now', 'localtime') WHERE id IN (` + placeholders + `)`
+	res, err := s.DatabaseService.ExecContext(s.dbContext(), update, args...)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	n, _ := res.RowsAffected()
+	job.Fixed = int(n)
+	return nil
+}
+
+// checkCountryLinks finds the QSOs and contacted stations whose country is not a name in the country table, listing
+// the country table entry for their callsign. They are not fixed, even in the fix mode: the name may have come from a
+// callsign lookup and be right, and a QSO whose entity changes must be edited with UpdateQso, which also sets its
+// DXCC, queues its upload and records it in the audit trail. The job is advisory, so it does not fail the report.
+func (s *Service) checkCountryLinks(job *MaintenanceJob, _ bool) error {
+	const op errors.Op = "facade.Service.checkCountryLinks"
+
+	job.Advisory = true
+
+	type unlinked struct {
+		id            int64
+		call, country string
+	}
+	// Resolved per callsign once; an empty name is a callsign the country table has no entry for.
+	resolved := make(map[string]string)
+
+	for _, table := range []string{"qso", "contacted_station"} {
+		// The table name is one of the two above, not input.
+		query := `SELECT t.id, t.call, t.country FROM ` + table + ` t
+WHERE t.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM country c WHERE c.name = t.country AND c.deleted_at IS NULL)
+ORDER BY t.id`
+		rows, err := s.DatabaseService.QueryContext(s.dbContext(), query)
+		if err != nil {
+			return errors.New(op).Err(err)
+		}
+		found := make([]unlinked, 0)
+		for rows.Next() {
+			var u unlinked
+			if err = rows.Scan(&u.id, &u.call, &u.country); err != nil {
+				_ = rows.Close()
+				return errors.New(op).Err(err)
+			}
+			found = append(found, u)
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return errors.New(op).Err(err)
+		}
+
+		for _, u := range found {
+			callsign := s.parseCallsign(u.call)
+			name, ok := resolved[callsign]
+			if !ok {
+				country, cerr := s.DatabaseService.FetchCountryByCallsign(callsign)
+				if cerr != nil && !stderr.Is(cerr, errors.ErrNotFound) {
+					return errors.New(op).Err(cerr)
+				}
+				name = country.Name
+				resolved[callsign] = name
+			}
+
+			label := strings.ReplaceAll(table, "_", " ")
+			if name == "" {
+				job.add(fmt.Sprintf("%s %d (%s): country %q not found, no country for the callsign", label, u.id, u.call, u.country))
+				continue
+			}
+			job.add(fmt.Sprintf("%s %d (%s): country %q not found, the country table has %q for the callsign", label, u.id, u.call, u.country, name))
+		}
+	}
+	return nil
+}
+
+// vacuumDatabase rebuilds the database file, returning the space of deleted rows, in the fix mode only.
+func (s *Service) vacuumDatabase(job *MaintenanceJob, fix bool) error {
+	const op errors.Op = "facade.Service.vacuumDatabase"
+
+	if !fix {
+		job.Skipped = "fix mode only"
+		return nil
+	}
+
+	path := s.DatabaseService.DatabaseConfig.Path
+	before, _ := os.Stat(path)
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), `VACUUM`); err != nil {
+		return errors.New(op).Err(err)
+	}
+	if after, err := os.Stat(path); err == nil && before != nil {
+		job.Details = append(job.Details, fmt.Sprintf("%d bytes before, %d bytes after", before.Size(), after.Size()))
+	}
+	return nil
+}
+
+// add counts a problem, keeping its description unless the details are full.
+func (j *MaintenanceJob) add(detail string) {
+	j.Found++
+	if len(j.Details) < maxMaintenanceDetails {
+		j.Details = append(j.Details, detail)
+	}
+}
+
+// collectMaintenanceRows runs a query returning an ID and a description per problem, adds the problems to the job
+// and returns the IDs.
+func (s *Service) collectMaintenanceRows(job *MaintenanceJob, query string) ([]int64, error) {
+	const op errors.Op = "facade.Service.collectMaintenanceRows"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		var detail string
+		if err = rows.Scan(&id, &detail); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		ids = append(ids, id)
+		job.add(detail)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	return ids, nil
+}
+
+// integrityProblems runs the SQLite integrity check and returns the problems it reports.
+func (s *Service) integrityProblems() ([]string, error) {
+	const op errors.Op = "facade.Service.integrityProblems"
+
+	results, err := s.queryStrings(`PRAGMA integrity_check`)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	problems := make([]string, 0)
+	for _, msg := range results {
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	return problems, nil
+}
+
+// foreignKeyProblem is a row referencing a missing row, as reported by the foreign key check.
+type foreignKeyProblem struct {
+	table  string
+	rowid  int64
+	parent string
+}
+
+func (p foreignKeyProblem) String() string {
+	return fmt.Sprintf("%s row %d references a missing %s row", p.table, p.rowid, p.parent)
+}
+
+// foreignKeyProblems runs the SQLite foreign key check.
+func (s *Service) foreignKeyProblems() ([]foreignKeyProblem, error) {
+	const op errors.Op = "facade.Service.foreignKeyProblems"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), `SELECT "table", rowid, parent FROM pragma_foreign_key_check`)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	problems := make([]foreignKeyProblem, 0)
+	for rows.Next() {
+		var p foreignKeyProblem
+		if err = rows.Scan(&p.table, &p.rowid, &p.parent); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		problems = append(problems, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	return problems, nil
+}
+
+// queryStrings runs a query returning one text column and returns its values.
+func (s *Service) queryStrings(query string, args ...any) ([]string, error) {
+	const op errors.Op = "facade.Service.queryStrings"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, args...)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	values := make([]string, 0)
+	for rows.Next() {
+		var v sql.NullString
+		if err = rows.Scan(&v); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		values = append(values, v.String)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	return values, nil
+}
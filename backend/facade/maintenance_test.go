@@ -0,0 +1,178 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/enums/upload"
+	"github.com/Station-Manager/enums/upload/action"
+	"github.com/Station-Manager/types"
+)
+
+// =============================================================================
+// Check Tests
+// =============================================================================
+
+func TestCheckDatabase(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4XY", "20m", "SSB")
+
+	check, err := s.CheckDatabase()
+	if err != nil {
+		t.Fatalf("CheckDatabase() error = %v", err)
+	}
+	if !check.Ok() {
+		t.Fatalf("CheckDatabase() = %+v, want no problems", check)
+	}
+
+	// A row left behind with the foreign keys off is a broken reference.
+	ctx := s.dbContext()
+	if _, err = s.DatabaseService.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+	if _, err = s.DatabaseService.ExecContext(ctx, `UPDATE qso SET logbook_id = 99 WHERE id = ?`, id); err != nil {
+		t.Fatalf("UPDATE error = %v", err)
+	}
+
+	check, err = s.CheckDatabase()
+	if err != nil {
+		t.Fatalf("CheckDatabase() error = %v", err)
+	}
+	if len(check.Problems) != 1 {
+		t.Errorf("CheckDatabase() = %+v, want one problem", check)
+	}
+}
+
+func TestCheckDatabase_NotStarted(t *testing.T) {
+	s := createTestService()
+	if _, err := s.CheckDatabase(); err == nil {
+		t.Error("CheckDatabase() error = nil, want an error")
+	}
+}
+
+// =============================================================================
+// Maintenance Tests
+// =============================================================================
+
+func maintenanceJob(t *testing.T, r MaintenanceReport, name string) MaintenanceJob {
+	t.Helper()
+	for _, job := range r.Jobs {
+		if job.Name == name {
+			return job
+		}
+	}
+	t.Fatalf("report has no %s job: %+v", name, r)
+	return MaintenanceJob{}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	s := createBackupTestService(t, 0)
+	ctx := s.dbContext()
+
+	if _, err := s.DatabaseService.InsertCountry(types.Country{Name: "England", Prefix: "G", Continent: "EU"}); err != nil {
+		t.Fatalf("InsertCountry() error = %v", err)
+	}
+	insertTestQso(t, s, "G4XY", "20m", "SSB")
+	renamed := newTestQso(s, "G3ABC", "40m", "CW")
+	renamed.Country = "Britain"
+	insertTestQsoValue(t, s, renamed)
+	unknown := newTestQso(s, "4U1ITU", "20m", "CW")
+	unknown.Country = "ITU HQ"
+	insertTestQsoValue(t, s, unknown)
+
+	// An upload still pending for a deleted QSO.
+	deleted := insertTestQso(t, s, "DL1ABC", "20m", "CW")
+	if err := s.DatabaseService.InsertQsoUpload(deleted, action.Insert, upload.OnlineServiceQRZ); err != nil {
+		t.Fatalf("InsertQsoUpload() error = %v", err)
+	}
+	if _, err := s.DatabaseService.ExecContext(ctx, "UPDATE qso SET deleted_at = datetime('now') WHERE id = ?", deleted); err != nil {
+		t.Fatalf("soft delete error = %v", err)
+	}
+	// A contacted station without a QSO.
+	if _, err := s.DatabaseService.InsertContactedStation(types.ContactedStation{Call: "G0XYZ", Name: "Sam", Country: "England"}); err != nil {
+		t.Fatalf("InsertContactedStation() error = %v", err)
+	}
+	// An upload of a QSO that does not exist, left behind with the foreign keys off.
+	if _, err := s.DatabaseService.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+	if _, err := s.DatabaseService.ExecContext(ctx, `INSERT INTO qso_upload (qso_id, service) VALUES (999, 'QRZ')`); err != nil {
+		t.Fatalf("INSERT error = %v", err)
+	}
+
+	type counts struct{ found, fixed int }
+	tests := []struct {
+		name string
+		fix  bool
+		want map[string]counts
+		ok   bool
+	}{
+		{
+			name: "report",
+			want: map[string]counts{
+				maintenanceIntegrity:        {0, 0},
+				maintenanceForeignKeys:      {1, 0},
+				maintenanceOrphanedUploads:  {2, 0},
+				maintenanceOrphanedStations: {1, 0},
+				maintenanceCountryLinks:     {2, 0},
+			},
+		},
+		{
+			name: "fix",
+			fix:  true,
+			ok:   true,
+			want: map[string]counts{
+				maintenanceIntegrity:        {0, 0},
+				maintenanceForeignKeys:      {1, 1},
+				maintenanceOrphanedUploads:  {1, 1},
+				maintenanceOrphanedStations: {1, 1},
+				maintenanceCountryLinks:     {2, 0},
+			},
+		},
+		{
+			// The countries are only reported, and do not fail the report.
+			name: "after fix",
+			ok:   true,
+			want: map[string]counts{
+				maintenanceIntegrity:        {0, 0},
+				maintenanceForeignKeys:      {0, 0},
+				maintenanceOrphanedUploads:  {0, 0},
+				maintenanceOrphanedStations: {0, 0},
+				maintenanceCountryLinks:     {2, 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		report, err := s.RunMaintenance(tt.fix)
+		if err != nil {
+			t.Fatalf("%s: RunMaintenance() error = %v", tt.name, err)
+		}
+		for name, want := range tt.want {
+			job := maintenanceJob(t, report, name)
+			if job.Error != "" || job.Found != want.found || job.Fixed != want.fixed {
+				t.Errorf("%s: %s = %+v, want %d found, %d fixed", tt.name, name, job, want.found, want.fixed)
+			}
+		}
+		if vacuum := maintenanceJob(t, report, maintenanceVacuum); (vacuum.Skipped == "") != tt.fix {
+			t.Errorf("%s: vacuum = %+v", tt.name, vacuum)
+		}
+		if report.Ok() != tt.ok {
+			t.Errorf("%s: Ok() = %v, want %v", tt.name, report.Ok(), tt.ok)
+		}
+	}
+
+	qso, err := s.DatabaseService.FetchQsoSliceByCallsign("G3ABC")
+	if err != nil || len(qso) != 1 || qso[0].Country != "Britain" {
+		t.Errorf("G3ABC = %+v, %v, want the country left as logged", qso, err)
+	}
+	// The database was backed up before it was fixed.
+	if backups, _ := s.FetchBackups(); len(backups) != 1 {
+		t.Errorf("%d backups, want 1", len(backups))
+	}
+}
+
+func TestRunMaintenance_NotStarted(t *testing.T) {
+	s := createTestService()
+	if _, err := s.RunMaintenance(false); err == nil {
+		t.Error("RunMaintenance() error = nil, want an error")
+	}
+}
@@ -0,0 +1,420 @@
+package facade
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// The N1MM+ packet types the facade broadcasts.
+const (
+	n1mmContactInfo    = "contactinfo"
+	n1mmContactReplace = "contactreplace"
+	n1mmContactDelete  = "contactdelete"
+	n1mmRadioInfo      = "RadioInfo"
+)
+
+const (
+	// n1mmApp identifies the sending application; tools written for N1MM+ expect its name.
+	n1mmApp = "N1MM"
+	// n1mmTimestampLayout is the layout of the timestamp element.
+	n1mmTimestampLayout = "2006-01-02 15:04:05"
+	// n1mmDefaultContest names the contest of QSOs that are not part of one, as N1MM+ does for its DX log.
+	n1mmDefaultContest = "DX"
+)
+
+// n1mmBands maps the app's bands to the band element, which is the band's lower edge in MHz.
+var n1mmBands = map[string]string{
+	"160m": "1.8", "80m": "3.5", "60m": "5", "40m": "7", "30m": "10", "20m": "14", "17m": "18", "15m": "21",
+	"12m": "24", "10m": "28", "6m": "50", "4m": "70", "2m": "144", "70cm": "420", "23cm": "1240",
+}
+
+// n1mmContact is a contactinfo or contactreplace packet. Elements the app has no data for are sent empty or zero, as
+// consumers expect all of them to be present.
+type n1mmContact struct {
+	XMLName         xml.Name
+	App             string `xml:"app"`
+	ContestName     string `xml:"contestname"`
+	ContestNr       int    `xml:"contestnr"`
+	Timestamp       string `xml:"timestamp"`
+	MyCall          string `xml:"mycall"`
+	Band            string `xml:"band"`
+	RxFreq          int64  `xml:"rxfreq"` // in units of 10 Hz
+	TxFreq          int64  `xml:"txfreq"`
+	Operator        string `xml:"operator"`
+	Mode            string `xml:"mode"`
+	Call            string `xml:"call"`
+	CountryPrefix   string `xml:"countryprefix"`
+	WpxPrefix       string `xml:"wpxprefix"`
+	StationPrefix   string `xml:"stationprefix"`
+	Continent       string `xml:"continent"`
+	Snt             string `xml:"snt"`
+	SntNr           string `xml:"sntnr"`
+	Rcv             string `xml:"rcv"`
+	RcvNr           string `xml:"rcvnr"`
+	Gridsquare      string `xml:"gridsquare"`
+	Exchange1       string `xml:"exchange1"`
+	Section         string `xml:"section"`
+	Comment         string `xml:"comment"`
+	Qth             string `xml:"qth"`
+	Name            string `xml:"name"`
+	Power           string `xml:"power"`
+	MiscText        string `xml:"misctext"`
+	Zone            string `xml:"zone"`
+	Prec            string `xml:"prec"`
+	Ck              string `xml:"ck"`
+	IsMultiplier1   int    `xml:"ismultiplier1"`
+	IsMultiplier2   int    `xml:"ismultiplier2"`
+	IsMultiplier3   int    `xml:"ismultiplier3"`
+	Points          int    `xml:"points"`
+	RadioNr         int    `xml:"radionr"`
+	Run1Run2        int    `xml:"run1run2"`
+	RoverLocation   string `xml:"RoverLocation"`
+	RadioInterfaced int    `xml:"RadioInterfaced"`
+	NetworkedCompNr int    `xml:"NetworkedCompNr"`
+	IsOriginal      string `xml:"IsOriginal"`
+	NetBiosName     string `xml:"NetBiosName"`
+	IsRunQso        int    `xml:"IsRunQSO"`
+	StationName     string `xml:"StationName"`
+	ID              string `xml:"ID"`
+	IsClaimedQso    int    `xml:"IsClaimedQso"`
+}
+
+// n1mmContactDeleted is a contactdelete packet.
+type n1mmContactDeleted struct {
+	XMLName     xml.Name `xml:"contactdelete"`
+	App         string   `xml:"app"`
+	Timestamp   string   `xml:"timestamp"`
+	Call        string   `xml:"call"`
+	ContestNr   int      `xml:"contestnr"`
+	StationName string   `xml:"StationName"`
+	ID          string   `xml:"ID"`
+}
+
+// n1mmRadio is a RadioInfo packet.
+type n1mmRadio struct {
+	XMLName            xml.Name `xml:"RadioInfo"`
+	App                string   `xml:"app"`
+	StationName        string   `xml:"StationName"`
+	RadioNr            int      `xml:"RadioNr"`
+	Freq               int64    `xml:"Freq"` // in units of 10 Hz
+	TxFreq             int64    `xml:"TXFreq"`
+	Mode               string   `xml:"Mode"`
+	OpCall             string   `xml:"OpCall"`
+	IsRunning          string   `xml:"IsRunning"`
+	FocusEntry         int      `xml:"FocusEntry"`
+	EntryWindowHwnd    int      `xml:"EntryWindowHwnd"`
+	Antenna            int      `xml:"Antenna"`
+	Rotors             string   `xml:"Rotors"`
+	FocusRadioNr       int      `xml:"FocusRadioNr"`
+	IsStereo           string   `xml:"IsStereo"`
+	IsSplit            string   `xml:"IsSplit"`
+	ActiveRadioNr      int      `xml:"ActiveRadioNr"`
+	IsTransmitting     string   `xml:"IsTransmitting"`
+	FunctionKeyCaption string   `xml:"FunctionKeyCaption"`
+	RadioName          string   `xml:"RadioName"`
+	AuxAntSelected     int      `xml:"AuxAntSelected"`
+	AuxAntSelectedName string   `xml:"AuxAntSelectedName"`
+	IsConnected        string   `xml:"IsConnected"`
+}
+
+// n1mmBroadcaster sends N1MM+ packets to the configured destinations.
+type n1mmBroadcaster struct {
+	conn     net.PacketConn
+	contacts []net.Addr
+	radio    []net.Addr
+	station  string
+	interval time.Duration
+}
+
+// newN1mmBroadcaster creates the broadcaster if it is enabled in the app options. It returns nil if it is disabled.
+func newN1mmBroadcaster(opts N1mmOptions) (*n1mmBroadcaster, error) {
+	const op errors.Op = "facade.newN1mmBroadcaster"
+	if !opts.Enabled {
+		return nil, nil
+	}
+
+	resolve := func(dests []string) ([]net.Addr, error) {
+		addrs := make([]net.Addr, 0, len(dests))
+		for _, d := range dests {
+			addr, err := net.ResolveUDPAddr("udp", strings.TrimSpace(d))
+			if err != nil {
+				return nil, errors.New(op).Err(err).Msgf("Invalid N1MM destination: %q", d)
+			}
+			addrs = append(addrs, addr)
+		}
+		return addrs, nil
+	}
+
+	contacts, err := resolve(opts.ContactDestinations)
+	if err != nil {
+		return nil, err
+	}
+	radio, err := resolve(opts.RadioDestinations)
+	if err != nil {
+		return nil, err
+	}
+
+	station := strings.TrimSpace(opts.StationName)
+	if station == "" {
+		if station, err = os.Hostname(); err != nil {
+			station = "STATION-MANAGER"
+		}
+	}
+
+	conn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return &n1mmBroadcaster{
+		conn:     conn,
+		contacts: contacts,
+		radio:    radio,
+		station:  station,
+		interval: time.Duration(opts.RadioIntervalMs) * time.Millisecond,
+	}, nil
+}
+
+// send writes a packet to each destination, returning the first error.
+func (b *n1mmBroadcaster) send(addrs []net.Addr, packet any) error {
+	const op errors.Op = "facade.n1mmBroadcaster.send"
+
+	data, err := xml.MarshalIndent(packet, "", "\t")
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	data = append([]byte(xml.Header), data...)
+
+	var firstErr error
+	for _, addr := range addrs {
+		if _, werr := b.conn.WriteTo(data, addr); werr != nil && firstErr == nil {
+			firstErr = errors.New(op).Err(werr)
+		}
+	}
+	return firstErr
+}
+
+// broadcastN1mmContact sends a contactinfo, contactreplace or contactdelete packet for a QSO. Failures are logged
+// only; the QSO has been saved regardless.
+func (s *Service) broadcastN1mmContact(kind string, qso types.Qso) {
+	b := s.n1mm
+	if b == nil || len(b.contacts) == 0 {
+		return
+	}
+
+	var packet any
+	if kind == n1mmContactDelete {
+		packet = n1mmDeletePacket(qso, b.station, time.Now().UTC())
+	} else {
+		packet = n1mmContactPacket(kind, qso, b.station)
+	}
+	if err := b.send(b.contacts, packet); err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("type", kind).Msg("Failed to broadcast the QSO to N1MM destinations")
+	}
+}
+
+// n1mmRadioWorker sends a RadioInfo packet built from the rig state at the configured interval until shutdown, and
+// then closes the broadcaster.
+func (s *Service) n1mmRadioWorker(shutdown <-chan struct{}) {
+	b := s.n1mm
+	if b == nil {
+		return
+	}
+	defer func() { _ = b.conn.Close() }()
+
+	var tick <-chan time.Time
+	if len(b.radio) > 0 && b.interval > 0 {
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-shutdown:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-tick:
+			status, updated, _ := s.catState.get()
+			if updated.IsZero() {
+				continue
+			}
+			packet := n1mmRadioPacket(status, s.catService().RigConfig().Name, s.CurrentLogbook.Callsign, b.station,
+				s.catHealth.get().State != catDisconnected)
+			if err := b.send(b.radio, packet); err != nil {
+				s.LoggerService.DebugWith().Err(err).Msg("Failed to broadcast N1MM radio info")
+			}
+		}
+	}
+}
+
+// n1mmContactPacket builds a contactinfo or contactreplace packet for a QSO.
+func n1mmContactPacket(kind string, qso types.Qso, station string) n1mmContact {
+	contest := strings.TrimSpace(qso.ContestId)
+	if contest == "" {
+		contest = n1mmDefaultContest
+	}
+
+	tx := n1mmFreq(qso.Freq)
+	rx := tx
+	if qso.FreqRx != "" {
+		rx = n1mmFreq(qso.FreqRx)
+	}
+
+	band := n1mmBands[strings.ToLower(qso.Band)]
+	if band == "" && tx > 0 {
+		band = strconv.FormatFloat(float64(tx)/1e5, 'f', -1, 64)
+	}
+
+	mode := qso.Mode
+	if qso.Submode != "" {
+		mode = qso.Submode
+	}
+
+	return n1mmContact{
+		XMLName:         xml.Name{Local: kind},
+		App:             n1mmApp,
+		ContestName:     contest,
+		ContestNr:       1,
+		Timestamp:       n1mmTimestamp(qso.QsoDate, qso.TimeOn),
+		MyCall:          qso.StationCallsign,
+		Band:            band,
+		RxFreq:          rx,
+		TxFreq:          tx,
+		Operator:        qso.Operator,
+		Mode:            mode,
+		Call:            qso.Call,
+		StationPrefix:   qso.StationCallsign,
+		Continent:       qso.Cont,
+		Snt:             qso.RstSent,
+		SntNr:           qso.STX,
+		Rcv:             qso.RstRcvd,
+		RcvNr:           qso.SRX,
+		Gridsquare:      qso.Gridsquare,
+		Comment:         qso.Comment,
+		Qth:             qso.QTH,
+		Name:            qso.Name,
+		Power:           qso.TxPwr,
+		Zone:            qso.CQZ,
+		Points:          1,
+		RadioNr:         1,
+		Run1Run2:        1,
+		RadioInterfaced: 1,
+		IsOriginal:      "True",
+		StationName:     station,
+		ID:              n1mmContactID(qso),
+		IsClaimedQso:    1,
+	}
+}
+
+// n1mmDeletePacket builds a contactdelete packet for a QSO.
+func n1mmDeletePacket(qso types.Qso, station string, at time.Time) n1mmContactDeleted {
+	return n1mmContactDeleted{
+		App:         n1mmApp,
+		Timestamp:   at.Format(n1mmTimestampLayout),
+		Call:        qso.Call,
+		ContestNr:   1,
+		StationName: station,
+		ID:          n1mmContactID(qso),
+	}
+}
+
+// n1mmRadioPacket builds a RadioInfo packet from the rig state. As when logging, the transmit frequency is the
+// selected VFO's, and the other VFO is the receive frequency when split.
+func n1mmRadioPacket(status types.CatStatus, rigName, opCall, station string, connected bool) n1mmRadio {
+	freq, other := status[tags.VfoAFreq.String()], status[tags.VfoBFreq.String()]
+	if normalizeVfo(status[tags.Select.String()]) == vfoB {
+		freq, other = other, freq
+	}
+	split := strings.HasPrefix(status[tags.Split.String()], "ON")
+
+	tx := n1mmFreq(freq)
+	rx := tx
+	if split {
+		rx = n1mmFreq(other)
+	}
+
+	return n1mmRadio{
+		App:            n1mmApp,
+		StationName:    station,
+		RadioNr:        1,
+		Freq:           rx,
+		TxFreq:         tx,
+		Mode:           n1mmRadioMode(status[tags.MainMode.String()]),
+		OpCall:         opCall,
+		IsRunning:      "False",
+		Antenna:        -1,
+		FocusRadioNr:   1,
+		IsStereo:       "False",
+		IsSplit:        n1mmBool(split),
+		ActiveRadioNr:  1,
+		IsTransmitting: "False",
+		RadioName:      rigName,
+		AuxAntSelected: -1,
+		IsConnected:    n1mmBool(connected),
+	}
+}
+
+// n1mmRadioMode converts a rig mode label to the radio mode N1MM+ reports: the data modes are reported as the
+// sideband they use.
+func n1mmRadioMode(label string) string {
+	label = strings.ToUpper(strings.TrimSpace(label))
+	base, side, _ := strings.Cut(label, "-")
+	switch base {
+	case "CW", "RTTY", "AM", "FM":
+		return base
+	case "DATA", "PKT":
+		if side == "L" {
+			return "LSB"
+		}
+		return "USB"
+	}
+	return label
+}
+
+// n1mmFreq converts a frequency in Hz to units of 10 Hz.
+func n1mmFreq(hz string) int64 {
+	v, ok := catFreqHz(hz)
+	if !ok {
+		return 0
+	}
+	return v / 10
+}
+
+// n1mmTimestamp formats an ADIF date and time (HHMM or HHMMSS) as a timestamp.
+func n1mmTimestamp(date, timeOn string) string {
+	if len(timeOn) == 4 {
+		timeOn += "00"
+	}
+	t, err := time.Parse("20060102150405", date+timeOn)
+	if err != nil {
+		return time.Now().UTC().Format(n1mmTimestampLayout)
+	}
+	return t.Format(n1mmTimestampLayout)
+}
+
+// n1mmContactID returns a stable ID for a QSO, so a contactreplace or contactdelete refers to the contactinfo sent
+// when it was logged.
+func n1mmContactID(qso types.Qso) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("station-manager:%d:%d", qso.LogbookID, qso.ID)))
+	return hex.EncodeToString(sum[:16])
+}
+
+func n1mmBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
@@ -0,0 +1,321 @@
+package facade
+
+import (
+	"encoding/xml"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+func testN1mmQso() types.Qso {
+	var qso types.Qso
+	qso.ID = 42
+	qso.LogbookID = 1
+	qso.Call = "DL1ABC"
+	qso.StationCallsign = "W1AW"
+	qso.Operator = "W1AW"
+	qso.Band = "20m"
+	qso.Freq = "14025000"
+	qso.Mode = "CW"
+	qso.QsoDate = "20261018"
+	qso.TimeOn = "1234"
+	qso.RstSent = "599"
+	qso.RstRcvd = "579"
+	qso.STX = "001"
+	qso.SRX = "123"
+	qso.ContestId = "CQ-WW-CW"
+	return qso
+}
+
+// listenN1mm returns a UDP listener on the loopback address for a test to receive packets on.
+func listenN1mm(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readN1mm reads one packet and returns its root element name and data.
+func readN1mm(t *testing.T, conn net.PacketConn) (string, []byte) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 8192)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	data := buf[:n]
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Errorf("packet does not start with the XML header: %q", data)
+	}
+	var root struct{ XMLName xml.Name }
+	if err = xml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return root.XMLName.Local, data
+}
+
+// =============================================================================
+// Packet Tests
+// =============================================================================
+
+func TestN1mmContactPacket(t *testing.T) {
+	qso := testN1mmQso()
+
+	p := n1mmContactPacket(n1mmContactInfo, qso, "SHACK")
+	data, err := xml.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got n1mmContact
+	if err = xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if got.XMLName.Local != n1mmContactInfo {
+		t.Errorf("root = %q, want %q", got.XMLName.Local, n1mmContactInfo)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"contestname", got.ContestName, "CQ-WW-CW"},
+		{"timestamp", got.Timestamp, "2026-10-18 12:34:00"},
+		{"mycall", got.MyCall, "W1AW"},
+		{"band", got.Band, "14"},
+		{"rxfreq", got.RxFreq, int64(1402500)},
+		{"txfreq", got.TxFreq, int64(1402500)},
+		{"mode", got.Mode, "CW"},
+		{"call", got.Call, "DL1ABC"},
+		{"snt", got.Snt, "599"},
+		{"sntnr", got.SntNr, "001"},
+		{"rcv", got.Rcv, "579"},
+		{"rcvnr", got.RcvNr, "123"},
+		{"StationName", got.StationName, "SHACK"},
+		{"ID", len(got.ID), 32},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestN1mmContactPacket_Defaults(t *testing.T) {
+	qso := testN1mmQso()
+	qso.ContestId = ""
+	qso.Mode = "MFSK"
+	qso.Submode = "FT4"
+	qso.Band = "1.25m"
+	qso.Freq = "222100000"
+	qso.FreqRx = "222200000"
+	qso.TimeOn = "123456"
+
+	p := n1mmContactPacket(n1mmContactReplace, qso, "SHACK")
+	if p.XMLName.Local != n1mmContactReplace {
+		t.Errorf("root = %q, want %q", p.XMLName.Local, n1mmContactReplace)
+	}
+	if p.ContestName != n1mmDefaultContest {
+		t.Errorf("contestname = %q, want %q", p.ContestName, n1mmDefaultContest)
+	}
+	if p.Mode != "FT4" {
+		t.Errorf("mode = %q, want the submode", p.Mode)
+	}
+	if p.Band != "222.1" {
+		t.Errorf("band = %q, want the frequency in MHz for an unmapped band", p.Band)
+	}
+	if p.TxFreq != 22210000 || p.RxFreq != 22220000 {
+		t.Errorf("txfreq, rxfreq = %d, %d, want the split frequencies", p.TxFreq, p.RxFreq)
+	}
+	if p.Timestamp != "2026-10-18 12:34:56" {
+		t.Errorf("timestamp = %q", p.Timestamp)
+	}
+}
+
+func TestN1mmContactID(t *testing.T) {
+	a := testN1mmQso()
+	b := a
+	b.Call = "G4XYZ"
+	if n1mmContactID(a) != n1mmContactID(b) {
+		t.Error("the ID should not change when the QSO is edited")
+	}
+	b.ID++
+	if n1mmContactID(a) == n1mmContactID(b) {
+		t.Error("different QSOs should have different IDs")
+	}
+
+	del := n1mmDeletePacket(a, "SHACK", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC))
+	if del.ID != n1mmContactID(a) || del.Call != "DL1ABC" || del.Timestamp != "2026-10-18 12:00:00" {
+		t.Errorf("n1mmDeletePacket() = %+v", del)
+	}
+}
+
+func TestN1mmRadioPacket(t *testing.T) {
+	status := types.CatStatus{
+		tags.VfoAFreq.String(): "014195000",
+		tags.VfoBFreq.String(): "014200000",
+		tags.Split.String():    "ON",
+		tags.Select.String():   "VFO-A",
+		tags.MainMode.String(): "DATA-U",
+	}
+	p := n1mmRadioPacket(status, "FT-991A", "W1AW", "SHACK", true)
+	if p.TxFreq != 1419500 || p.Freq != 1420000 {
+		t.Errorf("Freq, TXFreq = %d, %d, want the receive and selected VFOs", p.Freq, p.TxFreq)
+	}
+	if p.Mode != "USB" || p.IsSplit != "True" || p.IsConnected != "True" {
+		t.Errorf("n1mmRadioPacket() = %+v", p)
+	}
+	if p.RadioName != "FT-991A" || p.OpCall != "W1AW" || p.StationName != "SHACK" {
+		t.Errorf("n1mmRadioPacket() = %+v", p)
+	}
+
+	status[tags.Split.String()] = "OFF"
+	status[tags.Select.String()] = "VFO-B"
+	p = n1mmRadioPacket(status, "", "", "SHACK", false)
+	if p.TxFreq != 1420000 || p.Freq != 1420000 || p.IsSplit != "False" || p.IsConnected != "False" {
+		t.Errorf("n1mmRadioPacket() = %+v", p)
+	}
+}
+
+func TestN1mmRadioMode(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"CW-U", "CW"},
+		{"CW-L", "CW"},
+		{"DATA-U", "USB"},
+		{"PKT-U", "USB"},
+		{"DATA-L", "LSB"},
+		{"RTTY-L", "RTTY"},
+		{"FM-N", "FM"},
+		{"USB", "USB"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := n1mmRadioMode(tt.label); got != tt.want {
+			t.Errorf("n1mmRadioMode(%q) = %q, want %q", tt.label, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Broadcaster Tests
+// =============================================================================
+
+func TestNewN1mmBroadcaster(t *testing.T) {
+	b, err := newN1mmBroadcaster(N1mmOptions{})
+	if err != nil || b != nil {
+		t.Errorf("newN1mmBroadcaster(disabled) = %v, %v, want nil", b, err)
+	}
+
+	if _, err = newN1mmBroadcaster(N1mmOptions{Enabled: true, ContactDestinations: []string{"no-port"}}); err == nil {
+		t.Error("newN1mmBroadcaster() should reject an invalid destination")
+	}
+
+	b, err = newN1mmBroadcaster(N1mmOptions{Enabled: true, RadioDestinations: []string{"127.0.0.1:12060"}})
+	if err != nil || b == nil {
+		t.Fatalf("newN1mmBroadcaster() = %v, %v", b, err)
+	}
+	defer func() { _ = b.conn.Close() }()
+	if b.station == "" {
+		t.Error("the station name should default to the host name")
+	}
+	if len(b.radio) != 1 || len(b.contacts) != 0 {
+		t.Errorf("destinations = %v, %v", b.contacts, b.radio)
+	}
+}
+
+func TestBroadcastN1mmContact(t *testing.T) {
+	l1, l2 := listenN1mm(t), listenN1mm(t)
+
+	s := createStartedTestService()
+	b, err := newN1mmBroadcaster(N1mmOptions{
+		Enabled:             true,
+		ContactDestinations: []string{l1.LocalAddr().String(), l2.LocalAddr().String()},
+		StationName:         "SHACK",
+	})
+	if err != nil {
+		t.Fatalf("newN1mmBroadcaster() error = %v", err)
+	}
+	defer func() { _ = b.conn.Close() }()
+	s.n1mm = b
+
+	qso := testN1mmQso()
+	for _, kind := range []string{n1mmContactInfo, n1mmContactReplace, n1mmContactDelete} {
+		s.broadcastN1mmContact(kind, qso)
+		for _, l := range []net.PacketConn{l1, l2} {
+			root, data := readN1mm(t, l)
+			if root != kind {
+				t.Errorf("received %q, want %q", root, kind)
+			}
+			if !strings.Contains(string(data), "<ID>"+n1mmContactID(qso)+"</ID>") {
+				t.Errorf("%s packet does not carry the QSO's ID: %s", kind, data)
+			}
+		}
+	}
+}
+
+func TestBroadcastN1mmContact_Disabled(t *testing.T) {
+	s := createStartedTestService()
+	// Must not panic without a broadcaster.
+	s.broadcastN1mmContact(n1mmContactInfo, testN1mmQso())
+}
+
+func TestN1mmRadioWorker(t *testing.T) {
+	l := listenN1mm(t)
+
+	s := createPrefillTestService()
+	s.altCat = &stubCatBackend{rig: types.RigConfig{Name: "FT-991A"}}
+	s.CurrentLogbook.Callsign = "W1AW"
+	b, err := newN1mmBroadcaster(N1mmOptions{
+		Enabled:           true,
+		RadioDestinations: []string{l.LocalAddr().String()},
+		RadioIntervalMs:   10,
+		StationName:       "SHACK",
+	})
+	if err != nil {
+		t.Fatalf("newN1mmBroadcaster() error = %v", err)
+	}
+	s.n1mm = b
+	s.catState.update(types.CatStatus{
+		tags.VfoAFreq.String(): "007030000",
+		tags.MainMode.String(): "CW-U",
+	}, time.Now())
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.n1mmRadioWorker(shutdown)
+		close(done)
+	}()
+
+	root, data := readN1mm(t, l)
+	if root != n1mmRadioInfo {
+		t.Fatalf("received %q, want %q", root, n1mmRadioInfo)
+	}
+	var got n1mmRadio
+	if err = xml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Freq != 703000 || got.Mode != "CW" || got.RadioName != "FT-991A" || got.OpCall != "W1AW" {
+		t.Errorf("RadioInfo = %+v", got)
+	}
+
+	close(shutdown)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("n1mmRadioWorker did not stop on shutdown")
+	}
+	if _, err = b.conn.WriteTo([]byte("x"), l.LocalAddr()); err == nil {
+		t.Error("the broadcaster's connection should be closed on shutdown")
+	}
+}
@@ -0,0 +1,246 @@
+package facade
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Station-Manager/errors"
+)
+
+// operatorCallsign is the accepted form of an operator's callsign, after upper-casing.
+var operatorCallsign = regexp.MustCompile(`^[A-Z0-9]+(/[A-Z0-9]+)*$`)
+
+// Operator is an operator of the station, as registered for multi-operator events.
+type Operator struct {
+	Callsign string `json:"callsign"`
+	Name     string `json:"name"`
+}
+
+// FetchOperators returns the registered operators, by callsign.
+func (s *Service) FetchOperators() ([]Operator, error) {
+	const op errors.Op = "facade.Service.FetchOperators"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	operators, err := s.queryOperators(`SELECT callsign, name FROM operator ORDER BY callsign`)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch operators")
+		return nil, errors.Root(err)
+	}
+
+	return operators, nil
+}
+
+// SaveOperator registers an operator, or updates the name of one already registered.
+func (s *Service) SaveOperator(operator Operator) error {
+	const op errors.Op = "facade.Service.SaveOperator"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	operator.Callsign = normalizeOperatorCallsign(operator.Callsign)
+	operator.Name = strings.TrimSpace(operator.Name)
+	if !operatorCallsign.MatchString(operator.Callsign) || len(operator.Callsign) > 20 {
+		return errors.New(op).Msgf("Invalid operator callsign: %q", operator.Callsign)
+	}
+	if len(operator.Name) > 64 {
+		return errors.New(op).Msg("Operator name is too long")
+	}
+
+	const stmt = `INSERT INTO operator (callsign, name)
+VALUES (?, ?)
+ON CONFLICT (callsign) DO UPDATE SET name        = excluded.name,
+                                     modified_at = datetime('now', 'localtime')`
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, operator.Callsign, operator.Name); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to save operator")
+		return errors.Root(err)
+	}
+
+	// Keep the current operator's name in step.
+	s.operatorMu.Lock()
+	if s.operator.Callsign == operator.Callsign {
+		s.operator = operator
+	}
+	s.operatorMu.Unlock()
+
+	return nil
+}
+
+// RemoveOperator removes an operator from the registry. The QSOs they logged keep their callsign. The current
+// operator cannot be removed; switch to another operator first.
+func (s *Service) RemoveOperator(callsign string) error {
+	const op errors.Op = "facade.Service.RemoveOperator"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	callsign = normalizeOperatorCallsign(callsign)
+
+	s.operatorMu.Lock()
+	defer s.operatorMu.Unlock()
+
+	if callsign == s.operator.Callsign {
+		return errors.New(op).Msgf("%s is the current operator", callsign)
+	}
+
+	res, err := s.DatabaseService.ExecContext(s.dbContext(), `DELETE FROM operator WHERE callsign = ?`, callsign)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to remove operator")
+		return errors.Root(err)
+	}
+	if n, rerr := res.RowsAffected(); rerr == nil && n == 0 {
+		return errors.New(op).Err(errors.ErrNotFound).Msgf("Operator %s not found", callsign)
+	}
+
+	return nil
+}
+
+// FetchCurrentOperator returns the operator at the station. The callsign is empty while no operator has been
+// switched to, in which case QSOs are logged with the operator of the logging station configuration.
+func (s *Service) FetchCurrentOperator() (Operator, error) {
+	const op errors.Op = "facade.Service.FetchCurrentOperator"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return Operator{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return Operator{}, errors.Root(err)
+	}
+
+	return s.currentOperator(), nil
+}
+
+// SwitchOperator hands the station over to a registered operator, or back to the logging station configuration's
+// operator for an empty callsign. The current session is ended and a new one started, so that each session is
+// operated by one operator. The new operator is emitted with EventOperatorChanged.
+func (s *Service) SwitchOperator(callsign string) error {
+	const op errors.Op = "facade.Service.SwitchOperator"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	callsign = normalizeOperatorCallsign(callsign)
+
+	var next Operator
+	if callsign != "" {
+		operators, err := s.queryOperators(`SELECT callsign, name FROM operator WHERE callsign = ?`, callsign)
+		if err != nil {
+			err = errors.New(op).Err(err)
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch operator")
+			return errors.Root(err)
+		}
+		if len(operators) == 0 {
+			return errors.New(op).Err(errors.ErrNotFound).Msgf("Operator %s is not registered", callsign)
+		}
+		next = operators[0]
+	}
+
+	s.operatorMu.Lock()
+	defer s.operatorMu.Unlock()
+
+	if next.Callsign == s.operator.Callsign {
+		return nil
+	}
+
+	sessionID, err := s.DatabaseService.GenerateSession()
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to generate new session ID.")
+		return errors.Root(err)
+	}
+	if err = s.DatabaseService.SoftDeleteSessionByID(s.sessionID); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to soft-delete session ID")
+	}
+	s.sessionID = sessionID
+	s.operator = next
+	s.rate.reset()
+	// The session summaries mark the current session.
+	s.invalidateStats(s.CurrentLogbook.ID)
+
+	s.LoggerService.InfoWith().Str("operator", next.Callsign).Int64("session_id", sessionID).Msg("Operator switched")
+	s.emitEvent(EventOperatorChanged.String(), next)
+
+	return nil
+}
+
+// currentOperator returns the operator switched to, if any.
+func (s *Service) currentOperator() Operator {
+	s.operatorMu.Lock()
+	defer s.operatorMu.Unlock()
+	return s.operator
+}
+
+// currentOperatorSession returns the operator switched to, if any, along with the session they are logging in.
+func (s *Service) currentOperatorSession() (Operator, int64) {
+	s.operatorMu.Lock()
+	defer s.operatorMu.Unlock()
+	return s.operator, s.sessionID
+}
+
+func (s *Service) queryOperators(query string, args ...any) ([]Operator, error) {
+	const op errors.Op = "facade.Service.queryOperators"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, args...)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	operators := make([]Operator, 0)
+	for rows.Next() {
+		var o Operator
+		if err = rows.Scan(&o.Callsign, &o.Name); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		operators = append(operators, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return operators, nil
+}
+
+func normalizeOperatorCallsign(callsign string) string {
+	return strings.ToUpper(strings.TrimSpace(callsign))
+}
@@ -0,0 +1,182 @@
+package facade
+
+import (
+	"testing"
+)
+
+// =============================================================================
+// Registry Tests
+// =============================================================================
+
+func TestSaveOperator(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	tests := []struct {
+		name     string
+		operator Operator
+		wantErr  bool
+	}{
+		{name: "valid", operator: Operator{Callsign: " g4xy ", Name: "Alice"}},
+		{name: "portable", operator: Operator{Callsign: "DL/G3ABC/P"}},
+		{name: "renamed", operator: Operator{Callsign: "G4XY", Name: "Alice B"}},
+		{name: "empty", operator: Operator{}, wantErr: true},
+		{name: "spaces", operator: Operator{Callsign: "G4 XY"}, wantErr: true},
+		{name: "too long", operator: Operator{Callsign: "G4XYG4XYG4XYG4XYG4XYG4XY"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.SaveOperator(tt.operator)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SaveOperator(%+v) error = %v, wantErr %v", tt.operator, err, tt.wantErr)
+			}
+		})
+	}
+
+	operators, err := s.FetchOperators()
+	if err != nil {
+		t.Fatalf("FetchOperators() error = %v", err)
+	}
+	want := []Operator{{Callsign: "DL/G3ABC/P"}, {Callsign: "G4XY", Name: "Alice B"}}
+	if len(operators) != len(want) {
+		t.Fatalf("FetchOperators() = %+v, want %+v", operators, want)
+	}
+	for i := range want {
+		if operators[i] != want[i] {
+			t.Errorf("FetchOperators()[%d] = %+v, want %+v", i, operators[i], want[i])
+		}
+	}
+}
+
+func TestRemoveOperator(t *testing.T) {
+	s := createDatabaseTestService(t)
+	if err := s.SaveOperator(Operator{Callsign: "G4XY"}); err != nil {
+		t.Fatalf("SaveOperator() error = %v", err)
+	}
+	if err := s.SwitchOperator("G4XY"); err != nil {
+		t.Fatalf("SwitchOperator() error = %v", err)
+	}
+
+	if err := s.RemoveOperator("g4xy"); err == nil {
+		t.Error("RemoveOperator() of the current operator error = nil, want an error")
+	}
+	if err := s.SwitchOperator(""); err != nil {
+		t.Fatalf("SwitchOperator(\"\") error = %v", err)
+	}
+	if err := s.RemoveOperator("g4xy"); err != nil {
+		t.Errorf("RemoveOperator() error = %v", err)
+	}
+	if err := s.RemoveOperator("G4XY"); err == nil {
+		t.Error("RemoveOperator() of an unknown operator error = nil, want an error")
+	}
+}
+
+// =============================================================================
+// Switch Tests
+// =============================================================================
+
+func TestSwitchOperator(t *testing.T) {
+	s := createAdifTestService(t)
+	for _, call := range []string{"G4XY", "M0ABC"} {
+		if err := s.SaveOperator(Operator{Callsign: call}); err != nil {
+			t.Fatalf("SaveOperator() error = %v", err)
+		}
+	}
+
+	if err := s.SwitchOperator("K1ZZZ"); err == nil {
+		t.Error("SwitchOperator() of an unregistered operator error = nil, want an error")
+	}
+
+	firstSession := s.sessionID
+	if err := s.SwitchOperator("g4xy"); err != nil {
+		t.Fatalf("SwitchOperator() error = %v", err)
+	}
+	if s.sessionID == firstSession {
+		t.Error("SwitchOperator() did not start a new session")
+	}
+	current, err := s.FetchCurrentOperator()
+	if err != nil || current.Callsign != "G4XY" {
+		t.Errorf("FetchCurrentOperator() = %+v, %v, want G4XY", current, err)
+	}
+
+	// Switching to the current operator keeps the session.
+	session := s.sessionID
+	if err = s.SwitchOperator("G4XY"); err != nil {
+		t.Fatalf("SwitchOperator() error = %v", err)
+	}
+	if s.sessionID != session {
+		t.Error("SwitchOperator() to the current operator started a new session")
+	}
+
+	// QSOs without an operator are stamped with the current one; an explicit operator is kept.
+	if _, err = s.logQso(newTestQso(s, "DL1AA", "20m", "SSB")); err != nil {
+		t.Fatalf("logQso() error = %v", err)
+	}
+	explicit := newTestQso(s, "F5BB", "20m", "SSB")
+	explicit.Operator = "M0ABC"
+	if _, err = s.logQso(explicit); err != nil {
+		t.Fatalf("logQso() error = %v", err)
+	}
+
+	if err = s.SwitchOperator(""); err != nil {
+		t.Fatalf("SwitchOperator(\"\") error = %v", err)
+	}
+	// With no operator switched to, the station callsign is the operator.
+	if _, err = s.logQso(newTestQso(s, "EA3CC", "40m", "CW")); err != nil {
+		t.Fatalf("logQso() error = %v", err)
+	}
+
+	stats, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	want := map[string]int64{"G4XY": 1, "M0ABC": 1, "W1AW": 1}
+	if len(stats.ByOperator) != len(want) {
+		t.Fatalf("ByOperator = %+v, want %v", stats.ByOperator, want)
+	}
+	for _, o := range stats.ByOperator {
+		if want[o.Operator] != o.Qsos || o.Sessions != 1 {
+			t.Errorf("ByOperator[%s] = %+v, want %d QSO(s) in 1 session", o.Operator, o, want[o.Operator])
+		}
+	}
+	if len(stats.Sessions) != 2 || stats.Sessions[0].Operator != "W1AW" || stats.Sessions[1].Operator != "G4XY" {
+		t.Errorf("Sessions = %+v, want a W1AW session after a G4XY one", stats.Sessions)
+	}
+}
+
+func TestInitLoggingStationSection_Operator(t *testing.T) {
+	s := createDatabaseTestService(t)
+	if err := s.SaveOperator(Operator{Callsign: "G4XY"}); err != nil {
+		t.Fatalf("SaveOperator() error = %v", err)
+	}
+	if err := s.SwitchOperator("G4XY"); err != nil {
+		t.Fatalf("SwitchOperator() error = %v", err)
+	}
+
+	station, err := s.initLoggingStationSection()
+	if err != nil {
+		t.Fatalf("initLoggingStationSection() error = %v", err)
+	}
+	if station.Operator != "G4XY" {
+		t.Errorf("Operator = %q, want G4XY", station.Operator)
+	}
+}
+
+func TestOperators_NotStarted(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchOperators(); err == nil {
+		t.Error("FetchOperators() error = nil, want an error")
+	}
+	if err := s.SaveOperator(Operator{Callsign: "G4XY"}); err == nil {
+		t.Error("SaveOperator() error = nil, want an error")
+	}
+	if err := s.RemoveOperator("G4XY"); err == nil {
+		t.Error("RemoveOperator() error = nil, want an error")
+	}
+	if _, err := s.FetchCurrentOperator(); err == nil {
+		t.Error("FetchCurrentOperator() error = nil, want an error")
+	}
+	if err := s.SwitchOperator("G4XY"); err == nil {
+		t.Error("SwitchOperator() error = nil, want an error")
+	}
+}
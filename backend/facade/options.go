@@ -0,0 +1,320 @@
+package facade
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/utils"
+)
+
+// appOptionsFileName is the file, in the working directory, holding the settings for the features of the logging
+// app that the shared config module has no section for.
+const appOptionsFileName = "app_options.json"
+
+// AppOptions holds the logging app's own settings. A default file is generated on first start, and any section
+// missing from an existing file takes its defaults.
+type AppOptions struct {
+	DxCluster DxClusterOptions `json:"dx_cluster"`
+	// CatBackends selects the CAT backend per rig; rigs without an entry use the serial CAT service.
+	CatBackends []CatBackendOptions `json:"cat_backends"`
+	CatPrefill  CatPrefillOptions   `json:"cat_prefill"`
+	// CatStatusEvents configures how CAT status updates are emitted to the frontend.
+	CatStatusEvents CatStatusEventOptions `json:"cat_status_events"`
+	CatHealth       CatHealthOptions      `json:"cat_health"`
+	RigControl      RigControlOptions     `json:"rig_control"`
+	CwKeyer         CwKeyerOptions        `json:"cw_keyer"`
+	N1mm            N1mmOptions           `json:"n1mm"`
+	AdifListener    AdifListenerOptions   `json:"adif_listener"`
+	Wsjtx           WsjtxOptions          `json:"wsjtx"`
+	RestApi         RestApiOptions        `json:"rest_api"`
+	Backup          BackupOptions         `json:"backup"`
+}
+
+// DxClusterOptions configures the DX cluster client.
+type DxClusterOptions struct {
+	Enabled  bool   `json:"enabled"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Callsign string `json:"callsign"` // defaults to the logbook callsign
+	Password string `json:"password"`
+	// Commands are sent once logged in, e.g. to set the node's spot filter.
+	Commands            []string `json:"commands"`
+	ReconnectSeconds    int      `json:"reconnect_seconds"`
+	MaxReconnectSeconds int      `json:"max_reconnect_seconds"`
+	IdleTimeoutSeconds  int      `json:"idle_timeout_seconds"`
+	// MaxSpots is the number of recent spots kept for FetchDxSpots.
+	MaxSpots int `json:"max_spots"`
+}
+
+// CatBackendOptions selects the CAT backend for a rig in the config, identified by its ID.
+type CatBackendOptions struct {
+	RigID   int64  `json:"rig_id"`
+	Backend string `json:"backend"` // "serial" or "rigctld"
+	// Host and Port locate the rigctld daemon; the port defaults to 4532.
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	PollIntervalMs int    `json:"poll_interval_ms"`
+	TimeoutMs      int    `json:"timeout_ms"`
+}
+
+// CatPrefillOptions configures pre-filling new QSOs from the live rig state.
+type CatPrefillOptions struct {
+	Enabled bool `json:"enabled"`
+	// MaxAgeSeconds is how old the last reported rig state may be before it is refreshed.
+	MaxAgeSeconds int `json:"max_age_seconds"`
+	// RefreshWaitMs is how long to wait for the rig to answer a refresh. If it does not, the rig is taken to be
+	// disconnected and the QSO is not pre-filled.
+	RefreshWaitMs int `json:"refresh_wait_ms"`
+}
+
+// CatStatusEventOptions configures the emission of CAT status updates to the frontend.
+type CatStatusEventOptions struct {
+	// MinIntervalMs is the minimum time between two status events; updates in between are merged. Zero emits every
+	// update as soon as the frontend can take it.
+	MinIntervalMs int `json:"min_interval_ms"`
+}
+
+// CatHealthOptions configures the CAT health supervisor, which probes a silent rig and restarts the CAT backend if it
+// does not answer.
+type CatHealthOptions struct {
+	Enabled bool `json:"enabled"`
+	// HeartbeatMs is how long the rig may be silent before it is probed with a read command.
+	HeartbeatMs int `json:"heartbeat_ms"`
+	// ProbeTimeoutMs is how long to wait for the rig to answer a probe.
+	ProbeTimeoutMs int `json:"probe_timeout_ms"`
+	// ReconnectMinMs and ReconnectMaxMs bound the delay before each restart of the CAT backend, which doubles per
+	// attempt.
+	ReconnectMinMs int `json:"reconnect_min_ms"`
+	ReconnectMaxMs int `json:"reconnect_max_ms"`
+}
+
+// RigControlOptions configures the control of the rig from the app.
+type RigControlOptions struct {
+	// PttTimeoutSeconds is how long a transmitter keyed with SetRigPtt may stay keyed before the app unkeys it, in
+	// case the frontend never does. Zero never unkeys it.
+	PttTimeoutSeconds int `json:"ptt_timeout_seconds"`
+	// PttConfirmWaitMs is how long to wait for the rig to report the new PTT state after keying or unkeying it.
+	PttConfirmWaitMs int `json:"ptt_confirm_wait_ms"`
+}
+
+// CwKeyerOptions configures the CW keyer and its macros.
+type CwKeyerOptions struct {
+	// Keyer selects the keyer: "cat", the rig's internal keyer (the default), or "winkeyer".
+	Keyer  string    `json:"keyer"`
+	Macros []CwMacro `json:"macros"`
+	// ChunkLength is the most characters the rig's keyer takes in one command; longer text is split. Zero sends the
+	// text in one command.
+	ChunkLength int             `json:"chunk_length"`
+	WinKeyer    WinKeyerOptions `json:"winkeyer"`
+}
+
+// WinKeyerOptions configures a K1EL WinKeyer.
+type WinKeyerOptions struct {
+	Port   string `json:"port"`
+	Speed  int    `json:"speed"`  // WPM
+	Weight int    `json:"weight"` // 50 is standard
+}
+
+// N1mmOptions configures the N1MM+ compatible UDP broadcasts of logged QSOs and the rig state.
+type N1mmOptions struct {
+	Enabled bool `json:"enabled"`
+	// ContactDestinations and RadioDestinations are the host:port addresses the contact and RadioInfo packets are
+	// sent to, e.g. "127.0.0.1:12060"; broadcast addresses may be used.
+	ContactDestinations []string `json:"contact_destinations"`
+	RadioDestinations   []string `json:"radio_destinations"`
+	// RadioIntervalMs is the time between RadioInfo packets. Zero sends none.
+	RadioIntervalMs int `json:"radio_interval_ms"`
+	// StationName identifies this computer in the packets; it defaults to the host name.
+	StationName string `json:"station_name"`
+}
+
+// AdifListenerOptions configures the listeners that receive logged QSOs as raw ADIF records from other programs.
+type AdifListenerOptions struct {
+	Enabled   bool                   `json:"enabled"`
+	Endpoints []AdifListenerEndpoint `json:"endpoints"`
+}
+
+// AdifListenerEndpoint is an address to receive ADIF records on.
+type AdifListenerEndpoint struct {
+	Protocol string `json:"protocol"` // "udp" or "tcp"
+	Address  string `json:"address"`  // host:port, e.g. "127.0.0.1:2333"
+}
+
+// WsjtxOptions configures the WSJT-X integration, which follows the decodes of WSJT-X (or JTDX) instances, colours
+// their callsigns by worked/needed status and answers decodes chosen in the logger. The decodes are those received
+// by the listeners of the shared config with the "wsjtx" handler.
+type WsjtxOptions struct {
+	Enabled   bool                  `json:"enabled"`
+	Highlight WsjtxHighlightOptions `json:"highlight"`
+	Activity  WsjtxActivityOptions  `json:"activity"`
+}
+
+// WsjtxHighlightOptions sets the colours callsigns are highlighted with in WSJT-X, from the most wanted: a new DXCC
+// entity, a new band or mode slot of a worked entity, and a station already worked on the band.
+type WsjtxHighlightOptions struct {
+	Enabled    bool              `json:"enabled"`
+	NeededDxcc WsjtxColorOptions `json:"needed_dxcc"`
+	NeededSlot WsjtxColorOptions `json:"needed_slot"`
+	Worked     WsjtxColorOptions `json:"worked"`
+}
+
+// WsjtxActivityOptions configures the band activity collected from the decodes.
+type WsjtxActivityOptions struct {
+	// MaxAgeSeconds is how long a decode is kept; zero keeps decodes until pushed out by newer ones.
+	MaxAgeSeconds     int `json:"max_age_seconds"`
+	MaxDecodesPerBand int `json:"max_decodes_per_band"`
+	// EventIntervalMs is the minimum time between two activity events; the bands with new decodes in between are
+	// emitted together.
+	EventIntervalMs int `json:"event_interval_ms"`
+}
+
+// WsjtxColorOptions are the colours of a highlight, as "#rrggbb". An empty colour keeps the WSJT-X default.
+type WsjtxColorOptions struct {
+	Background string `json:"background"`
+	Foreground string `json:"foreground"`
+}
+
+// RestApiOptions configures the local HTTP API for scripts and other station software.
+type RestApiOptions struct {
+	Enabled bool `json:"enabled"`
+	// Address is the host:port to listen on. Keep it on the loopback interface unless other computers must reach
+	// the API.
+	Address string `json:"address"`
+	// Token must be sent by every request as "Authorization: Bearer <token>". The API does not start without one.
+	Token string `json:"token"`
+}
+
+// BackupOptions configures the backups of the database. A backup can also be taken and restored on demand.
+type BackupOptions struct {
+	// Enabled takes a backup every IntervalHours while the logger runs.
+	Enabled       bool `json:"enabled"`
+	IntervalHours int  `json:"interval_hours"`
+	// OnStop takes a backup when the logger is closed.
+	OnStop bool `json:"on_stop"`
+	// Dir is the directory backups are written to; it defaults to the backups directory in the working directory.
+	Dir string `json:"dir"`
+	// Keep is the number of backups kept; older ones are removed. Zero keeps every backup.
+	Keep int `json:"keep"`
+}
+
+// defaultAppOptions returns the options used for a missing file or section.
+func defaultAppOptions() AppOptions {
+	return AppOptions{
+		DxCluster: DxClusterOptions{
+			Port:                7300,
+			Commands:            []string{},
+			ReconnectSeconds:    5,
+			MaxReconnectSeconds: 300,
+			IdleTimeoutSeconds:  600,
+			MaxSpots:            200,
+		},
+		CatBackends: []CatBackendOptions{},
+		CatPrefill: CatPrefillOptions{
+			Enabled:       true,
+			MaxAgeSeconds: 10,
+			RefreshWaitMs: 300,
+		},
+		CatStatusEvents: CatStatusEventOptions{
+			MinIntervalMs: 50,
+		},
+		CatHealth: CatHealthOptions{
+			Enabled:        true,
+			HeartbeatMs:    10000,
+			ProbeTimeoutMs: 2000,
+			ReconnectMinMs: 1000,
+			ReconnectMaxMs: 60000,
+		},
+		RigControl: RigControlOptions{
+			PttTimeoutSeconds: 180,
+			PttConfirmWaitMs:  1000,
+		},
+		CwKeyer: CwKeyerOptions{
+			Keyer: cwKeyerCat,
+			Macros: []CwMacro{
+				{Key: "F1", Label: "CQ", Text: "CQ TEST {MYCALL} {MYCALL} TEST"},
+				{Key: "F2", Label: "Exch", Text: "{RST} {STX}"},
+				{Key: "F3", Label: "TU", Text: "TU {MYCALL}"},
+				{Key: "F4", Label: "My call", Text: "{MYCALL}"},
+				{Key: "F5", Label: "His call", Text: "{CALL}"},
+				{Key: "F6", Label: "Repeat", Text: "{RST} {STX} {STX}"},
+				{Key: "F7", Label: "?", Text: "?"},
+				{Key: "F8", Label: "AGN", Text: "AGN"},
+				{Key: "F9", Label: "Name", Text: "{CALL} TNX {NAME} UR {RST} {RST}"},
+				{Key: "F10", Label: "QRZ", Text: "QRZ {MYCALL}"},
+				{Key: "F11", Label: "Call?", Text: "CL?"},
+				{Key: "F12", Label: "73", Text: "TU 73 {MYCALL}"},
+			},
+			ChunkLength: 24,
+			WinKeyer: WinKeyerOptions{
+				Speed:  25,
+				Weight: 50,
+			},
+		},
+		N1mm: N1mmOptions{
+			ContactDestinations: []string{"127.0.0.1:12060"},
+			RadioDestinations:   []string{"127.0.0.1:12060"},
+			RadioIntervalMs:     1000,
+		},
+		AdifListener: AdifListenerOptions{
+			Endpoints: []AdifListenerEndpoint{{Protocol: "udp", Address: "127.0.0.1:2333"}},
+		},
+		Wsjtx: WsjtxOptions{
+			Highlight: WsjtxHighlightOptions{
+				Enabled:    true,
+				NeededDxcc: WsjtxColorOptions{Background: "#ff0000", Foreground: "#ffffff"},
+				NeededSlot: WsjtxColorOptions{Background: "#ffa500", Foreground: "#000000"},
+				Worked:     WsjtxColorOptions{Foreground: "#808080"},
+			},
+			Activity: WsjtxActivityOptions{
+				MaxAgeSeconds:     600,
+				MaxDecodesPerBand: 300,
+				EventIntervalMs:   1000,
+			},
+		},
+		RestApi: RestApiOptions{
+			Address: "127.0.0.1:8073",
+		},
+		Backup: BackupOptions{
+			Enabled:       true,
+			IntervalHours: 24,
+			OnStop:        true,
+			Keep:          14,
+		},
+	}
+}
+
+// loadAppOptions reads the app options file, generating it with the defaults if it does not exist.
+func (s *Service) loadAppOptions() (AppOptions, error) {
+	const op errors.Op = "facade.Service.loadAppOptions"
+
+	options := defaultAppOptions()
+	path := filepath.Join(s.ConfigService.WorkingDir, appOptionsFileName)
+
+	exists, err := utils.PathExists(path)
+	if err != nil {
+		return options, errors.New(op).Err(err)
+	}
+	if !exists {
+		data, merr := json.MarshalIndent(options, "", "  ")
+		if merr != nil {
+			return options, errors.New(op).Err(merr)
+		}
+		// Same permissions as config.json: the file may hold passwords.
+		if err = os.WriteFile(path, data, 0o640); err != nil {
+			return options, errors.New(op).Err(err)
+		}
+		return options, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return options, errors.New(op).Err(err)
+	}
+	if err = json.Unmarshal(data, &options); err != nil {
+		return defaultAppOptions(), errors.New(op).Err(err)
+	}
+
+	return options, nil
+}
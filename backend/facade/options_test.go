@@ -0,0 +1,82 @@
+package facade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Station-Manager/config"
+)
+
+func TestLoadAppOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string // empty: no file
+		wantErr  bool
+		check    func(t *testing.T, o AppOptions)
+	}{
+		{
+			name: "missing file generates defaults",
+			check: func(t *testing.T, o AppOptions) {
+				if o.DxCluster.Enabled || o.DxCluster.Port != 7300 || o.DxCluster.MaxSpots != 200 {
+					t.Errorf("DxCluster = %+v, want defaults", o.DxCluster)
+				}
+			},
+		},
+		{
+			name:     "partial section keeps the other defaults",
+			contents: `{"dx_cluster": {"enabled": true, "host": "dx.example.net"}}`,
+			check: func(t *testing.T, o AppOptions) {
+				if !o.DxCluster.Enabled || o.DxCluster.Host != "dx.example.net" || o.DxCluster.Port != 7300 {
+					t.Errorf("DxCluster = %+v", o.DxCluster)
+				}
+			},
+		},
+		{
+			name:     "cat backends",
+			contents: `{"cat_backends": [{"rig_id": 2, "backend": "rigctld", "host": "localhost"}]}`,
+			check: func(t *testing.T, o AppOptions) {
+				if len(o.CatBackends) != 1 || o.CatBackends[0].RigID != 2 || o.CatBackends[0].Backend != "rigctld" {
+					t.Errorf("CatBackends = %+v", o.CatBackends)
+				}
+				if o.DxCluster.Port != 7300 {
+					t.Errorf("DxCluster = %+v, want defaults", o.DxCluster)
+				}
+			},
+		},
+		{
+			name:     "invalid json",
+			contents: `{"dx_cluster": `,
+			wantErr:  true,
+			check: func(t *testing.T, o AppOptions) {
+				if o.DxCluster.Enabled || o.DxCluster.Port != 7300 {
+					t.Errorf("DxCluster = %+v, want defaults", o.DxCluster)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s := createStartedTestService()
+			s.ConfigService = &config.Service{WorkingDir: dir}
+
+			path := filepath.Join(dir, appOptionsFileName)
+			if tt.contents != "" {
+				if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			o, err := s.loadAppOptions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadAppOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			tt.check(t, o)
+
+			if _, err = os.Stat(path); err != nil {
+				t.Errorf("options file should exist: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,640 @@
+package facade
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+	"github.com/Station-Manager/utils"
+)
+
+// QSL status values, as defined by the ADIF QSL_SENT and QSL_RCVD enumerations.
+const (
+	qslStatusYes       = "Y"
+	qslStatusNo        = "N"
+	qslStatusRequested = "R"
+	qslStatusQueued    = "Q" // QSL_SENT only
+	qslStatusIgnore    = "I"
+	qslStatusVerified  = "V" // QSL_RCVD only
+)
+
+// QSL route values, as defined by the ADIF QSL_SENT_VIA and QSL_RCVD_VIA enumerations.
+const (
+	qslViaBureau     = "B"
+	qslViaDirect     = "D"
+	qslViaElectronic = "E"
+	qslViaManager    = "M"
+)
+
+// Supported QSL label export formats.
+const (
+	QslLabelFormatCSV = "csv"
+	QslLabelFormatPDF = "pdf"
+)
+
+const qslExportDir = "exports"
+
+var (
+	qslSentStatuses = map[string]bool{qslStatusYes: true, qslStatusNo: true, qslStatusRequested: true, qslStatusQueued: true, qslStatusIgnore: true}
+	qslRcvdStatuses = map[string]bool{qslStatusYes: true, qslStatusNo: true, qslStatusRequested: true, qslStatusIgnore: true, qslStatusVerified: true}
+	qslRoutes       = map[string]bool{qslViaBureau: true, qslViaDirect: true, qslViaElectronic: true, qslViaManager: true}
+)
+
+// QslCard holds the paper QSL state of a single QSO, along with the QSO fields needed to address and fill in a card.
+// The JSON field names follow the ADIF field names where one exists.
+type QslCard struct {
+	QsoID      int64  `json:"qso_id"`
+	Call       string `json:"call"`
+	QsoDate    string `json:"qso_date"`
+	TimeOn     string `json:"time_on"`
+	Band       string `json:"band"`
+	Mode       string `json:"mode"`
+	RstSent    string `json:"rst_sent"`
+	SentStatus string `json:"qsl_sent"`
+	SentVia    string `json:"qsl_sent_via"`
+	SentDate   string `json:"qslsdate"`
+	RcvdStatus string `json:"qsl_rcvd"`
+	RcvdVia    string `json:"qsl_rcvd_via"`
+	RcvdDate   string `json:"qslrdate"`
+	Manager    string `json:"qsl_via"`
+	Printed    bool   `json:"printed"`
+}
+
+// qslCardSelect is the base query for fetching QSL cards. QSOs without a qsl_card row are reported with the
+// default (not sent, not received) state.
+const qslCardSelect = `SELECT q.id, q.call, q.qso_date, q.time_on, q.band, q.mode, q.rst_sent,
+       COALESCE(c.sent_status, 'N'), COALESCE(c.sent_via, ''), COALESCE(c.sent_date, ''),
+       COALESCE(c.rcvd_status, 'N'), COALESCE(c.rcvd_via, ''), COALESCE(c.rcvd_date, ''),
+       COALESCE(c.manager, ''), c.printed_at IS NOT NULL
+FROM qso q
+         LEFT JOIN qsl_card c ON c.qso_id = q.id
+WHERE q.deleted_at IS NULL`
+
+// FetchQslCard returns the QSL card state for the given QSO.
+func (s *Service) FetchQslCard(qsoId int64) (QslCard, error) {
+	const op errors.Op = "facade.Service.FetchQslCard"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return QslCard{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return QslCard{}, errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return QslCard{}, errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	cards, err := s.fetchQslCardsByQsoIds([]int64{qsoId})
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSL card")
+		return QslCard{}, errors.Root(err)
+	}
+	if len(cards) == 0 {
+		return QslCard{}, errors.New(op).Err(errors.ErrNotFound).Msg("QSO not found")
+	}
+
+	return cards[0], nil
+}
+
+// UpdateQslCard sets the sent/received state, routes and manager of a QSO's QSL card.
+func (s *Service) UpdateQslCard(card QslCard) error {
+	const op errors.Op = "facade.Service.UpdateQslCard"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if card.QsoID < 1 {
+		return errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	card = normalizeQslCard(card)
+	if err := validateQslCard(card); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Invalid QSL card")
+		return errors.Root(err)
+	}
+
+	if err := s.upsertQslCard(card); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to update QSL card")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// QueueQslCards adds the given QSOs to the "to be sent" queue, to be sent via the given route. The manager callsign is
+// optional unless the route is via a QSL manager. QSOs whose card has already been sent are left untouched.
+func (s *Service) QueueQslCards(qsoIds []int64, via, manager string) error {
+	const op errors.Op = "facade.Service.QueueQslCards"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if len(qsoIds) == 0 {
+		return errors.New(op).Msg("No QSOs to queue")
+	}
+
+	via = strings.ToUpper(strings.TrimSpace(via))
+	manager = strings.ToUpper(strings.TrimSpace(manager))
+	if !qslRoutes[via] {
+		return errors.New(op).Msgf("Invalid QSL route: %q", via)
+	}
+	if via == qslViaManager && manager == "" {
+		return errors.New(op).Msg("A manager callsign is required when sending via a QSL manager")
+	}
+
+	const stmt = `INSERT INTO qsl_card (qso_id, sent_status, sent_via, manager)
+VALUES (?, 'Q', ?, ?)
+ON CONFLICT (qso_id) DO UPDATE SET sent_status = CASE WHEN sent_status = 'Y' THEN sent_status ELSE 'Q' END,
+                                   sent_via    = CASE WHEN sent_status = 'Y' THEN sent_via ELSE excluded.sent_via END,
+                                   manager     = CASE WHEN excluded.manager = '' THEN manager ELSE excluded.manager END,
+                                   modified_at = datetime('now', 'localtime')`
+
+	if err := s.execForEachQso(stmt, qsoIds, func(id int64) []any { return []any{id, via, manager} }); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to queue QSL cards")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// FetchQslQueue returns the QSL cards in the current logbook that have been queued or requested, but not yet
+// sent. The queue is ordered by recipient so that cards for the same station are printed together. An empty
+// route returns the queue for all routes.
+func (s *Service) FetchQslQueue(via string) ([]QslCard, error) {
+	const op errors.Op = "facade.Service.FetchQslQueue"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	via = strings.ToUpper(strings.TrimSpace(via))
+	if via != "" && !qslRoutes[via] {
+		return nil, errors.New(op).Msgf("Invalid QSL route: %q", via)
+	}
+
+	cards, err := s.fetchQslQueue(via)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSL queue")
+		return nil, errors.Root(err)
+	}
+
+	return cards, nil
+}
+
+// MarkQslCardsPrinted records that the cards (or labels) for the given QSOs have been printed.
+func (s *Service) MarkQslCardsPrinted(qsoIds []int64) error {
+	const op errors.Op = "facade.Service.MarkQslCardsPrinted"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if len(qsoIds) == 0 {
+		return errors.New(op).Msg("No QSOs to mark as printed")
+	}
+
+	const stmt = `INSERT INTO qsl_card (qso_id, printed_at)
+VALUES (?, datetime('now', 'localtime'))
+ON CONFLICT (qso_id) DO UPDATE SET printed_at  = excluded.printed_at,
+                                   modified_at = datetime('now', 'localtime')`
+
+	if err := s.execForEachQso(stmt, qsoIds, func(id int64) []any { return []any{id} }); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to mark QSL cards as printed")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// MarkQslCardsSent marks the cards for the given QSOs as sent today, which removes them from the queue.
+func (s *Service) MarkQslCardsSent(qsoIds []int64) error {
+	const op errors.Op = "facade.Service.MarkQslCardsSent"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if len(qsoIds) == 0 {
+		return errors.New(op).Msg("No QSOs to mark as sent")
+	}
+
+	const stmt = `INSERT INTO qsl_card (qso_id, sent_status, sent_date)
+VALUES (?, 'Y', ?)
+ON CONFLICT (qso_id) DO UPDATE SET sent_status = 'Y',
+                                   sent_date   = excluded.sent_date,
+                                   modified_at = datetime('now', 'localtime')`
+
+	today := utils.DateNowAsYYYYMMDD()
+	if err := s.execForEachQso(stmt, qsoIds, func(id int64) []any { return []any{id, today} }); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to mark QSL cards as sent")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// MarkQslCardReceived records that a QSL card for the given QSO was received today via the given route.
+func (s *Service) MarkQslCardReceived(qsoId int64, via string) error {
+	const op errors.Op = "facade.Service.MarkQslCardReceived"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if qsoId < 1 {
+		return errors.New(op).Msg("Invalid QSO ID")
+	}
+
+	via = strings.ToUpper(strings.TrimSpace(via))
+	if !qslRoutes[via] {
+		return errors.New(op).Msgf("Invalid QSL route: %q", via)
+	}
+
+	const stmt = `INSERT INTO qsl_card (qso_id, rcvd_status, rcvd_via, rcvd_date)
+VALUES (?, 'Y', ?, ?)
+ON CONFLICT (qso_id) DO UPDATE SET rcvd_status = 'Y',
+                                   rcvd_via    = excluded.rcvd_via,
+                                   rcvd_date   = excluded.rcvd_date,
+                                   modified_at = datetime('now', 'localtime')`
+
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, qsoId, via, utils.DateNowAsYYYYMMDD()); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to mark QSL card as received")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// ExportQslLabels writes address labels for the given QSOs to a file in the exports directory and returns the path
+// of the file. QSOs to the same recipient are grouped onto the same label. If no QSO IDs are given, labels are
+// generated for the entire "to be sent" queue. The format is either "csv" or "pdf".
+func (s *Service) ExportQslLabels(qsoIds []int64, format string) (string, error) {
+	const op errors.Op = "facade.Service.ExportQslLabels"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return "", errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return "", errors.Root(err)
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != QslLabelFormatCSV && format != QslLabelFormatPDF {
+		return "", errors.New(op).Msgf("Unsupported label format: %q", format)
+	}
+
+	var cards []QslCard
+	var err error
+	if len(qsoIds) == 0 {
+		cards, err = s.fetchQslQueue("")
+	} else {
+		cards, err = s.fetchQslCardsByQsoIds(qsoIds)
+	}
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSL cards for export")
+		return "", errors.Root(err)
+	}
+	if len(cards) == 0 {
+		return "", errors.New(op).Msg("No QSL cards to export")
+	}
+
+	labels := buildQslLabels(cards)
+
+	var buf bytes.Buffer
+	if format == QslLabelFormatCSV {
+		err = writeQslLabelsCSV(&buf, labels)
+	} else {
+		err = writeQslLabelsPDF(&buf, labels)
+	}
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to render QSL labels")
+		return "", errors.Root(err)
+	}
+
+	dir := filepath.Join(s.ConfigService.WorkingDir, qslExportDir)
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to create exports directory")
+		return "", errors.Root(err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("qsl-labels-%s.%s", time.Now().UTC().Format("20060102-150405"), format))
+	if err = os.WriteFile(path, buf.Bytes(), 0o640); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to write QSL labels")
+		return "", errors.Root(err)
+	}
+
+	s.LoggerService.InfoWith().Str("path", path).Int("labels", len(labels)).Msg("QSL labels exported")
+
+	return path, nil
+}
+
+// fetchQslQueue returns the queued and requested cards for the current logbook, optionally filtered by route.
+func (s *Service) fetchQslQueue(via string) ([]QslCard, error) {
+	query := qslCardSelect + ` AND q.logbook_id = ? AND c.sent_status IN ('Q', 'R')`
+	args := []any{s.CurrentLogbook.ID}
+	if via != "" {
+		query += ` AND c.sent_via = ?`
+		args = append(args, via)
+	}
+	query += ` ORDER BY COALESCE(NULLIF(c.manager, ''), q.call), q.qso_date, q.time_on`
+
+	return s.queryQslCards(query, args...)
+}
+
+// fetchQslCardsByQsoIds returns the cards for the given QSOs, in recipient order.
+func (s *Service) fetchQslCardsByQsoIds(qsoIds []int64) ([]QslCard, error) {
+	placeholders, args := inPlaceholders(qsoIds)
+	query := qslCardSelect + ` AND q.id IN (` + placeholders + `)` +
+		` ORDER BY COALESCE(NULLIF(c.manager, ''), q.call), q.qso_date, q.time_on`
+
+	return s.queryQslCards(query, args...)
+}
+
+func (s *Service) queryQslCards(query string, args ...any) ([]QslCard, error) {
+	const op errors.Op = "facade.Service.queryQslCards"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, args...)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cards []QslCard
+	for rows.Next() {
+		var c QslCard
+		if err = rows.Scan(&c.QsoID, &c.Call, &c.QsoDate, &c.TimeOn, &c.Band, &c.Mode, &c.RstSent,
+			&c.SentStatus, &c.SentVia, &c.SentDate, &c.RcvdStatus, &c.RcvdVia, &c.RcvdDate, &c.Manager, &c.Printed); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		cards = append(cards, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return cards, nil
+}
+
+// upsertQslCard writes the status fields of the given card. The printed flag is not touched.
+func (s *Service) upsertQslCard(card QslCard) error {
+	const op errors.Op = "facade.Service.upsertQslCard"
+
+	const stmt = `INSERT INTO qsl_card (qso_id, sent_status, sent_via, sent_date, rcvd_status, rcvd_via, rcvd_date, manager)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (qso_id) DO UPDATE SET sent_status = excluded.sent_status,
+                                   sent_via    = excluded.sent_via,
+                                   sent_date   = excluded.sent_date,
+                                   rcvd_status = excluded.rcvd_status,
+                                   rcvd_via    = excluded.rcvd_via,
+                                   rcvd_date   = excluded.rcvd_date,
+                                   manager     = excluded.manager,
+                                   modified_at = datetime('now', 'localtime')`
+
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), stmt, card.QsoID, card.SentStatus, card.SentVia,
+		card.SentDate, card.RcvdStatus, card.RcvdVia, card.RcvdDate, card.Manager); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// execForEachQso executes the statement once per QSO ID within a single transaction, so a batch is either
+// applied in full or not at all.
+func (s *Service) execForEachQso(stmt string, qsoIds []int64, args func(id int64) []any) error {
+	const op errors.Op = "facade.Service.execForEachQso"
+
+	tx, txCancel, err := s.DatabaseService.BeginTxContext(s.dbContext())
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer txCancel()
+	defer func() { _ = tx.Rollback() }() // No-op after successful commit
+
+	for _, id := range qsoIds {
+		if id < 1 {
+			return errors.New(op).Msgf("Invalid QSO ID: %d", id)
+		}
+		if _, err = tx.Exec(stmt, args(id)...); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// saveQslFromQso persists the paper QSL fields carried by a QSO, if any have been set or the QSO already has a card
+// (whose state is then cleared). The database module does not store these fields, so they are kept in the qsl_card
+// table instead; every read path that returns QSOs for editing merges the card back in with mergeQslIntoQso.
+func (s *Service) saveQslFromQso(qsoId int64, qsl types.Qsl) error {
+	const op errors.Op = "facade.Service.saveQslFromQso"
+
+	if qsl.QslSent == "" && qsl.QslRcvd == "" && qsl.QslVia == "" {
+		exists, err := s.qslCardExists(qsoId)
+		if err != nil {
+			return errors.New(op).Err(err)
+		}
+		if !exists {
+			return nil
+		}
+	}
+
+	card := normalizeQslCard(QslCard{
+		QsoID:      qsoId,
+		SentStatus: qsl.QslSent,
+		SentVia:    qsl.QslSendVia,
+		SentDate:   qsl.QslSDate,
+		RcvdStatus: qsl.QslRcvd,
+		RcvdVia:    qsl.QslRcvdVia,
+		RcvdDate:   qsl.QslRDate,
+		Manager:    qsl.QslVia,
+	})
+	if err := validateQslCard(card); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	if err := s.upsertQslCard(card); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// qslCardExists reports whether the QSO has a qsl_card row.
+func (s *Service) qslCardExists(qsoId int64) (bool, error) {
+	const op errors.Op = "facade.Service.qslCardExists"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), `SELECT 1 FROM qsl_card WHERE qso_id = ?`, qsoId)
+	if err != nil {
+		return false, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	exists := rows.Next()
+	if err = rows.Err(); err != nil {
+		return false, errors.New(op).Err(err)
+	}
+
+	return exists, nil
+}
+
+// mergeQslIntoQso copies the QSO's QSL card state, if one exists, into the QSO's ADIF QSL fields.
+func (s *Service) mergeQslIntoQso(qso *types.Qso) error {
+	const op errors.Op = "facade.Service.mergeQslIntoQso"
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(),
+		`SELECT sent_status, sent_via, sent_date, rcvd_status, rcvd_via, rcvd_date, manager FROM qsl_card WHERE qso_id = ?`, qso.ID)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return errors.New(op).Err(err)
+		}
+		return nil
+	}
+
+	var card QslCard
+	if err = rows.Scan(&card.SentStatus, &card.SentVia, &card.SentDate, &card.RcvdStatus, &card.RcvdVia, &card.RcvdDate, &card.Manager); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	qso.QslSent = card.SentStatus
+	qso.QslSendVia = card.SentVia
+	qso.QslSDate = card.SentDate
+	qso.QslRcvd = card.RcvdStatus
+	qso.QslRcvdVia = card.RcvdVia
+	qso.QslRDate = card.RcvdDate
+	qso.QslVia = card.Manager
+
+	return nil
+}
+
+// normalizeQslCard trims and upper-cases the card's enumerated fields and applies the defaults for empty statuses.
+func normalizeQslCard(card QslCard) QslCard {
+	card.SentStatus = strings.ToUpper(strings.TrimSpace(card.SentStatus))
+	card.SentVia = strings.ToUpper(strings.TrimSpace(card.SentVia))
+	card.SentDate = strings.ReplaceAll(strings.TrimSpace(card.SentDate), "-", "")
+	card.RcvdStatus = strings.ToUpper(strings.TrimSpace(card.RcvdStatus))
+	card.RcvdVia = strings.ToUpper(strings.TrimSpace(card.RcvdVia))
+	card.RcvdDate = strings.ReplaceAll(strings.TrimSpace(card.RcvdDate), "-", "")
+	card.Manager = strings.ToUpper(strings.TrimSpace(card.Manager))
+
+	if card.SentStatus == "" {
+		card.SentStatus = qslStatusNo
+	}
+	if card.RcvdStatus == "" {
+		card.RcvdStatus = qslStatusNo
+	}
+
+	return card
+}
+
+// validateQslCard checks a normalized card against the ADIF enumerations.
+func validateQslCard(card QslCard) error {
+	const op errors.Op = "facade.validateQslCard"
+
+	if !qslSentStatuses[card.SentStatus] {
+		return errors.New(op).Msgf("Invalid QSL sent status: %q", card.SentStatus)
+	}
+	if !qslRcvdStatuses[card.RcvdStatus] {
+		return errors.New(op).Msgf("Invalid QSL received status: %q", card.RcvdStatus)
+	}
+	if card.SentVia != "" && !qslRoutes[card.SentVia] {
+		return errors.New(op).Msgf("Invalid QSL sent route: %q", card.SentVia)
+	}
+	if card.RcvdVia != "" && !qslRoutes[card.RcvdVia] {
+		return errors.New(op).Msgf("Invalid QSL received route: %q", card.RcvdVia)
+	}
+	if card.SentVia == qslViaManager && card.Manager == "" {
+		return errors.New(op).Msg("A manager callsign is required when sending via a QSL manager")
+	}
+	if len(card.Manager) > 20 {
+		return errors.New(op).Msg("Manager callsign is too long")
+	}
+	for _, date := range []string{card.SentDate, card.RcvdDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("20060102", date); err != nil {
+			return errors.New(op).Msgf("Invalid QSL date: %q", date)
+		}
+	}
+
+	return nil
+}
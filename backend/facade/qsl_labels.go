@@ -0,0 +1,225 @@
+package facade
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// qslLabelMaxQsos is the number of QSO lines that fit on a single label. Recipients with more QSOs than this get
+// additional labels.
+const qslLabelMaxQsos = 4
+
+// Label sheet geometry, in PDF points, for a 3 x 7 A4 sheet (63.5 x 38.1mm labels, e.g. Avery L7160).
+const (
+	pdfPageWidth      = 595.28
+	pdfPageHeight     = 841.89
+	pdfLabelCols      = 3
+	pdfLabelRows      = 7
+	pdfLabelLeft      = 20.55
+	pdfLabelTop       = 42.95
+	pdfLabelPitchX    = 187.2
+	pdfLabelPitchY    = 108.0
+	pdfLabelPadding   = 8.0
+	pdfLabelsPerSheet = pdfLabelCols * pdfLabelRows
+)
+
+var qslRouteNames = map[string]string{
+	qslViaBureau:     "Bureau",
+	qslViaDirect:     "Direct",
+	qslViaElectronic: "Electronic",
+	qslViaManager:    "Manager",
+}
+
+// qslLabel is a single printed label: one recipient and up to qslLabelMaxQsos QSOs.
+type qslLabel struct {
+	To    string
+	Call  string
+	Via   string
+	Cards []QslCard
+}
+
+// buildQslLabels groups the cards by recipient (the QSL manager if set, otherwise the contacted station), splitting
+// recipients over several labels when needed. Cards are expected to be in recipient order.
+func buildQslLabels(cards []QslCard) []qslLabel {
+	var labels []qslLabel
+	for _, card := range cards {
+		to := card.Manager
+		if to == "" {
+			to = card.Call
+		}
+
+		n := len(labels)
+		if n > 0 && labels[n-1].To == to && labels[n-1].Call == card.Call && len(labels[n-1].Cards) < qslLabelMaxQsos {
+			labels[n-1].Cards = append(labels[n-1].Cards, card)
+			continue
+		}
+
+		labels = append(labels, qslLabel{
+			To:    to,
+			Call:  card.Call,
+			Via:   qslRouteNames[card.SentVia],
+			Cards: []QslCard{card},
+		})
+	}
+	return labels
+}
+
+// header returns the label's address line, e.g. "G4ABC" or "3B8XYZ via G4ABC".
+func (l qslLabel) header() string {
+	if l.To != l.Call {
+		return l.Call + " via " + l.To
+	}
+	return l.Call
+}
+
+// confirmation returns the closing line of the label, which depends on whether the station's card has been received.
+func (l qslLabel) confirmation() string {
+	for _, c := range l.Cards {
+		if c.RcvdStatus != qslStatusYes {
+			return "PSE QSL TNX"
+		}
+	}
+	return "TNX QSL"
+}
+
+// writeQslLabelsCSV writes one row per QSO, suitable for a mail merge.
+func writeQslLabelsCSV(w io.Writer, labels []qslLabel) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"label", "to", "via", "call", "qso_date", "time_on", "band", "mode", "rst_sent", "qsl"}); err != nil {
+		return err
+	}
+
+	for i, l := range labels {
+		confirmation := l.confirmation()
+		for _, c := range l.Cards {
+			record := []string{
+				fmt.Sprintf("%d", i+1), l.To, l.Via, c.Call, formatQslDate(c.QsoDate), c.TimeOn, c.Band, c.Mode, c.RstSent, confirmation,
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeQslLabelsPDF renders the labels onto as many A4 label sheets as required.
+func writeQslLabelsPDF(w io.Writer, labels []qslLabel) error {
+	doc := &pdfDocument{}
+	for start := 0; start < len(labels); start += pdfLabelsPerSheet {
+		end := min(start+pdfLabelsPerSheet, len(labels))
+
+		var page bytes.Buffer
+		for i, l := range labels[start:end] {
+			col := i % pdfLabelCols
+			row := i / pdfLabelCols
+			x := pdfLabelLeft + float64(col)*pdfLabelPitchX + pdfLabelPadding
+			y := pdfPageHeight - pdfLabelTop - float64(row)*pdfLabelPitchY - pdfLabelPadding
+
+			y -= 12
+			pdfText(&page, "F1", 11, x, y, l.header())
+			if l.Via != "" {
+				pdfText(&page, "F2", 7, x+110, y, strings.ToUpper(l.Via))
+			}
+
+			y -= 11
+			pdfText(&page, "F2", 7, x, y, fmt.Sprintf("%-10s %-4s %-5s %-6s %s", "DATE", "UTC", "BAND", "MODE", "RST"))
+			for _, c := range l.Cards {
+				y -= 9
+				pdfText(&page, "F2", 8, x, y, fmt.Sprintf("%-10s %-4s %-5s %-6s %s", formatQslDate(c.QsoDate), c.TimeOn, c.Band, c.Mode, c.RstSent))
+			}
+
+			pdfText(&page, "F1", 8, x, pdfPageHeight-pdfLabelTop-float64(row+1)*pdfLabelPitchY+pdfLabelPadding+4, l.confirmation())
+		}
+		doc.addPage(page.Bytes())
+	}
+
+	return doc.write(w)
+}
+
+// formatQslDate formats an ADIF YYYYMMDD date as YYYY-MM-DD for printing.
+func formatQslDate(date string) string {
+	if len(date) != 8 {
+		return date
+	}
+	return date[0:4] + "-" + date[4:6] + "-" + date[6:8]
+}
+
+// pdfText appends a single line of text to a page content stream.
+func pdfText(buf *bytes.Buffer, font string, size, x, y float64, text string) {
+	_, _ = fmt.Fprintf(buf, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(text))
+}
+
+// pdfEscape escapes a string for use in a PDF literal string. Only printable ASCII is supported by the built-in
+// fonts without an encoding dictionary, so anything else is replaced.
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pdfDocument is a minimal PDF 1.4 writer, sufficient for text-only pages using the standard Type 1 fonts.
+type pdfDocument struct {
+	pages [][]byte
+}
+
+func (d *pdfDocument) addPage(content []byte) {
+	d.pages = append(d.pages, content)
+}
+
+// write serializes the document. Objects 1-4 are the catalog, page tree and fonts; each page then takes two objects,
+// the page itself and its content stream.
+func (d *pdfDocument) write(w io.Writer) error {
+	var objects []string
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	)
+	for i, content := range d.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 6+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		_, _ = fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	_, _ = fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		_, _ = fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	_, _ = fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,355 @@
+package facade
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Station-Manager/types"
+	"github.com/Station-Manager/utils"
+)
+
+// =============================================================================
+// QSL Card Guard Tests
+// =============================================================================
+
+func TestFetchQslCard_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchQslCard(1); err == nil {
+		t.Error("FetchQslCard() should fail when service not initialized")
+	}
+}
+
+func TestFetchQslCard_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchQslCard(1); err == nil {
+		t.Error("FetchQslCard() should fail when service not started")
+	}
+}
+
+func TestQueueQslCards_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if err := s.QueueQslCards([]int64{1}, "B", ""); err == nil {
+		t.Error("QueueQslCards() should fail when service not started")
+	}
+}
+
+func TestQueueQslCards_InvalidInput(t *testing.T) {
+	s := createStartedTestService()
+
+	tests := []struct {
+		name    string
+		ids     []int64
+		via     string
+		manager string
+	}{
+		{"no ids", nil, "B", ""},
+		{"unknown route", []int64{1}, "X", ""},
+		{"manager route without manager", []int64{1}, "M", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.QueueQslCards(tt.ids, tt.via, tt.manager); err == nil {
+				t.Error("QueueQslCards() should fail")
+			}
+		})
+	}
+}
+
+func TestExportQslLabels_UnsupportedFormat(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.ExportQslLabels(nil, "docx"); err == nil {
+		t.Error("ExportQslLabels() should fail for an unsupported format")
+	}
+}
+
+// =============================================================================
+// QSL Card Workflow Tests
+// =============================================================================
+
+func TestQslWorkflow_QueuePrintSend(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id1 := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	id2 := insertTestQso(t, s, "3B8XYZ", "20m", "CW")
+	insertTestQso(t, s, "K1ABC", "40m", "SSB")
+
+	if err := s.QueueQslCards([]int64{id1}, "b", ""); err != nil {
+		t.Fatalf("QueueQslCards() error = %v", err)
+	}
+	if err := s.QueueQslCards([]int64{id2}, "M", "g3mgr"); err != nil {
+		t.Fatalf("QueueQslCards() error = %v", err)
+	}
+
+	queue, err := s.FetchQslQueue("")
+	if err != nil {
+		t.Fatalf("FetchQslQueue() error = %v", err)
+	}
+	if len(queue) != 2 {
+		t.Fatalf("FetchQslQueue() returned %d cards, want 2", len(queue))
+	}
+	// Ordered by recipient: G3MGR (manager for 3B8XYZ) before G4ABC.
+	if queue[0].Call != "3B8XYZ" || queue[0].Manager != "G3MGR" || queue[0].SentStatus != "Q" {
+		t.Errorf("queue[0] = %+v", queue[0])
+	}
+
+	bureau, err := s.FetchQslQueue("B")
+	if err != nil {
+		t.Fatalf("FetchQslQueue(B) error = %v", err)
+	}
+	if len(bureau) != 1 || bureau[0].QsoID != id1 {
+		t.Errorf("FetchQslQueue(B) = %+v", bureau)
+	}
+
+	if err = s.MarkQslCardsPrinted([]int64{id1, id2}); err != nil {
+		t.Fatalf("MarkQslCardsPrinted() error = %v", err)
+	}
+	if err = s.MarkQslCardsSent([]int64{id1}); err != nil {
+		t.Fatalf("MarkQslCardsSent() error = %v", err)
+	}
+
+	queue, err = s.FetchQslQueue("")
+	if err != nil {
+		t.Fatalf("FetchQslQueue() error = %v", err)
+	}
+	if len(queue) != 1 || queue[0].QsoID != id2 || !queue[0].Printed {
+		t.Errorf("FetchQslQueue() after send = %+v", queue)
+	}
+
+	card, err := s.FetchQslCard(id1)
+	if err != nil {
+		t.Fatalf("FetchQslCard() error = %v", err)
+	}
+	if card.SentStatus != "Y" || card.SentVia != "B" || card.SentDate != utils.DateNowAsYYYYMMDD() {
+		t.Errorf("FetchQslCard() = %+v", card)
+	}
+
+	// Re-queuing a card that has already been sent must not reset it.
+	if err = s.QueueQslCards([]int64{id1}, "D", ""); err != nil {
+		t.Fatalf("QueueQslCards() error = %v", err)
+	}
+	if card, _ = s.FetchQslCard(id1); card.SentStatus != "Y" || card.SentVia != "B" {
+		t.Errorf("re-queued sent card = %+v", card)
+	}
+}
+
+func TestQslWorkflow_ReceivedAndMergeIntoQso(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	if err := s.MarkQslCardReceived(id, "D"); err != nil {
+		t.Fatalf("MarkQslCardReceived() error = %v", err)
+	}
+
+	qso, err := s.GetQsoById(id)
+	if err != nil {
+		t.Fatalf("GetQsoById() error = %v", err)
+	}
+	if qso.QslRcvd != "Y" || qso.QslRcvdVia != "D" || qso.QslSent != "N" {
+		t.Errorf("GetQsoById() QSL = %+v", qso.Qsl)
+	}
+}
+
+func TestQslWorkflow_EditFromListsKeepsCard(t *testing.T) {
+	s := createAdifTestService(t)
+	id := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	if err := s.MarkQslCardReceived(id, "D"); err != nil {
+		t.Fatalf("MarkQslCardReceived() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		fetch func() ([]types.Qso, error)
+	}{
+		{name: "session", fetch: s.CurrentSessionQsoSlice},
+		{name: "search", fetch: func() ([]types.Qso, error) { return s.SearchQsos(QsoSearch{Call: "G4ABC"}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qsos, err := tt.fetch()
+			if err != nil || len(qsos) != 1 {
+				t.Fatalf("fetch = %+v, %v, want one QSO", qsos, err)
+			}
+			qso := qsos[0]
+			qso.RstRcvd = "57"
+			if err = s.UpdateQso(qso); err != nil {
+				t.Fatalf("UpdateQso() error = %v", err)
+			}
+
+			card, err := s.FetchQslCard(id)
+			if err != nil {
+				t.Fatalf("FetchQslCard() error = %v", err)
+			}
+			if card.RcvdStatus != "Y" || card.RcvdVia != "D" {
+				t.Errorf("FetchQslCard() after editing a listed QSO = %+v, want the card kept", card)
+			}
+		})
+	}
+}
+
+func TestQslWorkflow_BatchIsAtomic(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	// The second ID does not exist, so the foreign key fails and nothing should be queued.
+	if err := s.QueueQslCards([]int64{id, 9999}, "B", ""); err == nil {
+		t.Fatal("QueueQslCards() should fail for an unknown QSO")
+	}
+
+	queue, err := s.FetchQslQueue("")
+	if err != nil {
+		t.Fatalf("FetchQslQueue() error = %v", err)
+	}
+	if len(queue) != 0 {
+		t.Errorf("FetchQslQueue() = %+v, want empty", queue)
+	}
+}
+
+func TestSaveQslFromQso(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	if err := s.saveQslFromQso(id, types.Qsl{QslSent: "q", QslSendVia: "m", QslVia: "g3mgr"}); err != nil {
+		t.Fatalf("saveQslFromQso() error = %v", err)
+	}
+	card, err := s.FetchQslCard(id)
+	if err != nil {
+		t.Fatalf("FetchQslCard() error = %v", err)
+	}
+	if card.SentStatus != "Q" || card.SentVia != "M" || card.Manager != "G3MGR" {
+		t.Errorf("FetchQslCard() = %+v", card)
+	}
+
+	if err = s.saveQslFromQso(id, types.Qsl{QslSent: "X"}); err == nil {
+		t.Error("saveQslFromQso() should reject an invalid status")
+	}
+
+	// Clearing the QSL state of a QSO with a card clears the card, rather than leaving the old state to be merged
+	// back in.
+	if err = s.saveQslFromQso(id, types.Qsl{}); err != nil {
+		t.Fatalf("saveQslFromQso() error = %v", err)
+	}
+	if card, err = s.FetchQslCard(id); err != nil {
+		t.Fatalf("FetchQslCard() error = %v", err)
+	}
+	if card.SentStatus != "N" || card.SentVia != "" || card.Manager != "" {
+		t.Errorf("FetchQslCard() after clearing = %+v", card)
+	}
+
+	// A QSO without a card does not get one for empty QSL fields.
+	other := insertTestQso(t, s, "G4XYZ", "20m", "SSB")
+	if err = s.saveQslFromQso(other, types.Qsl{}); err != nil {
+		t.Fatalf("saveQslFromQso() error = %v", err)
+	}
+	if exists, _ := s.qslCardExists(other); exists {
+		t.Error("saveQslFromQso() created a card for empty QSL fields")
+	}
+}
+
+func TestExportQslLabels_WritesFile(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	if err := s.QueueQslCards([]int64{id}, "B", ""); err != nil {
+		t.Fatalf("QueueQslCards() error = %v", err)
+	}
+
+	for _, format := range []string{QslLabelFormatCSV, QslLabelFormatPDF} {
+		t.Run(format, func(t *testing.T) {
+			path, err := s.ExportQslLabels(nil, format)
+			if err != nil {
+				t.Fatalf("ExportQslLabels() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !bytes.Contains(data, []byte("G4ABC")) {
+				t.Errorf("export does not contain the callsign")
+			}
+		})
+	}
+}
+
+// =============================================================================
+// QSL Label Rendering Tests
+// =============================================================================
+
+func TestBuildQslLabels(t *testing.T) {
+	cards := []QslCard{
+		{Call: "3B8XYZ", Manager: "G3MGR", SentVia: "M"},
+		{Call: "G4ABC", SentVia: "B"},
+		{Call: "G4ABC", SentVia: "B"},
+		{Call: "G4ABC", SentVia: "B"},
+		{Call: "G4ABC", SentVia: "B"},
+		{Call: "G4ABC", SentVia: "B"},
+	}
+
+	labels := buildQslLabels(cards)
+	if len(labels) != 3 {
+		t.Fatalf("buildQslLabels() returned %d labels, want 3", len(labels))
+	}
+	if got := labels[0].header(); got != "3B8XYZ via G3MGR" {
+		t.Errorf("labels[0].header() = %q", got)
+	}
+	if labels[0].Via != "Manager" {
+		t.Errorf("labels[0].Via = %q", labels[0].Via)
+	}
+	if len(labels[1].Cards) != qslLabelMaxQsos || len(labels[2].Cards) != 1 {
+		t.Errorf("label split = %d/%d", len(labels[1].Cards), len(labels[2].Cards))
+	}
+}
+
+func TestQslLabel_Confirmation(t *testing.T) {
+	pse := qslLabel{Cards: []QslCard{{RcvdStatus: "Y"}, {RcvdStatus: "N"}}}
+	if got := pse.confirmation(); got != "PSE QSL TNX" {
+		t.Errorf("confirmation() = %q", got)
+	}
+	tnx := qslLabel{Cards: []QslCard{{RcvdStatus: "Y"}}}
+	if got := tnx.confirmation(); got != "TNX QSL" {
+		t.Errorf("confirmation() = %q", got)
+	}
+}
+
+func TestWriteQslLabelsCSV(t *testing.T) {
+	labels := buildQslLabels([]QslCard{{Call: "G4ABC", SentVia: "B", QsoDate: "20240301", TimeOn: "1200", Band: "20m", Mode: "SSB", RstSent: "59"}})
+
+	var buf bytes.Buffer
+	if err := writeQslLabelsCSV(&buf, labels); err != nil {
+		t.Fatalf("writeQslLabelsCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("CSV has %d lines, want 2", len(lines))
+	}
+	if want := "1,G4ABC,Bureau,G4ABC,2024-03-01,1200,20m,SSB,59,PSE QSL TNX"; lines[1] != want {
+		t.Errorf("CSV row = %q, want %q", lines[1], want)
+	}
+}
+
+func TestWriteQslLabelsPDF(t *testing.T) {
+	cards := make([]QslCard, 0, pdfLabelsPerSheet+1)
+	for i := 0; i < pdfLabelsPerSheet+1; i++ {
+		cards = append(cards, QslCard{Call: "G4A" + string(rune('A'+i%26)) + string(rune('A'+i/26))})
+	}
+
+	var buf bytes.Buffer
+	if err := writeQslLabelsPDF(&buf, buildQslLabels(cards)); err != nil {
+		t.Fatalf("writeQslLabelsPDF() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Error("output is not a complete PDF document")
+	}
+	if !strings.Contains(out, "/Count 2") {
+		t.Error("expected two pages for one more label than fits on a sheet")
+	}
+}
+
+func TestPdfEscape(t *testing.T) {
+	if got := pdfEscape(`a(b)c\dé`); got != `a\(b\)c\\d?` {
+		t.Errorf("pdfEscape() = %q", got)
+	}
+}
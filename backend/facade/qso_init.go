@@ -8,10 +8,20 @@ import (
 	"github.com/Station-Manager/types"
 )
 
+// NewQsoResult is a newly initialized QSO along with the band x mode worked-before matrix of its callsign and entity,
+// and its award needs: whether the contact would be a new DXCC entity, band slot or mode slot, for the band and mode
+// the QSO was initialized with.
+type NewQsoResult struct {
+	Qso    *types.Qso   `json:"qso"`
+	Matrix WorkedMatrix `json:"matrix"`
+	Needs  AwardNeeds   `json:"needs"`
+}
+
 // initializeQso initializes a QSO object by populating its sections such as logging station, contacted station, and country details.
 // It performs operations to calculate the necessary details like bearing and distance, and merges retrieved data into the final QSO object.
+// The QSO is returned with its award needs, from the worked-before matrix of the callsign and its entity.
 // If an error occurs during critical initialization steps, it returns an error.
-func (s *Service) initializeQso(callsign string) (*types.Qso, error) {
+func (s *Service) initializeQso(callsign string) (*NewQsoResult, error) {
 	const op errors.Op = "facade.Service.initializeQso"
 
 	loggingStation, err := s.initLoggingStationSection()
@@ -62,10 +72,17 @@ func (s *Service) initializeQso(callsign string) (*types.Qso, error) {
 		return nil, errors.New(op).Err(err)
 	}
 
-	return qso, nil
+	matrix, err := s.computeWorkedMatrix(qso.Call, country, qso.DXCC)
+	if err != nil {
+		// Not a serious error, the QSO can still be logged.
+		s.LoggerService.WarnWith().Err(err).Str("callsign", qso.Call).Msg("Failed to compute worked-before matrix")
+	}
+
+	return &NewQsoResult{Qso: qso, Matrix: matrix, Needs: matrix.awardNeeds(qso.Band, qso.Mode)}, nil
 }
 
-// initLoggingStationSection initializes the logging station using the current logbook's callsign and configuration data.
+// initLoggingStationSection initializes the logging station using the current logbook's callsign and configuration data,
+// with the current operator, if one has been switched to, as the operator.
 // Returns a LoggingStation instance and an error if the station configuration retrieval fails.
 func (s *Service) initLoggingStationSection() (types.LoggingStation, error) {
 	const op errors.Op = "facade.Service.initLoggingStationSection"
@@ -79,6 +96,10 @@ func (s *Service) initLoggingStationSection() (types.LoggingStation, error) {
 	// as the station callsign.
 	loggingStation.StationCallsign = s.CurrentLogbook.Callsign
 
+	if operator := s.currentOperator(); operator.Callsign != "" {
+		loggingStation.Operator = operator.Callsign
+	}
+
 	return loggingStation, nil
 }
 
@@ -159,9 +180,12 @@ func (s *Service) initCountrySection(callsign string) (types.Country, error) {
 
 // initQsoDetailsSection initializes the QsoDetails section with default values and returns the QsoDetails object.
 func (s *Service) initQsoDetailsSection() types.QsoDetails {
-	return types.QsoDetails{
+	details := types.QsoDetails{
 		AntPath: "S",
 	}
+	s.prefillQsoFromCat(&details)
+
+	return details
 }
 
 // getContactHistory retrieves the contact history for a given contacted station from the database.
@@ -0,0 +1,81 @@
+package facade
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+// QsySpot tunes the rig to a DX spot and returns a new QSO for the spotted station, pre-filled with the spot's
+// frequency, band and mode. The mode is taken from the spot if it names one, otherwise from the band plan. Failing
+// to tune the rig is not fatal, as the QSO can still be logged.
+func (s *Service) QsySpot(spot DxSpot) (*NewQsoResult, error) {
+	const op errors.Op = "facade.Service.QsySpot"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	khz := spot.Spot.Frequency
+	band := bandForKhz(khz)
+	if band == "" {
+		return nil, errors.New(op).Msgf("Spot frequency %.1f kHz is outside the supported bands", khz)
+	}
+
+	mode := spot.Mode
+	if mode == "" {
+		mode = modeFromComment(spot.Spot.Comment)
+	}
+	if mode == "" {
+		mode = modeForKhz(khz)
+	}
+
+	if err := s.tuneRig(khz, mode); err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("callsign", spot.Spot.DxCall).Msg("Failed to tune the rig to the spot")
+	}
+
+	callsign := strings.ToUpper(strings.TrimSpace(spot.Spot.DxCall))
+	if len(callsign) < 3 {
+		return nil, errors.New(op).Msg(errMsgInvalidCallsign)
+	}
+
+	result, err := s.initializeQso(callsign)
+	if err != nil {
+		return nil, errors.Root(err)
+	}
+
+	applySpotToQso(result.Qso, khz, band, mode)
+
+	// The award needs were derived without a band or mode; derive them again now they are known, before they are
+	// emitted.
+	result.Needs = result.Matrix.awardNeeds(result.Qso.Band, result.Qso.Mode)
+	s.emitWorkedBefore(result)
+
+	return result, nil
+}
+
+// applySpotToQso sets the QSO's frequency (in Hz), band and mode from a spot. An empty mode leaves the QSO's
+// mode unchanged.
+func applySpotToQso(qso *types.Qso, khz float64, band, mode string) {
+	qso.Freq = strconv.FormatInt(int64(math.Round(khz*1000)), 10)
+	qso.Band = band
+	if mode == "" {
+		return
+	}
+	qso.Mode = mode
+	qso.Submode = ""
+	if mode == modes.SSB.String() {
+		qso.Submode = sidebandForKhz(khz)
+	}
+}
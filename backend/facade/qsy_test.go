@@ -0,0 +1,54 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/logging-app/backend/dxcluster"
+	"github.com/Station-Manager/types"
+)
+
+func TestQsySpot_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.QsySpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC", Frequency: 14025}}); err == nil {
+		t.Error("QsySpot() should fail when service not initialized")
+	}
+}
+
+func TestQsySpot_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.QsySpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC", Frequency: 14025}}); err == nil {
+		t.Error("QsySpot() should fail when service not started")
+	}
+}
+
+func TestQsySpot_OutOfBand(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.QsySpot(DxSpot{Spot: dxcluster.Spot{DxCall: "G4ABC", Frequency: 144300}}); err == nil {
+		t.Error("QsySpot() should fail for a frequency outside the supported bands")
+	}
+}
+
+func TestApplySpotToQso(t *testing.T) {
+	tests := []struct {
+		name        string
+		khz         float64
+		band, mode  string
+		wantFreq    string
+		wantMode    string
+		wantSubmode string
+	}{
+		{"cw", 14025.5, "20m", "CW", "14025500", "CW", ""},
+		{"lsb", 7150, "40m", "SSB", "7150000", "SSB", "LSB"},
+		{"usb", 21300, "15m", "SSB", "21300000", "SSB", "USB"},
+		{"unknown mode keeps the qso's", 14085, "20m", "", "14085000", "RTTY", "RTTY-L"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qso := &types.Qso{QsoDetails: types.QsoDetails{Mode: "RTTY", Submode: "RTTY-L"}}
+			applySpotToQso(qso, tt.khz, tt.band, tt.mode)
+			if qso.Freq != tt.wantFreq || qso.Band != tt.band || qso.Mode != tt.wantMode || qso.Submode != tt.wantSubmode {
+				t.Errorf("applySpotToQso() = freq %q band %q mode %q submode %q", qso.Freq, qso.Band, qso.Mode, qso.Submode)
+			}
+		})
+	}
+}
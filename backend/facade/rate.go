@@ -0,0 +1,167 @@
+package facade
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+const (
+	// rateEmitInterval is how often the QSO rate is emitted to the frontend while the service is running.
+	rateEmitInterval = 5 * time.Second
+	// rateShortWindow and rateLongWindow are the rolling windows over which the QSO rate is measured.
+	rateShortWindow = 10 * time.Minute
+	rateLongWindow  = 60 * time.Minute
+)
+
+// QsoRateBand is the number of QSOs logged on a band, over the session and over the last 60 minutes.
+type QsoRateBand struct {
+	Band    string `json:"band"`
+	Session int    `json:"session"`
+	Last60  int    `json:"last_60"`
+}
+
+// QsoRate is a snapshot of the QSO rate for the current session. Rates are in QSOs per hour, so the 10-minute
+// rate is the number of QSOs logged in the last 10 minutes multiplied by six.
+type QsoRate struct {
+	Last10       int           `json:"last_10"`
+	Last60       int           `json:"last_60"`
+	Rate10       int           `json:"rate_10"`
+	Rate60       int           `json:"rate_60"`
+	SessionTotal int           `json:"session_total"`
+	ByBand       []QsoRateBand `json:"by_band"` // busiest band first
+	At           time.Time     `json:"at"`
+}
+
+// rateEntry is a single QSO logged during the session.
+type rateEntry struct {
+	at   time.Time
+	band string
+}
+
+// rateMeter tracks the QSOs logged during the current session. Only the last hour of QSOs is kept individually;
+// the session totals per band are kept as counts. The zero value is ready for use.
+type rateMeter struct {
+	mu      sync.Mutex
+	entries []rateEntry // oldest first
+	session map[string]int
+	total   int
+}
+
+// record adds a QSO logged at the given time.
+func (m *rateMeter) record(at time.Time, band string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.session == nil {
+		m.session = make(map[string]int)
+	}
+	m.session[band]++
+	m.total++
+	m.entries = append(m.entries, rateEntry{at: at, band: band})
+	m.prune(at)
+}
+
+// reset clears the meter for a new session.
+func (m *rateMeter) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = nil
+	m.session = nil
+	m.total = 0
+}
+
+// snapshot returns the rates as of the given time.
+func (m *rateMeter) snapshot(now time.Time) QsoRate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(now)
+
+	rate := QsoRate{SessionTotal: m.total, ByBand: make([]QsoRateBand, 0, len(m.session)), At: now.UTC()}
+	last60 := make(map[string]int)
+	for _, e := range m.entries {
+		if e.at.After(now) {
+			continue
+		}
+		rate.Last60++
+		last60[e.band]++
+		if now.Sub(e.at) < rateShortWindow {
+			rate.Last10++
+		}
+	}
+	rate.Rate10 = rate.Last10 * int(rateLongWindow/rateShortWindow)
+	rate.Rate60 = rate.Last60
+
+	for band, count := range m.session {
+		rate.ByBand = append(rate.ByBand, QsoRateBand{Band: band, Session: count, Last60: last60[band]})
+	}
+	slices.SortFunc(rate.ByBand, func(a, b QsoRateBand) int {
+		if c := cmp.Compare(b.Session, a.Session); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Band, b.Band)
+	})
+
+	return rate
+}
+
+// prune drops the entries that have fallen out of the long window. The caller must hold m.mu.
+func (m *rateMeter) prune(now time.Time) {
+	i := 0
+	for i < len(m.entries) && now.Sub(m.entries[i].at) >= rateLongWindow {
+		i++
+	}
+	if i > 0 {
+		m.entries = slices.Delete(m.entries, 0, i)
+	}
+}
+
+// FetchQsoRate returns the current QSO rate for the session.
+func (s *Service) FetchQsoRate() (QsoRate, error) {
+	const op errors.Op = "facade.Service.FetchQsoRate"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return QsoRate{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return QsoRate{}, errors.Root(err)
+	}
+
+	return s.rate.snapshot(time.Now()), nil
+}
+
+// recordQsoRate adds a freshly logged QSO to the rate meter and emits the updated rate immediately, rather than
+// waiting for the next tick of the emitter.
+func (s *Service) recordQsoRate(band string) {
+	now := time.Now()
+	s.rate.record(now, band)
+	s.emitEvent(EventQsoRate.String(), s.rate.snapshot(now))
+}
+
+// qsoRateEmitter periodically emits the QSO rate to the frontend, so the rolling windows keep moving between QSOs.
+func (s *Service) qsoRateEmitter(shutdown <-chan struct{}) {
+	ticker := time.NewTicker(rateEmitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-shutdown:
+			s.LoggerService.DebugWith().Msg("QSO rate emitter received shutdown signal")
+			return
+		case <-s.ctx.Done():
+			s.LoggerService.DebugWith().Msg("QSO rate emitter context cancelled")
+			return
+		case now := <-ticker.C:
+			s.emitEvent(EventQsoRate.String(), s.rate.snapshot(now))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package facade
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Rate Meter Tests
+// =============================================================================
+
+func TestRateMeter_Snapshot(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var m rateMeter
+	m.record(start, "20m")                     // drops out of both windows by the last snapshot
+	m.record(start.Add(30*time.Minute), "20m") // in the 60-minute window only
+	m.record(start.Add(55*time.Minute), "40m") // in both windows
+	m.record(start.Add(61*time.Minute), "20m") // in both windows
+	m.record(start.Add(62*time.Minute), "15m") // in both windows
+
+	tests := []struct {
+		name         string
+		now          time.Time
+		last10       int
+		last60       int
+		rate10       int
+		sessionTotal int
+	}{
+		{"just after the last QSO", start.Add(63 * time.Minute), 3, 4, 18, 5},
+		{"ten minutes later", start.Add(73 * time.Minute), 0, 4, 0, 5},
+		{"two hours later", start.Add(3 * time.Hour), 0, 0, 0, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.snapshot(tt.now)
+			if got.Last10 != tt.last10 || got.Last60 != tt.last60 || got.Rate10 != tt.rate10 || got.Rate60 != tt.last60 {
+				t.Errorf("snapshot() = %+v, want last10=%d last60=%d rate10=%d", got, tt.last10, tt.last60, tt.rate10)
+			}
+			if got.SessionTotal != tt.sessionTotal {
+				t.Errorf("SessionTotal = %d, want %d", got.SessionTotal, tt.sessionTotal)
+			}
+		})
+	}
+}
+
+func TestRateMeter_ByBand(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var m rateMeter
+	m.record(start, "40m")
+	m.record(start.Add(70*time.Minute), "20m")
+	m.record(start.Add(71*time.Minute), "20m")
+	m.record(start.Add(72*time.Minute), "15m")
+
+	got := m.snapshot(start.Add(75 * time.Minute)).ByBand
+	want := []QsoRateBand{
+		{Band: "20m", Session: 2, Last60: 2},
+		{Band: "15m", Session: 1, Last60: 1},
+		{Band: "40m", Session: 1, Last60: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ByBand = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ByBand[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRateMeter_Reset(t *testing.T) {
+	var m rateMeter
+	now := time.Now()
+	m.record(now, "20m")
+	m.reset()
+
+	got := m.snapshot(now)
+	if got.SessionTotal != 0 || got.Last60 != 0 || len(got.ByBand) != 0 {
+		t.Errorf("snapshot() after reset = %+v, want empty", got)
+	}
+}
+
+func TestRateMeter_PrunesOldEntries(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var m rateMeter
+	for i := range 100 {
+		m.record(start.Add(time.Duration(i)*time.Minute), "20m")
+	}
+	if len(m.entries) != int(rateLongWindow/time.Minute) {
+		t.Errorf("len(entries) = %d, want %d", len(m.entries), int(rateLongWindow/time.Minute))
+	}
+}
+
+// =============================================================================
+// QSO Rate Facade Tests
+// =============================================================================
+
+func TestFetchQsoRate_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchQsoRate(); err == nil {
+		t.Error("FetchQsoRate() should fail when service not initialized")
+	}
+}
+
+func TestFetchQsoRate_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchQsoRate(); err == nil {
+		t.Error("FetchQsoRate() should fail when service not started")
+	}
+}
+
+func TestFetchQsoRate_RecordsQsos(t *testing.T) {
+	s := createStartedTestService()
+	s.recordQsoRate("20m")
+	s.recordQsoRate("40m")
+
+	rate, err := s.FetchQsoRate()
+	if err != nil {
+		t.Fatalf("FetchQsoRate() error = %v", err)
+	}
+	if rate.Last10 != 2 || rate.Rate10 != 12 || rate.SessionTotal != 2 || len(rate.ByBand) != 2 {
+		t.Errorf("FetchQsoRate() = %+v", rate)
+	}
+}
+
+func TestQsoRateEmitter_Shutdown(t *testing.T) {
+	s := createStartedTestService()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ctx = ctx
+
+	tests := []struct {
+		name string
+		stop func(shutdown chan struct{})
+	}{
+		{"shutdown channel", func(shutdown chan struct{}) { close(shutdown) }},
+		{"context cancelled", func(chan struct{}) { cancel() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				s.qsoRateEmitter(shutdown)
+				close(done)
+			}()
+
+			tt.stop(shutdown)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("qsoRateEmitter() did not stop")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package facade
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Station-Manager/adif"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/restapi"
+	"github.com/Station-Manager/types"
+)
+
+// newRestApi binds the REST API if it is enabled in the app options. It returns nil if it is disabled.
+func (s *Service) newRestApi(opts RestApiOptions) (*restapi.Server, error) {
+	const op errors.Op = "facade.Service.newRestApi"
+	if !opts.Enabled {
+		return nil, nil
+	}
+
+	server, err := restapi.Listen(restapi.Config{Address: opts.Address, Token: opts.Token}, &restBackend{service: s})
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	return server, nil
+}
+
+// restApiWorker serves the REST API until shutdown.
+func (s *Service) restApiWorker(shutdown <-chan struct{}) {
+	server := s.restApi.Load()
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.LoggerService.InfoWith().Str("addr", server.Addr().String()).Msg("REST API started")
+	if err := server.Serve(ctx); err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("REST API failed")
+	}
+	s.LoggerService.DebugWith().Msg("REST API stopped")
+}
+
+// restBackend carries out the REST API operations on the service. It implements restapi.Backend.
+type restBackend struct {
+	service *Service
+}
+
+// errRestNotStarted is returned while the service is stopping.
+var errRestNotStarted = restapi.Errorf(http.StatusServiceUnavailable, errMsgServiceNotStarted)
+
+// LogQso logs a QSO sent by another program, completing it as the ADIF listeners do: the QSO is logged to the
+// current logbook, with the logging station from the config unless given.
+func (b *restBackend) LogQso(qso types.Qso) (types.Qso, error) {
+	s := b.service
+	if !s.started.Load() {
+		return types.Qso{}, errRestNotStarted
+	}
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	if err := s.completeRestQso(&qso); err != nil {
+		return types.Qso{}, restapi.Errorf(http.StatusUnprocessableEntity, "%s", errors.Root(err).Error())
+	}
+	// Checked with the session logQso sets, so that the validator's reasons can be returned: unlike the frontend, a
+	// script has no form showing what is wrong.
+	check := qso
+	_, check.SessionID = s.currentOperatorSession()
+	if err := s.validate.Struct(check); err != nil {
+		return types.Qso{}, restapi.Errorf(http.StatusUnprocessableEntity, "QSO validation failed: %v", err)
+	}
+
+	id, err := s.logQso(qso)
+	if err != nil {
+		return types.Qso{}, err
+	}
+
+	// The QSO is returned as stored, with the operator, session and distance logQso added.
+	stored, err := s.DatabaseService.FetchQsoById(id)
+	if err != nil {
+		s.LoggerService.ErrorWith().Err(err).Int64("id", id).Msg("Failed to fetch the QSO logged through the REST API")
+		return types.Qso{}, errors.Root(err)
+	}
+
+	s.LoggerService.InfoWith().Str("callsign", stored.Call).Str("band", stored.Band).Str("mode", stored.Mode).
+		Msg("Logged QSO received through the REST API")
+	s.emitEvent(EventExternalQso.String(), stored)
+	return stored, nil
+}
+
+func (b *restBackend) SearchQsos(search restapi.Search) ([]types.Qso, error) {
+	s := b.service
+	if !s.started.Load() {
+		return nil, errRestNotStarted
+	}
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	q := QsoSearch{
+		Call:  search.Call,
+		Band:  search.Band,
+		Mode:  search.Mode,
+		From:  search.From,
+		To:    search.To,
+		Limit: search.Limit,
+	}
+	if err := q.normalize(); err != nil {
+		return nil, restapi.Errorf(http.StatusBadRequest, "%s", errors.Root(err).Error())
+	}
+	qsos, err := s.searchQsos(q)
+	if err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to search QSOs for the REST API")
+		return nil, errors.Root(err)
+	}
+	return qsos, nil
+}
+
+// Lookup initializes a QSO for a callsign as NewQso does, without announcing the callsign's worked-before status to
+// the frontend, where the operator may be busy with another QSO.
+func (b *restBackend) Lookup(callsign string) (types.Qso, error) {
+	s := b.service
+	if !s.started.Load() {
+		return types.Qso{}, errRestNotStarted
+	}
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if len(callsign) < 3 {
+		return types.Qso{}, restapi.Errorf(http.StatusBadRequest, errMsgInvalidCallsign)
+	}
+
+	result, err := s.initializeQso(callsign)
+	if err != nil {
+		return types.Qso{}, errors.Root(err)
+	}
+	return *result.Qso, nil
+}
+
+func (b *restBackend) CatStatus() (restapi.CatStatus, error) {
+	s := b.service
+	if !s.started.Load() {
+		return restapi.CatStatus{}, errRestNotStarted
+	}
+
+	state, updated, _ := s.catState.get()
+	conn := s.catHealth.get()
+	return restapi.CatStatus{
+		Connection: conn.State,
+		Error:      conn.Error,
+		State:      state,
+		UpdatedAt:  updated,
+	}, nil
+}
+
+func (b *restBackend) SessionQsos() ([]types.Qso, error) {
+	s := b.service
+	if !s.started.Load() {
+		return nil, errRestNotStarted
+	}
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	_, sessionID := s.currentOperatorSession()
+	qsos, err := s.DatabaseService.FetchQsoSliceBySessionID(sessionID)
+	if err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch QSOs by session ID for the REST API")
+		return nil, errors.Root(err)
+	}
+	return qsos, nil
+}
+
+// completeRestQso fills in what a QSO sent to the REST API may leave out: the logbook, the logging station, the band
+// of the frequency, the end of the QSO and its entity. Modes and times are brought to the form the log keeps them
+// in, as for the ADIF listeners. A QSO from another station callsign is rejected.
+func (s *Service) completeRestQso(qso *types.Qso) error {
+	const op errors.Op = "facade.Service.completeRestQso"
+
+	qso.Call = strings.ToUpper(strings.TrimSpace(qso.Call))
+	if len(qso.Call) < 3 {
+		return errors.New(op).Msg(errMsgInvalidCallsign)
+	}
+
+	if call := strings.TrimSpace(qso.StationCallsign); call != "" &&
+		!strings.EqualFold(s.parseCallsign(call), s.parseCallsign(s.CurrentLogbook.Callsign)) {
+		return errors.New(op).Msgf("The QSO was made as %s, not the logbook's callsign %s", call,
+			s.CurrentLogbook.Callsign)
+	}
+	if qso.StationCallsign == "" {
+		station, err := s.initLoggingStationSection()
+		if err != nil {
+			return errors.New(op).Err(err)
+		}
+		qso.LoggingStation = station
+	}
+	qso.LogbookID = s.CurrentLogbook.ID
+
+	qso.Band = strings.ToLower(strings.TrimSpace(qso.Band))
+	if hz, ok := catFreqHz(qso.Freq); ok && qso.Band == "" {
+		qso.Band = bandForKhz(float64(hz) / 1000)
+	}
+	normalizeExternalMode(&qso.QsoDetails)
+	qso.TimeOn = adifTimeHHMM(qso.TimeOn)
+	qso.TimeOff = adifTimeHHMM(qso.TimeOff)
+	if qso.TimeOff == "" {
+		qso.TimeOff = qso.TimeOn
+	}
+	if qso.QsoDateOff == "" {
+		qso.QsoDateOff = qso.QsoDate
+	}
+	if qso.AntPath == "" {
+		qso.AntPath = "S"
+	}
+	for _, status := range []*string{&qso.QrzComUploadStatus, &qso.SmQsoUploadStatus, &qso.SmFwrdByEmailStatus} {
+		if *status == "" {
+			*status = adif.NoString
+		}
+	}
+
+	if qso.Country == "" || qso.DXCC == "" {
+		if country, err := s.initCountrySection(qso.Call); err != nil {
+			s.LoggerService.WarnWith().Err(err).Str("callsign", qso.Call).Msg("Failed to look up the country of a QSO received through the REST API")
+		} else if err = mergeCountryIntoContactedStation(&qso.ContactedStation, country); err == nil {
+			qso.CountryDetails = country
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package facade
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/types"
+	"github.com/gorilla/websocket"
+)
+
+const testRestToken = "s3cret"
+
+// startRestApiTestWorker binds the REST API and runs its worker until the end of the test. It returns the API's
+// base URL.
+func startRestApiTestWorker(t *testing.T, s *Service) string {
+	t.Helper()
+	server, err := s.newRestApi(RestApiOptions{Enabled: true, Address: "127.0.0.1:0", Token: testRestToken})
+	if err != nil {
+		t.Fatalf("newRestApi() error = %v", err)
+	}
+	s.restApi.Store(server)
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.restApiWorker(shutdown)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(shutdown)
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Error("restApiWorker did not stop on shutdown")
+		}
+	})
+	return "http://" + server.Addr().String() + "/api/v1"
+}
+
+// restCall makes an authorized request and decodes the JSON response into out, if given.
+func restCall(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testRestToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil && resp.StatusCode < 300 {
+		if err = json.Unmarshal(data, out); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// =============================================================================
+// Setup Tests
+// =============================================================================
+
+func TestNewRestApi(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	server, err := s.newRestApi(RestApiOptions{Address: "127.0.0.1:0", Token: testRestToken})
+	if err != nil || server != nil {
+		t.Errorf("newRestApi(disabled) = %v, %v, want nil", server, err)
+	}
+	if _, err = s.newRestApi(RestApiOptions{Enabled: true, Address: "127.0.0.1:0"}); err == nil {
+		t.Error("newRestApi() should fail without a token")
+	}
+}
+
+// =============================================================================
+// Endpoint Tests
+// =============================================================================
+
+func TestRestApi_LogQso(t *testing.T) {
+	s := createAdifTestService(t)
+	base := startRestApiTestWorker(t, s)
+
+	var logged types.Qso
+	status := restCall(t, http.MethodPost, base+"/qsos", `{"call":"g4xy","freq":"7074000","mode":"ft8",
+		"qso_date":"20261018","time_on":"080015","rst_sent":"-10","rst_rcvd":"-12","country":"England","dxcc":"223"}`, &logged)
+	if status != http.StatusCreated {
+		t.Fatalf("POST /qsos status = %d, want %d", status, http.StatusCreated)
+	}
+	if logged.ID == 0 || logged.Call != "G4XY" || logged.Band != "40m" || logged.Mode != "MFSK" || logged.Submode != "FT8" ||
+		logged.TimeOn != "0800" || logged.StationCallsign != "W1AW" || logged.SessionID != s.sessionID {
+		t.Errorf("logged QSO = %+v", logged)
+	}
+	// The QSO is returned as stored, with the operator logQso stamps.
+	if logged.Operator != "W1AW" {
+		t.Errorf("logged QSO operator = %q, want %q", logged.Operator, "W1AW")
+	}
+
+	var session []types.Qso
+	if status = restCall(t, http.MethodGet, base+"/session/qsos", "", &session); status != http.StatusOK {
+		t.Fatalf("GET /session/qsos status = %d", status)
+	}
+	if len(session) != 1 || session[0].ID != logged.ID {
+		t.Errorf("session QSOs = %+v, want the logged QSO", session)
+	}
+
+	var found []types.Qso
+	if status = restCall(t, http.MethodGet, base+"/qsos?call=G4*&band=40m", "", &found); status != http.StatusOK {
+		t.Fatalf("GET /qsos status = %d", status)
+	}
+	if len(found) != 1 || found[0].ID != logged.ID {
+		t.Errorf("found QSOs = %+v, want the logged QSO", found)
+	}
+}
+
+func TestRestApi_Rejected(t *testing.T) {
+	s := createAdifTestService(t)
+	base := startRestApiTestWorker(t, s)
+
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		want         int
+	}{
+		{"other station", http.MethodPost, "/qsos", `{"call":"G4XY","station_callsign":"K1AB","freq":"7025000","mode":"CW",
+			"qso_date":"20261018","time_on":"0800","country":"England","dxcc":"223"}`, http.StatusUnprocessableEntity},
+		{"no frequency", http.MethodPost, "/qsos", `{"call":"G4XY","mode":"CW","qso_date":"20261018","time_on":"0800",
+			"country":"England","dxcc":"223"}`, http.StatusUnprocessableEntity},
+		{"short call", http.MethodPost, "/qsos", `{"call":"G4"}`, http.StatusUnprocessableEntity},
+		{"bad date", http.MethodGet, "/qsos?from=yesterday", "", http.StatusBadRequest},
+		{"short lookup", http.MethodGet, "/lookup/G4", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := restCall(t, tt.method, base+tt.path, tt.body, nil); status != tt.want {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, status, tt.want)
+			}
+		})
+	}
+	if n := adifQsoCount(t, s); n != 0 {
+		t.Errorf("%d QSOs logged, want none", n)
+	}
+}
+
+func TestRestApi_CatStatus(t *testing.T) {
+	s := createDatabaseTestService(t)
+	base := startRestApiTestWorker(t, s)
+	s.catState.update(types.CatStatus{"VfoAFreq": "014074000"}, time.Now())
+
+	var status struct {
+		State map[string]string `json:"state"`
+	}
+	if code := restCall(t, http.MethodGet, base+"/cat", "", &status); code != http.StatusOK {
+		t.Fatalf("GET /cat status = %d", code)
+	}
+	if status.State["VfoAFreq"] != "014074000" {
+		t.Errorf("GET /cat state = %v", status.State)
+	}
+}
+
+// =============================================================================
+// Event Stream Tests
+// =============================================================================
+
+func TestRestApi_EventStream(t *testing.T) {
+	s := createDatabaseTestService(t)
+	base := startRestApiTestWorker(t, s)
+
+	url := strings.Replace(base, "http://", "ws://", 1) + "/events?events=" + EventQsoRate.String() + "&token=" + testRestToken
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// The subscription is registered after the handshake, so emit until the client has an event.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.emitEvent(EventDxSpot.String(), nil)
+				s.emitEvent(EventQsoRate.String(), QsoRate{Last10: 3})
+			}
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var got struct {
+		Event string  `json:"event"`
+		Data  QsoRate `json:"data"`
+	}
+	if err = conn.ReadJSON(&got); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if got.Event != EventQsoRate.String() || got.Data.Last10 != 3 {
+		t.Errorf("event = %+v, want the QSO rate", got)
+	}
+}
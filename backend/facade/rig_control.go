@@ -0,0 +1,465 @@
+package facade
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+const (
+	vfoA = "A"
+	vfoB = "B"
+
+	// maxFilterCodeLength bounds a filter code; rigs use a short number or letter for their filter settings.
+	maxFilterCodeLength = 4
+
+	// catTagPtt is the rig's transmit state, for rigs whose config reads it, e.g. with "TX;". Like the states in the
+	// tags enum, its value mappings map the rig's codes to labels, which must be "ON" and "OFF".
+	catTagPtt tags.CatStateTag = "PTT"
+)
+
+// RigCapabilities lists the controls the rig config supports, so the frontend only offers what will work.
+type RigCapabilities struct {
+	Rig        string   `json:"rig"`
+	Frequency  bool     `json:"frequency"`   // VFO A
+	FrequencyB bool     `json:"frequency_b"` // VFO B
+	Modes      []string `json:"modes"`       // the rig's mode labels accepted by SetRigMode
+	Filter     bool     `json:"filter"`
+	Vfos       []string `json:"vfos"` // the VFOs accepted by SelectRigVfo
+	Split      bool     `json:"split"`
+	Ptt        bool     `json:"ptt"`
+}
+
+// FetchRigCapabilities returns the rig controls supported by the current rig config.
+func (s *Service) FetchRigCapabilities() (RigCapabilities, error) {
+	const op errors.Op = "facade.Service.FetchRigCapabilities"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return RigCapabilities{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return RigCapabilities{}, errors.Root(err)
+	}
+
+	return rigCapabilities(s.catService().RigConfig()), nil
+}
+
+// SetRigFrequency sets the frequency, in kHz, of VFO "A" or "B".
+func (s *Service) SetRigFrequency(vfo string, khz float64) error {
+	const op errors.Op = "facade.Service.SetRigFrequency"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	cfg := s.catService().RigConfig()
+	name, tag := catCmdSetFreq, tags.VfoAFreq
+	switch normalizeVfo(vfo) {
+	case vfoA:
+	case vfoB:
+		name, tag = catCmdSetFreqB, tags.VfoBFreq
+	default:
+		return errors.New(op).Msgf("Invalid VFO: %q", vfo)
+	}
+
+	hz := int64(math.Round(khz * 1000))
+	if hz <= 0 || len(strconv.FormatInt(hz, 10)) > rigFreqDigits(cfg, tag) {
+		return errors.New(op).Msgf("Invalid frequency: %.3f kHz", khz)
+	}
+
+	if err := s.enqueueRigCommand(cfg, name, rigFreqParam(cfg, tag, khz)); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the rig frequency")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SetRigMode sets the rig's main mode. The mode is one of the rig's own mode labels (e.g., "USB", "CW-U",
+// "DATA-U"), as listed by FetchRigCapabilities.
+func (s *Service) SetRigMode(mode string) error {
+	const op errors.Op = "facade.Service.SetRigMode"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	cfg := s.catService().RigConfig()
+	code, ok := rigStateCode(cfg, tags.MainMode, func(v string) bool { return strings.EqualFold(v, strings.TrimSpace(mode)) })
+	if !ok {
+		return errors.New(op).Msgf("The rig does not support the mode: %q", mode)
+	}
+
+	if err := s.enqueueRigCommand(cfg, catCmdSetMode, code); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the rig mode")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SetRigFilter sets the rig's receive filter. The code is the rig's own filter setting and is passed through as given.
+func (s *Service) SetRigFilter(code string) error {
+	const op errors.Op = "facade.Service.SetRigFilter"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !validFilterCode(code) {
+		return errors.New(op).Msgf("Invalid filter code: %q", code)
+	}
+
+	if err := s.enqueueRigCommand(s.catService().RigConfig(), catCmdSetFilter, code); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the rig filter")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SelectRigVfo makes VFO "A" or "B" the active VFO.
+func (s *Service) SelectRigVfo(vfo string) error {
+	const op errors.Op = "facade.Service.SelectRigVfo"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	want := normalizeVfo(vfo)
+	if want != vfoA && want != vfoB {
+		return errors.New(op).Msgf("Invalid VFO: %q", vfo)
+	}
+
+	cfg := s.catService().RigConfig()
+	code, ok := rigStateCode(cfg, tags.Select, func(v string) bool { return normalizeVfo(v) == want })
+	if !ok {
+		return errors.New(op).Msgf("The rig config has no code for selecting VFO %s", want)
+	}
+
+	if err := s.enqueueRigCommand(cfg, catCmdSetVfo, code); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to select the rig VFO")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SetRigSplit turns split operation on or off.
+func (s *Service) SetRigSplit(on bool) error {
+	const op errors.Op = "facade.Service.SetRigSplit"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	cfg := s.catService().RigConfig()
+	code, ok := rigSplitCode(cfg, on)
+	if !ok {
+		return errors.New(op).Msg("The rig config has no code for setting split")
+	}
+
+	if err := s.enqueueRigCommand(cfg, catCmdSetSplit, code); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the rig split")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// SetRigPtt keys (on) or unkeys (off) the transmitter. A transmitter left keyed is unkeyed after the PTT timeout of
+// the rig control options, and when the service stops. The new state is confirmed from the rig's answer to the read
+// that follows the command; rigs whose config does not read the PTT state can only confirm that they answered.
+func (s *Service) SetRigPtt(on bool) error {
+	const op errors.Op = "facade.Service.SetRigPtt"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	if err := s.setRigPtt(on); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the rig PTT")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// setRigPtt keys or unkeys the transmitter, arming or disarming the PTT timeout, and waits for the rig to confirm.
+func (s *Service) setRigPtt(on bool) error {
+	const op errors.Op = "facade.Service.setRigPtt"
+
+	name := catCmdPttOff
+	if on {
+		name = catCmdPttOn
+	}
+
+	opts := s.options.RigControl
+	if !on {
+		s.ptt.unkey()
+	}
+
+	cfg := s.catService().RigConfig()
+	// Take the update channel before the command, so the answer to its read is not missed.
+	_, _, changed := s.catState.get()
+	if err := s.enqueueRigCommand(cfg, name); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	if on {
+		s.ptt.key(time.Duration(opts.PttTimeoutSeconds)*time.Second, s.pttTimedOut)
+	}
+
+	if err := s.confirmRigPtt(cfg, on, changed, time.Duration(opts.PttConfirmWaitMs)*time.Millisecond); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// confirmRigPtt waits for the rig to report the given PTT state in an update after the changed channel was taken.
+// If the rig config does not read the PTT state, any update confirms that the rig answered.
+func (s *Service) confirmRigPtt(cfg types.RigConfig, on bool, changed <-chan struct{}, wait time.Duration) error {
+	const op errors.Op = "facade.Service.confirmRigPtt"
+
+	want := "OFF"
+	if on {
+		want = "ON"
+	}
+	_, readsPtt := catStateMarker(cfg, catTagPtt)
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-changed:
+		case <-timeout.C:
+			if readsPtt {
+				return errors.New(op).Msgf("The rig did not report PTT %s", want)
+			}
+			return errors.New(op).Msg("The rig did not answer")
+		}
+
+		var status types.CatStatus
+		status, _, changed = s.catState.get()
+		if !readsPtt || strings.EqualFold(strings.TrimSpace(status[catTagPtt.String()]), want) {
+			return nil
+		}
+	}
+}
+
+// pttTimedOut unkeys a transmitter that was left keyed for longer than the PTT timeout.
+func (s *Service) pttTimedOut() {
+	s.LoggerService.WarnWith().Int("timeout_seconds", s.options.RigControl.PttTimeoutSeconds).
+		Msg("The transmitter was keyed for longer than the PTT timeout; unkeying it")
+	if err := s.setRigPtt(false); err != nil {
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to unkey the rig after the PTT timeout")
+	}
+}
+
+// pttWatchdog tracks whether the transmitter was keyed with SetRigPtt, and unkeys it after a timeout. The zero value
+// is ready for use.
+type pttWatchdog struct {
+	mu    sync.Mutex
+	keyed bool
+	timer *time.Timer
+	// generation tells a timer that fires after it was replaced or stopped that it is stale.
+	generation uint64
+}
+
+// key records that the transmitter is keyed and (re)starts the timeout; a zero timeout never unkeys it.
+func (w *pttWatchdog) key(timeout time.Duration, unkey func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked()
+	w.keyed = true
+	if timeout <= 0 {
+		return
+	}
+	generation := w.generation
+	w.timer = time.AfterFunc(timeout, func() {
+		w.mu.Lock()
+		current := generation == w.generation
+		w.mu.Unlock()
+		if current {
+			unkey()
+		}
+	})
+}
+
+// unkey records that the transmitter is unkeyed and stops the timeout. It reports whether it was keyed.
+func (w *pttWatchdog) unkey() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopLocked()
+	keyed := w.keyed
+	w.keyed = false
+	return keyed
+}
+
+func (w *pttWatchdog) stopLocked() {
+	w.generation++
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// enqueueRigCommand enqueues a rig control command, followed by a read so the frontend picks up the new state.
+func (s *Service) enqueueRigCommand(cfg types.RigConfig, name cmds.CatCmdName, params ...string) error {
+	const op errors.Op = "facade.Service.enqueueRigCommand"
+
+	if !hasCatCommand(cfg, name) {
+		return errors.New(op).Msgf("The rig config has no %s command", name)
+	}
+
+	if err := s.catService().EnqueueCommand(name, params...); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	if err := s.catService().EnqueueCommand(cmds.Read); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// rigCapabilities works out the supported controls from the rig config.
+func rigCapabilities(cfg types.RigConfig) RigCapabilities {
+	caps := RigCapabilities{
+		Rig:        cfg.Name,
+		Frequency:  hasCatCommand(cfg, catCmdSetFreq),
+		FrequencyB: hasCatCommand(cfg, catCmdSetFreqB),
+		Modes:      make([]string, 0),
+		Filter:     hasCatCommand(cfg, catCmdSetFilter),
+		Vfos:       make([]string, 0),
+		Ptt:        hasCatCommand(cfg, catCmdPttOn) && hasCatCommand(cfg, catCmdPttOff),
+	}
+
+	if m, ok := catStateMarker(cfg, tags.MainMode); ok && hasCatCommand(cfg, catCmdSetMode) {
+		for _, vm := range m.ValueMappings {
+			caps.Modes = append(caps.Modes, vm.Value)
+		}
+	}
+
+	if hasCatCommand(cfg, catCmdSetVfo) {
+		for _, vfo := range []string{vfoA, vfoB} {
+			if _, ok := rigStateCode(cfg, tags.Select, func(v string) bool { return normalizeVfo(v) == vfo }); ok {
+				caps.Vfos = append(caps.Vfos, vfo)
+			}
+		}
+	}
+
+	if hasCatCommand(cfg, catCmdSetSplit) {
+		_, onOk := rigSplitCode(cfg, true)
+		_, offOk := rigSplitCode(cfg, false)
+		caps.Split = onOk && offOk
+	}
+
+	return caps
+}
+
+// rigStateCode returns the rig's code for the first value of the tagged state that matches.
+func rigStateCode(cfg types.RigConfig, tag tags.CatStateTag, match func(value string) bool) (string, bool) {
+	m, ok := catStateMarker(cfg, tag)
+	if !ok {
+		return "", false
+	}
+	for _, vm := range m.ValueMappings {
+		if match(vm.Value) {
+			return vm.Key, true
+		}
+	}
+	return "", false
+}
+
+// rigSplitCode returns the rig's code for turning split on or off, from the values of its split state.
+func rigSplitCode(cfg types.RigConfig, on bool) (string, bool) {
+	want := "OFF"
+	if on {
+		want = "ON"
+	}
+	return rigStateCode(cfg, tags.Split, func(v string) bool { return strings.EqualFold(strings.TrimSpace(v), want) })
+}
+
+// normalizeVfo reduces the ways of naming a VFO ("a", "VFO-A", "VFO A") to "A" or "B", so the frontend's and the
+// rig config's names can be compared.
+func normalizeVfo(vfo string) string {
+	v := strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(vfo))
+	return strings.TrimPrefix(v, "VFO")
+}
+
+// validFilterCode reports whether a filter code is short and alphanumeric, so it cannot inject further commands.
+func validFilterCode(code string) bool {
+	if code == "" || len(code) > maxFilterCodeLength {
+		return false
+	}
+	for _, r := range code {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,353 @@
+package facade
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// testRigControlConfig extends testRigConfig with the rig control commands and the VFO select and split states.
+func testRigControlConfig() types.RigConfig {
+	cfg := testRigConfig()
+	cfg.Name = "FTdx10"
+	cfg.CatCommands = append(cfg.CatCommands,
+		types.CatCommand{Name: catCmdSetFreqB.String(), Cmd: "FB%s;"},
+		types.CatCommand{Name: catCmdSetFilter.String(), Cmd: "SH0%s;"},
+		types.CatCommand{Name: catCmdSetVfo.String(), Cmd: "VS%s;"},
+		types.CatCommand{Name: catCmdSetSplit.String(), Cmd: "ST%s;"},
+		types.CatCommand{Name: catCmdPttOn.String(), Cmd: "TX1;"},
+		types.CatCommand{Name: catCmdPttOff.String(), Cmd: "TX0;"},
+	)
+	cfg.CatStates = append(cfg.CatStates,
+		types.CatState{Prefix: "ST", Markers: []types.Marker{{
+			Tag:    tags.Split.String(),
+			Length: 1,
+			ValueMappings: []types.ValueMapping{
+				{Key: "0", Value: "OFF"},
+				{Key: "1", Value: "ON"},
+				{Key: "2", Value: "ON+"},
+			},
+		}}},
+		types.CatState{Prefix: "VS", Markers: []types.Marker{{
+			Tag:    tags.Select.String(),
+			Length: 1,
+			ValueMappings: []types.ValueMapping{
+				{Key: "0", Value: "VFO-A"},
+				{Key: "1", Value: "VFO-B"},
+			},
+		}}},
+	)
+	return cfg
+}
+
+// =============================================================================
+// Guard Tests
+// =============================================================================
+
+func TestRigControl_NotInitialized(t *testing.T) {
+	s := createTestService()
+
+	if _, err := s.FetchRigCapabilities(); err == nil {
+		t.Error("FetchRigCapabilities() should fail when service not initialized")
+	}
+	if err := s.SetRigFrequency("A", 14025); err == nil {
+		t.Error("SetRigFrequency() should fail when service not initialized")
+	}
+	if err := s.SetRigMode("USB"); err == nil {
+		t.Error("SetRigMode() should fail when service not initialized")
+	}
+	if err := s.SetRigFilter("10"); err == nil {
+		t.Error("SetRigFilter() should fail when service not initialized")
+	}
+	if err := s.SelectRigVfo("B"); err == nil {
+		t.Error("SelectRigVfo() should fail when service not initialized")
+	}
+	if err := s.SetRigSplit(true); err == nil {
+		t.Error("SetRigSplit() should fail when service not initialized")
+	}
+	if err := s.SetRigPtt(false); err == nil {
+		t.Error("SetRigPtt() should fail when service not initialized")
+	}
+}
+
+func TestRigControl_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+
+	if _, err := s.FetchRigCapabilities(); err == nil {
+		t.Error("FetchRigCapabilities() should fail when service not started")
+	}
+	if err := s.SetRigFrequency("A", 14025); err == nil {
+		t.Error("SetRigFrequency() should fail when service not started")
+	}
+	if err := s.SetRigPtt(false); err == nil {
+		t.Error("SetRigPtt() should fail when service not started")
+	}
+}
+
+// =============================================================================
+// Validation Tests
+// =============================================================================
+
+func TestRigControl_UnsupportedByRigConfig(t *testing.T) {
+	// The test CAT service has no rig config, so every control is unsupported.
+	s := createStartedTestService()
+
+	caps, err := s.FetchRigCapabilities()
+	if err != nil {
+		t.Fatalf("FetchRigCapabilities() error = %v", err)
+	}
+	if caps.Frequency || caps.FrequencyB || caps.Filter || caps.Split || caps.Ptt || len(caps.Modes) != 0 || len(caps.Vfos) != 0 {
+		t.Errorf("FetchRigCapabilities() = %+v, want nothing supported", caps)
+	}
+
+	if err = s.SetRigFrequency("A", 14025); err == nil {
+		t.Error("SetRigFrequency() should fail without a SETFREQ command")
+	}
+	if err = s.SetRigMode("USB"); err == nil {
+		t.Error("SetRigMode() should fail without a main mode state")
+	}
+	if err = s.SetRigFilter("10"); err == nil {
+		t.Error("SetRigFilter() should fail without a SETFILTER command")
+	}
+	if err = s.SelectRigVfo("B"); err == nil {
+		t.Error("SelectRigVfo() should fail without a VFO select state")
+	}
+	if err = s.SetRigSplit(true); err == nil {
+		t.Error("SetRigSplit() should fail without a split state")
+	}
+	if err = s.SetRigPtt(true); err == nil {
+		t.Error("SetRigPtt() should fail without a PTTON command")
+	}
+}
+
+func TestSetRigFrequency_InvalidInput(t *testing.T) {
+	s := createStartedTestService()
+
+	tests := []struct {
+		name string
+		vfo  string
+		khz  float64
+	}{
+		{"unknown vfo", "C", 14025},
+		{"empty vfo", "", 14025},
+		{"zero frequency", "A", 0},
+		{"negative frequency", "B", -7000},
+		{"too many digits", "A", 1_300_000_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.SetRigFrequency(tt.vfo, tt.khz); err == nil {
+				t.Errorf("SetRigFrequency(%q, %v) should fail", tt.vfo, tt.khz)
+			}
+		})
+	}
+}
+
+func TestSetRigFilter_InvalidCode(t *testing.T) {
+	s := createStartedTestService()
+	for _, code := range []string{"", "12345", "1;TX1", "-1"} {
+		if err := s.SetRigFilter(code); err == nil {
+			t.Errorf("SetRigFilter(%q) should fail", code)
+		}
+	}
+}
+
+// =============================================================================
+// PTT Tests
+// =============================================================================
+
+// createPttTestService returns a service whose rig reports the PTT state given by report for each PTT command, in
+// answer to the read that follows it. An empty report leaves the rig silent.
+func createPttTestService(readsPtt bool, report func(on bool) string) (*Service, func() []cmds.CatCmdName) {
+	s := createPrefillTestService()
+	s.options.RigControl.PttConfirmWaitMs = 200
+
+	cfg := testRigControlConfig()
+	if readsPtt {
+		cfg.CatStates = append(cfg.CatStates, types.CatState{Prefix: "TX", Markers: []types.Marker{{
+			Tag:           catTagPtt.String(),
+			Length:        1,
+			ValueMappings: []types.ValueMapping{{Key: "0", Value: "OFF"}, {Key: "1", Value: "ON"}},
+		}}})
+	}
+
+	var mu sync.Mutex
+	var sent []cmds.CatCmdName
+	var last bool
+	s.altCat = &stubCatBackend{rig: cfg, onEnqueue: func(name cmds.CatCmdName, _ ...string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, name)
+		switch name {
+		case catCmdPttOn, catCmdPttOff:
+			last = name == catCmdPttOn
+		case cmds.Read:
+			if state := report(last); state != "" {
+				go s.catState.update(types.CatStatus{catTagPtt.String(): state}, time.Now())
+			}
+		}
+		return nil
+	}}
+	return s, func() []cmds.CatCmdName {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(sent)
+	}
+}
+
+func TestSetRigPtt_Confirmation(t *testing.T) {
+	tests := []struct {
+		name     string
+		readsPtt bool
+		report   func(on bool) string
+		wantErr  bool
+	}{
+		{name: "confirmed", readsPtt: true, report: func(on bool) string { return map[bool]string{true: "ON", false: "OFF"}[on] }},
+		{name: "rig stays unkeyed", readsPtt: true, report: func(bool) string { return "OFF" }, wantErr: true},
+		{name: "rig silent", readsPtt: true, report: func(bool) string { return "" }, wantErr: true},
+		{name: "state not read, rig answers", report: func(bool) string { return "?" }},
+		{name: "state not read, rig silent", report: func(bool) string { return "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, sent := createPttTestService(tt.readsPtt, tt.report)
+			err := s.SetRigPtt(true)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetRigPtt(true) error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := sent(); len(got) != 2 || got[0] != catCmdPttOn || got[1] != cmds.Read {
+				t.Errorf("sent %v, want PTTON then a read", got)
+			}
+			// Whatever the rig reported, the transmitter is taken to be keyed until it is unkeyed.
+			if !s.ptt.unkey() {
+				t.Error("the transmitter was not recorded as keyed")
+			}
+		})
+	}
+}
+
+func TestSetRigPtt_Timeout(t *testing.T) {
+	s, sent := createPttTestService(true, func(on bool) string { return map[bool]string{true: "ON", false: "OFF"}[on] })
+	s.options.RigControl.PttTimeoutSeconds = 1
+
+	if err := s.SetRigPtt(true); err != nil {
+		t.Fatalf("SetRigPtt(true) error = %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for !slices.Contains(sent(), catCmdPttOff) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !slices.Contains(sent(), catCmdPttOff) {
+		t.Fatal("the transmitter was not unkeyed after the PTT timeout")
+	}
+	if s.ptt.unkey() {
+		t.Error("the transmitter is still recorded as keyed after the PTT timeout")
+	}
+}
+
+func TestPttWatchdog(t *testing.T) {
+	var w pttWatchdog
+	fired := make(chan struct{}, 2)
+
+	// Unkeying in time stops the timeout.
+	w.key(20*time.Millisecond, func() { fired <- struct{}{} })
+	if !w.unkey() {
+		t.Error("unkey() = false after key()")
+	}
+	if w.unkey() {
+		t.Error("unkey() = true when already unkeyed")
+	}
+
+	// Keying again restarts the timeout; only the last one fires.
+	w.key(20*time.Millisecond, func() { fired <- struct{}{} })
+	w.key(50*time.Millisecond, func() { fired <- struct{}{} })
+	time.Sleep(150 * time.Millisecond)
+	if n := len(fired); n != 1 {
+		t.Errorf("timeout fired %d times, want 1", n)
+	}
+
+	// A zero timeout never unkeys.
+	w.key(0, func() { fired <- struct{}{} })
+	if !w.unkey() {
+		t.Error("unkey() = false after key() without a timeout")
+	}
+}
+
+// =============================================================================
+// Helper Tests
+// =============================================================================
+
+func TestRigCapabilities(t *testing.T) {
+	caps := rigCapabilities(testRigControlConfig())
+
+	if caps.Rig != "FTdx10" || !caps.Frequency || !caps.FrequencyB || !caps.Filter || !caps.Split || !caps.Ptt {
+		t.Errorf("rigCapabilities() = %+v, want all controls supported", caps)
+	}
+	if !slices.Equal(caps.Vfos, []string{"A", "B"}) {
+		t.Errorf("Vfos = %v, want [A B]", caps.Vfos)
+	}
+	if !slices.Equal(caps.Modes, []string{"LSB", "USB", "CW-U", "FM", "RTTY-L", "DATA-U"}) {
+		t.Errorf("Modes = %v", caps.Modes)
+	}
+
+	// A rig without the PTTOFF command must not be offered PTT, or it could not be unkeyed.
+	cfg := testRigControlConfig()
+	cfg.CatCommands = slices.DeleteFunc(cfg.CatCommands, func(c types.CatCommand) bool { return c.Name == catCmdPttOff.String() })
+	if rigCapabilities(cfg).Ptt {
+		t.Error("rigCapabilities() should not offer PTT without a PTTOFF command")
+	}
+}
+
+func TestRigSplitCode(t *testing.T) {
+	cfg := testRigControlConfig()
+	if code, ok := rigSplitCode(cfg, true); !ok || code != "1" {
+		t.Errorf("rigSplitCode(on) = %q, %v; want \"1\", true", code, ok)
+	}
+	if code, ok := rigSplitCode(cfg, false); !ok || code != "0" {
+		t.Errorf("rigSplitCode(off) = %q, %v; want \"0\", true", code, ok)
+	}
+}
+
+func TestNormalizeVfo(t *testing.T) {
+	tests := map[string]string{
+		"A":     "A",
+		"b":     "B",
+		"VFO-A": "A",
+		"vfo b": "B",
+		"VFOA":  "A",
+		" a ":   "A",
+		"MAIN":  "MAIN",
+	}
+	for in, want := range tests {
+		if got := normalizeVfo(in); got != want {
+			t.Errorf("normalizeVfo(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidFilterCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"0", true},
+		{"23", true},
+		{"A", true},
+		{"1234", true},
+		{"", false},
+		{"12345", false},
+		{"1;", false},
+		{"a", false}, // callers upper-case first
+	}
+	for _, tt := range tests {
+		if got := validFilterCode(tt.code); got != tt.want {
+			t.Errorf("validFilterCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
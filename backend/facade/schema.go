@@ -0,0 +1,113 @@
+package facade
+
+import (
+	"context"
+
+	"github.com/Station-Manager/errors"
+)
+
+// appSchema holds the DDL for the tables owned by the logging app itself, as opposed to the core tables that are
+// managed by the database module's migrations. Every statement MUST be idempotent as the full list is executed each
+// time the database is opened.
+var appSchema = []string{
+	// Paper QSL card tracking. One row per QSO; a QSO without a row has not been touched by the QSL workflow.
+	`CREATE TABLE IF NOT EXISTS qsl_card
+(
+    qso_id      INTEGER  NOT NULL PRIMARY KEY,
+    created_at  DATETIME NOT NULL DEFAULT (datetime('now', 'localtime')),
+    modified_at DATETIME,
+    sent_status TEXT     NOT NULL DEFAULT 'N' CHECK (sent_status IN ('Y', 'N', 'R', 'Q', 'I')),
+    sent_via    TEXT     NOT NULL DEFAULT '' CHECK (sent_via IN ('', 'B', 'D', 'E', 'M')),
+    sent_date   TEXT     NOT NULL DEFAULT '' CHECK (sent_date = '' OR length(sent_date) = 8),
+    rcvd_status TEXT     NOT NULL DEFAULT 'N' CHECK (rcvd_status IN ('Y', 'N', 'R', 'I', 'V')),
+    rcvd_via    TEXT     NOT NULL DEFAULT '' CHECK (rcvd_via IN ('', 'B', 'D', 'E', 'M')),
+    rcvd_date   TEXT     NOT NULL DEFAULT '' CHECK (rcvd_date = '' OR length(rcvd_date) = 8),
+    manager     TEXT     NOT NULL DEFAULT '' CHECK (length(manager) <= 20),
+    printed_at  DATETIME,
+    CONSTRAINT fk_qsl_card_qso FOREIGN KEY (qso_id) REFERENCES qso (id) ON DELETE CASCADE
+)`,
+	`CREATE INDEX IF NOT EXISTS idx_qsl_card_queue ON qsl_card (sent_status) WHERE sent_status IN ('R', 'Q')`,
+
+	// Electronic confirmations (LoTW, eQSL). Paper QSL confirmations are held in qsl_card.
+	`CREATE TABLE IF NOT EXISTS qso_confirmation
+(
+    qso_id     INTEGER  NOT NULL,
+    source     TEXT     NOT NULL CHECK (source IN ('LOTW', 'EQSL')),
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'localtime')),
+    PRIMARY KEY (qso_id, source),
+    CONSTRAINT fk_qso_confirmation_qso FOREIGN KEY (qso_id) REFERENCES qso (id) ON DELETE CASCADE
+)`,
+
+	// Award references set by the operator, overriding the reference derived from the QSO (and the only source of
+	// WAS states).
+	`CREATE TABLE IF NOT EXISTS qso_award_ref
+(
+    qso_id    INTEGER NOT NULL,
+    award     TEXT    NOT NULL CHECK (award IN ('DXCC', 'WAS', 'WAZ', 'IOTA', 'GRID')),
+    reference TEXT    NOT NULL CHECK (length(reference) BETWEEN 1 AND 20),
+    PRIMARY KEY (qso_id, award),
+    CONSTRAINT fk_qso_award_ref_qso FOREIGN KEY (qso_id) REFERENCES qso (id) ON DELETE CASCADE
+)`,
+
+	// Audit trail of QSO changes. The snapshots are the QSO as JSON before and after the change, NULL where the QSO
+	// did not exist or was deleted. There is deliberately no foreign key, so the history outlives the QSO.
+	`CREATE TABLE IF NOT EXISTS qso_audit
+(
+    id          INTEGER  NOT NULL PRIMARY KEY AUTOINCREMENT,
+    qso_id      INTEGER  NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT (datetime('now', 'localtime')),
+    action      TEXT     NOT NULL CHECK (action IN ('insert', 'update', 'delete', 'revert')),
+    operator    TEXT     NOT NULL DEFAULT '',
+    before      TEXT,
+    after       TEXT,
+    diff        TEXT     NOT NULL DEFAULT '{}',
+    reverted_to INTEGER
+)`,
+	`CREATE INDEX IF NOT EXISTS idx_qso_audit_qso ON qso_audit (qso_id, id)`,
+
+	// Registry of the operators of the station, for multi-operator events.
+	`CREATE TABLE IF NOT EXISTS operator
+(
+    callsign    TEXT     NOT NULL PRIMARY KEY CHECK (length(callsign) BETWEEN 1 AND 20),
+    name        TEXT     NOT NULL DEFAULT '' CHECK (length(name) <= 64),
+    created_at  DATETIME NOT NULL DEFAULT (datetime('now', 'localtime')),
+    modified_at DATETIME
+)`,
+}
+
+// migrateAppSchema applies the app-owned schema. It must be called after the core migrations have run.
+func (s *Service) migrateAppSchema() error {
+	const op errors.Op = "facade.Service.migrateAppSchema"
+
+	ctx := s.dbContext()
+	for _, stmt := range appSchema {
+		if _, err := s.DatabaseService.ExecContext(ctx, stmt); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+
+	return nil
+}
+
+// dbContext returns the service context if available, otherwise it falls back to the background context.
+func (s *Service) dbContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// inPlaceholders returns a comma-separated list of SQL placeholders, one per ID, along with the IDs as query
+// arguments, for use in an "IN (...)" clause.
+func inPlaceholders(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	buf := make([]byte, 0, len(ids)*2)
+	for i, id := range ids {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '?')
+		args[i] = id
+	}
+	return string(buf), args
+}
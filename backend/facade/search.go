@@ -0,0 +1,152 @@
+package facade
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+const (
+	// searchDefaultLimit and searchMaxLimit bound the QSOs returned by SearchQsos.
+	searchDefaultLimit = 100
+	searchMaxLimit     = 1000
+	// searchDateLayout is the layout of the date bounds of a search, as in qso_date.
+	searchDateLayout = "20060102"
+)
+
+// QsoSearch selects QSOs in the current logbook. Empty fields match any QSO. A call ending in "*" matches the
+// calls that start with the rest, e.g. "DL*".
+type QsoSearch struct {
+	Call  string `json:"call"`
+	Band  string `json:"band"`
+	Mode  string `json:"mode"`
+	From  string `json:"from"` // YYYYMMDD, inclusive
+	To    string `json:"to"`   // YYYYMMDD, inclusive
+	Limit int    `json:"limit"`
+}
+
+// SearchQsos returns the QSOs in the current logbook that match the search, most recent first.
+func (s *Service) SearchQsos(search QsoSearch) ([]types.Qso, error) {
+	const op errors.Op = "facade.Service.SearchQsos"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	if err := search.normalize(); err != nil {
+		return nil, errors.Root(errors.New(op).Err(err))
+	}
+
+	qsos, err := s.searchQsos(search)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to search QSOs")
+		return nil, errors.Root(err)
+	}
+
+	return qsos, nil
+}
+
+// normalize checks the search and brings its fields to the form they are stored in.
+func (q *QsoSearch) normalize() error {
+	const op errors.Op = "facade.QsoSearch.normalize"
+
+	q.Call = strings.ToUpper(strings.TrimSpace(q.Call))
+	q.Band = strings.ToLower(strings.TrimSpace(q.Band))
+	q.Mode = strings.ToUpper(strings.TrimSpace(q.Mode))
+	for _, d := range []*string{&q.From, &q.To} {
+		*d = strings.TrimSpace(*d)
+		if _, err := time.Parse(searchDateLayout, *d); *d != "" && err != nil {
+			return errors.New(op).Msgf("Invalid date: %q, want YYYYMMDD", *d)
+		}
+	}
+	switch {
+	case q.Limit < 0:
+		return errors.New(op).Msgf("Invalid limit: %d", q.Limit)
+	case q.Limit == 0:
+		q.Limit = searchDefaultLimit
+	case q.Limit > searchMaxLimit:
+		q.Limit = searchMaxLimit
+	}
+	return nil
+}
+
+// searchQsos runs a normalized search.
+func (s *Service) searchQsos(search QsoSearch) ([]types.Qso, error) {
+	const op errors.Op = "facade.Service.searchQsos"
+
+	query := `SELECT id FROM qso WHERE logbook_id = ? AND deleted_at IS NULL`
+	args := []any{s.CurrentLogbook.ID}
+	if prefix, ok := strings.CutSuffix(search.Call, "*"); ok {
+		query += ` AND upper(call) LIKE ? ESCAPE '\'`
+		args = append(args, escapeLike(prefix)+"%")
+	} else if search.Call != "" {
+		query += ` AND upper(call) = ?`
+		args = append(args, search.Call)
+	}
+	if search.Band != "" {
+		query += ` AND lower(band) = ?`
+		args = append(args, search.Band)
+	}
+	if search.Mode != "" {
+		query += ` AND upper(mode) = ?`
+		args = append(args, search.Mode)
+	}
+	if search.From != "" {
+		query += ` AND qso_date >= ?`
+		args = append(args, search.From)
+	}
+	if search.To != "" {
+		query += ` AND qso_date <= ?`
+		args = append(args, search.To)
+	}
+	query += ` ORDER BY qso_date DESC, time_on DESC, id DESC LIMIT ?`
+	args = append(args, search.Limit)
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, args...)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, errors.New(op).Err(err)
+		}
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	_ = rows.Close()
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	qsos := make([]types.Qso, 0, len(ids))
+	for _, id := range ids {
+		qso, err := s.DatabaseService.FetchQsoById(id)
+		if err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		// Merged so that a QSO edited from the results keeps its QSL card.
+		if err = s.mergeQslIntoQso(&qso); err != nil {
+			return nil, errors.New(op).Err(err)
+		}
+		qsos = append(qsos, qso)
+	}
+	return qsos, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, with a backslash as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,88 @@
+package facade
+
+import (
+	"testing"
+)
+
+// =============================================================================
+// Normalization Tests
+// =============================================================================
+
+func TestQsoSearch_Normalize(t *testing.T) {
+	q := QsoSearch{Call: " dl* ", Band: "20M", Mode: "cw", From: "20240101"}
+	if err := q.normalize(); err != nil {
+		t.Fatalf("normalize() error = %v", err)
+	}
+	want := QsoSearch{Call: "DL*", Band: "20m", Mode: "CW", From: "20240101", Limit: searchDefaultLimit}
+	if q != want {
+		t.Errorf("normalize() = %+v, want %+v", q, want)
+	}
+
+	q = QsoSearch{Limit: searchMaxLimit + 1}
+	if err := q.normalize(); err != nil || q.Limit != searchMaxLimit {
+		t.Errorf("normalize() limit = %d, %v, want %d", q.Limit, err, searchMaxLimit)
+	}
+
+	for _, bad := range []QsoSearch{{From: "2024-01-01"}, {To: "20241301"}, {Limit: -1}} {
+		if err := bad.normalize(); err == nil {
+			t.Errorf("normalize(%+v) should fail", bad)
+		}
+	}
+}
+
+// =============================================================================
+// Search Tests
+// =============================================================================
+
+func TestSearchQsos(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	insertTestQso(t, s, "DL1ABC", "20m", "CW")
+	insertTestQso(t, s, "DL2XYZ", "40m", "SSB")
+	insertTestQso(t, s, "D_1ABC", "20m", "CW")
+	later := newTestQso(s, "G4ABC", "20m", "CW")
+	later.QsoDate = "20240315"
+	insertTestQsoValue(t, s, later)
+	deleted := insertTestQso(t, s, "DL3DEL", "20m", "CW")
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), "UPDATE qso SET deleted_at = datetime('now') WHERE id = ?", deleted); err != nil {
+		t.Fatalf("ExecContext() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		search QsoSearch
+		want   []string
+	}{
+		{"all, most recent first", QsoSearch{}, []string{"G4ABC", "D_1ABC", "DL2XYZ", "DL1ABC"}},
+		{"exact call", QsoSearch{Call: "dl1abc"}, []string{"DL1ABC"}},
+		{"prefix", QsoSearch{Call: "DL*"}, []string{"DL2XYZ", "DL1ABC"}},
+		{"prefix with a LIKE wildcard", QsoSearch{Call: "D_*"}, []string{"D_1ABC"}},
+		{"band and mode", QsoSearch{Band: "20M", Mode: "cw"}, []string{"G4ABC", "D_1ABC", "DL1ABC"}},
+		{"date range", QsoSearch{From: "20240310", To: "20240331"}, []string{"G4ABC"}},
+		{"limit", QsoSearch{Limit: 1}, []string{"G4ABC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qsos, err := s.SearchQsos(tt.search)
+			if err != nil {
+				t.Fatalf("SearchQsos() error = %v", err)
+			}
+			got := make([]string, 0, len(qsos))
+			for _, qso := range qsos {
+				got = append(got, qso.Call)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SearchQsos() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("SearchQsos() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := s.SearchQsos(QsoSearch{From: "yesterday"}); err == nil {
+		t.Error("SearchQsos() should fail for an invalid date")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Station-Manager/iocdi"
 	"github.com/Station-Manager/listeners"
 	"github.com/Station-Manager/logging"
+	"github.com/Station-Manager/logging-app/backend/restapi"
 	"github.com/Station-Manager/lookup/hamnut"
 	"github.com/Station-Manager/lookup/qrz"
 	"github.com/Station-Manager/types"
@@ -65,6 +66,52 @@ type Service struct {
 	mu       sync.Mutex
 
 	validate *validator.Validate
+
+	// statsCache holds the computed statistics per logbook ID; see FetchLogbookStats.
+	statsCache map[int64]LogbookStats
+	statsMu    sync.Mutex
+
+	// rate tracks the QSO rate for the current session; see FetchQsoRate.
+	rate rateMeter
+
+	// operator is the operator switched to with SwitchOperator; the zero value while none has been. operatorMu also
+	// serializes switching the session along with the operator.
+	operator   Operator
+	operatorMu sync.Mutex
+
+	// options holds the app's own settings, loaded from appOptionsFileName on Start.
+	options AppOptions
+	// dxCluster is the DX cluster client for the current run; nil when disabled.
+	dxCluster *dxCluster
+	// altCat is the CAT backend replacing CatService for the current run, e.g., rigctld; nil for the serial
+	// CAT service. See catService.
+	altCat catBackend
+	// catState is the last reported rig state, used to pre-fill new QSOs.
+	catState catSnapshot
+	// catHealth is the state of the connection to the rig; see catHealthSupervisor.
+	catHealth catHealth
+	// ptt unkeys a transmitter keyed with SetRigPtt after the PTT timeout.
+	ptt pttWatchdog
+	// winKeyer is the WinKeyer for the current run; nil when CW is sent with the rig's keyer.
+	winKeyer *winKeyer
+	// n1mm sends the N1MM+ compatible UDP packets for the current run; nil when disabled.
+	n1mm *n1mmBroadcaster
+	// adifListener receives QSOs logged by other programs as ADIF for the current run; nil when disabled.
+	adifListener *adifListener
+	// externalQsoMu serialises the duplicate check and insert of the QSOs from other programs and imports, as
+	// repeated broadcasts of a QSO may arrive on several listeners at once; see logUnlessLogged.
+	externalQsoMu sync.Mutex
+	// wsjtx exchanges messages with WSJT-X instances for the current run; nil when disabled.
+	wsjtx *wsjtxBridge
+	// restApi serves the local HTTP API for the current run; nil when disabled. It is read by every goroutine that
+	// emits events, so it is stored atomically.
+	restApi atomic.Pointer[restapi.Server]
+	// backupMu serialises the backups and restores of the database.
+	backupMu sync.Mutex
+	// dbGate is held by RestoreBackup while the database is closed and swapped, and for reading by the workers
+	// (REST API, ADIF listeners, WSJT-X, DX cluster, forwarding) around their database work, so that they wait for
+	// the swap.
+	dbGate sync.RWMutex
 }
 
 // Initialize sets up the Service instance by verifying required dependencies and initializing its state.
@@ -197,13 +244,28 @@ func (s *Service) Start(ctx context.Context) error {
 		return errors.Root(err)
 	}
 
-	// Start the CAT service
-	if err := s.CatService.Start(); err != nil {
+	options, err := s.loadAppOptions()
+	if err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to load app options, continuing with defaults")
+	}
+	s.options = options
+
+	// Start the CAT service, or the backend selected for the rig
+	if s.altCat, err = s.newCatBackend(s.CatService.RigConfig(), s.options.CatBackends); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to create CAT backend, continuing with the serial CAT service")
+	}
+	if err = s.catService().Start(); err != nil {
 		err = errors.New(op).Err(err)
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to start CAT service.")
 		return errors.Root(err)
 	}
 
+	// The WSJT-X integration is fed by the listeners, so it is created before they start
+	if s.wsjtx, err = newWsjtx(s.options.Wsjtx); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to create WSJT-X server, continuing without it")
+	}
+	s.configureWsjtxListeners()
+
 	// Start the listeners service
 	if err := s.ListenersService.Start(ctx); err != nil {
 		err = errors.New(op).Err(err)
@@ -216,32 +278,64 @@ func (s *Service) Start(ctx context.Context) error {
 	}
 	s.currentRun = run
 
+	s.catState.reset()
 	s.launchWorkerThread(run, s.catStatusChannelListener, "catStatusChannelListener")
+	s.catHealth.reset(s.options.CatHealth.Enabled)
+	if s.options.CatHealth.Enabled {
+		s.launchWorkerThread(run, s.catHealthSupervisor, "catHealthSupervisor")
+	}
 
-	// Create a map of all the configured forwarders
-	cfgs, err := s.ConfigService.ForwarderConfigs()
+	// A new session was generated when the database was opened, so the QSO rate starts afresh.
+	s.rate.reset()
+	s.launchWorkerThread(run, s.qsoRateEmitter, "qsoRateEmitter")
+
+	if s.dxCluster, err = s.newDxCluster(s.options.DxCluster); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to create DX cluster client, continuing without it")
+	}
+	if s.dxCluster != nil {
+		s.launchWorkerThread(run, s.dxClusterWorker, "dxClusterWorker")
+	}
+
+	if s.winKeyer, err = s.newWinKeyer(s.options.CwKeyer); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to create WinKeyer, continuing with the rig's keyer")
+	}
+	if s.winKeyer != nil {
+		s.launchWorkerThread(run, s.winKeyerWorker, "winKeyerWorker")
+	}
+
+	if s.n1mm, err = newN1mmBroadcaster(s.options.N1mm); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to create N1MM broadcaster, continuing without it")
+	}
+	if s.n1mm != nil {
+		s.launchWorkerThread(run, s.n1mmRadioWorker, "n1mmRadioWorker")
+	}
+
+	if s.adifListener, err = newAdifListener(s.options.AdifListener); err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to start ADIF listeners, continuing without them")
+	}
+	if s.adifListener != nil {
+		s.launchWorkerThread(run, s.adifListenerWorker, "adifListenerWorker")
+	}
+
+	if s.wsjtx != nil {
+		s.launchWorkerThread(run, s.wsjtxWorker, "wsjtxWorker")
+	}
+
+	restApi, err := s.newRestApi(s.options.RestApi)
 	if err != nil {
-		s.LoggerService.WarnWith().Err(err).Msg("Failed to get forwarder configs, continuing without forwarders")
-		cfgs = nil
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to start REST API, continuing without it")
+	}
+	s.restApi.Store(restApi)
+	if restApi != nil {
+		s.launchWorkerThread(run, s.restApiWorker, "restApiWorker")
 	}
-	s.forwarders = make(map[string]fwdrs.Forwarder, len(cfgs))
-	for _, cfg := range cfgs {
-		if !cfg.Enabled {
-			continue
-		}
 
-		name := cfg.Name
-		obj, serr := s.container.ResolveSafe(name)
-		if serr != nil {
-			s.LoggerService.WarnWith().Err(serr).Str("name", name).Msg("Failed to resolve forwarder service")
-			continue
-		}
-		fwd, ok := obj.(fwdrs.Forwarder)
-		if !ok {
-			return errors.New(op).Msg("Failed to cast Forwarder service")
-		}
+	if s.options.Backup.Enabled && s.options.Backup.IntervalHours > 0 {
+		s.launchWorkerThread(run, s.backupWorker, "backupWorker")
+	}
 
-		s.forwarders[name] = fwd
+	if err = s.resolveForwarders(); err != nil {
+		return errors.New(op).Err(err)
 	}
 
 	// Update forwarder poll interval from config
@@ -277,6 +371,14 @@ func (s *Service) Stop() error {
 	// Collect non-fatal shutdown errors for reporting
 	var shutdownErrors []error
 
+	// Unkey a transmitter left keyed while the CAT service and its status worker can still confirm it.
+	if s.ptt.unkey() {
+		if err := s.setRigPtt(false); err != nil {
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to unkey the rig")
+			shutdownErrors = append(shutdownErrors, err)
+		}
+	}
+
 	// Take a consistent view of the current run/forwarding state under lock to avoid races with Start.
 	s.mu.Lock()
 	run := s.currentRun
@@ -321,7 +423,7 @@ func (s *Service) Stop() error {
 	}
 
 	// Stop the CAT service
-	if err := s.CatService.Stop(); err != nil {
+	if err := s.catService().Stop(); err != nil {
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to stop CAT service")
 		shutdownErrors = append(shutdownErrors, err)
 	}
@@ -332,8 +434,17 @@ func (s *Service) Stop() error {
 		shutdownErrors = append(shutdownErrors, err)
 	}
 
+	// Back up the database once the workers can no longer write to it
+	if s.options.Backup.OnStop {
+		if _, err := s.backupDatabase("stop"); err != nil {
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to back up database")
+			shutdownErrors = append(shutdownErrors, err)
+		}
+	}
+
 	// Soft-delete the session ID
-	if err := s.DatabaseService.SoftDeleteSessionByID(s.sessionID); err != nil {
+	_, sessionID := s.currentOperatorSession()
+	if err := s.DatabaseService.SoftDeleteSessionByID(sessionID); err != nil {
 		// Not a show-stopper, just log the error
 		s.LoggerService.ErrorWith().Err(err).Msg("Failed to soft-delete session ID")
 		shutdownErrors = append(shutdownErrors, err)
@@ -441,3 +552,36 @@ func (s *Service) StopDatabase() error {
 
 	return nil
 }
+
+// resolveForwarders creates the map of the enabled forwarders from the forwarder configs. A forwarder that cannot be
+// resolved is skipped.
+func (s *Service) resolveForwarders() error {
+	const op errors.Op = "facade.Service.resolveForwarders"
+
+	cfgs, err := s.ConfigService.ForwarderConfigs()
+	if err != nil {
+		s.LoggerService.WarnWith().Err(err).Msg("Failed to get forwarder configs, continuing without forwarders")
+		cfgs = nil
+	}
+	s.forwarders = make(map[string]fwdrs.Forwarder, len(cfgs))
+	for _, cfg := range cfgs {
+		if !cfg.Enabled {
+			continue
+		}
+
+		name := cfg.Name
+		obj, serr := s.container.ResolveSafe(name)
+		if serr != nil {
+			s.LoggerService.WarnWith().Err(serr).Str("name", name).Msg("Failed to resolve forwarder service")
+			continue
+		}
+		fwd, ok := obj.(fwdrs.Forwarder)
+		if !ok {
+			return errors.New(op).Msg("Failed to cast Forwarder service")
+		}
+
+		s.forwarders[name] = fwd
+	}
+
+	return nil
+}
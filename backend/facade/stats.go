@@ -0,0 +1,445 @@
+package facade
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+const (
+	// statsTopCountries is the number of countries reported in LogbookStats.TopCountries.
+	statsTopCountries = 10
+	// statsMaxSessions is the number of most recent sessions summarized in LogbookStats.Sessions.
+	statsMaxSessions = 20
+	// statsTimestampLayout is the layout of a qso_date || time_on value, with time_on cut to HHMM as it may also be
+	// HHMMSS.
+	statsTimestampLayout = "200601021504"
+)
+
+// Statistics dimensions, as returned by the grouped counts query.
+const (
+	statsDimBand      = "band"
+	statsDimMode      = "mode"
+	statsDimContinent = "continent"
+	statsDimHour      = "hour"
+	statsDimCountry   = "country"
+)
+
+// statsContinentExpression derives the continent of a QSO row aliased as "q": the continent recorded with the QSO
+// if set, otherwise the continent of its country.
+const statsContinentExpression = `COALESCE(NULLIF(UPPER(TRIM(json_extract(q.additional_data, '$.cont'))), ''),
+         (SELECT UPPER(c.continent) FROM country c
+          WHERE c.deleted_at IS NULL AND UPPER(TRIM(c.name)) = UPPER(TRIM(q.country)) LIMIT 1), '')`
+
+// statsOperatorExpression derives the operator of a QSO row aliased as "q": the OPERATOR recorded with the QSO if
+// set, otherwise its station callsign, as ADIF treats a QSO without an OPERATOR.
+const statsOperatorExpression = `COALESCE(NULLIF(UPPER(TRIM(json_extract(q.additional_data, '$.operator'))), ''),
+         UPPER(TRIM(COALESCE(json_extract(q.additional_data, '$.station_callsign'), ''))))`
+
+// StatsCount is the number of QSOs for one value of a statistics dimension (a band, mode, continent, hour or country).
+type StatsCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// SessionSummary summarizes the QSOs logged during a single session of the application.
+type SessionSummary struct {
+	SessionID   int64     `json:"session_id"`
+	Current     bool      `json:"current"`
+	Operator    string    `json:"operator"` // the operator of the session's first QSO
+	Qsos        int64     `json:"qsos"`
+	UniqueCalls int64     `json:"unique_calls"`
+	FirstQso    time.Time `json:"first_qso"`
+	LastQso     time.Time `json:"last_qso"`
+	RatePerHour float64   `json:"rate_per_hour"` // over the session's span, with a minimum span of one hour
+	Bands       []string  `json:"bands"`
+	Modes       []string  `json:"modes"`
+}
+
+// OperatorStats summarizes the QSOs logged by a single operator.
+type OperatorStats struct {
+	Operator    string    `json:"operator"`
+	Qsos        int64     `json:"qsos"`
+	UniqueCalls int64     `json:"unique_calls"`
+	DxccCount   int64     `json:"dxcc_count"`
+	Sessions    int64     `json:"sessions"`
+	ActiveHours int64     `json:"active_hours"`
+	RatePerHour float64   `json:"rate_per_hour"` // the average number of QSOs per active hour
+	FirstQso    time.Time `json:"first_qso"`
+	LastQso     time.Time `json:"last_qso"`
+	Bands       []string  `json:"bands"`
+	Modes       []string  `json:"modes"`
+}
+
+// LogbookStats holds the aggregated statistics for a logbook.
+type LogbookStats struct {
+	LogbookID    int64            `json:"logbook_id"`
+	TotalQsos    int64            `json:"total_qsos"`
+	UniqueCalls  int64            `json:"unique_calls"`
+	DxccCount    int64            `json:"dxcc_count"`
+	FirstQso     time.Time        `json:"first_qso"`
+	LastQso      time.Time        `json:"last_qso"`
+	ActiveHours  int64            `json:"active_hours"`  // the number of distinct clock hours with at least one QSO
+	RatePerHour  float64          `json:"rate_per_hour"` // the average number of QSOs per active hour
+	PeakHour     time.Time        `json:"peak_hour"`
+	PeakHourQsos int64            `json:"peak_hour_qsos"`
+	ByBand       []StatsCount     `json:"by_band"`
+	ByMode       []StatsCount     `json:"by_mode"`
+	ByContinent  []StatsCount     `json:"by_continent"`
+	ByHour       []StatsCount     `json:"by_hour"` // UTC hour of day, "00" to "23"
+	TopCountries []StatsCount     `json:"top_countries"`
+	Sessions     []SessionSummary `json:"sessions"`    // most recent first
+	ByOperator   []OperatorStats  `json:"by_operator"` // most QSOs first
+	GeneratedAt  time.Time        `json:"generated_at"`
+}
+
+// FetchLogbookStats returns the aggregated statistics for the given logbook. The statistics are computed in the
+// database and cached until a QSO in the logbook is logged or updated.
+func (s *Service) FetchLogbookStats(logbookId int64) (LogbookStats, error) {
+	const op errors.Op = "facade.Service.FetchLogbookStats"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return LogbookStats{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return LogbookStats{}, errors.Root(err)
+	}
+
+	if logbookId < 1 {
+		err := errors.New(op).Msg("Invalid logbook id")
+		s.LoggerService.ErrorWith().Err(err).Msg("Invalid logbook id")
+		return LogbookStats{}, errors.Root(err)
+	}
+
+	if stats, ok := s.cachedStats(logbookId); ok {
+		return stats, nil
+	}
+
+	stats, err := s.computeLogbookStats(logbookId)
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to compute logbook statistics")
+		return LogbookStats{}, errors.Root(err)
+	}
+
+	s.statsMu.Lock()
+	if s.statsCache == nil {
+		s.statsCache = make(map[int64]LogbookStats)
+	}
+	s.statsCache[logbookId] = stats
+	s.statsMu.Unlock()
+
+	return stats, nil
+}
+
+// cachedStats returns the cached statistics for the logbook, if any.
+func (s *Service) cachedStats(logbookId int64) (LogbookStats, bool) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	stats, ok := s.statsCache[logbookId]
+	return stats, ok
+}
+
+// invalidateStats drops the cached statistics for the logbook. It must be called whenever a QSO in the logbook
+// is inserted, updated or deleted.
+func (s *Service) invalidateStats(logbookId int64) {
+	s.statsMu.Lock()
+	delete(s.statsCache, logbookId)
+	s.statsMu.Unlock()
+}
+
+// computeLogbookStats runs the statistics queries for the logbook.
+func (s *Service) computeLogbookStats(logbookId int64) (LogbookStats, error) {
+	const op errors.Op = "facade.Service.computeLogbookStats"
+
+	stats := LogbookStats{
+		LogbookID:    logbookId,
+		ByBand:       make([]StatsCount, 0),
+		ByMode:       make([]StatsCount, 0),
+		ByContinent:  make([]StatsCount, 0),
+		ByHour:       make([]StatsCount, 0),
+		TopCountries: make([]StatsCount, 0),
+		Sessions:     make([]SessionSummary, 0),
+		ByOperator:   make([]OperatorStats, 0),
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	if err := s.queryStatsTotals(logbookId, &stats); err != nil {
+		return stats, errors.New(op).Err(err)
+	}
+	if stats.TotalQsos == 0 {
+		return stats, nil
+	}
+	if err := s.queryStatsPeakHour(logbookId, &stats); err != nil {
+		return stats, errors.New(op).Err(err)
+	}
+	if err := s.queryStatsCounts(logbookId, &stats); err != nil {
+		return stats, errors.New(op).Err(err)
+	}
+	if err := s.queryStatsSessions(logbookId, &stats); err != nil {
+		return stats, errors.New(op).Err(err)
+	}
+	if err := s.queryStatsOperators(logbookId, &stats); err != nil {
+		return stats, errors.New(op).Err(err)
+	}
+
+	if stats.ActiveHours > 0 {
+		stats.RatePerHour = float64(stats.TotalQsos) / float64(stats.ActiveHours)
+	}
+
+	return stats, nil
+}
+
+// queryStatsTotals fills in the logbook-wide totals.
+func (s *Service) queryStatsTotals(logbookId int64, stats *LogbookStats) error {
+	const op errors.Op = "facade.Service.queryStatsTotals"
+
+	query := `SELECT COUNT(*),
+       COUNT(DISTINCT q.call),
+       COUNT(DISTINCT NULLIF(` + awardRefExpressions[AwardDXCC] + `, '')),
+       COUNT(DISTINCT q.qso_date || substr(q.time_on, 1, 2)),
+       COALESCE(MIN(q.qso_date || substr(q.time_on, 1, 4)), ''),
+       COALESCE(MAX(q.qso_date || substr(q.time_on, 1, 4)), '')
+FROM qso q
+WHERE q.logbook_id = ?1
+  AND q.deleted_at IS NULL`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, logbookId)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var first, last string
+	if rows.Next() {
+		if err = rows.Scan(&stats.TotalQsos, &stats.UniqueCalls, &stats.DxccCount, &stats.ActiveHours, &first, &last); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	stats.FirstQso = parseStatsTimestamp(first)
+	stats.LastQso = parseStatsTimestamp(last)
+
+	return nil
+}
+
+// queryStatsPeakHour fills in the clock hour with the most QSOs. Ties go to the earliest hour.
+func (s *Service) queryStatsPeakHour(logbookId int64, stats *LogbookStats) error {
+	const op errors.Op = "facade.Service.queryStatsPeakHour"
+
+	query := `SELECT q.qso_date || substr(q.time_on, 1, 2) || '00' AS hour, COUNT(*)
+FROM qso q
+WHERE q.logbook_id = ?1
+  AND q.deleted_at IS NULL
+GROUP BY hour
+ORDER BY COUNT(*) DESC, hour
+LIMIT 1`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, logbookId)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hour string
+	if rows.Next() {
+		if err = rows.Scan(&hour, &stats.PeakHourQsos); err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	stats.PeakHour = parseStatsTimestamp(hour)
+
+	return nil
+}
+
+// queryStatsCounts fills in the per-dimension breakdowns with a single grouped query.
+func (s *Service) queryStatsCounts(logbookId int64, stats *LogbookStats) error {
+	const op errors.Op = "facade.Service.queryStatsCounts"
+
+	query := `WITH l AS (SELECT q.band, q.mode, substr(q.time_on, 1, 2) AS hour, TRIM(q.country) AS country,
+                  ` + statsContinentExpression + ` AS continent
+           FROM qso q
+           WHERE q.logbook_id = ?1
+             AND q.deleted_at IS NULL)
+SELECT 'band', band, COUNT(*) FROM l GROUP BY band
+UNION ALL
+SELECT 'mode', mode, COUNT(*) FROM l GROUP BY mode
+UNION ALL
+SELECT 'continent', continent, COUNT(*) FROM l WHERE continent <> '' GROUP BY continent
+UNION ALL
+SELECT 'hour', hour, COUNT(*) FROM l GROUP BY hour
+UNION ALL
+SELECT 'country', country, COUNT(*) FROM l WHERE country <> '' GROUP BY UPPER(country)`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, logbookId)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var dim string
+		var c StatsCount
+		if err = rows.Scan(&dim, &c.Key, &c.Count); err != nil {
+			return errors.New(op).Err(err)
+		}
+		switch dim {
+		case statsDimBand:
+			stats.ByBand = append(stats.ByBand, c)
+		case statsDimMode:
+			stats.ByMode = append(stats.ByMode, c)
+		case statsDimContinent:
+			stats.ByContinent = append(stats.ByContinent, c)
+		case statsDimHour:
+			stats.ByHour = append(stats.ByHour, c)
+		case statsDimCountry:
+			stats.TopCountries = append(stats.TopCountries, c)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	sortStatsCounts(stats.ByBand)
+	sortStatsCounts(stats.ByMode)
+	sortStatsCounts(stats.ByContinent)
+	sortStatsCounts(stats.TopCountries)
+	slices.SortFunc(stats.ByHour, func(a, b StatsCount) int { return cmp.Compare(a.Key, b.Key) })
+	if len(stats.TopCountries) > statsTopCountries {
+		stats.TopCountries = stats.TopCountries[:statsTopCountries]
+	}
+
+	return nil
+}
+
+// queryStatsSessions fills in the summaries of the most recent sessions.
+func (s *Service) queryStatsSessions(logbookId int64, stats *LogbookStats) error {
+	const op errors.Op = "facade.Service.queryStatsSessions"
+
+	query := `WITH l AS (SELECT q.id, q.session_id, q.call, q.band, q.mode, q.qso_date || substr(q.time_on, 1, 4) AS at,
+                  ` + statsOperatorExpression + ` AS operator
+           FROM qso q
+           WHERE q.logbook_id = ?1
+             AND q.deleted_at IS NULL)
+SELECT l.session_id,
+       (SELECT f.operator FROM l f WHERE f.session_id = l.session_id ORDER BY f.at, f.id LIMIT 1),
+       COUNT(*),
+       COUNT(DISTINCT l.call),
+       MIN(l.at),
+       MAX(l.at),
+       GROUP_CONCAT(DISTINCT l.band),
+       GROUP_CONCAT(DISTINCT l.mode)
+FROM l
+GROUP BY l.session_id
+ORDER BY l.session_id DESC
+LIMIT ?2`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, logbookId, statsMaxSessions)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	_, sessionID := s.currentOperatorSession()
+	for rows.Next() {
+		var ss SessionSummary
+		var first, last, bandList, modeList string
+		if err = rows.Scan(&ss.SessionID, &ss.Operator, &ss.Qsos, &ss.UniqueCalls, &first, &last, &bandList, &modeList); err != nil {
+			return errors.New(op).Err(err)
+		}
+		ss.Current = ss.SessionID == sessionID
+		ss.FirstQso = parseStatsTimestamp(first)
+		ss.LastQso = parseStatsTimestamp(last)
+		ss.Bands = strings.Split(bandList, ",")
+		ss.Modes = strings.Split(modeList, ",")
+		ss.RatePerHour = float64(ss.Qsos) / max(ss.LastQso.Sub(ss.FirstQso).Hours(), 1)
+		stats.Sessions = append(stats.Sessions, ss)
+	}
+	if err = rows.Err(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// queryStatsOperators fills in the per-operator summaries.
+func (s *Service) queryStatsOperators(logbookId int64, stats *LogbookStats) error {
+	const op errors.Op = "facade.Service.queryStatsOperators"
+
+	query := `SELECT ` + statsOperatorExpression + ` AS operator,
+       COUNT(*),
+       COUNT(DISTINCT q.call),
+       COUNT(DISTINCT NULLIF(` + awardRefExpressions[AwardDXCC] + `, '')),
+       COUNT(DISTINCT q.session_id),
+       COUNT(DISTINCT q.qso_date || substr(q.time_on, 1, 2)),
+       MIN(q.qso_date || substr(q.time_on, 1, 4)),
+       MAX(q.qso_date || substr(q.time_on, 1, 4)),
+       GROUP_CONCAT(DISTINCT q.band),
+       GROUP_CONCAT(DISTINCT q.mode)
+FROM qso q
+WHERE q.logbook_id = ?1
+  AND q.deleted_at IS NULL
+GROUP BY operator
+ORDER BY COUNT(*) DESC, operator`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, logbookId)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var o OperatorStats
+		var first, last, bandList, modeList string
+		if err = rows.Scan(&o.Operator, &o.Qsos, &o.UniqueCalls, &o.DxccCount, &o.Sessions, &o.ActiveHours,
+			&first, &last, &bandList, &modeList); err != nil {
+			return errors.New(op).Err(err)
+		}
+		o.FirstQso = parseStatsTimestamp(first)
+		o.LastQso = parseStatsTimestamp(last)
+		o.Bands = strings.Split(bandList, ",")
+		o.Modes = strings.Split(modeList, ",")
+		if o.ActiveHours > 0 {
+			o.RatePerHour = float64(o.Qsos) / float64(o.ActiveHours)
+		}
+		stats.ByOperator = append(stats.ByOperator, o)
+	}
+	if err = rows.Err(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// sortStatsCounts sorts by descending count, then by key.
+func sortStatsCounts(counts []StatsCount) {
+	slices.SortFunc(counts, func(a, b StatsCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+}
+
+// parseStatsTimestamp parses a qso_date || HHMM value as UTC, returning the zero time if it is malformed.
+func parseStatsTimestamp(value string) time.Time {
+	t, err := time.Parse(statsTimestampLayout, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
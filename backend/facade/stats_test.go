@@ -0,0 +1,191 @@
+package facade
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Stats Guard Tests
+// =============================================================================
+
+func TestFetchLogbookStats_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchLogbookStats(1); err == nil {
+		t.Error("FetchLogbookStats() should fail when service not initialized")
+	}
+}
+
+func TestFetchLogbookStats_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchLogbookStats(1); err == nil {
+		t.Error("FetchLogbookStats() should fail when service not started")
+	}
+}
+
+func TestFetchLogbookStats_InvalidLogbookId(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.FetchLogbookStats(0); err == nil {
+		t.Error("FetchLogbookStats() should fail for an invalid logbook id")
+	}
+}
+
+// =============================================================================
+// Stats Database Tests
+// =============================================================================
+
+func TestFetchLogbookStats(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	insertTestQso(t, s, "G4ABC", "40m", "CW")
+
+	q := newTestQso(s, "G3XYZ", "20m", "SSB")
+	q.TimeOn = "1230"
+	insertTestQsoValue(t, s, q)
+
+	q = newTestQso(s, "JA1ABC", "20m", "CW")
+	q.Country = "Japan"
+	q.DXCC = "339"
+	q.Cont = "AS"
+	q.QsoDate, q.TimeOn = "20240302", "0815"
+	ja := insertTestQsoValue(t, s, q)
+
+	// A time with seconds, as other loggers write it, is cut to the minute.
+	ctx := s.dbContext()
+	if _, err := s.DatabaseService.ExecContext(ctx, `PRAGMA ignore_check_constraints = ON`); err != nil {
+		t.Fatalf("PRAGMA error = %v", err)
+	}
+	if _, err := s.DatabaseService.ExecContext(ctx, `UPDATE qso SET time_on = '081530' WHERE id = ?`, ja); err != nil {
+		t.Fatalf("UPDATE error = %v", err)
+	}
+
+	deleted := insertTestQso(t, s, "K1ABC", "10m", "FM")
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), "UPDATE qso SET deleted_at = datetime('now') WHERE id = ?", deleted); err != nil {
+		t.Fatalf("soft delete error = %v", err)
+	}
+
+	stats, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+
+	if stats.TotalQsos != 4 || stats.UniqueCalls != 3 || stats.DxccCount != 2 {
+		t.Errorf("totals = %d QSOs, %d calls, %d DXCC; want 4, 3, 2", stats.TotalQsos, stats.UniqueCalls, stats.DxccCount)
+	}
+	if stats.ActiveHours != 2 || stats.RatePerHour != 2 {
+		t.Errorf("ActiveHours = %d, RatePerHour = %v; want 2, 2", stats.ActiveHours, stats.RatePerHour)
+	}
+	if want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); !stats.PeakHour.Equal(want) || stats.PeakHourQsos != 3 {
+		t.Errorf("PeakHour = %v (%d), want %v (3)", stats.PeakHour, stats.PeakHourQsos, want)
+	}
+	if want := time.Date(2024, 3, 2, 8, 15, 0, 0, time.UTC); !stats.LastQso.Equal(want) {
+		t.Errorf("LastQso = %v, want %v", stats.LastQso, want)
+	}
+	if want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); !stats.FirstQso.Equal(want) {
+		t.Errorf("FirstQso = %v, want %v", stats.FirstQso, want)
+	}
+
+	counts := []struct {
+		name string
+		got  []StatsCount
+		want []StatsCount
+	}{
+		{"ByBand", stats.ByBand, []StatsCount{{"20m", 3}, {"40m", 1}}},
+		{"ByMode", stats.ByMode, []StatsCount{{"CW", 2}, {"SSB", 2}}},
+		{"ByContinent", stats.ByContinent, []StatsCount{{"AS", 1}}},
+		{"ByHour", stats.ByHour, []StatsCount{{"08", 1}, {"12", 3}}},
+		{"TopCountries", stats.TopCountries, []StatsCount{{"England", 3}, {"Japan", 1}}},
+	}
+	for _, tt := range counts {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if len(stats.Sessions) != 1 {
+		t.Fatalf("len(Sessions) = %d, want 1", len(stats.Sessions))
+	}
+	session := stats.Sessions[0]
+	if !session.Current || session.Qsos != 4 || session.UniqueCalls != 3 {
+		t.Errorf("Sessions[0] = %+v", session)
+	}
+	if !session.LastQso.Equal(stats.LastQso) {
+		t.Errorf("Sessions[0] LastQso = %v, want %v", session.LastQso, stats.LastQso)
+	}
+	if len(session.Bands) != 2 || len(session.Modes) != 2 {
+		t.Errorf("Sessions[0] bands = %v, modes = %v", session.Bands, session.Modes)
+	}
+}
+
+func TestFetchLogbookStats_Empty(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	stats, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	if stats.TotalQsos != 0 || stats.ByBand == nil || stats.Sessions == nil || !stats.FirstQso.IsZero() {
+		t.Errorf("FetchLogbookStats() = %+v, want empty statistics", stats)
+	}
+}
+
+func TestFetchLogbookStats_Cache(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+
+	first, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+
+	// Inserting directly bypasses the facade, so the cached statistics must be returned.
+	insertTestQso(t, s, "G3XYZ", "20m", "SSB")
+	cached, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	if cached.TotalQsos != first.TotalQsos || !cached.GeneratedAt.Equal(first.GeneratedAt) {
+		t.Errorf("FetchLogbookStats() should return the cached statistics")
+	}
+
+	s.invalidateStats(s.CurrentLogbook.ID)
+	fresh, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	if fresh.TotalQsos != 2 {
+		t.Errorf("TotalQsos = %d after invalidation, want 2", fresh.TotalQsos)
+	}
+}
+
+func TestFetchLogbookStats_SwitchOperator(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	if err := s.SaveOperator(Operator{Callsign: "G4XY"}); err != nil {
+		t.Fatalf("SaveOperator() error = %v", err)
+	}
+
+	before, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	if len(before.Sessions) != 1 || !before.Sessions[0].Current {
+		t.Fatalf("Sessions = %+v, want the current session", before.Sessions)
+	}
+
+	// The new operator logs in a new session, so the cached summary is no longer current.
+	if err = s.SwitchOperator("G4XY"); err != nil {
+		t.Fatalf("SwitchOperator() error = %v", err)
+	}
+	after, err := s.FetchLogbookStats(s.CurrentLogbook.ID)
+	if err != nil {
+		t.Fatalf("FetchLogbookStats() error = %v", err)
+	}
+	if len(after.Sessions) != 1 || after.Sessions[0].Current {
+		t.Errorf("Sessions = %+v after SwitchOperator, want none current", after.Sessions)
+	}
+}
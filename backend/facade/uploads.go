@@ -0,0 +1,88 @@
+package facade
+
+import (
+	"time"
+
+	"github.com/Station-Manager/enums/upload/status"
+	"github.com/Station-Manager/errors"
+)
+
+// ForwardResult is the outcome of ForwardUploads.
+type ForwardResult struct {
+	Uploaded int `json:"uploaded"`
+	Failed   int `json:"failed"`
+}
+
+// ForwardUploads uploads the QSOs waiting to be forwarded to the online services now, one at a time, instead of
+// waiting for the forwarding workers. Uploads that failed before are held back for the retry cooldown; with
+// retryFailed they are retried at once. Each upload is attempted once per call.
+func (s *Service) ForwardUploads(retryFailed bool) (ForwardResult, error) {
+	const op errors.Op = "facade.Service.ForwardUploads"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return ForwardResult{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return ForwardResult{}, errors.Root(err)
+	}
+
+	// The pending uploads are fetched with the failed ones whose last attempt is older than the cooldown. A failed
+	// upload is left without a last attempt, to be retried at the next poll, so it is held back for the cooldown here
+	// unless it is to be retried now.
+	query := `UPDATE qso_upload SET last_attempt_at = ? WHERE status = ? AND last_attempt_at IS NULL`
+	args := []any{time.Now().Unix(), status.Failed.String()}
+	if retryFailed {
+		query = `UPDATE qso_upload SET last_attempt_at = NULL WHERE status = ?`
+		args = args[1:]
+	}
+	if _, err := s.DatabaseService.ExecContext(s.dbContext(), query, args...); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to prepare the failed uploads")
+		return ForwardResult{}, errors.Root(err)
+	}
+
+	var result ForwardResult
+	for {
+		if err := s.dbContext().Err(); err != nil {
+			return result, errors.Root(errors.New(op).Err(err).Msg("Forwarding cancelled"))
+		}
+
+		// Each batch is reserved while it is forwarded.
+		uploads, err := s.DatabaseService.FetchPendingUploads()
+		if err != nil {
+			err = errors.New(op).Err(err)
+			s.LoggerService.ErrorWith().Err(err).Msg("Failed to fetch pending uploads")
+			return result, errors.Root(err)
+		}
+		if len(uploads) == 0 {
+			return result, nil
+		}
+
+		for _, upload := range uploads {
+			var networkErr error
+			if provider, ok := s.forwarders[upload.Service]; ok {
+				networkErr = s.forwardNetworkOnly(provider, upload)
+			} else {
+				networkErr = errors.New(op).Msgf("no forwarder found for service: %s", upload.Service)
+			}
+			if err = s.updateDatabaseOnly(upload, networkErr); err != nil {
+				return result, errors.Root(errors.New(op).Err(err))
+			}
+
+			if networkErr != nil {
+				// Attempted once per call: held back like the others until the cooldown has passed.
+				const hold = `UPDATE qso_upload SET last_attempt_at = ? WHERE id = ?`
+				if _, err = s.DatabaseService.ExecContext(s.dbContext(), hold, time.Now().Unix(), upload.ID); err != nil {
+					return result, errors.Root(errors.New(op).Err(err))
+				}
+				result.Failed++
+			} else {
+				result.Uploaded++
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package facade
+
+import (
+	"testing"
+
+	"github.com/Station-Manager/enums/upload"
+	"github.com/Station-Manager/enums/upload/action"
+	fwdrs "github.com/Station-Manager/forwarding"
+	"github.com/Station-Manager/types"
+)
+
+type testForwarder struct {
+	forwarded int
+}
+
+func (f *testForwarder) ForwardNetworkOnly(types.Qso, ...string) error {
+	f.forwarded++
+	return nil
+}
+
+func (f *testForwarder) Forward(qso types.Qso, param ...string) error {
+	return f.ForwardNetworkOnly(qso, param...)
+}
+
+func TestForwardUploads(t *testing.T) {
+	s := createDatabaseTestService(t)
+	id := insertTestQso(t, s, "G4XY", "20m", "SSB")
+	if err := s.DatabaseService.InsertQsoUpload(id, action.Insert, upload.OnlineServiceQRZ); err != nil {
+		t.Fatalf("InsertQsoUpload() error = %v", err)
+	}
+
+	// Without a forwarder, the upload fails.
+	result, err := s.ForwardUploads(false)
+	if err != nil {
+		t.Fatalf("ForwardUploads() error = %v", err)
+	}
+	if result != (ForwardResult{Failed: 1}) {
+		t.Fatalf("ForwardUploads() = %+v, want 1 failed", result)
+	}
+
+	fwd := &testForwarder{}
+	s.forwarders = map[string]fwdrs.Forwarder{upload.OnlineServiceQRZ.String(): fwd}
+
+	// A failed upload waits for its cooldown unless retried.
+	if result, err = s.ForwardUploads(false); err != nil || result != (ForwardResult{}) {
+		t.Errorf("ForwardUploads() = %+v, %v, want nothing attempted", result, err)
+	}
+	if result, err = s.ForwardUploads(true); err != nil || result != (ForwardResult{Uploaded: 1}) {
+		t.Errorf("ForwardUploads(retry) = %+v, %v, want 1 uploaded", result, err)
+	}
+	if fwd.forwarded != 1 {
+		t.Errorf("forwarded %d QSOs, want 1", fwd.forwarded)
+	}
+}
@@ -0,0 +1,184 @@
+package facade
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/winkeyer"
+)
+
+// The keyers CW can be sent with, as selected in the app options.
+const (
+	cwKeyerCat      = "cat"
+	cwKeyerWinKeyer = "winkeyer"
+)
+
+// CwKeyerStatus is the state of the WinKeyer.
+type CwKeyerStatus struct {
+	Keyer   string          `json:"keyer"` // "cat" or "winkeyer"
+	Port    string          `json:"port"`
+	State   string          `json:"state"`
+	Version int             `json:"version"` // the WinKeyer's firmware version, while connected
+	Status  winkeyer.Status `json:"status"`
+	Error   string          `json:"error"` // the reason for the last disconnection, if any
+	Since   time.Time       `json:"since"`
+}
+
+// CwEcho carries characters sent by the WinKeyer.
+type CwEcho struct {
+	Text string `json:"text"`
+}
+
+// winKeyer holds the WinKeyer for the current run.
+type winKeyer struct {
+	keyer *winkeyer.Keyer
+
+	mu     sync.Mutex
+	status CwKeyerStatus
+}
+
+// FetchCwKeyerStatus returns the state of the keyer CW is sent with.
+func (s *Service) FetchCwKeyerStatus() (CwKeyerStatus, error) {
+	const op errors.Op = "facade.Service.FetchCwKeyerStatus"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return CwKeyerStatus{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return CwKeyerStatus{}, errors.Root(err)
+	}
+
+	w := s.winKeyer
+	if w == nil {
+		return CwKeyerStatus{Keyer: cwKeyerCat}, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status, nil
+}
+
+// SetCwWeight sets the keying weight of the WinKeyer, where 50 is standard. The rig's keyer has no such setting.
+func (s *Service) SetCwWeight(weight int) error {
+	const op errors.Op = "facade.Service.SetCwWeight"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	w := s.winKeyer
+	if w == nil {
+		return errors.New(op).Msg("The keying weight can only be set on a WinKeyer")
+	}
+
+	if err := w.keyer.SetWeight(weight); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to set the CW weight")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// newWinKeyer creates the WinKeyer if the app options select it. It returns nil if CW is sent with the rig's keyer.
+func (s *Service) newWinKeyer(opts CwKeyerOptions) (*winKeyer, error) {
+	const op errors.Op = "facade.Service.newWinKeyer"
+
+	switch strings.ToLower(strings.TrimSpace(opts.Keyer)) {
+	case "", cwKeyerCat:
+		return nil, nil
+	case cwKeyerWinKeyer:
+	default:
+		return nil, errors.New(op).Msgf("Unknown CW keyer %q", opts.Keyer)
+	}
+
+	keyer, err := winkeyer.New(winkeyer.Config{
+		Port:   opts.WinKeyer.Port,
+		Speed:  opts.WinKeyer.Speed,
+		Weight: opts.WinKeyer.Weight,
+	})
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return &winKeyer{
+		keyer: keyer,
+		status: CwKeyerStatus{
+			Keyer: cwKeyerWinKeyer,
+			Port:  opts.WinKeyer.Port,
+			State: string(winkeyer.StateDisconnected),
+		},
+	}, nil
+}
+
+// winKeyerWorker runs the WinKeyer until shutdown, emitting its echo and status to the frontend.
+func (s *Service) winKeyerWorker(shutdown <-chan struct{}) {
+	w := s.winKeyer
+	if w == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	_ = w.keyer.Run(ctx, &winKeyerHandler{winKeyer: w, service: s})
+	s.LoggerService.DebugWith().Msg("WinKeyer stopped")
+}
+
+// winKeyerHandler records the WinKeyer's state and emits it, with the echoed characters, to the frontend.
+type winKeyerHandler struct {
+	*winKeyer
+	service *Service
+}
+
+func (h *winKeyerHandler) HandleEcho(text string) {
+	h.service.emitEvent(EventCwEcho.String(), CwEcho{Text: text})
+}
+
+func (h *winKeyerHandler) HandleStatus(status winkeyer.Status) {
+	h.mu.Lock()
+	h.status.Status = status
+	current := h.status
+	h.mu.Unlock()
+	h.service.emitEvent(EventCwKeyerStatus.String(), current)
+}
+
+func (h *winKeyerHandler) HandleState(state winkeyer.State, err error) {
+	if err != nil {
+		h.service.LoggerService.WarnWith().Err(err).Msg("WinKeyer connection lost")
+	}
+
+	h.mu.Lock()
+	h.status.State = string(state)
+	h.status.Since = time.Now().UTC()
+	h.status.Status = winkeyer.Status{}
+	h.status.Version = int(h.keyer.Version())
+	h.status.Error = ""
+	if err != nil {
+		h.status.Error = errors.Root(err).Error()
+	}
+	current := h.status
+	h.mu.Unlock()
+	h.service.emitEvent(EventCwKeyerStatus.String(), current)
+}
@@ -0,0 +1,96 @@
+package facade
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Station-Manager/logging-app/backend/winkeyer"
+)
+
+func TestNewWinKeyer(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CwKeyerOptions
+		wantNil bool
+		wantErr bool
+	}{
+		{"default keyer", CwKeyerOptions{}, true, false},
+		{"rig keyer", CwKeyerOptions{Keyer: "CAT"}, true, false},
+		{"winkeyer", CwKeyerOptions{Keyer: "winkeyer", WinKeyer: WinKeyerOptions{Port: "/dev/ttyUSB1"}}, false, false},
+		{"winkeyer without port", CwKeyerOptions{Keyer: "winkeyer"}, true, true},
+		{"unknown keyer", CwKeyerOptions{Keyer: "paddle"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := createStartedTestService()
+			w, err := s.newWinKeyer(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newWinKeyer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (w == nil) != tt.wantNil {
+				t.Fatalf("newWinKeyer() = %v, wantNil %v", w, tt.wantNil)
+			}
+			if w != nil && (w.status.Keyer != cwKeyerWinKeyer || w.status.State != string(winkeyer.StateDisconnected)) {
+				t.Errorf("status = %+v", w.status)
+			}
+		})
+	}
+}
+
+func TestFetchCwKeyerStatus(t *testing.T) {
+	s := createStartedTestService()
+
+	got, err := s.FetchCwKeyerStatus()
+	if err != nil {
+		t.Fatalf("FetchCwKeyerStatus() error = %v", err)
+	}
+	if got.Keyer != cwKeyerCat {
+		t.Errorf("FetchCwKeyerStatus() = %+v, want the rig's keyer", got)
+	}
+
+	s.winKeyer, err = s.newWinKeyer(CwKeyerOptions{Keyer: cwKeyerWinKeyer, WinKeyer: WinKeyerOptions{Port: "/dev/ttyUSB1"}})
+	if err != nil {
+		t.Fatalf("newWinKeyer() error = %v", err)
+	}
+	h := &winKeyerHandler{winKeyer: s.winKeyer, service: s}
+	h.HandleState(winkeyer.StateDisconnected, errors.New("port gone"))
+	h.HandleStatus(winkeyer.Status{Busy: true})
+
+	got, err = s.FetchCwKeyerStatus()
+	if err != nil {
+		t.Fatalf("FetchCwKeyerStatus() error = %v", err)
+	}
+	if got.Keyer != cwKeyerWinKeyer || got.Port != "/dev/ttyUSB1" || got.Error != "port gone" || !got.Status.Busy {
+		t.Errorf("FetchCwKeyerStatus() = %+v", got)
+	}
+
+	if _, err = createInitializedTestService().FetchCwKeyerStatus(); err == nil {
+		t.Error("FetchCwKeyerStatus() should fail when not started")
+	}
+}
+
+func TestWinKeyer_NotConnected(t *testing.T) {
+	s, sent := createCwTestService()
+	var err error
+	s.winKeyer, err = s.newWinKeyer(CwKeyerOptions{Keyer: cwKeyerWinKeyer, WinKeyer: WinKeyerOptions{Port: "/dev/ttyUSB1"}})
+	if err != nil {
+		t.Fatalf("newWinKeyer() error = %v", err)
+	}
+
+	if err = s.SendCwText("CQ"); err == nil {
+		t.Error("SendCwText() should fail while the WinKeyer is not connected")
+	}
+	if err = s.AbortCw(); err == nil {
+		t.Error("AbortCw() should fail while the WinKeyer is not connected")
+	}
+	if len(*sent) != 0 {
+		t.Errorf("sent %+v to the rig, want nothing while a WinKeyer is selected", *sent)
+	}
+}
+
+func TestSetCwWeight_RigKeyer(t *testing.T) {
+	s, _ := createCwTestService()
+	if err := s.SetCwWeight(50); err == nil {
+		t.Error("SetCwWeight() should fail without a WinKeyer")
+	}
+}
@@ -0,0 +1,240 @@
+package facade
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Station-Manager/enums/bands"
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+	"github.com/Station-Manager/utils"
+)
+
+// matrixBands and matrixModes fix the row and column order of the worked-before matrix. All bands are always
+// shown; of the modes, only the defaults and those that have actually been worked are shown.
+var (
+	matrixBands = []string{
+		bands.Band160.String(), bands.Band80.String(), bands.Band60.String(), bands.Band40.String(),
+		bands.Band30.String(), bands.Band20.String(), bands.Band17.String(), bands.Band15.String(),
+		bands.Band12.String(), bands.Band10.String(), bands.Band6.String(),
+	}
+	matrixModes = []string{
+		string(modes.CW), string(modes.SSB), string(modes.AM), string(modes.FM), string(modes.RTTY),
+		string(modes.PSK), string(modes.MFSK), string(modes.DIGITALVOICE), string(modes.HELL), string(modes.PACKET),
+	}
+	matrixDefaultModes = []string{string(modes.CW), string(modes.SSB)}
+)
+
+// WorkedMatrixCell is the worked/confirmed state of one band/mode slot, for both the callsign and its DXCC entity.
+type WorkedMatrixCell struct {
+	Band            string `json:"band"`
+	Mode            string `json:"mode"`
+	CallWorked      bool   `json:"call_worked"`
+	CallConfirmed   bool   `json:"call_confirmed"`
+	EntityWorked    bool   `json:"entity_worked"`
+	EntityConfirmed bool   `json:"entity_confirmed"`
+}
+
+// WorkedMatrix is the band x mode worked-before matrix for a callsign and its DXCC entity. Only slots that have
+// been worked are present in Cells; Bands and Modes give the row and column order for display. The entity is
+// matched on its ADIF code (Dxcc), as the awards engine counts it; Country is its name, for display.
+type WorkedMatrix struct {
+	Call    string             `json:"call"`
+	Country string             `json:"country"`
+	Dxcc    string             `json:"dxcc"`
+	Bands   []string           `json:"bands"`
+	Modes   []string           `json:"modes"`
+	Cells   []WorkedMatrixCell `json:"cells"`
+}
+
+// Cell returns the given slot, and whether it has been worked by either the callsign or the entity.
+func (m WorkedMatrix) Cell(band, mode string) (WorkedMatrixCell, bool) {
+	for _, c := range m.Cells {
+		if c.Band == band && c.Mode == mode {
+			return c, true
+		}
+	}
+	return WorkedMatrixCell{Band: band, Mode: mode}, false
+}
+
+// FetchWorkedMatrix returns the band x mode worked-before matrix for the given callsign and its DXCC entity in the
+// current logbook.
+func (s *Service) FetchWorkedMatrix(callsign string) (WorkedMatrix, error) {
+	const op errors.Op = "facade.Service.FetchWorkedMatrix"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return WorkedMatrix{}, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return WorkedMatrix{}, errors.Root(err)
+	}
+
+	callsign = strings.ToUpper(strings.TrimSpace(callsign))
+	if len(callsign) < 3 {
+		return WorkedMatrix{}, errors.New(op).Msg(errMsgInvalidCallsign)
+	}
+
+	country, err := s.resolveCountry(callsign)
+	if err != nil {
+		// The matrix is still useful for the callsign alone.
+		s.LoggerService.WarnWith().Err(err).Str("callsign", callsign).Msg("Failed to resolve country for callsign")
+	}
+
+	matrix, err := s.computeWorkedMatrix(callsign, country, "")
+	if err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to compute worked-before matrix")
+		return WorkedMatrix{}, errors.Root(err)
+	}
+
+	return matrix, nil
+}
+
+// computeWorkedMatrix builds the matrix for the callsign and its country with a single grouped query. The entity is
+// the one with the given ADIF code, or, if none is given, the code dxccCode finds for the country. Portable and
+// prefixed forms of the callsign (e.g., G4ABC/P, EA8/G4ABC) count as the same callsign.
+func (s *Service) computeWorkedMatrix(callsign string, country types.Country, dxcc string) (WorkedMatrix, error) {
+	const op errors.Op = "facade.Service.computeWorkedMatrix"
+
+	base := s.parseCallsign(callsign)
+	matrix := WorkedMatrix{Call: callsign, Country: country.Name, Cells: make([]WorkedMatrixCell, 0)}
+
+	dxcc, err := s.dxccCode(country, dxcc)
+	if err != nil {
+		return matrix, errors.New(op).Err(err)
+	}
+	matrix.Dxcc = dxcc
+
+	entity := `(?2 <> '' AND ` + awardRefExpressions[AwardDXCC] + ` = ?2)`
+	query := `SELECT band, mode, MAX(is_call), MAX(is_call AND confirmed), MAX(is_entity), MAX(is_entity AND confirmed)
+FROM (SELECT q.band,
+             q.mode,
+             (q.call = ?1 OR q.call LIKE ?1 || '/%' OR q.call LIKE '%/' || ?1)         AS is_call,
+             ` + entity + ` AS is_entity,
+             ` + confirmedExpression(allConfirmationSources) + ` AS confirmed
+      FROM qso q
+      WHERE q.logbook_id = ?3
+        AND q.deleted_at IS NULL
+        AND (` + entity + `
+          OR q.call = ?1 OR q.call LIKE ?1 || '/%' OR q.call LIKE '%/' || ?1))
+GROUP BY band, mode`
+
+	rows, err := s.DatabaseService.QueryContext(s.dbContext(), query, base, dxcc, s.CurrentLogbook.ID)
+	if err != nil {
+		return matrix, errors.New(op).Err(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var c WorkedMatrixCell
+		if err = rows.Scan(&c.Band, &c.Mode, &c.CallWorked, &c.CallConfirmed, &c.EntityWorked, &c.EntityConfirmed); err != nil {
+			return matrix, errors.New(op).Err(err)
+		}
+		matrix.Cells = append(matrix.Cells, c)
+	}
+	if err = rows.Err(); err != nil {
+		return matrix, errors.New(op).Err(err)
+	}
+
+	matrix.Bands = slices.Clone(matrixBands)
+	for _, c := range matrix.Cells {
+		if !slices.Contains(matrix.Bands, c.Band) {
+			matrix.Bands = append(matrix.Bands, c.Band)
+		}
+	}
+	for _, mode := range matrixModes {
+		if slices.Contains(matrixDefaultModes, mode) || slices.ContainsFunc(matrix.Cells, func(c WorkedMatrixCell) bool { return c.Mode == mode }) {
+			matrix.Modes = append(matrix.Modes, mode)
+		}
+	}
+	for _, c := range matrix.Cells {
+		if !slices.Contains(matrix.Modes, c.Mode) {
+			matrix.Modes = append(matrix.Modes, c.Mode)
+		}
+	}
+
+	return matrix, nil
+}
+
+// dxccCode returns the ADIF code of a country's DXCC entity: the known code if one is given (e.g. from a QRZ
+// lookup), else the code for the country's ISO code, else the code the logbook most often has for the country's
+// name, for entities that share an ISO code with others (e.g. England). It returns "" when no code is found.
+func (s *Service) dxccCode(country types.Country, known string) (string, error) {
+	const op errors.Op = "facade.Service.dxccCode"
+
+	if code := normalizeDxccCode(known); code != "" {
+		return code, nil
+	}
+	if code, ok := utils.DXCCFromISO2(country.Ccode); ok {
+		return normalizeDxccCode(code), nil
+	}
+	if strings.TrimSpace(country.Name) == "" {
+		return "", nil
+	}
+
+	query := `SELECT code
+FROM (SELECT ` + awardRefExpressions[AwardDXCC] + ` AS code
+      FROM qso q
+      WHERE q.logbook_id = ?
+        AND q.deleted_at IS NULL
+        AND UPPER(TRIM(q.country)) = UPPER(TRIM(?)))
+WHERE code IS NOT NULL
+GROUP BY code
+ORDER BY COUNT(*) DESC
+LIMIT 1`
+	codes, err := s.queryStrings(query, s.CurrentLogbook.ID, country.Name)
+	if err != nil {
+		return "", errors.New(op).Err(err)
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+	return codes[0], nil
+}
+
+// normalizeDxccCode returns an ADIF entity code in the form the awards engine derives it in (no leading zeros), or
+// "" if it is not a valid code.
+func normalizeDxccCode(code string) string {
+	n, err := strconv.Atoi(strings.TrimSpace(code))
+	if err != nil || n < 1 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// awardNeeds derives the entity's DXCC, band slot and mode slot needs from the matrix.
+func (m WorkedMatrix) awardNeeds(band, mode string) AwardNeeds {
+	needs := AwardNeeds{Call: m.Call, Country: m.Country, Band: band, Mode: mode}
+	if m.Dxcc == "" {
+		return needs
+	}
+
+	var entity, onBand, inMode bool
+	for _, c := range m.Cells {
+		if !c.EntityWorked {
+			continue
+		}
+		entity = true
+		onBand = onBand || c.Band == band
+		inMode = inMode || c.Mode == mode
+	}
+
+	needs.NewDxcc = !entity
+	needs.NewBandSlot = band != "" && !onBand
+	needs.NewModeSlot = mode != "" && !inMode
+
+	return needs
+}
+
+// emitWorkedBefore emits the worked-before matrix of a freshly initialized QSO, along with its award needs, to the
+// frontend.
+func (s *Service) emitWorkedBefore(result *NewQsoResult) {
+	s.emitEvent(EventWorkedMatrix.String(), result.Matrix)
+	s.emitEvent(EventAwardStatus.String(), result.Needs)
+}
@@ -0,0 +1,175 @@
+package facade
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Station-Manager/types"
+)
+
+func TestFetchWorkedMatrix_NotInitialized(t *testing.T) {
+	s := createTestService()
+	if _, err := s.FetchWorkedMatrix("G4ABC"); err == nil {
+		t.Error("FetchWorkedMatrix() should fail when service not initialized")
+	}
+}
+
+func TestFetchWorkedMatrix_NotStarted(t *testing.T) {
+	s := createInitializedTestService()
+	if _, err := s.FetchWorkedMatrix("G4ABC"); err == nil {
+		t.Error("FetchWorkedMatrix() should fail when service not started")
+	}
+}
+
+func TestFetchWorkedMatrix_InvalidCallsign(t *testing.T) {
+	s := createStartedTestService()
+	if _, err := s.FetchWorkedMatrix(" g4 "); err == nil {
+		t.Error("FetchWorkedMatrix() should fail for an invalid callsign")
+	}
+}
+
+func TestComputeWorkedMatrix(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	callOn20 := insertTestQso(t, s, "G4ABC/P", "20m", "SSB")
+	insertTestQso(t, s, "EA8/G4ABC", "40m", "CW")
+	insertTestQso(t, s, "G3XYZ", "15m", "RTTY")
+	japan := newTestQso(s, "JA1ABC", "20m", "SSB")
+	japan.ContactedStation.Country = "Japan"
+	japan.ContactedStation.DXCC = "339"
+	insertTestQsoValue(t, s, japan)
+
+	if err := s.SetQsoConfirmation(callOn20, ConfirmationEqsl, true); err != nil {
+		t.Fatalf("SetQsoConfirmation() error = %v", err)
+	}
+
+	matrix, err := s.computeWorkedMatrix("G4ABC", types.Country{Name: "England"}, "")
+	if err != nil {
+		t.Fatalf("computeWorkedMatrix() error = %v", err)
+	}
+
+	tests := []struct {
+		band, mode string
+		want       WorkedMatrixCell
+		worked     bool
+	}{
+		{"20m", "SSB", WorkedMatrixCell{CallWorked: true, CallConfirmed: true, EntityWorked: true, EntityConfirmed: true}, true},
+		{"40m", "CW", WorkedMatrixCell{CallWorked: true, EntityWorked: true}, true},
+		{"15m", "RTTY", WorkedMatrixCell{EntityWorked: true}, true},
+		{"10m", "SSB", WorkedMatrixCell{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.band+"/"+tt.mode, func(t *testing.T) {
+			got, worked := matrix.Cell(tt.band, tt.mode)
+			tt.want.Band, tt.want.Mode = tt.band, tt.mode
+			if worked != tt.worked || got != tt.want {
+				t.Errorf("Cell() = %+v, %v; want %+v, %v", got, worked, tt.want, tt.worked)
+			}
+		})
+	}
+
+	if matrix.Dxcc != "223" {
+		t.Errorf("Dxcc = %q, want the code the log has for England", matrix.Dxcc)
+	}
+	if len(matrix.Cells) != 3 {
+		t.Errorf("len(Cells) = %d, want 3 (Japan must not be included)", len(matrix.Cells))
+	}
+	if !slices.Equal(matrix.Modes, []string{"CW", "SSB", "RTTY"}) {
+		t.Errorf("Modes = %v", matrix.Modes)
+	}
+	if len(matrix.Bands) != len(matrixBands) || matrix.Bands[0] != "160m" {
+		t.Errorf("Bands = %v", matrix.Bands)
+	}
+}
+
+func TestComputeWorkedMatrix_UnknownCountry(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "SSB")
+	insertTestQso(t, s, "G3XYZ", "20m", "CW")
+
+	matrix, err := s.computeWorkedMatrix("G4ABC", types.Country{}, "")
+	if err != nil {
+		t.Fatalf("computeWorkedMatrix() error = %v", err)
+	}
+	if len(matrix.Cells) != 1 || !matrix.Cells[0].CallWorked || matrix.Cells[0].EntityWorked {
+		t.Errorf("Cells = %+v", matrix.Cells)
+	}
+}
+
+func TestComputeWorkedMatrix_EntityByDxccCode(t *testing.T) {
+	s := createDatabaseTestService(t)
+	for _, q := range []struct{ call, band, mode, country string }{
+		{"DL1ABC", "20m", "SSB", "Fed. Rep. of Germany"},
+		{"DK2XYZ", "40m", "CW", "Germany"},
+	} {
+		qso := newTestQso(s, q.call, q.band, q.mode)
+		qso.ContactedStation.Country = q.country
+		qso.ContactedStation.DXCC = "230"
+		insertTestQsoValue(t, s, qso)
+	}
+
+	tests := []struct {
+		name    string
+		country types.Country
+		dxcc    string
+	}{
+		{"known code", types.Country{Name: "Germany"}, "0230"},
+		{"code from the ISO code", types.Country{Name: "Deutschland", Ccode: "DE"}, ""},
+		{"code from the log", types.Country{Name: "germany"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matrix, err := s.computeWorkedMatrix("DL9NEW", tt.country, tt.dxcc)
+			if err != nil {
+				t.Fatalf("computeWorkedMatrix() error = %v", err)
+			}
+			if matrix.Dxcc != "230" || len(matrix.Cells) != 2 {
+				t.Fatalf("matrix = %+v, want both spellings of entity 230", matrix)
+			}
+			if needs := matrix.awardNeeds("15m", "SSB"); needs.NewDxcc || !needs.NewBandSlot || needs.NewModeSlot {
+				t.Errorf("awardNeeds() = %+v", needs)
+			}
+		})
+	}
+}
+
+func TestWorkedMatrix_AwardNeeds(t *testing.T) {
+	matrix := WorkedMatrix{
+		Country: "England",
+		Dxcc:    "223",
+		Cells: []WorkedMatrixCell{
+			{Band: "20m", Mode: "SSB", CallWorked: true},
+			{Band: "40m", Mode: "CW", EntityWorked: true},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		band, mode string
+		want       AwardNeeds
+	}{
+		{"worked slot", "40m", "CW", AwardNeeds{}},
+		{"call-only cell does not count for the entity", "20m", "SSB", AwardNeeds{NewBandSlot: true, NewModeSlot: true}},
+		{"unknown band and mode", "", "", AwardNeeds{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matrix.awardNeeds(tt.band, tt.mode)
+			if got.NewDxcc != tt.want.NewDxcc || got.NewBandSlot != tt.want.NewBandSlot || got.NewModeSlot != tt.want.NewModeSlot {
+				t.Errorf("awardNeeds() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if needs := (WorkedMatrix{Country: "Japan", Dxcc: "339"}).awardNeeds("20m", "SSB"); !needs.NewDxcc {
+		t.Error("awardNeeds() should flag a new DXCC for an entity with no cells")
+	}
+}
+
+func TestEmitWorkedBefore_WithoutWailsContext(t *testing.T) {
+	s := createDatabaseTestService(t)
+	qso := &types.Qso{ContactedStation: types.ContactedStation{Call: "G4ABC"}}
+
+	// Must not terminate the process when the context is not a Wails runtime context.
+	s.emitWorkedBefore(&NewQsoResult{Qso: qso})
+}
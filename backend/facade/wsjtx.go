@@ -0,0 +1,536 @@
+package facade
+
+import (
+	"context"
+	"maps"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/enums/modes"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/listeners/handlers"
+	"github.com/Station-Manager/logging-app/backend/wsjtx"
+	"github.com/Station-Manager/maidenhead"
+	"github.com/Station-Manager/types"
+)
+
+// wsjtxHandlerName is the packet handler named by the WSJT-X listeners of the shared config. The facade registers
+// its own handler under it, in place of the listeners module's, so that the listener WSJT-X sends to is the only
+// receiver of its messages.
+const wsjtxHandlerName = "wsjtx"
+
+// Handler config keys of the WSJT-X packet handler. The service is injected under the key the listeners module's
+// handler takes its QSO logger from; auto_log is set in the shared config.
+const (
+	wsjtxConfigService = "qso_logger"
+	wsjtxConfigAutoLog = "auto_log"
+)
+
+func init() {
+	handlers.Register(wsjtxHandlerName, newWsjtxPacketHandler)
+}
+
+// wsjtxDecodeQueueSize bounds the decodes waiting to be classified. If classification falls behind, new decodes
+// are dropped.
+const wsjtxDecodeQueueSize = 256
+
+// wsjtxCallsign matches the callsigns in decoded messages, telling them apart from the CQ modifiers, grids,
+// reports and sign-offs that share the message.
+var wsjtxCallsign = regexp.MustCompile(`^([A-Z0-9]+/)?[A-Z0-9]*[0-9][A-Z0-9]*[A-Z](/[A-Z0-9]+)?$`)
+
+// wsjtxHighlight is the class a decoded callsign is highlighted by, from the least to the most wanted.
+type wsjtxHighlight int
+
+const (
+	wsjtxHighlightNone wsjtxHighlight = iota
+	wsjtxHighlightWorked
+	wsjtxHighlightNeededSlot
+	wsjtxHighlightNeededDxcc
+)
+
+// wsjtxColors are the background and foreground colours of a highlight class.
+type wsjtxColors struct {
+	background, foreground wsjtx.Color
+}
+
+// WsjtxDecode is a decode from a WSJT-X instance enriched with the DXCC entity of the calling station, its distance
+// and its worked/needed status in the current logbook. It is passed back to ReplyToWsjtxDecode to answer the decode.
+type WsjtxDecode struct {
+	Client           string       `json:"client"` // the ID of the instance
+	Decode           wsjtx.Decode `json:"decode"`
+	Callsign         string       `json:"callsign"`    // empty if the message names no station to answer
+	Grid             string       `json:"grid"`        // empty unless sent in the message
+	DistanceKm       int          `json:"distance_km"` // zero unless both grids are known
+	Band             string       `json:"band"`
+	Mode             string       `json:"mode"`
+	Country          string       `json:"country"`
+	Continent        string       `json:"continent"`
+	CallWorked       bool         `json:"call_worked"`
+	CallWorkedOnBand bool         `json:"call_worked_on_band"`
+	NewDxcc          bool         `json:"new_dxcc"`
+	NewBandSlot      bool         `json:"new_band_slot"`
+	NewModeSlot      bool         `json:"new_mode_slot"`
+	ReceivedAt       time.Time    `json:"received_at"`
+
+	// deGrid is the grid the instance reported for the station.
+	deGrid string
+}
+
+// highlight returns the class the decode's callsign is highlighted by.
+func (d WsjtxDecode) highlight() wsjtxHighlight {
+	switch {
+	case d.Callsign == "":
+		return wsjtxHighlightNone
+	case d.NewDxcc:
+		return wsjtxHighlightNeededDxcc
+	case d.NewBandSlot || d.NewModeSlot:
+		return wsjtxHighlightNeededSlot
+	case d.CallWorkedOnBand:
+		return wsjtxHighlightWorked
+	}
+	return wsjtxHighlightNone
+}
+
+// wsjtxPacketHandler passes the messages received by a WSJT-X listener to the WSJT-X integration, and logs the QSOs
+// WSJT-X reports as logged when auto_log is set. It implements handlers.PacketHandler.
+type wsjtxPacketHandler struct {
+	service *Service
+	autoLog bool
+}
+
+func newWsjtxPacketHandler(config map[string]any) (handlers.PacketHandler, error) {
+	const op errors.Op = "facade.newWsjtxPacketHandler"
+
+	s, ok := config[wsjtxConfigService].(*Service)
+	if !ok || s == nil {
+		return nil, errors.New(op).Msg("The WSJT-X handler was not given the logging service")
+	}
+	autoLog, _ := config[wsjtxConfigAutoLog].(bool)
+	return &wsjtxPacketHandler{service: s, autoLog: autoLog}, nil
+}
+
+func (h *wsjtxPacketHandler) Name() string {
+	return wsjtxHandlerName
+}
+
+// Handle parses a datagram from a WSJT-X instance and passes it on. Malformed datagrams are dropped.
+func (h *wsjtxPacketHandler) Handle(pkt handlers.Packet) error {
+	s := h.service
+
+	// WSJT-X only sends over UDP, and is sent messages back the same way.
+	from, ok := pkt.RemoteAddr.(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	msg, err := wsjtx.Parse(pkt.Data)
+	if err != nil || msg == nil {
+		return nil
+	}
+
+	if logged, ok := msg.(*wsjtx.LoggedAdif); ok {
+		if h.autoLog {
+			s.logWsjtxQso(*logged)
+		}
+		return nil
+	}
+	if b := s.wsjtx; b != nil {
+		b.server.Dispatch(msg, from, b)
+	}
+	return nil
+}
+
+func (h *wsjtxPacketHandler) Close() error {
+	return nil
+}
+
+// configureWsjtxListeners gives the service to the WSJT-X listeners of the listeners service. It must be called
+// before the listeners service is started, which creates their handlers.
+func (s *Service) configureWsjtxListeners() {
+	for i := range s.ListenersService.ListenerConfigs {
+		cfg := &s.ListenersService.ListenerConfigs[i]
+		if cfg.Handler != wsjtxHandlerName {
+			continue
+		}
+		// The map is shared with the config service, so it is replaced rather than changed.
+		handlerCfg := maps.Clone(cfg.HandlerConfig)
+		if handlerCfg == nil {
+			handlerCfg = make(map[string]any, 1)
+		}
+		handlerCfg[wsjtxConfigService] = s
+		cfg.HandlerConfig = handlerCfg
+	}
+}
+
+// logWsjtxQso logs a QSO WSJT-X reports as logged, unless the logbook already holds it.
+func (s *Service) logWsjtxQso(logged wsjtx.LoggedAdif) {
+	s.dbGate.RLock()
+	defer s.dbGate.RUnlock()
+
+	if _, err := s.logAdifRecord([]byte(logged.Adif)); err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("client", logged.ID).Msg("Failed to log the QSO logged in WSJT-X")
+	}
+}
+
+// wsjtxBridge holds the WSJT-X server and the state of the instances it hears from for the current run.
+type wsjtxBridge struct {
+	server *wsjtx.Server
+	// colors are the highlight colours per class; nil when highlighting is disabled.
+	colors   map[wsjtxHighlight]wsjtxColors
+	activity *wsjtxActivity
+	// eventInterval is the minimum time between two activity events.
+	eventInterval time.Duration
+
+	mu          sync.Mutex
+	statuses    map[string]wsjtx.Status              // instance ID -> last status
+	highlighted map[string]map[string]wsjtxHighlight // instance ID -> callsign -> class last sent
+
+	// countries caches the entities found in the local country table. It is only used by the goroutine classifying
+	// the queued decodes.
+	countries map[string]types.Country
+	queue     chan WsjtxDecode
+}
+
+// HandleStatus records the state of an instance, whose dial frequency and mode apply to its later decodes. It
+// implements wsjtx.Handler.
+func (b *wsjtxBridge) HandleStatus(status wsjtx.Status) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.statuses[status.ID] = status
+}
+
+// HandleDecode queues a decode for classification, with the band and mode the instance was on when it was
+// received. It implements wsjtx.Handler.
+func (b *wsjtxBridge) HandleDecode(decode wsjtx.Decode) {
+	b.mu.Lock()
+	status := b.statuses[decode.ID]
+	b.mu.Unlock()
+
+	queued := WsjtxDecode{
+		Client:     decode.ID,
+		Decode:     decode,
+		Mode:       wsjtxMode(status.Mode),
+		ReceivedAt: time.Now().UTC(),
+		deGrid:     status.DeGrid,
+	}
+	if status.DialFrequency > 0 {
+		queued.Band = bandForKhz(float64(status.DialFrequency+uint64(decode.DeltaFrequency)) / 1000)
+	}
+
+	select {
+	case b.queue <- queued:
+	default:
+		// Classification is behind; drop the decode rather than block the server.
+	}
+}
+
+// HandleClear implements wsjtx.Handler. Highlights outlive the band activity, so there is nothing to do.
+func (b *wsjtxBridge) HandleClear(wsjtx.Clear) {}
+
+// HandleClose forgets an instance that has exited. It implements wsjtx.Handler.
+func (b *wsjtxBridge) HandleClose(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.statuses, id)
+	delete(b.highlighted, id)
+}
+
+// setHighlight records the class of a callsign at an instance, and reports whether it changed.
+func (b *wsjtxBridge) setHighlight(id, callsign string, class wsjtxHighlight) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	calls := b.highlighted[id]
+	if calls == nil {
+		calls = make(map[string]wsjtxHighlight)
+		b.highlighted[id] = calls
+	}
+	if calls[callsign] == class {
+		return false
+	}
+	calls[callsign] = class
+	return true
+}
+
+// ReplyToWsjtxDecode makes the WSJT-X instance that sent a decode answer it, as if it had been double-clicked.
+func (s *Service) ReplyToWsjtxDecode(decode WsjtxDecode) error {
+	const op errors.Op = "facade.Service.ReplyToWsjtxDecode"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return errors.Root(err)
+	}
+
+	b := s.wsjtx
+	if b == nil {
+		return errors.New(op).Msg("The WSJT-X integration is not enabled")
+	}
+
+	d := decode.Decode
+	reply := wsjtx.Reply{
+		ID:             decode.Client,
+		Time:           d.Time,
+		SNR:            d.SNR,
+		DeltaTime:      d.DeltaTime,
+		DeltaFrequency: d.DeltaFrequency,
+		Mode:           d.Mode,
+		Message:        d.Message,
+		LowConfidence:  d.LowConfidence,
+	}
+	if err := b.server.Send(decode.Client, reply); err != nil {
+		err = errors.New(op).Err(err)
+		s.LoggerService.ErrorWith().Err(err).Msg("Failed to send reply to WSJT-X")
+		return errors.Root(err)
+	}
+
+	return nil
+}
+
+// newWsjtx creates the WSJT-X server if the integration is enabled in the app options. It returns nil if it is
+// disabled.
+func newWsjtx(opts WsjtxOptions) (*wsjtxBridge, error) {
+	const op errors.Op = "facade.newWsjtx"
+	if !opts.Enabled {
+		return nil, nil
+	}
+
+	var colors map[wsjtxHighlight]wsjtxColors
+	if opts.Highlight.Enabled {
+		colors = make(map[wsjtxHighlight]wsjtxColors, 3)
+		for class, c := range map[wsjtxHighlight]WsjtxColorOptions{
+			wsjtxHighlightWorked:     opts.Highlight.Worked,
+			wsjtxHighlightNeededSlot: opts.Highlight.NeededSlot,
+			wsjtxHighlightNeededDxcc: opts.Highlight.NeededDxcc,
+		} {
+			bg, err := wsjtx.ParseColor(c.Background)
+			if err != nil {
+				return nil, errors.New(op).Err(err)
+			}
+			fg, err := wsjtx.ParseColor(c.Foreground)
+			if err != nil {
+				return nil, errors.New(op).Err(err)
+			}
+			colors[class] = wsjtxColors{background: bg, foreground: fg}
+		}
+	}
+
+	server, err := wsjtx.NewServer()
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+
+	return &wsjtxBridge{
+		server:        server,
+		colors:        colors,
+		activity:      newWsjtxActivity(opts.Activity),
+		eventInterval: time.Duration(opts.Activity.EventIntervalMs) * time.Millisecond,
+		statuses:      make(map[string]wsjtx.Status),
+		highlighted:   make(map[string]map[string]wsjtxHighlight),
+		countries:     make(map[string]types.Country),
+		queue:         make(chan WsjtxDecode, wsjtxDecodeQueueSize),
+	}, nil
+}
+
+// wsjtxWorker runs the WSJT-X integration until shutdown, classifying each decode the listeners pass to the server,
+// highlighting its callsign at the instance and adding it to the band activity, which is emitted to the frontend as
+// it changes.
+func (s *Service) wsjtxWorker(shutdown <-chan struct{}) {
+	b := s.wsjtx
+	if b == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	myGrid := ""
+	if station, err := s.ConfigService.LoggingStationConfigs(); err == nil {
+		myGrid = station.MyGridsquare
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case decode := <-b.queue:
+				s.handleWsjtxDecode(b, decode, myGrid)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		b.activity.run(ctx.Done(), b.eventInterval, func(activity []WsjtxBandActivity) {
+			s.emitEvent(EventWsjtxActivity.String(), activity)
+		})
+	}()
+
+	s.LoggerService.InfoWith().Msg("WSJT-X integration started")
+	<-ctx.Done()
+	wg.Wait()
+	b.server.Close()
+	s.LoggerService.DebugWith().Msg("WSJT-X integration stopped")
+}
+
+// handleWsjtxDecode classifies a decode, adds it to the band activity and highlights its callsign if its class has
+// changed. Distances are measured from myGrid, or the grid the instance reported if it is empty.
+func (s *Service) handleWsjtxDecode(b *wsjtxBridge, decode WsjtxDecode, myGrid string) {
+	s.dbGate.RLock()
+	enriched := s.enrichWsjtxDecode(b, decode, myGrid)
+	s.dbGate.RUnlock()
+	b.activity.add(enriched)
+
+	if b.colors == nil || enriched.Callsign == "" {
+		return
+	}
+	class := enriched.highlight()
+	if !b.setHighlight(enriched.Client, enriched.Callsign, class) {
+		return
+	}
+
+	// A callsign that is no longer of interest is sent the invalid colours, which restore the default.
+	colors := b.colors[class]
+	msg := wsjtx.HighlightCallsign{
+		ID:         enriched.Client,
+		Callsign:   enriched.Callsign,
+		Background: colors.background,
+		Foreground: colors.foreground,
+	}
+	if err := b.server.Send(enriched.Client, msg); err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("callsign", enriched.Callsign).Msg("Failed to highlight callsign in WSJT-X")
+	}
+}
+
+// enrichWsjtxDecode adds the calling station, its grid and distance, DXCC entity and worked/needed status to a
+// queued decode. Failures leave the corresponding fields empty. The entity only comes from the local country table,
+// as an online lookup for each new station would let the queue overflow in a busy band.
+func (s *Service) enrichWsjtxDecode(b *wsjtxBridge, decode WsjtxDecode, myGrid string) WsjtxDecode {
+	decode.Callsign = wsjtxDecodeCallsign(decode.Decode.Message)
+	if decode.Callsign == "" {
+		return decode
+	}
+
+	decode.Grid = wsjtxDecodeGrid(decode.Decode.Message)
+	if myGrid == "" {
+		myGrid = decode.deGrid
+	}
+	if km, ok := gridDistanceKm(myGrid, decode.Grid); ok {
+		decode.DistanceKm = km
+	}
+
+	country := s.cachedCountry(b.countries, decode.Callsign)
+	decode.Country = country.Name
+	decode.Continent = country.Continent
+
+	matrix, err := s.computeWorkedMatrix(decode.Callsign, country, "")
+	if err != nil {
+		s.LoggerService.WarnWith().Err(err).Str("callsign", decode.Callsign).Msg("Failed to compute worked status for WSJT-X decode")
+		return decode
+	}
+
+	for _, c := range matrix.Cells {
+		decode.CallWorked = decode.CallWorked || c.CallWorked
+		decode.CallWorkedOnBand = decode.CallWorkedOnBand || (c.CallWorked && c.Band == decode.Band)
+	}
+	needs := matrix.awardNeeds(decode.Band, decode.Mode)
+	decode.NewDxcc = needs.NewDxcc
+	decode.NewBandSlot = needs.NewBandSlot
+	decode.NewModeSlot = needs.NewModeSlot
+
+	return decode
+}
+
+// wsjtxDecodeCallsign returns the callsign of the station sending a decoded message, or an empty string if there
+// is none. In "CQ [modifier] CALL GRID" it is the call after the CQ; in "CALL1 CALL2 ..." it is the second call.
+// Callsigns shown in angle brackets, sent as hashes, are returned without them.
+func wsjtxDecodeCallsign(message string) string {
+	fields := strings.Fields(strings.ToUpper(message))
+	if len(fields) < 2 {
+		return ""
+	}
+
+	call := fields[1]
+	if fields[0] == "CQ" && !wsjtxCallsign.MatchString(strings.Trim(call, "<>")) && len(fields) > 2 {
+		// A directed CQ, e.g. "CQ DX" or "CQ NA".
+		call = fields[2]
+	}
+
+	call = strings.Trim(call, "<>")
+	if !wsjtxCallsign.MatchString(call) {
+		return ""
+	}
+	return call
+}
+
+// wsjtxDecodeGrid returns the grid that ends a decoded message, e.g. "CQ DL1ABC JO62", or an empty string if there
+// is none.
+func wsjtxDecodeGrid(message string) string {
+	fields := strings.Fields(strings.ToUpper(message))
+	if len(fields) < 3 {
+		return ""
+	}
+	grid := fields[len(fields)-1]
+	// RR73 is a sign-off, although it has the form of a grid (in the Arctic Ocean).
+	if grid == "RR73" || !gridRefRegex.MatchString(grid) {
+		return ""
+	}
+	return grid
+}
+
+// gridDistanceKm returns the short path distance between two grids of four or six characters, and whether both
+// are valid. A four character grid is taken at its centre.
+func gridDistanceKm(from, to string) (int, bool) {
+	from, to = sixCharGrid(from), sixCharGrid(to)
+	if from == "" || to == "" {
+		return 0, false
+	}
+	km, _, err := maidenhead.GetShortPathDistance(from, to)
+	if err != nil {
+		return 0, false
+	}
+	return int(km), true
+}
+
+// sixCharGrid extends a four character grid to the subsquare at its centre.
+func sixCharGrid(grid string) string {
+	grid = strings.TrimSpace(grid)
+	switch len(grid) {
+	case 4:
+		return grid + "ll"
+	case 6:
+		return grid
+	}
+	return ""
+}
+
+// wsjtxMode returns the mode for a WSJT-X mode name, such as "FT8", or an empty string if it is not known.
+func wsjtxMode(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	switch {
+	case modes.IsValidMode(name):
+		return name
+	case unlistedMfskModes[name]:
+		return modes.MFSK.String()
+	}
+	if m, ok := modes.GetModeBySubmode(name); ok {
+		return m.String()
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package facade
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+)
+
+// WsjtxBandActivity is the recent decodes heard on a band, oldest first.
+type WsjtxBandActivity struct {
+	Band    string        `json:"band"`
+	Decodes []WsjtxDecode `json:"decodes"`
+}
+
+// wsjtxActivity is the rolling band activity of the WSJT-X instances. Decodes are kept per band until they are older
+// than maxAge or pushed out by newer ones, and the bands that change are emitted at most once per interval.
+type wsjtxActivity struct {
+	maxAge     time.Duration
+	maxPerBand int
+
+	mu      sync.Mutex
+	bands   map[string][]WsjtxDecode // oldest first
+	changed map[string]bool
+	// ready holds a signal while there are changed bands.
+	ready chan struct{}
+}
+
+func newWsjtxActivity(opts WsjtxActivityOptions) *wsjtxActivity {
+	return &wsjtxActivity{
+		maxAge:     time.Duration(opts.MaxAgeSeconds) * time.Second,
+		maxPerBand: max(opts.MaxDecodesPerBand, 1),
+		bands:      make(map[string][]WsjtxDecode),
+		changed:    make(map[string]bool),
+		ready:      make(chan struct{}, 1),
+	}
+}
+
+// add stores a decode in its band's activity. Decodes on an unknown band, sent before the instance reported its
+// dial frequency, are dropped.
+func (a *wsjtxActivity) add(decode WsjtxDecode) {
+	if decode.Band == "" {
+		return
+	}
+
+	a.mu.Lock()
+	decodes := append(a.bands[decode.Band], decode)
+	if len(decodes) > a.maxPerBand {
+		decodes = slices.Delete(decodes, 0, len(decodes)-a.maxPerBand)
+	}
+	a.bands[decode.Band] = decodes
+	a.changed[decode.Band] = true
+	a.mu.Unlock()
+
+	select {
+	case a.ready <- struct{}{}:
+	default:
+		// Already signalled; the band will be emitted with the others.
+	}
+}
+
+// pruneLocked drops the decodes received before the cutoff, and the bands left empty. The caller must hold a.mu.
+func (a *wsjtxActivity) pruneLocked(now time.Time) {
+	if a.maxAge <= 0 {
+		return
+	}
+	cutoff := now.Add(-a.maxAge)
+	for band, decodes := range a.bands {
+		i := 0
+		for i < len(decodes) && decodes[i].ReceivedAt.Before(cutoff) {
+			i++
+		}
+		if i == len(decodes) {
+			delete(a.bands, band)
+			continue
+		}
+		a.bands[band] = decodes[i:]
+	}
+}
+
+// activityLocked returns the activity of the given bands that have decodes, in band plan order. The caller must
+// hold a.mu.
+func (a *wsjtxActivity) activityLocked(bands []string) []WsjtxBandActivity {
+	slices.SortFunc(bands, func(x, y string) int { return bandIndex(x) - bandIndex(y) })
+	activity := make([]WsjtxBandActivity, 0, len(bands))
+	for _, band := range bands {
+		if decodes, ok := a.bands[band]; ok {
+			activity = append(activity, WsjtxBandActivity{Band: band, Decodes: slices.Clone(decodes)})
+		}
+	}
+	return activity
+}
+
+// snapshot returns the activity of every band heard.
+func (a *wsjtxActivity) snapshot(now time.Time) []WsjtxBandActivity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(now)
+	bands := make([]string, 0, len(a.bands))
+	for band := range a.bands {
+		bands = append(bands, band)
+	}
+	return a.activityLocked(bands)
+}
+
+// take returns the activity of the bands that changed since the last call, or nil if none did (or all of their
+// decodes have aged out).
+func (a *wsjtxActivity) take(now time.Time) []WsjtxBandActivity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.changed) == 0 {
+		return nil
+	}
+	a.pruneLocked(now)
+	bands := make([]string, 0, len(a.changed))
+	for band := range a.changed {
+		bands = append(bands, band)
+	}
+	clear(a.changed)
+	if activity := a.activityLocked(bands); len(activity) > 0 {
+		return activity
+	}
+	return nil
+}
+
+// run emits the activity of the changed bands whenever there are some, at most once per interval, until stop is
+// closed.
+func (a *wsjtxActivity) run(stop <-chan struct{}, interval time.Duration, emit func([]WsjtxBandActivity)) {
+	runThrottled(stop, a.ready, interval, func() ([]WsjtxBandActivity, bool) {
+		activity := a.take(time.Now())
+		return activity, activity != nil
+	}, emit)
+}
+
+// FetchWsjtxActivity returns the recent decodes of the WSJT-X instances per band, in band plan order.
+func (s *Service) FetchWsjtxActivity() ([]WsjtxBandActivity, error) {
+	const op errors.Op = "facade.Service.FetchWsjtxActivity"
+	if !s.initialized.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotInit)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotInit)
+		return nil, errors.Root(err)
+	}
+
+	if !s.started.Load() {
+		err := errors.New(op).Msg(errMsgServiceNotStarted)
+		s.LoggerService.ErrorWith().Err(err).Msg(errMsgServiceNotStarted)
+		return nil, errors.Root(err)
+	}
+
+	b := s.wsjtx
+	if b == nil {
+		return make([]WsjtxBandActivity, 0), nil
+	}
+
+	return b.activity.snapshot(time.Now()), nil
+}
@@ -0,0 +1,93 @@
+package facade
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/logging-app/backend/wsjtx"
+)
+
+func testWsjtxDecode(band, message string, at time.Time) WsjtxDecode {
+	return WsjtxDecode{Client: "WSJT-X", Band: band, Decode: wsjtx.Decode{ID: "WSJT-X", Message: message}, ReceivedAt: at}
+}
+
+// =============================================================================
+// Activity Buffer Tests
+// =============================================================================
+
+func TestWsjtxActivity_Rolling(t *testing.T) {
+	a := newWsjtxActivity(WsjtxActivityOptions{MaxAgeSeconds: 60, MaxDecodesPerBand: 2})
+	now := time.Now()
+
+	a.add(testWsjtxDecode("20m", "CQ K1ABC FN42", now.Add(-2*time.Minute)))
+	a.add(testWsjtxDecode("20m", "CQ K2ABC FN42", now))
+	a.add(testWsjtxDecode("20m", "CQ K3ABC FN42", now))
+	a.add(testWsjtxDecode("40m", "CQ K4ABC FN42", now.Add(-2*time.Minute)))
+	a.add(testWsjtxDecode("80m", "CQ K5ABC FN42", now))
+	a.add(testWsjtxDecode("", "CQ K6ABC FN42", now))
+
+	// The bands are in band plan order; 40m has aged out and 20m is capped at its newest two decodes.
+	got := a.take(now)
+	if len(got) != 2 || got[0].Band != "80m" || got[1].Band != "20m" {
+		t.Fatalf("take() = %+v, want 80m and 20m", got)
+	}
+	if d := got[1].Decodes; len(d) != 2 || d[0].Decode.Message != "CQ K2ABC FN42" || d[1].Decode.Message != "CQ K3ABC FN42" {
+		t.Errorf("20m decodes = %+v", d)
+	}
+	if again := a.take(now); again != nil {
+		t.Errorf("take() = %+v, want nil with no new decodes", again)
+	}
+
+	a.add(testWsjtxDecode("80m", "CQ K7ABC FN42", now))
+	if got = a.take(now); len(got) != 1 || got[0].Band != "80m" || len(got[0].Decodes) != 2 {
+		t.Errorf("take() = %+v, want only 80m", got)
+	}
+	if all := a.snapshot(now); len(all) != 2 {
+		t.Errorf("snapshot() = %+v, want 80m and 20m", all)
+	}
+}
+
+func TestWsjtxActivity_RunThrottles(t *testing.T) {
+	a := newWsjtxActivity(WsjtxActivityOptions{MaxDecodesPerBand: 10})
+
+	var mu sync.Mutex
+	var emitted [][]WsjtxBandActivity
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.run(stop, 100*time.Millisecond, func(activity []WsjtxBandActivity) {
+			mu.Lock()
+			defer mu.Unlock()
+			emitted = append(emitted, activity)
+		})
+		close(done)
+	}()
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(emitted)
+	}
+
+	now := time.Now()
+	a.add(testWsjtxDecode("20m", "CQ K1ABC FN42", now))
+	if !waitFor(t, time.Second, func() bool { return count() == 1 }) {
+		t.Fatal("the first decode was not emitted")
+	}
+
+	// Decodes within the interval are emitted together.
+	a.add(testWsjtxDecode("20m", "CQ K2ABC FN42", now))
+	a.add(testWsjtxDecode("40m", "CQ K3ABC FN42", now))
+	if !waitFor(t, time.Second, func() bool { return count() == 2 }) {
+		t.Fatal("the later decodes were not emitted")
+	}
+	time.Sleep(150 * time.Millisecond)
+	close(stop)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(emitted) != 2 || len(emitted[1]) != 2 || len(emitted[1][1].Decodes) != 2 {
+		t.Errorf("emitted %+v, want the two bands in one event", emitted)
+	}
+}
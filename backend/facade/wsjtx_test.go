@@ -0,0 +1,363 @@
+package facade
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/listeners"
+	"github.com/Station-Manager/listeners/handlers"
+	"github.com/Station-Manager/logging-app/backend/wsjtx"
+	"github.com/Station-Manager/types"
+)
+
+// wsjtxInstance is a stand-in WSJT-X instance: its messages are passed to the service's WSJT-X packet handler as a
+// listener would, and it reads what is sent back to its socket.
+type wsjtxInstance struct {
+	conn    *net.UDPConn
+	handler handlers.PacketHandler
+}
+
+func newWsjtxInstance(t *testing.T, s *Service, autoLog bool) *wsjtxInstance {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	h, err := newWsjtxPacketHandler(map[string]any{wsjtxConfigService: s, wsjtxConfigAutoLog: autoLog})
+	if err != nil {
+		t.Fatalf("newWsjtxPacketHandler() error = %v", err)
+	}
+	return &wsjtxInstance{conn: conn, handler: h}
+}
+
+func (i *wsjtxInstance) send(t *testing.T, msg any) {
+	t.Helper()
+	data, err := wsjtx.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	pkt := handlers.Packet{Data: data, RemoteAddr: i.conn.LocalAddr(), Protocol: "udp", ListenerName: "WSJT-X"}
+	if err = i.handler.Handle(pkt); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+}
+
+func (i *wsjtxInstance) receive(t *testing.T) any {
+	t.Helper()
+	_ = i.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 4096)
+	n, err := i.conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	msg, err := wsjtx.Parse(buf[:n])
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return msg
+}
+
+// startWsjtxTestWorker creates a WSJT-X server with the default highlight colours and runs its worker until the end of
+// the test. The given countries are cached beforehand so that no lookups are made.
+func startWsjtxTestWorker(t *testing.T, s *Service, countries map[string]types.Country) *wsjtxBridge {
+	t.Helper()
+	opts := defaultAppOptions().Wsjtx
+	opts.Enabled = true
+	b, err := newWsjtx(opts)
+	if err != nil {
+		t.Fatalf("newWsjtx() error = %v", err)
+	}
+	for call, country := range countries {
+		b.countries[call] = country
+	}
+	s.wsjtx = b
+
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		s.wsjtxWorker(shutdown)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(shutdown)
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Error("wsjtxWorker did not stop on shutdown")
+		}
+	})
+	return b
+}
+
+// =============================================================================
+// Decode Parsing Tests
+// =============================================================================
+
+func TestWsjtxDecodeCallsign(t *testing.T) {
+	tests := []struct {
+		message, want string
+	}{
+		{"CQ DL1ABC JO62", "DL1ABC"},
+		{"CQ DX DL1ABC JO62", "DL1ABC"},
+		{"CQ 290 K1ABC FN42", "K1ABC"},
+		{"CQ POTA KH6/W1XYZ", "KH6/W1XYZ"},
+		{"W1AW DL1ABC -10", "DL1ABC"},
+		{"W1AW DL1ABC R-10", "DL1ABC"},
+		{"DL1ABC W1AW RR73", "W1AW"},
+		{"W1AW <PJ4/K1ABC> -08", "PJ4/K1ABC"},
+		{"W1AW <...> RR73", ""},
+		{"CQ DX", ""},
+		{"TNX 73 GL", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := wsjtxDecodeCallsign(tt.message); got != tt.want {
+			t.Errorf("wsjtxDecodeCallsign(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestWsjtxDecodeGrid(t *testing.T) {
+	tests := []struct {
+		message, want string
+	}{
+		{"CQ DL1ABC JO62", "JO62"},
+		{"CQ DX DL1ABC jo62", "JO62"},
+		{"W1AW DL1ABC JO62", "JO62"},
+		{"DL1ABC W1AW RR73", ""},
+		{"W1AW DL1ABC -10", ""},
+		{"CQ DL1ABC", ""},
+		{"CQ DL1ABC ZZ99", ""},
+	}
+	for _, tt := range tests {
+		if got := wsjtxDecodeGrid(tt.message); got != tt.want {
+			t.Errorf("wsjtxDecodeGrid(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestGridDistanceKm(t *testing.T) {
+	tests := []struct {
+		from, to string
+		min, max int
+		ok       bool
+	}{
+		{"FN31pr", "JO62", 6100, 6300, true},
+		{"FN31", "FN31", 0, 0, true},
+		{"", "JO62", 0, 0, false},
+		{"FN31pr", "JO6", 0, 0, false},
+		{"FN31pr", "ZZ99", 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := gridDistanceKm(tt.from, tt.to)
+		if ok != tt.ok || got < tt.min || got > tt.max {
+			t.Errorf("gridDistanceKm(%q, %q) = %d, %v", tt.from, tt.to, got, ok)
+		}
+	}
+}
+
+func TestWsjtxMode(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"FT8", "MFSK"},
+		{"FT4", "MFSK"},
+		{"JT65", "MFSK"},
+		{"psk31", "PSK"},
+		{"", ""},
+		{"ECHO", ""},
+	}
+	for _, tt := range tests {
+		if got := wsjtxMode(tt.name); got != tt.want {
+			t.Errorf("wsjtxMode(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// =============================================================================
+// Server Tests
+// =============================================================================
+
+func TestNewWsjtx(t *testing.T) {
+	b, err := newWsjtx(WsjtxOptions{})
+	if err != nil || b != nil {
+		t.Errorf("newWsjtx(disabled) = %v, %v, want nil", b, err)
+	}
+
+	opts := defaultAppOptions().Wsjtx
+	opts.Enabled = true
+	opts.Highlight.Worked.Foreground = "grey"
+	if _, err = newWsjtx(opts); err == nil {
+		t.Error("newWsjtx() should fail for an invalid colour")
+	}
+}
+
+func TestWsjtxWorker_Highlights(t *testing.T) {
+	s := createDatabaseTestService(t)
+	insertTestQso(t, s, "G4ABC", "20m", "MFSK")
+
+	startWsjtxTestWorker(t, s, map[string]types.Country{
+		"JA1ABC": {Name: "Japan", Ccode: "JP"},
+		"G4ABC":  {Name: "England"},
+		"G3XYZ":  {Name: "England"},
+	})
+
+	wsjt := newWsjtxInstance(t, s, false)
+	wsjt.send(t, wsjtx.Status{ID: "WSJT-X", DialFrequency: 14074000, Mode: "FT8", DeGrid: "FN31"})
+	for _, message := range []string{
+		"CQ JA1ABC PM95",   // a new DXCC entity
+		"CQ JA1ABC PM95",   // already highlighted
+		"W1AW G3XYZ -10",   // a worked entity on a worked band and mode
+		"CQ DX G4ABC IO91", // worked on the band
+	} {
+		wsjt.send(t, wsjtx.Decode{ID: "WSJT-X", New: true, DeltaFrequency: 1500, Mode: "~", Message: message})
+	}
+
+	want := []wsjtx.HighlightCallsign{
+		{ID: "WSJT-X", Callsign: "JA1ABC", Background: wsjtx.Color{Valid: true, R: 0xff}, Foreground: wsjtx.Color{Valid: true, R: 0xff, G: 0xff, B: 0xff}},
+		{ID: "WSJT-X", Callsign: "G4ABC", Foreground: wsjtx.Color{Valid: true, R: 0x80, G: 0x80, B: 0x80}},
+	}
+	for _, w := range want {
+		if got, ok := wsjt.receive(t).(*wsjtx.HighlightCallsign); !ok || *got != w {
+			t.Fatalf("instance received %+v, want %+v", got, w)
+		}
+	}
+
+	// On a band where England has not been worked, the same stations are needed for the band slot.
+	wsjt.send(t, wsjtx.Status{ID: "WSJT-X", DialFrequency: 7074000, Mode: "FT8"})
+	wsjt.send(t, wsjtx.Decode{ID: "WSJT-X", New: true, DeltaFrequency: 1500, Mode: "~", Message: "CQ G4ABC IO91"})
+	slot := wsjtx.HighlightCallsign{ID: "WSJT-X", Callsign: "G4ABC", Background: wsjtx.Color{Valid: true, R: 0xff, G: 0xa5}, Foreground: wsjtx.Color{Valid: true}}
+	if got, ok := wsjt.receive(t).(*wsjtx.HighlightCallsign); !ok || *got != slot {
+		t.Errorf("instance received %+v, want %+v", got, slot)
+	}
+
+	// Every decode is in the band activity, measured from the grid the instance reported.
+	activity, err := s.FetchWsjtxActivity()
+	if err != nil {
+		t.Fatalf("FetchWsjtxActivity() error = %v", err)
+	}
+	if len(activity) != 2 || activity[0].Band != "40m" || activity[1].Band != "20m" {
+		t.Fatalf("FetchWsjtxActivity() = %+v, want 40m and 20m", activity)
+	}
+	if n := len(activity[1].Decodes); n != 4 {
+		t.Fatalf("20m has %d decodes, want 4", n)
+	}
+	ja := activity[1].Decodes[0]
+	if ja.Callsign != "JA1ABC" || ja.Grid != "PM95" || ja.DistanceKm < 10000 || ja.Country != "Japan" || !ja.NewDxcc {
+		t.Errorf("JA1ABC decode = %+v", ja)
+	}
+	g4 := activity[1].Decodes[3]
+	if g4.Callsign != "G4ABC" || !g4.CallWorked || !g4.CallWorkedOnBand || g4.NewDxcc {
+		t.Errorf("G4ABC decode = %+v", g4)
+	}
+}
+
+func TestEnrichWsjtxDecode_LocalCountry(t *testing.T) {
+	s := createDatabaseTestService(t)
+	opts := defaultAppOptions().Wsjtx
+	opts.Enabled = true
+	b, err := newWsjtx(opts)
+	if err != nil {
+		t.Fatalf("newWsjtx() error = %v", err)
+	}
+	decode := WsjtxDecode{Decode: wsjtx.Decode{Message: "CQ JA1ABC PM95"}, Band: "20m", Mode: "MFSK"}
+
+	// A station whose country has not been looked up is classified without one, and is not cached.
+	if got := s.enrichWsjtxDecode(b, decode, "FN31"); got.Callsign != "JA1ABC" || got.Country != "" || got.NewDxcc {
+		t.Errorf("enrichWsjtxDecode() = %+v, want no country", got)
+	}
+
+	if _, err = s.DatabaseService.InsertCountry(types.Country{Name: "Japan", Prefix: "JA", Ccode: "JP", Continent: "AS"}); err != nil {
+		t.Fatalf("InsertCountry() error = %v", err)
+	}
+	if got := s.enrichWsjtxDecode(b, decode, "FN31"); got.Country != "Japan" || !got.NewDxcc {
+		t.Errorf("enrichWsjtxDecode() = %+v, want a new DXCC in Japan", got)
+	}
+}
+
+func TestReplyToWsjtxDecode(t *testing.T) {
+	s := createDatabaseTestService(t)
+
+	if err := s.ReplyToWsjtxDecode(WsjtxDecode{Client: "WSJT-X"}); err == nil {
+		t.Error("ReplyToWsjtxDecode() should fail when WSJT-X is not enabled")
+	}
+
+	b := startWsjtxTestWorker(t, s, nil)
+	decode := wsjtx.Decode{ID: "WSJT-X", New: true, Time: 45015000, SNR: -12, DeltaTime: 0.2, DeltaFrequency: 1234, Mode: "~", Message: "CQ K1ABC FN42"}
+	if err := s.ReplyToWsjtxDecode(WsjtxDecode{Client: "WSJT-X", Decode: decode}); err == nil {
+		t.Error("ReplyToWsjtxDecode() should fail for an instance not heard from")
+	}
+
+	wsjt := newWsjtxInstance(t, s, false)
+	wsjt.send(t, wsjtx.Heartbeat{ID: "WSJT-X", MaxSchema: 3})
+	if clients := b.server.Clients(); len(clients) != 1 {
+		t.Fatalf("Clients() = %v, want the instance registered", clients)
+	}
+
+	if err := s.ReplyToWsjtxDecode(WsjtxDecode{Client: "WSJT-X", Decode: decode, Callsign: "K1ABC"}); err != nil {
+		t.Fatalf("ReplyToWsjtxDecode() error = %v", err)
+	}
+	want := wsjtx.Reply{ID: "WSJT-X", Time: decode.Time, SNR: decode.SNR, DeltaTime: decode.DeltaTime,
+		DeltaFrequency: decode.DeltaFrequency, Mode: decode.Mode, Message: decode.Message}
+	if got, ok := wsjt.receive(t).(*wsjtx.Reply); !ok || *got != want {
+		t.Errorf("instance received %+v, want %+v", got, want)
+	}
+}
+
+// =============================================================================
+// Packet Handler Tests
+// =============================================================================
+
+func TestNewWsjtxPacketHandler(t *testing.T) {
+	if _, err := newWsjtxPacketHandler(map[string]any{wsjtxConfigAutoLog: true}); err == nil {
+		t.Error("newWsjtxPacketHandler() should fail without the service")
+	}
+	if factory, ok := handlers.Get(wsjtxHandlerName); !ok || factory == nil {
+		t.Errorf("handlers.Get(%q) = %v, want the facade's handler registered", wsjtxHandlerName, ok)
+	}
+}
+
+func TestConfigureWsjtxListeners(t *testing.T) {
+	shared := map[string]any{"auto_log": true}
+	s := &Service{ListenersService: &listeners.Service{ListenerConfigs: []types.ListenerConfig{
+		{Name: "WSJT-X", Handler: wsjtxHandlerName, HandlerConfig: shared},
+		{Name: "Other"},
+	}}}
+	s.configureWsjtxListeners()
+
+	cfgs := s.ListenersService.ListenerConfigs
+	if got := cfgs[0].HandlerConfig[wsjtxConfigService]; got != s {
+		t.Errorf("WSJT-X listener %s = %v, want the service", wsjtxConfigService, got)
+	}
+	if cfgs[0].HandlerConfig[wsjtxConfigAutoLog] != true {
+		t.Error("the shared handler config was not kept")
+	}
+	if _, ok := shared[wsjtxConfigService]; ok {
+		t.Error("the shared config's map should not be changed")
+	}
+	if cfgs[1].HandlerConfig != nil {
+		t.Errorf("other listener config = %v, want it untouched", cfgs[1].HandlerConfig)
+	}
+}
+
+func TestWsjtxPacketHandler_LoggedAdif(t *testing.T) {
+	s := createAdifTestService(t)
+	logged := wsjtx.LoggedAdif{ID: "WSJT-X", Adif: "<adif_ver:5>3.1.0<programid:6>WSJT-X<EOH>" + testAdifRecord}
+
+	// Without auto_log, a logged QSO is left to the operator.
+	newWsjtxInstance(t, s, false).send(t, logged)
+	if n := adifQsoCount(t, s); n != 0 {
+		t.Fatalf("%d QSOs logged without auto_log, want 0", n)
+	}
+
+	// With it, the QSO is logged once, however many times it is reported, even with the integration disabled.
+	wsjt := newWsjtxInstance(t, s, true)
+	wsjt.send(t, logged)
+	wsjt.send(t, logged)
+	if n := adifQsoCount(t, s); n != 1 {
+		t.Errorf("%d QSOs logged with auto_log, want 1", n)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package restapi serves a small HTTP API for scripts, Stream Deck buttons and
+other station software: logging a QSO, searching the log, looking up a
+callsign, reading the rig state and listing the QSOs of the current session.
+
+Every request must carry the configured token as "Authorization: Bearer
+<token>". Requests and responses are JSON; QSOs have the shape of types.Qso,
+as passed to the frontend. Errors are reported as {"error": "..."} with a
+4xx or 5xx status.
+
+	POST /api/v1/qsos              log a QSO; responds 201 with the logged QSO
+	GET  /api/v1/qsos              search the log: call (a trailing * matches a
+	                               prefix), band, mode, from and to (YYYYMMDD)
+	                               and limit
+	GET  /api/v1/lookup/{callsign} a new QSO initialized for the callsign
+	GET  /api/v1/cat               the rig state and the CAT connection state
+	GET  /api/v1/session/qsos      the QSOs of the current session
+	GET  /api/v1/events            the event stream (WebSocket)
+
+The operations themselves are left to the Backend.
+
+# Event Stream
+
+The events the logger shows in its window, such as the rig state and DX
+spots, are streamed to WebSocket clients as they are published, each as
+
+	{"event": "STATUS", "data": {...}, "time": "2026-10-18T08:00:15Z"}
+
+A client receives every event unless it names the ones it wants in the
+events query parameter, e.g. ?events=STATUS,DX_SPOT, or later sends
+{"subscribe": ["QSO_RATE"]} to replace them; "*" selects every event
+again. As browsers cannot set the Authorization header on a WebSocket
+handshake, the token may be passed as the token query parameter instead.
+A client that falls far behind is disconnected rather than holding up the
+others.
+*/
+package restapi
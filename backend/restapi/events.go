@@ -0,0 +1,239 @@
+package restapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// eventBuffer is how many events may wait for a slow subscriber before it is disconnected.
+	eventBuffer = 256
+	// eventWriteTimeout bounds the time a subscriber may take to accept a message.
+	eventWriteTimeout = 10 * time.Second
+	// eventPongTimeout is how long a subscriber may stay silent, and eventPingInterval how often it is pinged.
+	eventPongTimeout  = 60 * time.Second
+	eventPingInterval = eventPongTimeout * 9 / 10
+	// maxSubscribeBytes bounds a message from a subscriber.
+	maxSubscribeBytes = 4096
+)
+
+// allEvents is the filter that matches every event.
+const allEvents = "*"
+
+// Event is a message on the event stream.
+type Event struct {
+	Event string    `json:"event"`
+	Data  any       `json:"data"`
+	Time  time.Time `json:"time"`
+}
+
+// subscribeMessage replaces the events a subscriber receives. An empty list, or one containing "*", selects every
+// event.
+type subscribeMessage struct {
+	Subscribe []string `json:"subscribe"`
+}
+
+// eventHub passes the published events on to the subscribers of the event stream.
+type eventHub struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[*subscriber]struct{})}
+}
+
+// subscriber is a connection to the event stream.
+type subscriber struct {
+	conn *websocket.Conn
+	send chan []byte
+
+	mu     sync.Mutex
+	filter map[string]bool // nil for every event
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// setFilter selects the events the subscriber receives.
+func (c *subscriber) setFilter(names []string) {
+	var filter map[string]bool
+	for _, name := range names {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == allEvents {
+			filter = nil
+			break
+		}
+		if name == "" {
+			continue
+		}
+		if filter == nil {
+			filter = make(map[string]bool)
+		}
+		filter[name] = true
+	}
+
+	c.mu.Lock()
+	c.filter = filter
+	c.mu.Unlock()
+}
+
+func (c *subscriber) wants(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.filter == nil || c.filter[name]
+}
+
+// close disconnects the subscriber, telling it why with the given close code unless the code is zero, as when the
+// connection has failed.
+func (c *subscriber) close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		if code != 0 {
+			msg := websocket.FormatCloseMessage(code, reason)
+			_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		}
+		_ = c.conn.Close()
+		close(c.done)
+	})
+}
+
+// publish sends an event to the subscribers that want it. A subscriber that has fallen eventBuffer events behind is
+// disconnected rather than holding up the others.
+func (h *eventHub) publish(name string, data any) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var msg []byte
+	for c := range h.subscribers {
+		if !c.wants(name) {
+			continue
+		}
+		if msg == nil {
+			var err error
+			if msg, err = json.Marshal(Event{Event: name, Data: data, Time: time.Now().UTC()}); err != nil {
+				return
+			}
+		}
+		select {
+		case c.send <- msg:
+		default:
+			go c.close(websocket.ClosePolicyViolation, "Too slow")
+		}
+	}
+}
+
+func (h *eventHub) add(c *subscriber) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.subscribers[c] = struct{}{}
+	return true
+}
+
+func (h *eventHub) remove(c *subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, c)
+	h.mu.Unlock()
+}
+
+// close disconnects every subscriber and refuses new ones.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	h.closed = true
+	subscribers := make([]*subscriber, 0, len(h.subscribers))
+	for c := range h.subscribers {
+		subscribers = append(subscribers, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range subscribers {
+		c.close(websocket.CloseGoingAway, "Server stopping")
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	// The token authorizes the request, so pages served from anywhere, such as a display on a tablet, may subscribe.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// serveEvents upgrades the request to a WebSocket and streams the events to it. The events query parameter selects
+// the events, as a comma-separated list of names; the subscriber may change it later with a subscribeMessage.
+func (h *eventHub) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		writeError(w, Errorf(http.StatusBadRequest, "A WebSocket upgrade is required"))
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has responded.
+		return
+	}
+
+	c := &subscriber{conn: conn, send: make(chan []byte, eventBuffer), done: make(chan struct{})}
+	if v := r.URL.Query().Get("events"); v != "" {
+		c.setFilter(strings.Split(v, ","))
+	}
+	if !h.add(c) {
+		c.close(websocket.CloseGoingAway, "Server stopping")
+		return
+	}
+	defer h.remove(c)
+
+	go c.readSubscriptions()
+	c.writeEvents()
+}
+
+// readSubscriptions applies the subscribeMessages from the subscriber until it disconnects.
+func (c *subscriber) readSubscriptions() {
+	defer c.close(websocket.CloseNormalClosure, "")
+
+	c.conn.SetReadLimit(maxSubscribeBytes)
+	_ = c.conn.SetReadDeadline(time.Now().Add(eventPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(eventPongTimeout))
+	})
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg subscribeMessage
+		if err = json.Unmarshal(data, &msg); err != nil {
+			c.close(websocket.CloseUnsupportedData, "Invalid subscribe message")
+			return
+		}
+		c.setFilter(msg.Subscribe)
+	}
+}
+
+// writeEvents sends the events and keep-alive pings to the subscriber until it is disconnected.
+func (c *subscriber) writeEvents() {
+	ping := time.NewTicker(eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.close(0, "")
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventWriteTimeout)); err != nil {
+				c.close(0, "")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package restapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// subscribe connects to the event stream of the server with the given query, e.g. "?events=STATUS".
+func subscribe(t *testing.T, srv *Server, query string, header http.Header) *websocket.Conn {
+	t.Helper()
+	before := srv.events.count()
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+srv.Addr().String()+"/api/v1/events"+query, header)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	// The subscriber is added once the handshake has completed.
+	deadline := time.Now().Add(2 * time.Second)
+	for srv.events.count() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("the subscriber was not added")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return conn
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": []string{"Bearer " + token}}
+}
+
+// count returns the number of subscribers.
+func (h *eventHub) count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// allWant reports whether every subscriber wants the event.
+func (h *eventHub) allWant(name string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.subscribers {
+		if !c.wants(name) {
+			return false
+		}
+	}
+	return true
+}
+
+func receiveEvent(t *testing.T, conn *websocket.Conn) Event {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var e Event
+	if err := conn.ReadJSON(&e); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	return e
+}
+
+// =============================================================================
+// Event Stream Tests
+// =============================================================================
+
+func TestEvents_Authorization(t *testing.T) {
+	srv := serve(t, &fakeBackend{})
+	url := "ws://" + srv.Addr().String() + "/api/v1/events"
+
+	for _, query := range []string{"", "?token=wrong"} {
+		_, resp, err := websocket.DefaultDialer.Dial(url+query, nil)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Dial(%q) = %v, want 401", query, err)
+		}
+	}
+
+	// Browsers pass the token in the URL; other requests may not.
+	subscribe(t, srv, "?token="+testToken, nil)
+	if code := call(t, http.MethodGet, "http://"+srv.Addr().String()+"/api/v1/events", "", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /events without an upgrade = %d, want 400", code)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://"+srv.Addr().String()+"/api/v1/cat?token="+testToken, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /cat with the token in the URL = %v, %v, want 401", resp, err)
+	} else {
+		_ = resp.Body.Close()
+	}
+}
+
+func TestEvents_Filter(t *testing.T) {
+	srv := serve(t, &fakeBackend{})
+	all := subscribe(t, srv, "", bearer(testToken))
+	spots := subscribe(t, srv, "?events=dx_spot,QSO_RATE", bearer(testToken))
+
+	srv.Publish("STATUS", map[string]string{"VfoAFreq": "014074000"})
+	srv.Publish("DX_SPOT", map[string]string{"dx": "JA1ABC"})
+
+	if e := receiveEvent(t, all); e.Event != "STATUS" || e.Time.IsZero() {
+		t.Errorf("first event = %+v, want STATUS", e)
+	}
+	if e := receiveEvent(t, all); e.Event != "DX_SPOT" {
+		t.Errorf("second event = %+v, want DX_SPOT", e)
+	}
+	e := receiveEvent(t, spots)
+	if data, ok := e.Data.(map[string]any); e.Event != "DX_SPOT" || !ok || data["dx"] != "JA1ABC" {
+		t.Errorf("filtered event = %+v, want the DX spot", e)
+	}
+
+	// A subscribe message replaces the filter.
+	if err := spots.WriteJSON(subscribeMessage{Subscribe: []string{"STATUS"}}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !srv.events.allWant("STATUS") {
+		if time.Now().After(deadline) {
+			t.Fatal("the subscribe message was not applied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	srv.Publish("QSO_RATE", nil)
+	srv.Publish("STATUS", nil)
+	if e := receiveEvent(t, spots); e.Event != "STATUS" {
+		t.Errorf("event after subscribing = %+v, want STATUS", e)
+	}
+}
+
+func TestEvents_InvalidSubscribe(t *testing.T) {
+	srv := serve(t, &fakeBackend{})
+	conn := subscribe(t, srv, "", bearer(testToken))
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("subscribe STATUS")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseUnsupportedData) {
+		t.Errorf("ReadMessage() error = %v, want close 1003", err)
+	}
+}
+
+func TestEvents_CloseOnShutdown(t *testing.T) {
+	srv, err := Listen(Config{Address: "127.0.0.1:0", Token: testToken}, &fakeBackend{})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(t.Context()) }()
+	conn := subscribe(t, srv, "", bearer(testToken))
+
+	srv.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err = conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("ReadMessage() error = %v, want close 1001", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Error("Serve() did not return after Close()")
+	}
+	// Publishing to a closed server is harmless.
+	srv.Publish("STATUS", nil)
+}
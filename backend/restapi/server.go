@@ -0,0 +1,271 @@
+package restapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	stderr "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultMaxBodyBytes = 1 << 20
+	// shutdownTimeout bounds the wait for the requests in progress when the server stops.
+	shutdownTimeout = 5 * time.Second
+	// readHeaderTimeout bounds the time a client may take to send the request headers.
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Config holds the settings of a server.
+type Config struct {
+	// Address is the host:port to listen on, e.g. "127.0.0.1:8073".
+	Address string
+	// Token must be presented by every request as a bearer token. It is required.
+	Token string
+	// MaxBodyBytes bounds a request body; it defaults to 1 MiB.
+	MaxBodyBytes int64
+}
+
+// Search selects QSOs in the log. Empty fields match any QSO.
+type Search struct {
+	Call  string
+	Band  string
+	Mode  string
+	From  string // YYYYMMDD
+	To    string // YYYYMMDD
+	Limit int    // zero for the backend's default
+}
+
+// CatStatus is the rig state as last reported over CAT, and the state of the connection to the rig.
+type CatStatus struct {
+	Connection string            `json:"connection"`
+	Error      string            `json:"error,omitempty"` // the reason for the last disconnection, if any
+	State      map[string]string `json:"state"`
+	UpdatedAt  time.Time         `json:"updated_at"` // zero if the rig has not reported its state
+}
+
+// Backend carries out the operations of the API. Calls are made from the server's goroutines, one per request,
+// so implementations must be safe for concurrent use. Errors of type *Error are reported with their status;
+// other errors with 500 Internal Server Error.
+type Backend interface {
+	// LogQso logs a QSO and returns it as logged, with its ID.
+	LogQso(qso types.Qso) (types.Qso, error)
+	SearchQsos(search Search) ([]types.Qso, error)
+	// Lookup returns a new QSO initialized for a callsign, as the logger would start it.
+	Lookup(callsign string) (types.Qso, error)
+	CatStatus() (CatStatus, error)
+	SessionQsos() ([]types.Qso, error)
+}
+
+// Error is an error reported to the client with the given HTTP status.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf returns an *Error with the given status and formatted message.
+func Errorf(status int, format string, args ...any) error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// Server serves the API.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+	mux      *http.ServeMux
+	events   *eventHub
+}
+
+// Listen binds a server for the given configuration. Requests are not served until Serve is called.
+func Listen(cfg Config, backend Backend) (*Server, error) {
+	const op errors.Op = "restapi.Listen"
+
+	if strings.TrimSpace(cfg.Token) == "" {
+		return nil, errors.New(op).Msg("A token is required")
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	l, err := net.Listen("tcp", strings.TrimSpace(cfg.Address))
+	if err != nil {
+		return nil, errors.New(op).Err(err).Msgf("Failed to listen on %q", cfg.Address)
+	}
+
+	s := &Server{listener: l, mux: http.NewServeMux(), events: newEventHub()}
+	h := &handlers{backend: backend, maxBodyBytes: cfg.MaxBodyBytes}
+	s.mux.HandleFunc("POST /api/v1/qsos", h.logQso)
+	s.mux.HandleFunc("GET /api/v1/qsos", h.searchQsos)
+	s.mux.HandleFunc("GET /api/v1/lookup/{callsign}", h.lookup)
+	s.mux.HandleFunc("GET /api/v1/cat", h.catStatus)
+	s.mux.HandleFunc("GET /api/v1/session/qsos", h.sessionQsos)
+	s.mux.HandleFunc("GET /api/v1/events", s.events.serveEvents)
+
+	s.server = &http.Server{
+		Handler:           authorize(cfg.Token, s.mux),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	// Shutdown does not wait for hijacked connections, so the event stream is closed with the server.
+	s.server.RegisterOnShutdown(s.events.close)
+	return s, nil
+}
+
+// Addr returns the address the server is bound to.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Publish sends an event to the subscribers of the event stream that want it. The data is sent as JSON. It does not
+// block.
+func (s *Server) Publish(name string, data any) {
+	s.events.publish(name, data)
+}
+
+// Close stops the server at once. Serve does so gracefully when its context is cancelled.
+func (s *Server) Close() {
+	s.events.close()
+	_ = s.server.Close()
+	_ = s.listener.Close()
+}
+
+// Serve serves requests until the context is cancelled, and then waits for the requests in progress to complete.
+// It returns nil once stopped by the context.
+func (s *Server) Serve(ctx context.Context) error {
+	const op errors.Op = "restapi.Server.Serve"
+
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			_ = s.server.Close()
+		}
+	})
+	defer stop()
+
+	err := s.server.Serve(s.listener)
+	if stderr.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return errors.New(op).Err(err)
+}
+
+// authorize passes on the requests that carry the token, and rejects the others. Browsers cannot set headers on a
+// WebSocket handshake, so it may pass the token as the token query parameter instead.
+func authorize(token string, next http.Handler) http.Handler {
+	want := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok && websocket.IsWebSocketUpgrade(r) {
+			got = r.URL.Query().Get("token")
+			ok = got != ""
+		}
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="station-manager"`)
+			writeError(w, Errorf(http.StatusUnauthorized, "A valid token is required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type handlers struct {
+	backend      Backend
+	maxBodyBytes int64
+}
+
+func (h *handlers) logQso(w http.ResponseWriter, r *http.Request) {
+	var qso types.Qso
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	if err := dec.Decode(&qso); err != nil {
+		writeError(w, Errorf(http.StatusBadRequest, "Invalid QSO: %v", err))
+		return
+	}
+
+	logged, err := h.backend.LogQso(qso)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, logged)
+}
+
+func (h *handlers) searchQsos(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	search := Search{
+		Call: q.Get("call"),
+		Band: q.Get("band"),
+		Mode: q.Get("mode"),
+		From: q.Get("from"),
+		To:   q.Get("to"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			writeError(w, Errorf(http.StatusBadRequest, "Invalid limit: %q", v))
+			return
+		}
+		search.Limit = limit
+	}
+
+	qsos, err := h.backend.SearchQsos(search)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, qsos)
+}
+
+func (h *handlers) lookup(w http.ResponseWriter, r *http.Request) {
+	qso, err := h.backend.Lookup(r.PathValue("callsign"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, qso)
+}
+
+func (h *handlers) catStatus(w http.ResponseWriter, _ *http.Request) {
+	status, err := h.backend.CatStatus()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *handlers) sessionQsos(w http.ResponseWriter, _ *http.Request) {
+	qsos, err := h.backend.SessionQsos()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, qsos)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var e *Error
+	if stderr.As(err, &e) {
+		status = e.Status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
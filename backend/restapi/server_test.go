@@ -0,0 +1,205 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	stderr "errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/types"
+)
+
+const testToken = "s3cret"
+
+// fakeBackend records the calls made to it and answers with canned values.
+type fakeBackend struct {
+	mu       sync.Mutex
+	logged   []types.Qso
+	searches []Search
+	err      error
+}
+
+func (b *fakeBackend) LogQso(qso types.Qso) (types.Qso, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return types.Qso{}, b.err
+	}
+	b.logged = append(b.logged, qso)
+	qso.ID = int64(len(b.logged))
+	return qso, nil
+}
+
+func (b *fakeBackend) SearchQsos(search Search) ([]types.Qso, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.searches = append(b.searches, search)
+	return []types.Qso{{ID: 7}}, b.err
+}
+
+func (b *fakeBackend) Lookup(callsign string) (types.Qso, error) {
+	if callsign == "NOPE" {
+		return types.Qso{}, Errorf(http.StatusBadRequest, "Invalid callsign")
+	}
+	return types.Qso{ContactedStation: types.ContactedStation{Call: callsign}}, nil
+}
+
+func (b *fakeBackend) CatStatus() (CatStatus, error) {
+	return CatStatus{Connection: "connected", State: map[string]string{"VfoAFreq": "014074000"}}, nil
+}
+
+func (b *fakeBackend) SessionQsos() ([]types.Qso, error) {
+	return nil, stderr.New("database is locked")
+}
+
+// startServer serves the API for the backend until the end of the test and returns its base URL.
+func startServer(t *testing.T, backend Backend) string {
+	t.Helper()
+	return "http://" + serve(t, backend).Addr().String()
+}
+
+// serve serves the API for the backend until the end of the test.
+func serve(t *testing.T, backend Backend) *Server {
+	t.Helper()
+	srv, err := Listen(Config{Address: "127.0.0.1:0", Token: testToken}, backend)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Serve() error = %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Serve() did not return after cancellation")
+		}
+	})
+	return srv
+}
+
+// call makes a request with the test token and decodes the JSON response into out, if set.
+func call(t *testing.T, method, url, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil {
+		if err = json.Unmarshal(data, out); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// =============================================================================
+// Server Tests
+// =============================================================================
+
+func TestListen_RequiresToken(t *testing.T) {
+	if srv, err := Listen(Config{Address: "127.0.0.1:0", Token: " "}, &fakeBackend{}); err == nil {
+		srv.Close()
+		t.Error("Listen() should fail without a token")
+	}
+}
+
+func TestServer_Authorization(t *testing.T) {
+	base := startServer(t, &fakeBackend{})
+
+	for _, header := range []string{"", "Bearer wrong", "Basic " + testToken, testToken} {
+		req, _ := http.NewRequest(http.MethodGet, base+"/api/v1/cat", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("Authorization %q: status %d, want 401 with a challenge", header, resp.StatusCode)
+		}
+	}
+
+	var status CatStatus
+	if code := call(t, http.MethodGet, base+"/api/v1/cat", "", &status); code != http.StatusOK || status.Connection != "connected" {
+		t.Errorf("GET /cat = %d, %+v", code, status)
+	}
+}
+
+func TestServer_LogQso(t *testing.T) {
+	backend := &fakeBackend{}
+	base := startServer(t, backend)
+
+	var logged types.Qso
+	code := call(t, http.MethodPost, base+"/api/v1/qsos", `{"call":"DL1ABC","band":"20m","mode":"SSB"}`, &logged)
+	if code != http.StatusCreated || logged.ID != 1 || logged.Call != "DL1ABC" || logged.Band != "20m" {
+		t.Errorf("POST /qsos = %d, %+v", code, logged)
+	}
+
+	var e map[string]string
+	if code = call(t, http.MethodPost, base+"/api/v1/qsos", `{"call":`, &e); code != http.StatusBadRequest || e["error"] == "" {
+		t.Errorf("POST /qsos with a truncated body = %d, %v", code, e)
+	}
+
+	backend.err = Errorf(http.StatusUnprocessableEntity, "QSO validation failed")
+	if code = call(t, http.MethodPost, base+"/api/v1/qsos", `{"call":"DL1ABC"}`, &e); code != http.StatusUnprocessableEntity || e["error"] != "QSO validation failed" {
+		t.Errorf("POST /qsos rejected by the backend = %d, %v", code, e)
+	}
+}
+
+func TestServer_SearchQsos(t *testing.T) {
+	backend := &fakeBackend{}
+	base := startServer(t, backend)
+
+	var qsos []types.Qso
+	code := call(t, http.MethodGet, base+"/api/v1/qsos?call=DL*&band=20m&mode=CW&from=20240101&to=20241231&limit=5", "", &qsos)
+	if code != http.StatusOK || len(qsos) != 1 {
+		t.Fatalf("GET /qsos = %d, %+v", code, qsos)
+	}
+	want := Search{Call: "DL*", Band: "20m", Mode: "CW", From: "20240101", To: "20241231", Limit: 5}
+	if len(backend.searches) != 1 || backend.searches[0] != want {
+		t.Errorf("searches = %+v, want %+v", backend.searches, want)
+	}
+
+	if code = call(t, http.MethodGet, base+"/api/v1/qsos?limit=many", "", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /qsos with an invalid limit = %d, want 400", code)
+	}
+}
+
+func TestServer_LookupAndErrors(t *testing.T) {
+	base := startServer(t, &fakeBackend{})
+
+	var qso types.Qso
+	if code := call(t, http.MethodGet, base+"/api/v1/lookup/K1ABC", "", &qso); code != http.StatusOK || qso.Call != "K1ABC" {
+		t.Errorf("GET /lookup/K1ABC = %d, %+v", code, qso)
+	}
+	if code := call(t, http.MethodGet, base+"/api/v1/lookup/NOPE", "", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /lookup/NOPE = %d, want 400", code)
+	}
+
+	var e map[string]string
+	if code := call(t, http.MethodGet, base+"/api/v1/session/qsos", "", &e); code != http.StatusInternalServerError || e["error"] != "database is locked" {
+		t.Errorf("GET /session/qsos = %d, %v", code, e)
+	}
+	if code := call(t, http.MethodDelete, base+"/api/v1/qsos", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /qsos = %d, want 405", code)
+	}
+}
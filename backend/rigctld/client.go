@@ -0,0 +1,421 @@
+package rigctld
+
+import (
+	"bufio"
+	stderr "errors"
+	"fmt"
+	"maps"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/types"
+)
+
+const (
+	DefaultPort = 4532
+
+	defaultPollInterval   = 500 * time.Millisecond
+	defaultTimeout        = 2 * time.Second
+	defaultReconnectDelay = 5 * time.Second
+	sendChannelSize       = 10
+
+	// pollRequest is queued on the send channel by the init and read commands, to poll every state at once.
+	pollRequest = ""
+)
+
+// Config holds the connection settings for a rigctld daemon.
+type Config struct {
+	Host string
+	Port int
+	// Rig is the configured rig. Only its identity is used; the commands and states are the client's own.
+	Rig          types.RigConfig
+	PollInterval time.Duration
+	// Timeout bounds connecting to the daemon and each command's reply.
+	Timeout        time.Duration
+	ReconnectDelay time.Duration
+	// ErrorHandler, if set, is called with connection failures and commands the rig rejected. It is called from the
+	// client's goroutine, so it should return quickly.
+	ErrorHandler func(err error)
+}
+
+// runState encapsulates the state of the client during its current run.
+type runState struct {
+	shutdownChannel chan struct{}
+	wg              sync.WaitGroup
+}
+
+// Client is a CAT backend for a rig controlled by rigctld.
+type Client struct {
+	cfg Config
+	rig types.RigConfig
+
+	started    atomic.Bool
+	mu         sync.Mutex
+	currentRun *runState
+
+	statusChannel chan types.CatStatus
+	sendChannel   chan string
+}
+
+// replyError is a command the daemon replied to with a non-zero RPRT code, e.g., one the rig does not support.
+type replyError struct {
+	cmd  string
+	code int
+}
+
+func (e *replyError) Error() string {
+	return fmt.Sprintf("rigctld command %q failed: RPRT %d", e.cmd, e.code)
+}
+
+// New returns a client for the given configuration, applying defaults for unset values.
+func New(cfg Config) (*Client, error) {
+	const op errors.Op = "rigctld.New"
+
+	cfg.Host = strings.TrimSpace(cfg.Host)
+	if cfg.Host == "" {
+		return nil, errors.New(op).Msg("rigctld host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = DefaultPort
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		return nil, errors.New(op).Msgf("Invalid rigctld port: %d", cfg.Port)
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+
+	return &Client{
+		cfg: cfg,
+		rig: rigConfig(cfg.Rig),
+		// As with the serial drivers, the status stream is "latest-wins" so the frontend does not lag behind.
+		statusChannel: make(chan types.CatStatus, 1),
+		sendChannel:   make(chan string, sendChannelSize),
+	}, nil
+}
+
+// Start connects to the daemon and starts polling the rig. A daemon that cannot be reached is retried until the
+// client is stopped, so Start does not fail for it.
+func (c *Client) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started.Load() {
+		return nil
+	}
+
+	run := &runState{shutdownChannel: make(chan struct{})}
+	c.currentRun = run
+
+	run.wg.Add(1)
+	go func() {
+		defer run.wg.Done()
+		c.run(run.shutdownChannel)
+	}()
+
+	c.started.Store(true)
+
+	return nil
+}
+
+// Stop disconnects from the daemon and waits for the client's goroutine to finish.
+func (c *Client) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started.Load() {
+		return nil
+	}
+	c.started.Store(false)
+
+	run := c.currentRun
+	c.currentRun = nil
+	close(run.shutdownChannel)
+	run.wg.Wait()
+
+	return nil
+}
+
+// StatusChannel returns the channel on which the rig's state is delivered. Each status holds the states that have
+// changed since the last, or every state after connecting and after an init or read command.
+func (c *Client) StatusChannel() (<-chan types.CatStatus, error) {
+	return c.statusChannel, nil
+}
+
+// RigConfig returns the client's rig config, listing the commands and states it supports.
+func (c *Client) RigConfig() types.RigConfig {
+	return c.rig
+}
+
+// EnqueueCommand queues a command with the given name and parameters to be sent to the daemon.
+func (c *Client) EnqueueCommand(cmdName cmds.CatCmdName, params ...string) error {
+	const op errors.Op = "rigctld.Client.EnqueueCommand"
+	if !c.started.Load() {
+		return errors.New(op).Msg("Client not started.")
+	}
+
+	command, ok := lookupCommand(cmdName)
+	if !ok {
+		return errors.New(op).Msgf("Command %s is not supported by rigctld", cmdName)
+	}
+
+	if expected := strings.Count(command.Cmd, "%s"); expected != len(params) {
+		return errors.New(op).Msgf("Invalid command format: expected %d parameters, got %d", expected, len(params))
+	}
+
+	args := make([]any, len(params))
+	valid := validParam
+	if cmdName == CmdSendCw {
+		valid = validText
+	}
+	for i, p := range params {
+		if !valid(p) {
+			return errors.New(op).Msgf("Invalid command parameter: %q", p)
+		}
+		args[i] = p
+	}
+
+	select {
+	case c.sendChannel <- fmt.Sprintf(command.Cmd, args...):
+		return nil
+	default:
+		return errors.New(op).Msg("Send channel is full.")
+	}
+}
+
+// run connects to the daemon and runs a session until shutdown, reconnecting whenever the connection is lost.
+func (c *Client) run(shutdown <-chan struct{}) {
+	const op errors.Op = "rigctld.Client.run"
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	for {
+		conn, err := net.DialTimeout("tcp", addr, c.cfg.Timeout)
+		if err == nil {
+			err = c.session(conn, shutdown)
+			_ = conn.Close()
+		}
+		if err != nil {
+			c.reportError(errors.New(op).Err(err))
+		}
+
+		select {
+		case <-shutdown:
+			return
+		case <-time.After(c.cfg.ReconnectDelay):
+		}
+	}
+}
+
+// session polls the rig and sends the queued commands over a single connection. It returns nil on shutdown and
+// the error otherwise.
+func (c *Client) session(conn net.Conn, shutdown <-chan struct{}) error {
+	// Unblock a pending read as soon as shutdown is signalled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-shutdown:
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	r := bufio.NewReader(conn)
+	ticker := time.NewTicker(c.cfg.PollInterval)
+	defer ticker.Stop()
+
+	last := types.CatStatus{}
+	full := true
+	for {
+		status, err := c.poll(conn, r)
+		if err != nil {
+			return c.sessionErr(err, shutdown)
+		}
+		if changed := statusChanges(last, status, full); len(changed) > 0 {
+			c.sendStatus(changed)
+		}
+		last, full = status, false
+
+		select {
+		case <-shutdown:
+			return nil
+		case <-ticker.C:
+		case cmd := <-c.sendChannel:
+			if cmd == pollRequest {
+				full = true
+				continue
+			}
+			if _, err = c.request(conn, r, cmd); err != nil {
+				var rerr *replyError
+				if !stderr.As(err, &rerr) {
+					return c.sessionErr(err, shutdown)
+				}
+				c.reportError(err)
+			}
+		}
+	}
+}
+
+// sessionErr returns nil for the error caused by closing the connection on shutdown.
+func (c *Client) sessionErr(err error, shutdown <-chan struct{}) error {
+	select {
+	case <-shutdown:
+		return nil
+	default:
+		return err
+	}
+}
+
+// poll reads every state from the rig. States the rig does not support are left out.
+func (c *Client) poll(conn net.Conn, r *bufio.Reader) (types.CatStatus, error) {
+	status := types.CatStatus{tags.Identity.String(): c.rig.Name}
+	// The power level is converted for the frequency and mode just read, which the states list before it.
+	var freq, mode string
+	for _, st := range c.rig.CatStates {
+		values, err := c.request(conn, r, st.Prefix)
+		if err != nil {
+			var rerr *replyError
+			if stderr.As(err, &rerr) {
+				continue
+			}
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		value := values[0]
+		switch st.Prefix {
+		case "f":
+			freq = value
+		case "m":
+			mode = value
+		case powerState:
+			mW, ok, err := c.powerMilliwatts(conn, r, value, freq, mode)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			value = mW
+		}
+		if v, ok := statusValue(st, value); ok {
+			status[st.Markers[0].Tag] = v
+		}
+	}
+	return status, nil
+}
+
+// powerMilliwatts converts a power level to milliwatts with rigctld's power2mW. The bool return is false if the
+// frequency or mode is unknown, or the rig cannot convert the level.
+func (c *Client) powerMilliwatts(conn net.Conn, r *bufio.Reader, level, freq, mode string) (string, bool, error) {
+	if !validParam(level) || !validParam(freq) || !validParam(mode) {
+		return "", false, nil
+	}
+	values, err := c.request(conn, r, fmt.Sprintf(`\power2mW %s %s %s`, level, freq, mode))
+	if err != nil {
+		var rerr *replyError
+		if stderr.As(err, &rerr) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if len(values) == 0 {
+		return "", false, nil
+	}
+	return values[0], true, nil
+}
+
+// request sends a command in the extended response protocol and returns the values of the reply.
+func (c *Client) request(conn net.Conn, r *bufio.Reader, cmd string) ([]string, error) {
+	if err := conn.SetDeadline(time.Now().Add(c.cfg.Timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write([]byte("+" + cmd + "\n")); err != nil {
+		return nil, err
+	}
+
+	// The first line echoes the command; each following line holds a value, as "Name: value" or the bare value,
+	// up to the closing "RPRT n".
+	var values []string
+	for first := true; ; first = false {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+
+		if code, ok := strings.CutPrefix(line, "RPRT "); ok {
+			n, cerr := strconv.Atoi(strings.TrimSpace(code))
+			if cerr != nil {
+				return nil, cerr
+			}
+			if n != 0 {
+				return nil, &replyError{cmd: cmd, code: n}
+			}
+			return values, nil
+		}
+		if first || line == "" {
+			continue
+		}
+		if _, v, ok := strings.Cut(line, ": "); ok {
+			line = strings.TrimSpace(v)
+		}
+		values = append(values, line)
+	}
+}
+
+// sendStatus delivers a status, replacing one the consumer has not yet taken. The states of the replaced status
+// are carried over, so none is lost.
+func (c *Client) sendStatus(status types.CatStatus) {
+	for {
+		select {
+		case c.statusChannel <- status:
+			return
+		default:
+		}
+		select {
+		case old := <-c.statusChannel:
+			for k, v := range old {
+				if _, ok := status[k]; !ok {
+					status[k] = v
+				}
+			}
+		default:
+		}
+	}
+}
+
+func (c *Client) reportError(err error) {
+	if c.cfg.ErrorHandler != nil {
+		c.cfg.ErrorHandler(err)
+	}
+}
+
+// statusChanges returns the states that differ from the last poll, or all of them if full is set.
+func statusChanges(last, status types.CatStatus, full bool) types.CatStatus {
+	if full {
+		return maps.Clone(status)
+	}
+	changed := types.CatStatus{}
+	for k, v := range status {
+		if old, ok := last[k]; !ok || old != v {
+			changed[k] = v
+		}
+	}
+	return changed
+}
@@ -0,0 +1,466 @@
+package rigctld
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// fakeRigctld is a minimal rigctld: it answers the client's commands in the extended response protocol from a
+// simulated rig, and records the commands it is sent.
+type fakeRigctld struct {
+	ln       net.Listener
+	received chan string
+
+	mu    sync.Mutex
+	freq  string
+	mode  string
+	split string
+	vfo   string
+	power string
+	// maxMw is the rig's full power in milliwatts; power2mW fails if it is zero.
+	maxMw int
+}
+
+func newFakeRigctld(t *testing.T) *fakeRigctld {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	f := &fakeRigctld{
+		ln:       ln,
+		received: make(chan string, 256),
+		freq:     "14074000",
+		mode:     "PKTUSB",
+		split:    "0",
+		vfo:      "VFOA",
+		power:    "0.500000",
+		maxMw:    200000,
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeRigctld) port() int {
+	return f.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeRigctld) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRigctld) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		line := sc.Text()
+		f.received <- line
+		if _, err := conn.Write([]byte(f.reply(line))); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRigctld) reply(line string) string {
+	cmd, ok := strings.CutPrefix(line, "+")
+	if !ok {
+		return "RPRT -1\n"
+	}
+	args := strings.Fields(cmd)
+	if len(args) == 0 {
+		return "RPRT -1\n"
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch args[0] {
+	case "f":
+		return "get_freq:\nFrequency: " + f.freq + "\nRPRT 0\n"
+	case "i":
+		// Like many rigs, the fake has no separate VFO B frequency to read.
+		return "get_split_freq:\nRPRT -11\n"
+	case "m":
+		return "get_mode:\nMode: " + f.mode + "\nPassband: 3000\nRPRT 0\n"
+	case "s":
+		return "get_split_vfo:\nSplit: " + f.split + "\nTX VFO: VFOB\nRPRT 0\n"
+	case "v":
+		return "get_vfo:\nVFO: " + f.vfo + "\nRPRT 0\n"
+	case "l":
+		return "get_level: RFPOWER\n" + f.power + "\nRPRT 0\n"
+	case `\power2mW`:
+		if len(args) != 4 || f.maxMw == 0 {
+			return "power2mW:\nRPRT -11\n"
+		}
+		level, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return "power2mW:\nRPRT -11\n"
+		}
+		return "power2mW: " + strings.Join(args[1:], " ") + "\nPower mW: " +
+			strconv.Itoa(int(level*float64(f.maxMw))) + "\nRPRT 0\n"
+	case "F":
+		f.freq = strings.TrimLeft(args[1], "0")
+		return "set_freq: " + args[1] + "\nRPRT 0\n"
+	case "M":
+		if args[1] == "WFM" {
+			return "set_mode: WFM 0\nRPRT -1\n"
+		}
+		f.mode = args[1]
+		return "set_mode: " + args[1] + " " + args[2] + "\nRPRT 0\n"
+	case "S":
+		f.split = args[1]
+		return "set_split_vfo: " + args[1] + " " + args[2] + "\nRPRT 0\n"
+	case "V":
+		f.vfo = args[1]
+		return "set_vfo: " + args[1] + "\nRPRT 0\n"
+	case "T":
+		return "set_ptt: " + args[1] + "\nRPRT 0\n"
+	}
+	return "RPRT -4\n"
+}
+
+// waitCommand waits for the fake to receive the given line.
+func (f *fakeRigctld) waitCommand(t *testing.T, want string) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case got := <-f.received:
+			if got == want {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for command %q", want)
+		}
+	}
+}
+
+func newTestClient(t *testing.T, f *fakeRigctld) *Client {
+	t.Helper()
+	c, err := New(Config{
+		Host:           "127.0.0.1",
+		Port:           f.port(),
+		Rig:            types.RigConfig{ID: 2, Name: "IC-7300", Model: "Icom IC-7300"},
+		PollInterval:   20 * time.Millisecond,
+		ReconnectDelay: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = c.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	return c
+}
+
+// waitStatus merges the statuses received until the given state has the wanted value.
+func waitStatus(t *testing.T, c *Client, tag tags.CatStateTag, want string) types.CatStatus {
+	t.Helper()
+	ch, err := c.StatusChannel()
+	if err != nil {
+		t.Fatalf("StatusChannel() error = %v", err)
+	}
+	merged := types.CatStatus{}
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case status := <-ch:
+			for k, v := range status {
+				merged[k] = v
+			}
+			if merged[tag.String()] == want {
+				return merged
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s = %q, have %v", tag, want, merged)
+			return nil
+		}
+	}
+}
+
+// =============================================================================
+// Configuration Tests
+// =============================================================================
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"valid", Config{Host: "localhost", Port: 4532}, false},
+		{"default port", Config{Host: "localhost"}, false},
+		{"missing host", Config{Host: "  ", Port: 4532}, true},
+		{"invalid port", Config{Host: "localhost", Port: 70000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && c.cfg.Port == 0 {
+				t.Error("New() should default the port")
+			}
+		})
+	}
+}
+
+func TestRigConfig(t *testing.T) {
+	c, err := New(Config{Host: "localhost", Rig: types.RigConfig{ID: 2, Name: "IC-7300", CatCommands: []types.CatCommand{{Name: "INIT", Cmd: "FE"}}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	cfg := c.RigConfig()
+	if cfg.ID != 2 || cfg.Name != "IC-7300" {
+		t.Errorf("RigConfig() identity = %d %q", cfg.ID, cfg.Name)
+	}
+	if len(cfg.CatCommands) != len(commands) || len(cfg.CatStates) != len(states) {
+		t.Error("RigConfig() should list the client's own commands and states")
+	}
+}
+
+// =============================================================================
+// Status Tests
+// =============================================================================
+
+func TestClient_Status(t *testing.T) {
+	f := newFakeRigctld(t)
+	c := newTestClient(t, f)
+
+	status := waitStatus(t, c, tags.VfoAFreq, "014074000")
+	want := types.CatStatus{
+		tags.Identity.String(): "IC-7300",
+		tags.VfoAFreq.String(): "014074000",
+		tags.MainMode.String(): "DATA-U",
+		tags.Split.String():    "OFF",
+		tags.Select.String():   "VFO-A",
+		tags.TxPwr.String():    "100",
+	}
+	for k, v := range want {
+		if status[k] != v {
+			t.Errorf("status[%s] = %q, want %q", k, status[k], v)
+		}
+	}
+	if _, ok := status[tags.VfoBFreq.String()]; ok {
+		t.Error("status should leave out a state the rig does not support")
+	}
+}
+
+func TestClient_PowerNotConvertible(t *testing.T) {
+	f := newFakeRigctld(t)
+	f.mu.Lock()
+	f.maxMw = 0
+	f.mu.Unlock()
+	c := newTestClient(t, f)
+
+	status := waitStatus(t, c, tags.VfoAFreq, "014074000")
+	if v, ok := status[tags.TxPwr.String()]; ok {
+		t.Errorf("status[TXPWR] = %q, want it left out when the rig cannot convert the power level", v)
+	}
+}
+
+func TestClient_SetCommands(t *testing.T) {
+	f := newFakeRigctld(t)
+	c := newTestClient(t, f)
+	waitStatus(t, c, tags.VfoAFreq, "014074000")
+
+	if err := c.EnqueueCommand(CmdSetFreq, "007030000"); err != nil {
+		t.Fatalf("EnqueueCommand(SETFREQ) error = %v", err)
+	}
+	f.waitCommand(t, "+F 007030000")
+	waitStatus(t, c, tags.VfoAFreq, "007030000")
+
+	if err := c.EnqueueCommand(CmdSetMode, "CWR"); err != nil {
+		t.Fatalf("EnqueueCommand(SETMODE) error = %v", err)
+	}
+	f.waitCommand(t, "+M CWR 0")
+	waitStatus(t, c, tags.MainMode, "CW-L")
+
+	if err := c.EnqueueCommand(CmdSetSplit, "1"); err != nil {
+		t.Fatalf("EnqueueCommand(SETSPLIT) error = %v", err)
+	}
+	f.waitCommand(t, "+S 1 VFOB")
+	waitStatus(t, c, tags.Split, "ON")
+
+	if err := c.EnqueueCommand(CmdPttOn); err != nil {
+		t.Fatalf("EnqueueCommand(PTTON) error = %v", err)
+	}
+	f.waitCommand(t, "+T 1")
+}
+
+func TestClient_RejectedCommandKeepsConnection(t *testing.T) {
+	f := newFakeRigctld(t)
+
+	errs := make(chan error, 8)
+	c, err := New(Config{Host: "127.0.0.1", Port: f.port(), PollInterval: 20 * time.Millisecond, ErrorHandler: func(err error) { errs <- err }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = c.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	waitStatus(t, c, tags.VfoAFreq, "014074000")
+
+	if err = c.EnqueueCommand(CmdSetMode, "WFM"); err != nil {
+		t.Fatalf("EnqueueCommand() error = %v", err)
+	}
+	select {
+	case err = <-errs:
+		if !strings.Contains(err.Error(), "RPRT -1") {
+			t.Errorf("reported error = %v, want the RPRT code", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the rejected command was not reported")
+	}
+
+	if err = c.EnqueueCommand(CmdSetFreq, "014025000"); err != nil {
+		t.Fatalf("EnqueueCommand() error = %v", err)
+	}
+	waitStatus(t, c, tags.VfoAFreq, "014025000")
+}
+
+func TestClient_Reconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	// Refuse the first connection by closing it straight away.
+	go func() {
+		if conn, aerr := ln.Accept(); aerr == nil {
+			_ = conn.Close()
+		}
+		_ = ln.Close()
+	}()
+
+	c, err := New(Config{Host: "127.0.0.1", Port: port, PollInterval: 20 * time.Millisecond, ReconnectDelay: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = c.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	// Bring up a working daemon on the same port.
+	time.Sleep(20 * time.Millisecond)
+	f := &fakeRigctld{received: make(chan string, 256), freq: "3573000", mode: "USB", split: "0", vfo: "VFOA", power: "1", maxMw: 100000}
+	for i := 0; ; i++ {
+		if f.ln, err = net.Listen("tcp", ln.Addr().String()); err == nil {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("Listen() error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Cleanup(func() { _ = f.ln.Close() })
+	go f.serve()
+
+	status := waitStatus(t, c, tags.VfoAFreq, "003573000")
+	if status[tags.TxPwr.String()] != "100" {
+		t.Errorf("status[TXPWR] = %q, want %q", status[tags.TxPwr.String()], "100")
+	}
+}
+
+// =============================================================================
+// Command Tests
+// =============================================================================
+
+func TestEnqueueCommand_Validation(t *testing.T) {
+	c, err := New(Config{Host: "127.0.0.1", Port: 1})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err = c.EnqueueCommand(cmds.Read); err == nil {
+		t.Error("EnqueueCommand() should fail before Start()")
+	}
+
+	if err = c.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+
+	tests := []struct {
+		name    string
+		cmd     cmds.CatCmdName
+		params  []string
+		wantErr bool
+	}{
+		{"read", cmds.Read, nil, false},
+		{"set freq", CmdSetFreq, []string{"014025000"}, false},
+		{"unsupported", cmds.PlayBack, []string{"1"}, true},
+		{"missing parameter", CmdSetFreq, nil, true},
+		{"extra parameter", CmdPttOn, []string{"1"}, true},
+		{"parameter with a space", CmdSetMode, []string{"USB 0\n+T 1"}, true},
+		{"empty parameter", CmdSetVfo, []string{""}, true},
+		{"cw text with spaces", CmdSendCw, []string{"CQ TEST K1ABC"}, false},
+		{"cw text with a newline", CmdSendCw, []string{"CQ\n+T 1"}, true},
+		{"blank cw text", CmdSendCw, []string{"  "}, true},
+		{"stop cw", CmdStopCw, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.EnqueueCommand(tt.cmd, tt.params...); (err != nil) != tt.wantErr {
+				t.Errorf("EnqueueCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatusValue(t *testing.T) {
+	byTag := func(tag tags.CatStateTag) types.CatState {
+		for _, st := range states {
+			if st.Markers[0].Tag == tag.String() {
+				return st
+			}
+		}
+		t.Fatalf("no state for %s", tag)
+		return types.CatState{}
+	}
+
+	tests := []struct {
+		tag    tags.CatStateTag
+		value  string
+		want   string
+		wantOk bool
+	}{
+		{tags.VfoAFreq, "14074000", "014074000", true},
+		{tags.VfoAFreq, "50313000.000000", "050313000", true},
+		{tags.VfoAFreq, "abc", "", false},
+		{tags.MainMode, "PKTUSB", "DATA-U", true},
+		{tags.MainMode, "CW", "CW-U", true},
+		{tags.MainMode, "SAM", "", true},
+		{tags.Split, "1", "ON", true},
+		{tags.Select, "Main", "VFO-A", true},
+		{tags.TxPwr, "25000", "025", true},
+		{tags.TxPwr, "1499.6", "001", true},
+		{tags.TxPwr, "-1", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := statusValue(byTag(tt.tag), tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("statusValue(%s, %q) = %q, %v; want %q, %v", tt.tag, tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package rigctld implements a CAT backend that controls a rig through a Hamlib
+rigctld daemon over TCP, for rigs that the serial drivers of the cat module do
+not support.
+
+The Client has the same methods as cat.Service that the facade uses, so either
+can drive the rig. It polls the daemon for the rig's state and delivers it on
+StatusChannel() as the same types.CatStatus map the serial drivers produce: the
+same state tags, frequencies in Hz zero-padded to the state's length, and modes,
+split and VFO mapped to the labels of the default Yaesu rig config (e.g.,
+"PKTUSB" becomes "DATA-U"). Transmit power is reported in watts, converted
+from rigctld's power level with its power2mW command for the current frequency
+and mode; it is left out for rigs that cannot convert it.
+
+CW text is sent with rigctld's send_morse command, so it is keyed by the rig's
+own keyer, or by Hamlib's, as the rig supports.
+
+Commands are sent in rigctld's extended response protocol, so every reply ends
+with an "RPRT" line and errors are told apart reliably. A lost connection is
+re-established until the client is stopped.
+*/
+package rigctld
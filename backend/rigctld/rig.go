@@ -0,0 +1,167 @@
+package rigctld
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Station-Manager/enums/cmds"
+	"github.com/Station-Manager/enums/tags"
+	"github.com/Station-Manager/types"
+)
+
+// The rig control commands the client understands in addition to those of the cmds enum. The names match the
+// commands the facade sends; the parameters are formatted from the states below, just as for a serial rig.
+const (
+	CmdSetFreq  cmds.CatCmdName = "SETFREQ"
+	CmdSetFreqB cmds.CatCmdName = "SETFREQB"
+	CmdSetMode  cmds.CatCmdName = "SETMODE"
+	CmdSetVfo   cmds.CatCmdName = "SETVFO"
+	CmdSetSplit cmds.CatCmdName = "SETSPLIT"
+	CmdPttOn    cmds.CatCmdName = "PTTON"
+	CmdPttOff   cmds.CatCmdName = "PTTOFF"
+	// CmdSendCw sends its parameter with the rig's keyer. Unlike the other parameters, the text may hold spaces.
+	CmdSendCw      cmds.CatCmdName = "SENDCW"
+	CmdStopCw      cmds.CatCmdName = "STOPCW"
+	CmdSetKeySpeed cmds.CatCmdName = "SETKEYSPEED"
+)
+
+const (
+	// freqDigits and powerDigits are the widths the serial drivers report frequencies and power in.
+	freqDigits  = 9
+	powerDigits = 3
+	// rfPowerLevel is the rigctld level holding the transmit power, from 0 to 1 of the rig's maximum.
+	rfPowerLevel = "RFPOWER"
+	// powerState reads the power level, which the client converts to milliwatts with rigctld's power2mW before it
+	// is reported in watts, as the serial drivers do.
+	powerState = "l " + rfPowerLevel
+)
+
+// commands are the rigctld commands for each command name. The init and read commands have no rigctld equivalent;
+// they make the client poll the rig at once.
+var commands = []types.CatCommand{
+	{Name: cmds.Init.String(), Cmd: ""},
+	{Name: cmds.Read.String(), Cmd: ""},
+	{Name: CmdSetFreq.String(), Cmd: "F %s"},
+	{Name: CmdSetFreqB.String(), Cmd: "I %s"},
+	// A passband of 0 selects the rig's default filter for the mode.
+	{Name: CmdSetMode.String(), Cmd: "M %s 0"},
+	{Name: CmdSetVfo.String(), Cmd: "V %s"},
+	{Name: CmdSetSplit.String(), Cmd: "S %s VFOB"},
+	{Name: CmdPttOn.String(), Cmd: "T 1"},
+	{Name: CmdPttOff.String(), Cmd: "T 0"},
+	// rigctld takes the rest of the line as the text to send.
+	{Name: CmdSendCw.String(), Cmd: "b %s"},
+	{Name: CmdStopCw.String(), Cmd: `\stop_morse`},
+	{Name: CmdSetKeySpeed.String(), Cmd: "L KEYSPD %s"},
+}
+
+// states are the rig states the client polls. The prefix is the rigctld command that reads the state; only the
+// first value of the reply is used. Value mappings translate rigctld's values to the labels of the serial rig
+// configs, and are used in reverse to format the parameters of the set commands.
+var states = []types.CatState{
+	{Prefix: "f", Markers: []types.Marker{{Tag: tags.VfoAFreq.String(), Length: freqDigits}}},
+	{Prefix: "i", Markers: []types.Marker{{Tag: tags.VfoBFreq.String(), Length: freqDigits}}},
+	{Prefix: "m", Markers: []types.Marker{{
+		Tag: tags.MainMode.String(),
+		ValueMappings: []types.ValueMapping{
+			{Key: "LSB", Value: "LSB"},
+			{Key: "USB", Value: "USB"},
+			{Key: "CW", Value: "CW-U"},
+			{Key: "FM", Value: "FM"},
+			{Key: "AM", Value: "AM"},
+			{Key: "RTTY", Value: "RTTY-L"},
+			{Key: "CWR", Value: "CW-L"},
+			{Key: "PKTLSB", Value: "DATA-L"},
+			{Key: "RTTYR", Value: "RTTY-U"},
+			{Key: "PKTFM", Value: "DATA-FM"},
+			{Key: "FMN", Value: "FM-N"},
+			{Key: "PKTUSB", Value: "DATA-U"},
+			{Key: "AMN", Value: "AM-N"},
+		},
+	}}},
+	{Prefix: "s", Markers: []types.Marker{{
+		Tag: tags.Split.String(),
+		ValueMappings: []types.ValueMapping{
+			{Key: "0", Value: "OFF"},
+			{Key: "1", Value: "ON"},
+		},
+	}}},
+	{Prefix: "v", Markers: []types.Marker{{
+		Tag: tags.Select.String(),
+		ValueMappings: []types.ValueMapping{
+			{Key: "VFOA", Value: "VFO-A"},
+			{Key: "VFOB", Value: "VFO-B"},
+			{Key: "Main", Value: "VFO-A"},
+			{Key: "Sub", Value: "VFO-B"},
+		},
+	}}},
+	{Prefix: powerState, Markers: []types.Marker{{Tag: tags.TxPwr.String(), Length: powerDigits}}},
+}
+
+// rigConfig returns the rig config the client presents: the identity of the configured rig with the client's own
+// commands and states.
+func rigConfig(rig types.RigConfig) types.RigConfig {
+	return types.RigConfig{
+		ID:          rig.ID,
+		Name:        rig.Name,
+		Model:       rig.Model,
+		CatCommands: append([]types.CatCommand(nil), commands...),
+		CatStates:   append([]types.CatState(nil), states...),
+	}
+}
+
+// statusValue converts the first value of a reply to a state into the form the serial drivers report. The bool
+// return is false if the value cannot be converted.
+func statusValue(state types.CatState, value string) (string, bool) {
+	m := state.Markers[0]
+
+	if len(m.ValueMappings) > 0 {
+		for _, vm := range m.ValueMappings {
+			if vm.Key == value {
+				return vm.Value, true
+			}
+		}
+		// As with the serial drivers, an unmapped value is reported as empty.
+		return "", true
+	}
+
+	switch m.Tag {
+	case tags.VfoAFreq.String(), tags.VfoBFreq.String():
+		hz, err := strconv.ParseFloat(value, 64)
+		if err != nil || hz < 0 {
+			return "", false
+		}
+		return fmt.Sprintf("%0*d", m.Length, int64(math.Round(hz))), true
+	case tags.TxPwr.String():
+		// The value is the power in milliwatts, converted from the level by Client.poll.
+		mW, err := strconv.ParseFloat(value, 64)
+		if err != nil || mW < 0 {
+			return "", false
+		}
+		return fmt.Sprintf("%0*d", m.Length, int64(math.Round(mW/1000))), true
+	}
+
+	return value, true
+}
+
+// lookupCommand returns the command with the given name.
+func lookupCommand(name cmds.CatCmdName) (types.CatCommand, bool) {
+	for _, c := range commands {
+		if c.Name == name.String() {
+			return c, true
+		}
+	}
+	return types.CatCommand{}, false
+}
+
+// validParam reports whether a command parameter is a single token that cannot end the command early.
+func validParam(p string) bool {
+	return p != "" && !strings.ContainsFunc(p, func(r rune) bool { return r <= ' ' || r == 0x7f })
+}
+
+// validText reports whether a text parameter, which takes the rest of the line, cannot end the command early.
+func validText(p string) bool {
+	return strings.TrimSpace(p) != "" && !strings.ContainsFunc(p, func(r rune) bool { return r < ' ' || r == 0x7f })
+}
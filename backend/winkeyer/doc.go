@@ -0,0 +1,18 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package winkeyer implements a driver for K1EL WinKeyer CW keyers (WK2 and later)
+connected to a serial port.
+
+The Keyer opens the port at 1200 baud, 8N2, opens a host session and sets the
+speed and weighting. Text passed to Send is buffered and written to the keyer in
+small chunks, pausing while the keyer reports that its buffer is nearly full
+(XOFF). Serial echo is enabled, so each character is handed back to the Handler
+as the keyer sends it, along with every status change (busy, break-in, XOFF).
+
+A lost port is reopened until the context passed to Run is cancelled; the host
+session is closed before the port when the keyer is stopped.
+*/
+package winkeyer
@@ -0,0 +1,348 @@
+package winkeyer
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Station-Manager/errors"
+	"go.bug.st/serial"
+)
+
+const (
+	baudRate              = 1200
+	defaultSpeed          = 25
+	defaultWeight         = 50
+	defaultOpenTimeout    = 2 * time.Second
+	defaultReconnectDelay = 5 * time.Second
+	// readInterval is how often the read loop wakes up when the keyer is silent, to write buffered text and notice
+	// cancellation.
+	readInterval = 50 * time.Millisecond
+	// writeChunk bounds the text written between two checks of the keyer's XOFF state.
+	writeChunk = 16
+	// maxPendingBytes bounds the text waiting to be written.
+	maxPendingBytes = 1024
+)
+
+// State is the connection state of the keyer.
+type State string
+
+const (
+	StateDisconnected State = "disconnected"
+	StateConnecting   State = "connecting"
+	StateConnected    State = "connected" // the host session is open
+)
+
+// Config holds the serial port and initial settings of the keyer.
+type Config struct {
+	Port string
+	// Speed is the sending speed in WPM, and Weight the keying weight, where 50 is standard.
+	Speed  int
+	Weight int
+	// OpenTimeout is how long to wait for the keyer to answer the host open command.
+	OpenTimeout    time.Duration
+	ReconnectDelay time.Duration
+}
+
+// Handler receives the echoed characters, status and state changes of a running keyer. Calls are made from the
+// keyer's goroutine, so implementations should return quickly.
+type Handler interface {
+	HandleEcho(text string)
+	HandleStatus(status Status)
+	HandleState(state State, err error)
+}
+
+// Keyer is a WinKeyer driver.
+type Keyer struct {
+	cfg Config
+
+	mu      sync.Mutex
+	port    serial.Port // nil while the host session is not open
+	version byte
+	xoff    bool
+	pending []byte
+}
+
+// New returns a keyer for the given configuration, applying defaults for unset values.
+func New(cfg Config) (*Keyer, error) {
+	const op errors.Op = "winkeyer.New"
+
+	cfg.Port = strings.TrimSpace(cfg.Port)
+	if cfg.Port == "" {
+		return nil, errors.New(op).Msg("WinKeyer serial port is required")
+	}
+
+	if cfg.Speed == 0 {
+		cfg.Speed = defaultSpeed
+	}
+	if cfg.Speed < MinSpeed || cfg.Speed > MaxSpeed {
+		return nil, errors.New(op).Msgf("Invalid WinKeyer speed: %d WPM", cfg.Speed)
+	}
+	if cfg.Weight == 0 {
+		cfg.Weight = defaultWeight
+	}
+	if cfg.Weight < MinWeight || cfg.Weight > MaxWeight {
+		return nil, errors.New(op).Msgf("Invalid WinKeyer weight: %d", cfg.Weight)
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultOpenTimeout
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+
+	return &Keyer{cfg: cfg}, nil
+}
+
+// Run opens the keyer and delivers its echo and status to the handler until the context is cancelled, reopening the
+// port whenever it is lost. It always returns the context's error.
+func (k *Keyer) Run(ctx context.Context, h Handler) error {
+	for {
+		h.HandleState(StateConnecting, nil)
+
+		err := k.session(ctx, h)
+		if ctx.Err() != nil {
+			h.HandleState(StateDisconnected, nil)
+			return ctx.Err()
+		}
+		h.HandleState(StateDisconnected, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(k.cfg.ReconnectDelay):
+		}
+	}
+}
+
+// Version returns the keyer's firmware version, or 0 if the host session is not open.
+func (k *Keyer) Version() byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.version
+}
+
+// Send queues text to be sent. The text is converted to upper case; characters that cannot be sent are an error.
+func (k *Keyer) Send(text string) error {
+	const op errors.Op = "winkeyer.Keyer.Send"
+
+	text, ok := normalizeText(text)
+	if !ok {
+		return errors.New(op).Msgf("Invalid CW text: %q", text)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.port == nil {
+		return errors.New(op).Msg("WinKeyer not connected")
+	}
+	if len(k.pending)+len(text) > maxPendingBytes {
+		return errors.New(op).Msg("WinKeyer send buffer is full")
+	}
+
+	k.pending = append(k.pending, text...)
+	if err := k.flushLocked(); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// Abort stops sending and discards the text not yet sent.
+func (k *Keyer) Abort() error {
+	const op errors.Op = "winkeyer.Keyer.Abort"
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.pending = nil
+	if err := k.writeLocked(cmdClearBuffer); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	return nil
+}
+
+// SetSpeed sets the sending speed in WPM. The speed is kept for when the port is reopened.
+func (k *Keyer) SetSpeed(wpm int) error {
+	const op errors.Op = "winkeyer.Keyer.SetSpeed"
+	if wpm < MinSpeed || wpm > MaxSpeed {
+		return errors.New(op).Msgf("Invalid WinKeyer speed: %d WPM", wpm)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.writeLocked(cmdSetSpeed, byte(wpm)); err != nil {
+		return errors.New(op).Err(err)
+	}
+	k.cfg.Speed = wpm
+
+	return nil
+}
+
+// SetWeight sets the keying weight, where 50 is standard. The weight is kept for when the port is reopened.
+func (k *Keyer) SetWeight(weight int) error {
+	const op errors.Op = "winkeyer.Keyer.SetWeight"
+	if weight < MinWeight || weight > MaxWeight {
+		return errors.New(op).Msgf("Invalid WinKeyer weight: %d", weight)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.writeLocked(cmdSetWeight, byte(weight)); err != nil {
+		return errors.New(op).Err(err)
+	}
+	k.cfg.Weight = weight
+
+	return nil
+}
+
+// session opens the port and the host session, and reads from the keyer until the context is cancelled or the port
+// fails.
+func (k *Keyer) session(ctx context.Context, h Handler) error {
+	const op errors.Op = "winkeyer.Keyer.session"
+
+	port, err := serial.Open(k.cfg.Port, &serial.Mode{
+		BaudRate: baudRate,
+		DataBits: 8,
+		Parity:   serial.NoParity,
+		StopBits: serial.TwoStopBits,
+	})
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	defer func() { _ = port.Close() }()
+
+	if err = port.SetReadTimeout(readInterval); err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	version, err := k.hostOpen(ctx, port)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	k.mu.Lock()
+	k.port = port
+	k.version = version
+	k.xoff = false
+	k.pending = nil
+	setup := []byte{cmdSetMode, modeSerialEcho, cmdSetSpeed, byte(k.cfg.Speed), cmdSetWeight, byte(k.cfg.Weight)}
+	err = k.writeLocked(setup...)
+	k.mu.Unlock()
+	defer func() {
+		k.mu.Lock()
+		k.port = nil
+		k.version = 0
+		k.pending = nil
+		k.mu.Unlock()
+	}()
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+
+	h.HandleState(StateConnected, nil)
+
+	buf := make([]byte, 64)
+	for {
+		if ctx.Err() != nil {
+			k.mu.Lock()
+			_ = k.writeLocked(cmdAdmin, adminHostClose)
+			k.mu.Unlock()
+			return nil
+		}
+
+		n, rerr := port.Read(buf)
+		if rerr != nil {
+			return errors.New(op).Err(rerr)
+		}
+		k.handleBytes(buf[:n], h)
+
+		k.mu.Lock()
+		err = k.flushLocked()
+		k.mu.Unlock()
+		if err != nil {
+			return errors.New(op).Err(err)
+		}
+	}
+}
+
+// hostOpen opens the host session and returns the keyer's firmware version.
+func (k *Keyer) hostOpen(ctx context.Context, port serial.Port) (byte, error) {
+	const op errors.Op = "winkeyer.Keyer.hostOpen"
+
+	if _, err := port.Write([]byte{cmdAdmin, adminHostOpen}); err != nil {
+		return 0, errors.New(op).Err(err)
+	}
+
+	deadline := time.Now().Add(k.cfg.OpenTimeout)
+	buf := make([]byte, 1)
+	for time.Now().Before(deadline) && ctx.Err() == nil {
+		n, err := port.Read(buf)
+		if err != nil {
+			return 0, errors.New(op).Err(err)
+		}
+		if n == 1 {
+			return buf[0], nil
+		}
+	}
+
+	return 0, errors.New(op).Msg("The WinKeyer did not answer the host open command")
+}
+
+// handleBytes delivers the bytes read from the keyer to the handler. Consecutive echoed characters are delivered
+// together.
+func (k *Keyer) handleBytes(data []byte, h Handler) {
+	var echo []byte
+	flushEcho := func() {
+		if len(echo) > 0 {
+			h.HandleEcho(string(echo))
+			echo = echo[:0]
+		}
+	}
+
+	for _, b := range data {
+		switch b & tagMask {
+		case tagStatus:
+			flushEcho()
+			status := parseStatus(b)
+			k.mu.Lock()
+			k.xoff = status.Xoff
+			k.mu.Unlock()
+			h.HandleStatus(status)
+		case tagPot:
+			// Speed pot reports are not enabled; ignore any that arrive.
+		default:
+			echo = append(echo, b)
+		}
+	}
+	flushEcho()
+}
+
+// flushLocked writes the next chunk of pending text, unless the keyer's buffer is nearly full. The caller must hold
+// the mutex.
+func (k *Keyer) flushLocked() error {
+	if k.port == nil || k.xoff || len(k.pending) == 0 {
+		return nil
+	}
+
+	n := min(len(k.pending), writeChunk)
+	if err := k.writeLocked(k.pending[:n]...); err != nil {
+		return err
+	}
+	k.pending = k.pending[n:]
+	return nil
+}
+
+// writeLocked writes bytes to the keyer. The caller must hold the mutex.
+func (k *Keyer) writeLocked(data ...byte) error {
+	const op errors.Op = "winkeyer.Keyer.writeLocked"
+
+	if k.port == nil {
+		return errors.New(op).Msg("WinKeyer not connected")
+	}
+	if _, err := k.port.Write(data); err != nil {
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+//go:build linux
+
+package winkeyer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPty opens a pseudo-terminal, returning the master and the path of the slave, which stands in for the keyer's
+// serial port.
+func openPty(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals are not available: %v", err)
+	}
+	t.Cleanup(func() { _ = master.Close() })
+
+	rc, err := master.SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn() error = %v", err)
+	}
+	var n int
+	var ierr error
+	err = rc.Control(func(fd uintptr) {
+		if n, ierr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN); ierr != nil {
+			return
+		}
+		ierr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0)
+	})
+	if err != nil || ierr != nil {
+		t.Skipf("cannot set up the pseudo-terminal: %v %v", err, ierr)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+// fakeKeyer plays the WinKeyer on the master side of a pty: it answers the host open command, echoes the text it
+// is sent between busy and idle status bytes, and records the commands.
+type fakeKeyer struct {
+	master *os.File
+	// holdEcho, while set, keeps the keyer from echoing and reporting, as if sending were slow.
+	holdEcho bool
+
+	mu       sync.Mutex
+	text     strings.Builder
+	speed    byte
+	weight   byte
+	mode     byte
+	cleared  int
+	opened   int
+	closed   int
+	received chan struct{}
+}
+
+func newFakeKeyer(master *os.File) *fakeKeyer {
+	f := &fakeKeyer{master: master, received: make(chan struct{}, 64)}
+	go f.run()
+	return f
+}
+
+func (f *fakeKeyer) run() {
+	buf := make([]byte, 256)
+	var pending []byte
+	for {
+		n, err := f.master.Read(buf)
+		if err != nil {
+			// The slave was closed or the test has ended; a new open of the slave is picked up by retrying.
+			if errors.Is(err, os.ErrClosed) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		pending = append(pending, buf[:n]...)
+		pending = f.handle(pending)
+		select {
+		case f.received <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handle processes complete commands, returning any incomplete tail.
+func (f *fakeKeyer) handle(data []byte) []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var echo []byte
+	for len(data) > 0 {
+		b := data[0]
+		switch b {
+		case cmdAdmin, cmdSetSpeed, cmdSetWeight, cmdSetMode:
+			if len(data) < 2 {
+				return data
+			}
+			switch b {
+			case cmdAdmin:
+				switch data[1] {
+				case adminHostOpen:
+					f.opened++
+					_, _ = f.master.Write([]byte{31})
+				case adminHostClose:
+					f.closed++
+				}
+			case cmdSetSpeed:
+				f.speed = data[1]
+			case cmdSetWeight:
+				f.weight = data[1]
+			case cmdSetMode:
+				f.mode = data[1]
+			}
+			data = data[2:]
+		case cmdClearBuffer:
+			f.cleared++
+			data = data[1:]
+		default:
+			f.text.WriteByte(b)
+			echo = append(echo, b)
+			data = data[1:]
+		}
+	}
+
+	if len(echo) > 0 && !f.holdEcho {
+		out := append([]byte{tagStatus | statusBusy}, echo...)
+		out = append(out, tagStatus)
+		_, _ = f.master.Write(out)
+	}
+	return nil
+}
+
+func (f *fakeKeyer) write(data ...byte) {
+	_, _ = f.master.Write(data)
+}
+
+func (f *fakeKeyer) snapshot() (text string, speed, weight, mode byte, cleared, opened, closed int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.text.String(), f.speed, f.weight, f.mode, f.cleared, f.opened, f.closed
+}
+
+// recordingHandler records what a running keyer hands back.
+type recordingHandler struct {
+	mu       sync.Mutex
+	echo     strings.Builder
+	statuses []Status
+	states   []State
+}
+
+func (h *recordingHandler) HandleEcho(text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.echo.WriteString(text)
+}
+
+func (h *recordingHandler) HandleStatus(status Status) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.statuses = append(h.statuses, status)
+}
+
+func (h *recordingHandler) HandleState(state State, _ error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.states = append(h.states, state)
+}
+
+func (h *recordingHandler) lastState() State {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.states) == 0 {
+		return ""
+	}
+	return h.states[len(h.states)-1]
+}
+
+func (h *recordingHandler) echoed() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.echo.String()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// startKeyer runs a keyer on the pty until the test ends.
+func startKeyer(t *testing.T, cfg Config) (*Keyer, *fakeKeyer, *recordingHandler, context.CancelFunc) {
+	t.Helper()
+
+	master, slave := openPty(t)
+	fake := newFakeKeyer(master)
+
+	cfg.Port = slave
+	k, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	h := &recordingHandler{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = k.Run(ctx, h)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor(t, "the host session", func() bool { return h.lastState() == StateConnected })
+	return k, fake, h, cancel
+}
+
+// =============================================================================
+// Keyer Tests
+// =============================================================================
+
+func TestKeyer_OpenAndSetup(t *testing.T) {
+	k, fake, _, _ := startKeyer(t, Config{Speed: 28, Weight: 55})
+
+	waitFor(t, "the setup commands", func() bool {
+		_, speed, weight, _, _, _, _ := fake.snapshot()
+		return speed == 28 && weight == 55
+	})
+	_, _, _, mode, _, opened, _ := fake.snapshot()
+	if mode&modeSerialEcho == 0 {
+		t.Errorf("mode = %#x, want serial echo enabled", mode)
+	}
+	if opened != 1 {
+		t.Errorf("host opens = %d, want 1", opened)
+	}
+	if got := k.Version(); got != 31 {
+		t.Errorf("Version() = %d, want 31", got)
+	}
+}
+
+func TestKeyer_SendEchoesText(t *testing.T) {
+	k, fake, h, _ := startKeyer(t, Config{})
+
+	if err := k.Send("cq  test m0cmc"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	waitFor(t, "the echo", func() bool { return h.echoed() == "CQ TEST M0CMC" })
+
+	if text, _, _, _, _, _, _ := fake.snapshot(); text != "CQ TEST M0CMC" {
+		t.Errorf("keyer received %q", text)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var busy, idle bool
+	for _, s := range h.statuses {
+		busy = busy || s.Busy
+		idle = idle || !s.Busy
+	}
+	if !busy || !idle {
+		t.Errorf("statuses = %+v, want busy and idle", h.statuses)
+	}
+}
+
+func TestKeyer_XoffPausesSending(t *testing.T) {
+	k, fake, h, _ := startKeyer(t, Config{})
+	fake.mu.Lock()
+	fake.holdEcho = true
+	fake.mu.Unlock()
+
+	fake.write(tagStatus | statusXoff)
+	waitFor(t, "the XOFF status", func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.statuses) > 0 && h.statuses[len(h.statuses)-1].Xoff
+	})
+
+	if err := k.Send("TEST"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	time.Sleep(3 * readInterval)
+	if text, _, _, _, _, _, _ := fake.snapshot(); text != "" {
+		t.Fatalf("keyer received %q while XOFF", text)
+	}
+
+	fake.write(tagStatus)
+	waitFor(t, "the text after XOFF", func() bool {
+		text, _, _, _, _, _, _ := fake.snapshot()
+		return text == "TEST"
+	})
+}
+
+func TestKeyer_LongTextIsChunked(t *testing.T) {
+	k, fake, _, _ := startKeyer(t, Config{})
+
+	text := strings.Repeat("CQ TEST ", 6) + "K"
+	if err := k.Send(text); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	waitFor(t, "all of the text", func() bool {
+		got, _, _, _, _, _, _ := fake.snapshot()
+		return got == text
+	})
+}
+
+func TestKeyer_SpeedWeightAbort(t *testing.T) {
+	k, fake, _, _ := startKeyer(t, Config{})
+
+	if err := k.SetSpeed(32); err != nil {
+		t.Fatalf("SetSpeed() error = %v", err)
+	}
+	if err := k.SetWeight(60); err != nil {
+		t.Fatalf("SetWeight() error = %v", err)
+	}
+	if err := k.Abort(); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+	waitFor(t, "the commands", func() bool {
+		_, speed, weight, _, cleared, _, _ := fake.snapshot()
+		return speed == 32 && weight == 60 && cleared == 1
+	})
+
+	if err := k.SetSpeed(MaxSpeed + 1); err == nil {
+		t.Error("SetSpeed() should fail out of range")
+	}
+	if err := k.SetWeight(MinWeight - 1); err == nil {
+		t.Error("SetWeight() should fail out of range")
+	}
+}
+
+func TestKeyer_CloseSendsHostClose(t *testing.T) {
+	_, fake, h, cancel := startKeyer(t, Config{})
+
+	cancel()
+	waitFor(t, "the host close", func() bool {
+		_, _, _, _, _, _, closed := fake.snapshot()
+		return closed == 1
+	})
+	waitFor(t, "the disconnected state", func() bool { return h.lastState() == StateDisconnected })
+}
+
+func TestKeyer_NotConnected(t *testing.T) {
+	k, err := New(Config{Port: "/dev/null-winkeyer"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err = k.Send("TEST"); err == nil {
+		t.Error("Send() should fail when not connected")
+	}
+	if err = k.Abort(); err == nil {
+		t.Error("Abort() should fail when not connected")
+	}
+}
@@ -0,0 +1,82 @@
+package winkeyer
+
+import (
+	"strings"
+)
+
+// WinKeyer host commands. Each is a command byte followed by its parameters, if any.
+const (
+	cmdAdmin       byte = 0x00
+	cmdSetSpeed    byte = 0x02
+	cmdSetWeight   byte = 0x03
+	cmdClearBuffer byte = 0x0a
+	cmdSetMode     byte = 0x0e
+
+	// adminHostOpen starts a host session; the keyer answers with its firmware version.
+	adminHostOpen byte = 0x02
+	// adminHostClose ends the host session.
+	adminHostClose byte = 0x03
+
+	// modeSerialEcho makes the keyer echo each character as it is sent.
+	modeSerialEcho byte = 0x04
+)
+
+// Bytes from the keyer: the top two bits tell status bytes and speed pot values from echoed characters.
+const (
+	tagMask   byte = 0xc0
+	tagStatus byte = 0xc0
+	tagPot    byte = 0x80
+)
+
+// Status bits of a status byte.
+const (
+	statusXoff    byte = 1 << 0
+	statusBreakin byte = 1 << 1
+	statusBusy    byte = 1 << 2
+	statusKeydown byte = 1 << 3
+	statusWait    byte = 1 << 4
+)
+
+const (
+	MinSpeed  = 5
+	MaxSpeed  = 99
+	MinWeight = 10
+	MaxWeight = 90
+)
+
+// Status is the state reported by the keyer in a status byte.
+type Status struct {
+	Xoff    bool `json:"xoff"`    // the keyer's buffer is more than two-thirds full
+	Breakin bool `json:"breakin"` // the paddles are in use
+	Busy    bool `json:"busy"`    // the keyer is sending
+	Keydown bool `json:"keydown"` // the key is down for tuning
+	Wait    bool `json:"wait"`    // the keyer is waiting for an internal event
+}
+
+// parseStatus decodes a status byte.
+func parseStatus(b byte) Status {
+	return Status{
+		Xoff:    b&statusXoff != 0,
+		Breakin: b&statusBreakin != 0,
+		Busy:    b&statusBusy != 0,
+		Keydown: b&statusKeydown != 0,
+		Wait:    b&statusWait != 0,
+	}
+}
+
+// cwCharacters are the characters the keyer sends, besides letters and digits. Other bytes are either commands or
+// ignored by the keyer.
+const cwCharacters = " /?.,=+-()':\"@"
+
+// normalizeText converts text to the form the keyer sends: upper case with single spaces. The bool return is false
+// if the text is empty or holds a character the keyer cannot send.
+func normalizeText(text string) (string, bool) {
+	text = strings.Join(strings.Fields(strings.ToUpper(text)), " ")
+	if text == "" {
+		return "", false
+	}
+	valid := !strings.ContainsFunc(text, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && !strings.ContainsRune(cwCharacters, r)
+	})
+	return text, valid
+}
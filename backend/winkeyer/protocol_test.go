@@ -0,0 +1,69 @@
+package winkeyer
+
+import (
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		b    byte
+		want Status
+	}{
+		{0xc0, Status{}},
+		{0xc1, Status{Xoff: true}},
+		{0xc4, Status{Busy: true}},
+		{0xc6, Status{Breakin: true, Busy: true}},
+		{0xd8, Status{Keydown: true, Wait: true}},
+	}
+	for _, tt := range tests {
+		if got := parseStatus(tt.b); got != tt.want {
+			t.Errorf("parseStatus(%#x) = %+v, want %+v", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   string
+		wantOk bool
+	}{
+		{"cq test", "CQ TEST", true},
+		{" 5nn  001 ", "5NN 001", true},
+		{"K1ABC/P?", "K1ABC/P?", true},
+		{"", "", false},
+		{"CQ\x0aTEST", "CQ TEST", true},
+		{"CQ #1", "CQ #1", false},
+		{"CQ \x1b", "CQ \x1b", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeText(tt.text)
+		if ok != tt.wantOk || (ok && got != tt.want) {
+			t.Errorf("normalizeText(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"defaults", Config{Port: "/dev/ttyUSB0"}, false},
+		{"no port", Config{}, true},
+		{"speed too low", Config{Port: "/dev/ttyUSB0", Speed: 4}, true},
+		{"weight too high", Config{Port: "/dev/ttyUSB0", Weight: 91}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, err := New(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (k.cfg.Speed != defaultSpeed || k.cfg.Weight != defaultWeight) {
+				t.Errorf("New() cfg = %+v, want the default speed and weight", k.cfg)
+			}
+		})
+	}
+}
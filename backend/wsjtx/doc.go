@@ -0,0 +1,25 @@
+// Copyright 2026 Station-Manager. All rights reserved.
+// Use of this source code is governed by the license
+// that can be found in the LICENSE file.
+
+/*
+Package wsjtx implements both directions of the WSJT-X UDP protocol, as also
+spoken by JTDX and MSHV, so that a logging program can follow the decodes of a
+running instance and control it.
+
+Messages are Qt QDataStream encoded: big-endian integers, doubles, and strings
+as UTF-8 byte arrays. Parse decodes the messages WSJT-X sends (Heartbeat,
+Status, Decode, Clear, Close, LoggedAdif) and, for symmetry, those it receives;
+Marshal encodes any of them, notably Reply, which acts as a double-click on a
+decode, and HighlightCallsign, which colours a callsign in the band activity
+window.
+
+The Server does not bind the port WSJT-X sends to, which belongs to the
+listener that receives the datagrams and passes the parsed messages to
+Dispatch. It remembers the address each instance sends from, as WSJT-X only
+accepts messages sent back to the port it sends from, and sends them from its
+own socket.
+
+See NetworkMessage.hpp in the WSJT-X sources for the message definitions.
+*/
+package wsjtx
@@ -0,0 +1,436 @@
+package wsjtx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Station-Manager/errors"
+)
+
+// Magic starts every message.
+const Magic uint32 = 0xadbccbda
+
+// SchemaVersion is the schema of the messages Marshal encodes. Every WSJT-X version since 1.7 accepts it.
+const SchemaVersion uint32 = 2
+
+// Message types.
+const (
+	TypeHeartbeat         uint32 = 0
+	TypeStatus            uint32 = 1
+	TypeDecode            uint32 = 2
+	TypeClear             uint32 = 3
+	TypeReply             uint32 = 4
+	TypeClose             uint32 = 6
+	TypeLoggedAdif        uint32 = 12
+	TypeHighlightCallsign uint32 = 13
+)
+
+// Keyboard modifiers of a Reply, as Qt defines them shifted right by 24 bits. Shift, for example, makes WSJT-X
+// only set up the QSO without enabling transmit.
+const (
+	ModifierShift   uint8 = 0x02
+	ModifierControl uint8 = 0x04
+	ModifierAlt     uint8 = 0x08
+)
+
+// nullLength marks a null byte array.
+const nullLength uint32 = 0xffffffff
+
+// maxStringLength bounds a string, as no message comes close to it.
+const maxStringLength = 64 * 1024
+
+// Heartbeat is sent by an instance periodically, and by a client to announce itself.
+type Heartbeat struct {
+	ID        string `json:"id"`
+	MaxSchema uint32 `json:"max_schema"`
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+}
+
+// Status is sent by an instance whenever its state changes. The fields after DxGrid were added in later versions
+// and are zero when absent.
+type Status struct {
+	ID                   string `json:"id"`
+	DialFrequency        uint64 `json:"dial_frequency"` // Hz
+	Mode                 string `json:"mode"`
+	DxCall               string `json:"dx_call"`
+	Report               string `json:"report"`
+	TxMode               string `json:"tx_mode"`
+	TxEnabled            bool   `json:"tx_enabled"`
+	Transmitting         bool   `json:"transmitting"`
+	Decoding             bool   `json:"decoding"`
+	RxDF                 uint32 `json:"rx_df"` // audio offset, Hz
+	TxDF                 uint32 `json:"tx_df"`
+	DeCall               string `json:"de_call"`
+	DeGrid               string `json:"de_grid"`
+	DxGrid               string `json:"dx_grid"`
+	TxWatchdog           bool   `json:"tx_watchdog"`
+	Submode              string `json:"submode"`
+	FastMode             bool   `json:"fast_mode"`
+	SpecialOperationMode uint8  `json:"special_operation_mode"`
+	FrequencyTolerance   uint32 `json:"frequency_tolerance"`
+	TRPeriod             uint32 `json:"tr_period"` // seconds
+	ConfigurationName    string `json:"configuration_name"`
+	TxMessage            string `json:"tx_message"`
+}
+
+// Decode is a decoded message. Mode is the mode's symbol, e.g. "~" for FT8 and "+" for FT4.
+type Decode struct {
+	ID             string  `json:"id"`
+	New            bool    `json:"new"`
+	Time           uint32  `json:"time"` // ms since midnight UTC
+	SNR            int32   `json:"snr"`
+	DeltaTime      float64 `json:"delta_time"`      // s
+	DeltaFrequency uint32  `json:"delta_frequency"` // audio offset, Hz
+	Mode           string  `json:"mode"`
+	Message        string  `json:"message"`
+	LowConfidence  bool    `json:"low_confidence"`
+	OffAir         bool    `json:"off_air"`
+}
+
+// Clear is sent by an instance when its band activity is cleared, and to an instance to clear it. Window is
+// 0 for the band activity, 1 for the Rx frequency window and 2 for both.
+type Clear struct {
+	ID     string `json:"id"`
+	Window uint8  `json:"window"`
+}
+
+// Reply makes an instance respond to a decode as if it had been double-clicked. The fields identify the decode
+// and must be as received.
+type Reply struct {
+	ID             string
+	Time           uint32
+	SNR            int32
+	DeltaTime      float64
+	DeltaFrequency uint32
+	Mode           string
+	Message        string
+	LowConfidence  bool
+	Modifiers      uint8
+}
+
+// Close is sent by an instance when it exits, and to an instance to make it exit.
+type Close struct {
+	ID string `json:"id"`
+}
+
+// LoggedAdif is sent by an instance when a QSO is logged, with the QSO as an ADIF file of one record.
+type LoggedAdif struct {
+	ID   string `json:"id"`
+	Adif string `json:"adif"`
+}
+
+// Color is a colour of a HighlightCallsign. The zero value is the invalid colour, which restores the default.
+type Color struct {
+	Valid   bool
+	R, G, B uint8
+}
+
+// ParseColor parses a "#rrggbb" colour. An empty string is the invalid colour.
+func ParseColor(s string) (Color, error) {
+	const op errors.Op = "wsjtx.ParseColor"
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Color{}, nil
+	}
+	if len(s) != 7 || s[0] != '#' {
+		return Color{}, errors.New(op).Msgf("Invalid colour: %q", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return Color{}, errors.New(op).Msgf("Invalid colour: %q", s)
+	}
+	return Color{Valid: true, R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// HighlightCallsign sets the colours of a callsign in an instance's band activity window. Invalid colours for
+// both clear the highlight. With HighlightLast, only the most recent decode of the callsign is highlighted.
+type HighlightCallsign struct {
+	ID            string
+	Callsign      string
+	Background    Color
+	Foreground    Color
+	HighlightLast bool
+}
+
+// Parse decodes a message. It returns a *Heartbeat, *Status, *Decode, *Clear, *Reply, *Close, *LoggedAdif or
+// *HighlightCallsign, or nil for a valid message of another type.
+func Parse(data []byte) (any, error) {
+	const op errors.Op = "wsjtx.Parse"
+
+	r := &reader{r: bytes.NewReader(data)}
+	magic, schema, typ := r.uint32(), r.uint32(), r.uint32()
+	if r.err != nil {
+		return nil, errors.New(op).Msg("Message too short")
+	}
+	if magic != Magic {
+		return nil, errors.New(op).Msgf("Invalid magic number: %#x", magic)
+	}
+	if schema < 1 || schema > 3 {
+		return nil, errors.New(op).Msgf("Unsupported schema: %d", schema)
+	}
+	id := r.string()
+
+	var msg any
+	switch typ {
+	case TypeHeartbeat:
+		m := &Heartbeat{ID: id, MaxSchema: r.uint32(), Version: r.string()}
+		if r.more() {
+			m.Revision = r.string()
+		}
+		msg = m
+	case TypeStatus:
+		m := &Status{ID: id}
+		m.DialFrequency = r.uint64()
+		m.Mode, m.DxCall, m.Report, m.TxMode = r.string(), r.string(), r.string(), r.string()
+		m.TxEnabled, m.Transmitting, m.Decoding = r.bool(), r.bool(), r.bool()
+		m.RxDF, m.TxDF = r.uint32(), r.uint32()
+		m.DeCall, m.DeGrid, m.DxGrid = r.string(), r.string(), r.string()
+		if r.more() {
+			m.TxWatchdog = r.bool()
+			m.Submode = r.string()
+			m.FastMode = r.bool()
+		}
+		if r.more() {
+			m.SpecialOperationMode = r.uint8()
+		}
+		if r.more() {
+			m.FrequencyTolerance, m.TRPeriod = r.uint32(), r.uint32()
+		}
+		if r.more() {
+			m.ConfigurationName = r.string()
+		}
+		if r.more() {
+			m.TxMessage = r.string()
+		}
+		msg = m
+	case TypeDecode:
+		m := &Decode{ID: id, New: r.bool(), Time: r.uint32(), SNR: r.int32(), DeltaTime: r.float64(),
+			DeltaFrequency: r.uint32(), Mode: r.string(), Message: r.string(), LowConfidence: r.bool()}
+		if r.more() {
+			m.OffAir = r.bool()
+		}
+		msg = m
+	case TypeClear:
+		m := &Clear{ID: id}
+		if r.more() {
+			m.Window = r.uint8()
+		}
+		msg = m
+	case TypeReply:
+		msg = &Reply{ID: id, Time: r.uint32(), SNR: r.int32(), DeltaTime: r.float64(), DeltaFrequency: r.uint32(),
+			Mode: r.string(), Message: r.string(), LowConfidence: r.bool(), Modifiers: r.uint8()}
+	case TypeClose:
+		msg = &Close{ID: id}
+	case TypeLoggedAdif:
+		msg = &LoggedAdif{ID: id, Adif: r.string()}
+	case TypeHighlightCallsign:
+		msg = &HighlightCallsign{ID: id, Callsign: r.string(), Background: r.color(), Foreground: r.color(),
+			HighlightLast: r.bool()}
+	default:
+		if r.err != nil {
+			break
+		}
+		return nil, nil
+	}
+
+	if r.err != nil {
+		return nil, errors.New(op).Err(r.err).Msgf("Malformed message of type %d", typ)
+	}
+	return msg, nil
+}
+
+// Marshal encodes a message, given as a value or pointer of one of the message types.
+func Marshal(msg any) ([]byte, error) {
+	const op errors.Op = "wsjtx.Marshal"
+
+	w := &writer{}
+	header := func(typ uint32, id string) {
+		w.uint32(Magic)
+		w.uint32(SchemaVersion)
+		w.uint32(typ)
+		w.string(id)
+	}
+
+	switch m := deref(msg).(type) {
+	case Heartbeat:
+		header(TypeHeartbeat, m.ID)
+		w.uint32(m.MaxSchema)
+		w.string(m.Version)
+		w.string(m.Revision)
+	case Status:
+		header(TypeStatus, m.ID)
+		w.uint64(m.DialFrequency)
+		w.string(m.Mode, m.DxCall, m.Report, m.TxMode)
+		w.bool(m.TxEnabled, m.Transmitting, m.Decoding)
+		w.uint32(m.RxDF)
+		w.uint32(m.TxDF)
+		w.string(m.DeCall, m.DeGrid, m.DxGrid)
+		w.bool(m.TxWatchdog)
+		w.string(m.Submode)
+		w.bool(m.FastMode)
+		w.uint8(m.SpecialOperationMode)
+		w.uint32(m.FrequencyTolerance)
+		w.uint32(m.TRPeriod)
+		w.string(m.ConfigurationName, m.TxMessage)
+	case Decode:
+		header(TypeDecode, m.ID)
+		w.bool(m.New)
+		w.uint32(m.Time)
+		w.uint32(uint32(m.SNR))
+		w.float64(m.DeltaTime)
+		w.uint32(m.DeltaFrequency)
+		w.string(m.Mode, m.Message)
+		w.bool(m.LowConfidence, m.OffAir)
+	case Clear:
+		header(TypeClear, m.ID)
+		w.uint8(m.Window)
+	case Reply:
+		header(TypeReply, m.ID)
+		w.uint32(m.Time)
+		w.uint32(uint32(m.SNR))
+		w.float64(m.DeltaTime)
+		w.uint32(m.DeltaFrequency)
+		w.string(m.Mode, m.Message)
+		w.bool(m.LowConfidence)
+		w.uint8(m.Modifiers)
+	case Close:
+		header(TypeClose, m.ID)
+	case LoggedAdif:
+		header(TypeLoggedAdif, m.ID)
+		w.string(m.Adif)
+	case HighlightCallsign:
+		header(TypeHighlightCallsign, m.ID)
+		w.string(m.Callsign)
+		w.color(m.Background)
+		w.color(m.Foreground)
+		w.bool(m.HighlightLast)
+	default:
+		return nil, errors.New(op).Msgf("Unsupported message: %T", msg)
+	}
+	return w.buf.Bytes(), nil
+}
+
+// deref returns the value a pointer to a message points to.
+func deref(msg any) any {
+	switch m := msg.(type) {
+	case *Heartbeat:
+		return *m
+	case *Status:
+		return *m
+	case *Decode:
+		return *m
+	case *Clear:
+		return *m
+	case *Reply:
+		return *m
+	case *Close:
+		return *m
+	case *LoggedAdif:
+		return *m
+	case *HighlightCallsign:
+		return *m
+	}
+	return msg
+}
+
+// reader reads QDataStream values, keeping the first error so that a message can be read field by field and
+// checked once.
+type reader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *reader) read(v any) {
+	if r.err == nil {
+		r.err = binary.Read(r.r, binary.BigEndian, v)
+	}
+}
+
+// more reports whether optional fields follow.
+func (r *reader) more() bool {
+	return r.err == nil && r.r.Len() > 0
+}
+
+func (r *reader) uint8() (v uint8)     { r.read(&v); return }
+func (r *reader) uint32() (v uint32)   { r.read(&v); return }
+func (r *reader) int32() (v int32)     { r.read(&v); return }
+func (r *reader) uint64() (v uint64)   { r.read(&v); return }
+func (r *reader) float64() (v float64) { r.read(&v); return }
+func (r *reader) bool() bool           { return r.uint8() != 0 }
+
+func (r *reader) string() string {
+	n := r.uint32()
+	if r.err != nil || n == nullLength || n == 0 {
+		return ""
+	}
+	if n > maxStringLength || int(n) > r.r.Len() {
+		r.err = fmt.Errorf("invalid string length: %d", n)
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.err = err
+		return ""
+	}
+	return string(b)
+}
+
+// color reads a QColor: its spec, then alpha, red, green and blue as 16-bit values, and padding.
+func (r *reader) color() Color {
+	var spec int8
+	r.read(&spec)
+	var v [5]uint16
+	r.read(&v)
+	if r.err != nil || spec == 0 {
+		return Color{}
+	}
+	return Color{Valid: true, R: uint8(v[1] >> 8), G: uint8(v[2] >> 8), B: uint8(v[3] >> 8)}
+}
+
+// writer writes QDataStream values.
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) put(v any) {
+	// Writes to a bytes.Buffer cannot fail.
+	_ = binary.Write(&w.buf, binary.BigEndian, v)
+}
+
+func (w *writer) uint8(v uint8)     { w.put(v) }
+func (w *writer) uint32(v uint32)   { w.put(v) }
+func (w *writer) uint64(v uint64)   { w.put(v) }
+func (w *writer) float64(v float64) { w.put(v) }
+
+func (w *writer) bool(vs ...bool) {
+	for _, v := range vs {
+		if v {
+			w.uint8(1)
+		} else {
+			w.uint8(0)
+		}
+	}
+}
+
+func (w *writer) string(vs ...string) {
+	for _, v := range vs {
+		w.uint32(uint32(len(v)))
+		w.buf.WriteString(v)
+	}
+}
+
+// color writes a QColor as an RGB spec, scaling the 8-bit components to 16 bits as Qt does.
+func (w *writer) color(c Color) {
+	if !c.Valid {
+		w.put(int8(0))
+		w.put([5]uint16{})
+		return
+	}
+	w.put(int8(1))
+	w.put([5]uint16{0xffff, uint16(c.R) * 0x101, uint16(c.G) * 0x101, uint16(c.B) * 0x101, 0})
+}
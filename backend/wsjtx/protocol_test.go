@@ -0,0 +1,149 @@
+package wsjtx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalParse_RoundTrip(t *testing.T) {
+	msgs := []any{
+		&Heartbeat{ID: "WSJT-X", MaxSchema: 3, Version: "2.7.0", Revision: "abc123"},
+		&Status{ID: "WSJT-X", DialFrequency: 14074000, Mode: "FT8", DxCall: "DL1ABC", Report: "-10", TxMode: "FT8",
+			TxEnabled: true, Decoding: true, RxDF: 1200, TxDF: 1500, DeCall: "W1AW", DeGrid: "FN31", DxGrid: "JO62",
+			Submode: "", SpecialOperationMode: 0, FrequencyTolerance: 20, TRPeriod: 15,
+			ConfigurationName: "Default", TxMessage: "DL1ABC W1AW FN31"},
+		&Decode{ID: "JTDX", New: true, Time: 45015000, SNR: -12, DeltaTime: 0.3, DeltaFrequency: 1234, Mode: "~",
+			Message: "CQ DL1ABC JO62"},
+		&Clear{ID: "WSJT-X", Window: 2},
+		&Reply{ID: "WSJT-X", Time: 45015000, SNR: -12, DeltaTime: -0.1, DeltaFrequency: 1234, Mode: "~",
+			Message: "CQ DL1ABC JO62", Modifiers: ModifierShift},
+		&Close{ID: "WSJT-X"},
+		&LoggedAdif{ID: "WSJT-X", Adif: "<adif_ver:5>3.1.0<EOH><call:6>DL1ABC<band:3>20m<mode:3>FT8<EOR>"},
+		&HighlightCallsign{ID: "WSJT-X", Callsign: "DL1ABC", Background: Color{Valid: true, R: 0xff, G: 0x50},
+			HighlightLast: true},
+	}
+	for _, msg := range msgs {
+		data, err := Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal(%T) error = %v", msg, err)
+		}
+		got, err := Parse(data)
+		if err != nil {
+			t.Fatalf("Parse(%T) error = %v", msg, err)
+		}
+		if !reflect.DeepEqual(got, msg) {
+			t.Errorf("Parse(Marshal()) = %+v, want %+v", got, msg)
+		}
+	}
+}
+
+// qt builds a message as Qt would encode it, for checking the encoding independently of Marshal.
+type qt struct{ bytes.Buffer }
+
+func (b *qt) put(vs ...any) *qt {
+	for _, v := range vs {
+		if s, ok := v.(string); ok {
+			_ = binary.Write(b, binary.BigEndian, uint32(len(s)))
+			b.WriteString(s)
+			continue
+		}
+		_ = binary.Write(b, binary.BigEndian, v)
+	}
+	return b
+}
+
+func TestMarshal_HighlightCallsignLayout(t *testing.T) {
+	got, err := Marshal(HighlightCallsign{ID: "WSJT-X", Callsign: "K1ABC", Background: Color{Valid: true, R: 0x12, G: 0x34, B: 0x56}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := (&qt{}).put(Magic, uint32(2), uint32(13), "WSJT-X", "K1ABC",
+		int8(1), uint16(0xffff), uint16(0x1212), uint16(0x3434), uint16(0x5656), uint16(0),
+		int8(0), [5]uint16{},
+		uint8(0)).Bytes()
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() =\n% x\nwant\n% x", got, want)
+	}
+}
+
+func TestMarshal_ReplyLayout(t *testing.T) {
+	got, err := Marshal(Reply{ID: "WSJT-X", Time: 1000, SNR: -5, DeltaTime: 0.5, DeltaFrequency: 700, Mode: "~",
+		Message: "CQ K1ABC FN42", Modifiers: ModifierShift})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := (&qt{}).put(Magic, uint32(2), uint32(4), "WSJT-X", uint32(1000), int32(-5), float64(0.5), uint32(700),
+		"~", "CQ K1ABC FN42", uint8(0), ModifierShift).Bytes()
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() =\n% x\nwant\n% x", got, want)
+	}
+}
+
+func TestParse_OlderSchema(t *testing.T) {
+	// A schema 2 status from an older version ends after the DX grid, and its decodes have no off-air flag.
+	status := (&qt{}).put(Magic, uint32(2), uint32(1), "WSJT-X", uint64(7074000), "FT8", "", "", "FT8",
+		uint8(0), uint8(0), uint8(1), uint32(1500), uint32(1500), "W1AW", "FN31", "").Bytes()
+	msg, err := Parse(status)
+	if err != nil {
+		t.Fatalf("Parse(status) error = %v", err)
+	}
+	if s, ok := msg.(*Status); !ok || s.DialFrequency != 7074000 || s.DeGrid != "FN31" || s.TRPeriod != 0 {
+		t.Errorf("Parse(status) = %+v", msg)
+	}
+
+	decode := (&qt{}).put(Magic, uint32(2), uint32(2), "WSJT-X", uint8(1), uint32(0), int32(3), float64(0),
+		uint32(900), "~", "K1ABC W1AW -10", uint8(0)).Bytes()
+	msg, err = Parse(decode)
+	if err != nil {
+		t.Fatalf("Parse(decode) error = %v", err)
+	}
+	if d, ok := msg.(*Decode); !ok || d.Message != "K1ABC W1AW -10" || d.OffAir {
+		t.Errorf("Parse(decode) = %+v", msg)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", (&qt{}).put(uint32(0x12345678), uint32(2), uint32(0), "WSJT-X").Bytes()},
+		{"bad schema", (&qt{}).put(Magic, uint32(9), uint32(0), "WSJT-X").Bytes()},
+		{"truncated decode", (&qt{}).put(Magic, uint32(2), uint32(2), "WSJT-X", uint8(1), uint32(0)).Bytes()},
+		{"string too long", (&qt{}).put(Magic, uint32(2), uint32(6), uint32(1000), "x").Bytes()},
+	}
+	for _, tt := range tests {
+		if msg, err := Parse(tt.data); err == nil {
+			t.Errorf("%s: Parse() = %+v, want an error", tt.name, msg)
+		}
+	}
+
+	// Valid messages of types the package does not handle are ignored.
+	if msg, err := Parse((&qt{}).put(Magic, uint32(2), uint32(11), "WSJT-X", "FN31").Bytes()); msg != nil || err != nil {
+		t.Errorf("Parse(location) = %v, %v, want nil", msg, err)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Color
+		wantErr bool
+	}{
+		{"", Color{}, false},
+		{"#ff8000", Color{Valid: true, R: 0xff, G: 0x80}, false},
+		{"#FFFFFF", Color{Valid: true, R: 0xff, G: 0xff, B: 0xff}, false},
+		{"ff8000", Color{}, true},
+		{"#ff80", Color{}, true},
+		{"#gg8000", Color{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColor(%q) = %+v, %v", tt.s, got, err)
+		}
+	}
+}
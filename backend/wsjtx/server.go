@@ -0,0 +1,107 @@
+package wsjtx
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/Station-Manager/errors"
+)
+
+// Handler receives the messages dispatched by a server. Calls are made from the goroutine that received the
+// datagram, so implementations should return quickly.
+type Handler interface {
+	HandleStatus(status Status)
+	HandleDecode(decode Decode)
+	HandleClear(clear Clear)
+	// HandleClose is called when an instance exits; it can no longer be sent messages.
+	HandleClose(id string)
+}
+
+// Server is the logger's end of the protocol. It does not receive by itself: the listener bound to the port
+// WSJT-X sends to passes each message to Dispatch, and the server sends messages back to the instances from a
+// socket of its own.
+type Server struct {
+	conn *net.UDPConn
+
+	mu      sync.Mutex
+	clients map[string]*net.UDPAddr // instance ID -> the address it sends from
+}
+
+// NewServer opens the socket the server sends from, on an ephemeral port.
+func NewServer() (*Server, error) {
+	const op errors.Op = "wsjtx.NewServer"
+
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, errors.New(op).Err(err)
+	}
+	return &Server{conn: conn, clients: make(map[string]*net.UDPAddr)}, nil
+}
+
+// Close closes the socket the server sends from. Messages can still be dispatched, but no longer sent.
+func (s *Server) Close() {
+	_ = s.conn.Close()
+}
+
+// Dispatch passes a message parsed from a datagram received from the given address to the handler, remembering
+// the address of the instance that sent it. Messages of other types are ignored.
+func (s *Server) Dispatch(msg any, from *net.UDPAddr, h Handler) {
+	switch m := msg.(type) {
+	case *Heartbeat:
+		s.register(m.ID, from)
+	case *Status:
+		s.register(m.ID, from)
+		h.HandleStatus(*m)
+	case *Decode:
+		s.register(m.ID, from)
+		h.HandleDecode(*m)
+	case *Clear:
+		s.register(m.ID, from)
+		h.HandleClear(*m)
+	case *Close:
+		s.mu.Lock()
+		delete(s.clients, m.ID)
+		s.mu.Unlock()
+		h.HandleClose(m.ID)
+	}
+}
+
+func (s *Server) register(id string, from *net.UDPAddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[id] = from
+}
+
+// Clients returns the IDs of the instances heard from, sorted.
+func (s *Server) Clients() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.clients))
+	for id := range s.clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Send sends a message to the instance with the given ID, which must have been heard from.
+func (s *Server) Send(id string, msg any) error {
+	const op errors.Op = "wsjtx.Server.Send"
+
+	s.mu.Lock()
+	addr, ok := s.clients[id]
+	s.mu.Unlock()
+	if !ok {
+		return errors.New(op).Msgf("Unknown WSJT-X instance: %q", id)
+	}
+
+	data, err := Marshal(msg)
+	if err != nil {
+		return errors.New(op).Err(err)
+	}
+	if _, err = s.conn.WriteToUDP(data, addr); err != nil {
+		return errors.New(op).Err(err)
+	}
+	return nil
+}
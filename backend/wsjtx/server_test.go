@@ -0,0 +1,137 @@
+package wsjtx
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a Handler that records the messages it receives.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []Status
+	clears   []Clear
+	closes   []string
+	decodes  chan Decode
+}
+
+func newRecorder() *recorder {
+	return &recorder{decodes: make(chan Decode, 16)}
+}
+
+func (r *recorder) HandleStatus(s Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, s)
+}
+
+func (r *recorder) HandleDecode(d Decode) { r.decodes <- d }
+
+func (r *recorder) HandleClear(c Clear) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clears = append(r.clears, c)
+}
+
+func (r *recorder) HandleClose(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closes = append(r.closes, id)
+}
+
+// instance is a stand-in WSJT-X instance: it reads what the server sends back to its socket.
+type instance struct {
+	conn *net.UDPConn
+}
+
+func newInstance(t *testing.T) *instance {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &instance{conn: conn}
+}
+
+func (i *instance) addr() *net.UDPAddr {
+	return i.conn.LocalAddr().(*net.UDPAddr)
+}
+
+func (i *instance) receive(t *testing.T) any {
+	t.Helper()
+	_ = i.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 65535)
+	n, err := i.conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	msg, err := Parse(buf[:n])
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return msg
+}
+
+func TestServer(t *testing.T) {
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	t.Cleanup(srv.Close)
+	rec := newRecorder()
+
+	if err = srv.Send("WSJT-X", Clear{ID: "WSJT-X"}); err == nil {
+		t.Error("Send() to an instance not heard from should fail")
+	}
+
+	wsjt := newInstance(t)
+	decode := Decode{ID: "WSJT-X", New: true, Time: 1000, SNR: -3, DeltaFrequency: 800, Mode: "~", Message: "CQ K1ABC FN42"}
+	srv.Dispatch(&decode, wsjt.addr(), rec)
+	select {
+	case got := <-rec.decodes:
+		if got != decode {
+			t.Errorf("HandleDecode() got %+v, want %+v", got, decode)
+		}
+	default:
+		t.Fatal("the decode was not passed to the handler")
+	}
+	if clients := srv.Clients(); len(clients) != 1 || clients[0] != "WSJT-X" {
+		t.Errorf("Clients() = %v", clients)
+	}
+
+	// Messages are sent back to the address the instance sends from.
+	reply := Reply{ID: "WSJT-X", Time: decode.Time, SNR: decode.SNR, DeltaFrequency: decode.DeltaFrequency, Mode: "~",
+		Message: decode.Message}
+	if err = srv.Send("WSJT-X", reply); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got, ok := wsjt.receive(t).(*Reply); !ok || *got != reply {
+		t.Errorf("instance received %+v, want %+v", got, reply)
+	}
+
+	// Messages the server has no use for are ignored.
+	srv.Dispatch(&LoggedAdif{ID: "OTHER", Adif: "<EOR>"}, wsjt.addr(), rec)
+	if clients := srv.Clients(); len(clients) != 1 {
+		t.Errorf("Clients() = %v after a LoggedAdif", clients)
+	}
+
+	// An instance that closes is forgotten.
+	srv.Dispatch(&Close{ID: "WSJT-X"}, wsjt.addr(), rec)
+	if clients := srv.Clients(); len(clients) != 0 {
+		t.Errorf("Clients() = %v after Close", clients)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.closes) != 1 || rec.closes[0] != "WSJT-X" {
+		t.Errorf("HandleClose() got %v", rec.closes)
+	}
+
+	srv.Dispatch(&Heartbeat{ID: "WSJT-X", MaxSchema: 3}, wsjt.addr(), rec)
+	srv.Close()
+	if err = srv.Send("WSJT-X", Clear{ID: "WSJT-X"}); err == nil {
+		t.Error("Send() after Close should fail")
+	}
+}
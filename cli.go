@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/Station-Manager/errors"
+	"github.com/Station-Manager/logging-app/backend/facade"
+)
+
+// cliCommand is a subcommand of the headless command line.
+type cliCommand struct {
+	name  string
+	usage string
+	// parse checks the arguments, returning the function that runs the command on the started facade.
+	parse func(fs *flag.FlagSet, args []string) (func(ctx context.Context, svc *facade.Service) int, error)
+}
+
+var cliCommands = []cliCommand{
+	{name: "import", usage: "import FILE\n\tLog the QSOs of an ADIF file (- for stdin), skipping those already logged", parse: parseImport},
+	{name: "export", usage: "export [-call CALL] [-band BAND] [-mode MODE] [-from YYYYMMDD] [-to YYYYMMDD] [-o FILE]\n\tWrite the matching QSOs as ADIF, oldest first", parse: parseExport},
+	{name: "stats", usage: "stats\n\tPrint the logbook statistics as JSON", parse: parseStats},
+	{name: "lookup", usage: "lookup CALLSIGN\n\tPrint a new QSO initialized for the callsign as JSON", parse: parseLookup},
+	{name: "forward", usage: "forward [--retry-failed]\n\tUpload the QSOs waiting to be forwarded, retrying failed uploads at once if asked", parse: parseForward},
+	{name: "db", usage: "db check|maintain [--fix]|backup|backups|restore BACKUP\n\tCheck or maintain the database, back it up, list the backups or restore one", parse: parseDb},
+}
+
+// runCli runs a command of the headless command line and returns the exit code. The facade is started without the
+// GUI, CAT and listeners, on the same container as the GUI.
+func runCli(workingDir string, args []string) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		printCliUsage(os.Stdout)
+		return 0
+	}
+
+	var cmd *cliCommand
+	for i := range cliCommands {
+		if cliCommands[i].name == name {
+			cmd = &cliCommands[i]
+		}
+	}
+	if cmd == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", name)
+		printCliUsage(os.Stderr)
+		return ExitUsage
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run, err := cmd.parse(fs, args[1:])
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\nusage: %s %s\n", err, os.Args[0], cmd.usage)
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svc, code := startHeadlessFacade(ctx, workingDir)
+	if code != 0 {
+		return code
+	}
+	defer func() {
+		if err := svc.StopHeadless(); err != nil {
+			errors.PrintChain(err)
+			_, _ = fmt.Fprintf(os.Stderr, "failed to stop facade service: %v\n", errors.Root(err))
+		}
+	}()
+
+	return run(ctx, svc)
+}
+
+func printCliUsage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "usage: %s [command]\n\nWithout a command, the logger window is opened. Commands:\n\n", os.Args[0])
+	for _, cmd := range cliCommands {
+		_, _ = fmt.Fprintf(w, "  %s\n", cmd.usage)
+	}
+}
+
+// startHeadlessFacade builds the container and starts the facade for the command line.
+func startHeadlessFacade(ctx context.Context, workingDir string) (*facade.Service, int) {
+	if err := initializeContainer(workingDir); err != nil {
+		errors.PrintChain(err)
+		_, _ = fmt.Fprintf(os.Stderr, "failed to initialize container: %v\n", errors.Root(err))
+		return nil, ExitContainerInit
+	}
+
+	svc, err := getFacadeService()
+	if err != nil {
+		errors.PrintChain(err)
+		_, _ = fmt.Fprintf(os.Stderr, "failed to get facade service: %v\n", errors.Root(err))
+		return nil, ExitFacadeService
+	}
+
+	if err = svc.SetContainer(container); err != nil {
+		errors.PrintChain(err)
+		_, _ = fmt.Fprintf(os.Stderr, "failed to set container: %v\n", errors.Root(err))
+		return nil, ExitFacadeService
+	}
+
+	required, err := svc.ConfigService.RequiredConfigs()
+	if err != nil {
+		errors.PrintChain(err)
+		_, _ = fmt.Fprintf(os.Stderr, "failed to get required configs: %v\n", errors.Root(err))
+		return nil, ExitFacadeService
+	}
+	if !required.SetupComplete {
+		_, _ = fmt.Fprintf(os.Stderr, "Setup not completed. Run the logger without a command to complete it.\n")
+		return nil, ExitSetupIncomplete
+	}
+
+	if err = svc.StartHeadless(ctx); err != nil {
+		errors.PrintChain(err)
+		_, _ = fmt.Fprintf(os.Stderr, "failed to start facade service: %v\n", errors.Root(err))
+		return nil, ExitFacadeService
+	}
+
+	return svc, 0
+}
+
+// commandFailed reports the error of a command and returns ExitCommand.
+func commandFailed(what string, err error) int {
+	errors.PrintChain(err)
+	_, _ = fmt.Fprintf(os.Stderr, "failed to %s: %v\n", what, errors.Root(err))
+	return ExitCommand
+}
+
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return commandFailed("write JSON", err)
+	}
+	return 0
+}
+
+// positional parses the flags and returns the n positional arguments that must follow them.
+func positional(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != n {
+		return nil, fmt.Errorf("expected %d argument(s), got %d", n, fs.NArg())
+	}
+	return fs.Args(), nil
+}
+
+func parseImport(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	path := pos[0]
+
+	return func(_ context.Context, svc *facade.Service) int {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return commandFailed("read the ADIF file", err)
+		}
+
+		result, err := svc.ImportAdif(string(data))
+		if err != nil {
+			return commandFailed("import the ADIF file", err)
+		}
+		for _, r := range result.Rejected {
+			_, _ = fmt.Fprintf(os.Stderr, "record %d (%s): %s\n", r.Record, r.Call, r.Reason)
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "%d record(s): %d imported, %d already logged, %d rejected\n",
+			result.Records, result.Imported, result.Duplicates, len(result.Rejected))
+		if len(result.Rejected) > 0 {
+			return ExitPartial
+		}
+		return 0
+	}, nil
+}
+
+func parseExport(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	var search facade.QsoSearch
+	fs.StringVar(&search.Call, "call", "", "callsign, or a prefix ending in *")
+	fs.StringVar(&search.Band, "band", "", "band, e.g. 20m")
+	fs.StringVar(&search.Mode, "mode", "", "mode, e.g. CW")
+	fs.StringVar(&search.From, "from", "", "first date, YYYYMMDD")
+	fs.StringVar(&search.To, "to", "", "last date, YYYYMMDD")
+	out := fs.String("o", "-", "output file, - for stdout")
+	if _, err := positional(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	return func(_ context.Context, svc *facade.Service) int {
+		data, err := svc.ExportAdif(search)
+		if err != nil {
+			return commandFailed("export the QSOs", err)
+		}
+		if *out == "-" {
+			_, err = io.WriteString(os.Stdout, data)
+		} else {
+			err = os.WriteFile(*out, []byte(data), 0o644)
+		}
+		if err != nil {
+			return commandFailed("write the ADIF file", err)
+		}
+		return 0
+	}, nil
+}
+
+func parseStats(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	if _, err := positional(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	return func(_ context.Context, svc *facade.Service) int {
+		stats, err := svc.FetchLogbookStats(svc.CurrentLogbook.ID)
+		if err != nil {
+			return commandFailed("compute the logbook statistics", err)
+		}
+		return printJSON(stats)
+	}, nil
+}
+
+func parseLookup(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	pos, err := positional(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	callsign := strings.ToUpper(strings.TrimSpace(pos[0]))
+
+	return func(_ context.Context, svc *facade.Service) int {
+		result, err := svc.NewQso(callsign)
+		if err != nil {
+			return commandFailed("look up "+callsign, err)
+		}
+		return printJSON(result)
+	}, nil
+}
+
+func parseForward(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	retryFailed := fs.Bool("retry-failed", false, "retry the failed uploads now rather than after their cooldown")
+	if _, err := positional(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	return func(_ context.Context, svc *facade.Service) int {
+		result, err := svc.ForwardUploads(*retryFailed)
+		if err != nil {
+			return commandFailed("forward the QSOs", err)
+		}
+		_, _ = fmt.Fprintf(os.Stdout, "%d uploaded, %d failed\n", result.Uploaded, result.Failed)
+		if result.Failed > 0 {
+			return ExitPartial
+		}
+		return 0
+	}, nil
+}
+
+func parseDb(fs *flag.FlagSet, args []string) (func(context.Context, *facade.Service) int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected a db command")
+	}
+	cmd := args[0]
+	fix := fs.Bool("fix", false, "fix the problems found, after backing up the database")
+	want := 0
+	if cmd == "restore" {
+		want = 1
+	}
+	pos, err := positional(fs, args[1:], want)
+	if err != nil {
+		return nil, err
+	}
+	if *fix && cmd != "maintain" {
+		return nil, fmt.Errorf("-fix applies to db maintain only")
+	}
+
+	switch cmd {
+	case "check":
+		return runDbCheck, nil
+	case "maintain":
+		return func(_ context.Context, svc *facade.Service) int {
+			report, err := svc.RunMaintenance(*fix)
+			if err != nil {
+				return commandFailed("maintain the database", err)
+			}
+			if code := printJSON(report); code != 0 {
+				return code
+			}
+			if !report.Ok() {
+				return ExitDatabaseCheck
+			}
+			return 0
+		}, nil
+	case "backup":
+		return func(_ context.Context, svc *facade.Service) int {
+			info, err := svc.BackupDatabase()
+			if err != nil {
+				return commandFailed("back up the database", err)
+			}
+			_, _ = fmt.Fprintln(os.Stdout, info.Name)
+			return 0
+		}, nil
+	case "backups":
+		return func(_ context.Context, svc *facade.Service) int {
+			backups, err := svc.FetchBackups()
+			if err != nil {
+				return commandFailed("list the backups", err)
+			}
+			for _, b := range backups {
+				_, _ = fmt.Fprintf(os.Stdout, "%s\t%d\n", b.Name, b.Size)
+			}
+			return 0
+		}, nil
+	case "restore":
+		backup := pos[0]
+		return func(_ context.Context, svc *facade.Service) int {
+			if err := svc.RestoreBackup(backup); err != nil {
+				return commandFailed("restore "+backup, err)
+			}
+			return 0
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown db command: %s", cmd)
+	}
+}
+
+func runDbCheck(_ context.Context, svc *facade.Service) int {
+	check, err := svc.CheckDatabase()
+	if err != nil {
+		return commandFailed("check the database", err)
+	}
+	if check.Ok() {
+		_, _ = fmt.Fprintln(os.Stdout, "ok")
+		return 0
+	}
+	for _, problem := range check.Problems {
+		_, _ = fmt.Fprintln(os.Stdout, problem)
+	}
+	return ExitDatabaseCheck
+}
@@ -6,6 +6,11 @@ const (
 	ExitFacadeService
 	ExitPanic
 	ExitWailsRun
+	ExitUsage
+	ExitSetupIncomplete
+	ExitCommand
+	ExitPartial
+	ExitDatabaseCheck
 )
 
 const (
@@ -15,9 +15,6 @@ import (
 	"github.com/Station-Manager/logging-app/backend/facade"
 	"github.com/Station-Manager/lookup/hamnut"
 	"github.com/Station-Manager/lookup/qrz"
-
-	// Register packet handlers
-	_ "github.com/Station-Manager/listeners/handlers/wsjtx"
 )
 
 // initializeContainer initializes the dependency injection container with required services and configurations.
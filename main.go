@@ -44,6 +44,10 @@ func main() {
 		os.Exit(ExitWorkingDir)
 	}
 
+	if len(os.Args) > 1 {
+		os.Exit(runCli(workingDir, os.Args[1:]))
+	}
+
 	if err = initializeContainer(workingDir); err != nil {
 		errors.PrintChain(err)
 		_, _ = fmt.Fprintf(os.Stderr, "failed to initialize container: %v\n", errors.Root(err))
@@ -17,6 +17,9 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 )
 
+// facadeEvents is declared at package level as the facade parameter of the option builders shadows the package name.
+var facadeEvents = facade.AllEvents
+
 func setupOpts(facade *facade.Service) *options.App {
 	startup := func(ctx context.Context) {
 		defer func() {
@@ -85,6 +88,7 @@ func setupOpts(facade *facade.Service) *options.App {
 		EnumBind: []interface{}{
 			tags.AllCatStateTags,
 			events.AllEvents,
+			facadeEvents,
 		},
 		WindowStartState:                 options.Normal,
 		ErrorFormatter:                   nil,
@@ -185,6 +189,7 @@ func mainOpts(facade *facade.Service) *options.App {
 		EnumBind: []interface{}{
 			tags.AllCatStateTags,
 			events.AllEvents,
+			facadeEvents,
 		},
 		WindowStartState:                 options.Normal,
 		ErrorFormatter:                   nil,